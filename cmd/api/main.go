@@ -10,13 +10,30 @@ import (
 	"time"
 
 	"learnforge/internal/ai"
+	"learnforge/internal/analytics"
+	"learnforge/internal/attempt"
 	"learnforge/internal/cache"
 	"learnforge/internal/config"
+	"learnforge/internal/course"
+	"learnforge/internal/curation"
+	"learnforge/internal/deck"
+	"learnforge/internal/domain"
+	"learnforge/internal/exam"
+	"learnforge/internal/experiment"
+	"learnforge/internal/feed"
+	"learnforge/internal/feedback"
+	"learnforge/internal/ingest"
+	"learnforge/internal/jobs"
+	"learnforge/internal/replay"
+	"learnforge/internal/review"
 	"learnforge/internal/service"
 	"learnforge/internal/slack"
+	"learnforge/internal/srs"
 	"learnforge/internal/store"
 	"learnforge/internal/summary"
 	httptransport "learnforge/internal/transport/http"
+	"learnforge/internal/verify"
+	"learnforge/internal/webhook"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -30,7 +47,7 @@ func main() {
 
 	log.SetFlags(0)
 
-	var st store.Store
+	var st store.Backend
 	if cfg.Storage == "postgres" {
 		if cfg.DatabaseURL == "" {
 			log.Fatal("DATABASE_URL is required when STORAGE=postgres")
@@ -50,16 +67,40 @@ func main() {
 		log.Fatal("AI_API_KEY is required")
 	}
 
-	var aiClient ai.Client
+	aiClient := ai.NewClient(cfg.AIProvider, cfg.AIBaseURL, cfg.AIApiKey, cfg.AIModel)
 	if cfg.AIProvider == "gemini" {
-		aiClient = ai.NewGeminiClient(cfg.AIApiKey, cfg.AIModel)
 		log.Println(`{"level":"info","msg":"Using Gemini AI provider"}`)
 	} else {
-		aiClient = ai.NewOpenAIClient(cfg.AIBaseURL, cfg.AIApiKey, cfg.AIModel)
 		log.Println(`{"level":"info","msg":"Using OpenAI AI provider"}`)
 	}
 
-	svc := service.NewService(st, aiClient)
+	experiments, err := experiment.FromConfig(cfg, aiClient)
+	if err != nil {
+		log.Fatalf("Invalid experiment configuration: %v", err)
+	}
+	experimentManager := experiment.NewManager(st, experiments)
+	if len(experiments) > 0 {
+		log.Printf(`{"level":"info","msg":"Experiments enabled","count":%d}`, len(experiments))
+	}
+
+	newClient := func(provider, model string) ai.Client {
+		if provider == "" {
+			provider = cfg.AIProvider
+		}
+		return ai.NewClient(provider, cfg.ProviderBaseURL(provider), cfg.AIApiKey, model)
+	}
+
+	uploadLimits := ingest.UploadLimits{}
+	for format, max := range cfg.UploadLimits {
+		if _, ok := ingest.DefaultUploadLimits[ingest.Format(format)]; !ok {
+			log.Fatalf("Unknown upload format %q in upload_limits", format)
+		}
+		uploadLimits[ingest.Format(format)] = max
+	}
+
+	webhooks := webhook.NewDispatcher(st, webhook.Options{Secret: cfg.WebhookSecret})
+	webhooks.Start()
+	defer webhooks.Stop()
 
 	var cacheClient cache.Cache
 	if cfg.RedisURL != "" {
@@ -77,6 +118,51 @@ func main() {
 	}
 	defer cacheClient.Close()
 
+	cacheTTLs := service.CacheTTLs{
+		Default: time.Duration(cfg.GenerationCacheTTLSeconds) * time.Second,
+		Modes:   map[string]time.Duration{},
+	}
+	for mode, seconds := range cfg.GenerationCacheModeTTLSeconds {
+		if mode == "" || !domain.ValidateMode(mode) {
+			log.Fatalf("Unknown mode %q in generation_cache_mode_ttl_seconds", mode)
+		}
+		cacheTTLs.Modes[mode] = time.Duration(seconds) * time.Second
+	}
+
+	modelLimiter := ai.NewLimiter(cfg.AIRequestsPerMinute, cfg.AIMaxConcurrentRequests)
+	fetcher := ingest.NewFetcher(ingest.FetchOptions{MaxBytes: int64(cfg.FetchMaxBytes)})
+	svcOpts := []service.Option{
+		service.WithExperiments(experimentManager),
+		service.WithFetcher(fetcher),
+		service.WithExtractor(ingest.NewExtractor(uploadLimits)),
+		service.WithWebhooks(webhooks),
+		service.WithRateLimiter(modelLimiter),
+		service.WithIdempotencyTTL(time.Duration(cfg.IdempotencyTTLHours) * time.Hour),
+		service.WithGenerationCache(cacheClient, cacheTTLs),
+		service.WithReviewRequired(cfg.ReviewRequired),
+	}
+	scheduler, err := srs.NewScheduler(cfg.SRSScheduler)
+	if err != nil {
+		log.Fatalf("Invalid SRS_SCHEDULER: %v", err)
+	}
+	if cfg.ReviewRequired && cfg.AdminAPIKey == "" {
+		log.Fatal("REVIEW_REQUIRED needs ADMIN_API_KEY, which reviewers use to publish results")
+	}
+
+	if cfg.VerifyMode != "off" {
+		if !verify.ValidAction(cfg.VerifyMode) {
+			log.Fatal("VERIFY_MODE must be one of: off, mark, drop, regenerate")
+		}
+		completer, ok := newClient(cfg.VerifyProvider, cfg.VerifyModel).(ai.Completer)
+		if !ok {
+			log.Fatalf("Provider %s does not support answer verification", cfg.VerifyProvider)
+		}
+		svcOpts = append(svcOpts, service.WithVerifier(verify.NewVerifier(completer, cfg.VerifyMode)))
+		log.Printf(`{"level":"info","msg":"Quiz answer verification enabled","mode":"%s","model":"%s"}`, cfg.VerifyMode, cfg.VerifyModel)
+	}
+
+	svc := service.NewService(st, aiClient, svcOpts...)
+
 	var slackSummary, slackError *slack.Client
 	if cfg.SlackWebhookURL != "" {
 		slackSummary = slack.NewClient(cfg.SlackWebhookURL)
@@ -85,14 +171,40 @@ func main() {
 		slackError = slack.NewClient(cfg.SlackErrorWebhookURL)
 	}
 
-	summarySvc := summary.NewService(st, cacheClient, slackSummary, slackError)
+	itemAnalyzer := analytics.NewAnalyzer(st, st, analytics.WithMinResponses(cfg.ItemStatsMinResponses))
+	feedbackSvc := feedback.NewService(st, st,
+		feedback.WithReviewRequired(cfg.ReviewRequired),
+		feedback.WithMinDownvotes(cfg.FeedbackMinDownvotes),
+		feedback.WithExperiments(experimentManager),
+	)
+	summarySvc := summary.NewService(st, cacheClient, slackSummary, slackError,
+		summary.WithItemAnalytics(itemAnalyzer),
+		summary.WithFeedback(feedbackSvc),
+	)
 	summaryScheduler := summary.NewScheduler(summarySvc)
 	if slackSummary != nil {
 		summaryScheduler.Start()
 		defer summaryScheduler.Stop()
 	}
 
-	handler := httptransport.NewHandler(svc, summarySvc)
+	feedManager := feed.NewManager(svc, fetcher, st, slackSummary)
+	if err := feedManager.Start(context.Background()); err != nil {
+		log.Printf(`{"level":"error","msg":"Failed to start feed scheduler","error":"%v"}`, err)
+	} else {
+		defer feedManager.Stop()
+	}
+
+	jobPool := jobs.NewPool(svc, st, jobs.Config{
+		Workers:           cfg.JobWorkers,
+		MaxAttempts:       cfg.JobMaxAttempts,
+		JobTimeout:        time.Duration(cfg.JobTimeoutSeconds) * time.Second,
+		VisibilityTimeout: time.Duration(cfg.JobVisibilityTimeoutSeconds) * time.Second,
+		MaxBatchItems:     cfg.BatchMaxItems,
+	}, jobs.WithWebhooks(webhooks))
+	jobPool.Start()
+	defer jobPool.Stop()
+
+	handler := httptransport.NewHandler(svc, summarySvc, cfg.AdminAPIKey)
 
 	r := chi.NewRouter()
 	r.Use(httptransport.RequestIDMiddleware)
@@ -100,12 +212,42 @@ func main() {
 	r.Use(httptransport.MetricsMiddleware)
 
 	handler.RegisterRoutes(r)
+	httptransport.NewJobHandler(jobPool, svc, cfg.AdminAPIKey).RegisterRoutes(r)
+	httptransport.NewBatchHandler(jobPool, svc).RegisterRoutes(r)
+	httptransport.NewCurationHandler(curation.NewEditor(st, st, curation.WithWebhooks(webhooks)), cfg.AdminAPIKey).RegisterRoutes(r)
 
 	if cfg.SummaryAPIKey != "" {
 		summaryHandler := httptransport.NewSummaryHandler(summarySvc, cfg.SummaryAPIKey)
 		summaryHandler.RegisterRoutes(r)
 	}
 
+	courseBuilder := course.NewBuilder(svc, st)
+	httptransport.NewCourseHandler(courseBuilder, st, cfg.AdminAPIKey, cfg.DocsRoot).RegisterRoutes(r)
+	httptransport.NewDeckHandler(deck.NewManager(st, st, deck.WithReviewRequired(cfg.ReviewRequired), deck.WithFeedback(feedbackSvc)), cfg.AdminAPIKey).RegisterRoutes(r)
+	recorder := attempt.NewRecorder(st, st, attempt.WithReviewRequired(cfg.ReviewRequired), attempt.WithExperiments(experimentManager))
+	httptransport.NewAttemptHandler(recorder, cfg.AdminAPIKey).RegisterRoutes(r)
+	examBuilder := exam.NewBuilder(st, st, exam.WithReviewRequired(cfg.ReviewRequired), exam.WithFeedback(feedbackSvc), exam.WithItemStats(itemAnalyzer))
+	httptransport.NewExamHandler(examBuilder, recorder, cfg.AdminAPIKey).RegisterRoutes(r)
+	httptransport.NewSRSHandler(srs.NewService(st, st, st, scheduler, srs.WithReviewRequired(cfg.ReviewRequired), srs.WithFeedback(feedbackSvc)), cfg.AdminAPIKey).RegisterRoutes(r)
+	httptransport.NewFeedbackHandler(feedbackSvc, cfg.AdminAPIKey).RegisterRoutes(r)
+
+	if cfg.AdminAPIKey != "" {
+		replays := replay.NewRuns()
+		defer replays.Stop()
+		adminHandler := httptransport.NewAdminHandler(st, newClient, modelLimiter, replays, cfg.AdminAPIKey)
+		adminHandler.RegisterRoutes(r)
+		httptransport.NewFeedHandler(feedManager, cfg.AdminAPIKey).RegisterRoutes(r)
+		httptransport.NewWebhookHandler(webhooks, cfg.AdminAPIKey).RegisterRoutes(r)
+		httptransport.NewAnalyticsHandler(itemAnalyzer, cfg.AdminAPIKey).RegisterRoutes(r)
+		httptransport.NewExperimentHandler(experimentManager, cfg.AdminAPIKey).RegisterRoutes(r)
+
+		var slackReview *slack.Client
+		if cfg.SlackReviewWebhookURL != "" {
+			slackReview = slack.NewClient(cfg.SlackReviewWebhookURL)
+		}
+		httptransport.NewReviewHandler(review.NewWorkflow(st, st, slackReview), cfg.AdminAPIKey).RegisterRoutes(r)
+	}
+
 	handler.RegisterWebRoutes(r)
 
 	r.Handle("/metrics", promhttp.Handler())
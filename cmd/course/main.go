@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/config"
+	"learnforge/internal/course"
+	"learnforge/internal/service"
+	"learnforge/internal/store"
+)
+
+func main() {
+	dir := flag.String("dir", "", "directory of Markdown/AsciiDoc documents (required)")
+	name := flag.String("name", "", "course name (defaults to the directory name)")
+	id := flag.String("id", "", "course ID (defaults to a slug of the name)")
+	level := flag.String("level", "", "lesson level: beginner, intermediate or advanced")
+	language := flag.String("language", "", "lesson language (defaults to en)")
+	force := flag.Bool("force", false, "regenerate every document, even if unchanged")
+	flag.Parse()
+
+	log.SetFlags(0)
+
+	if *dir == "" {
+		log.Fatal("-dir is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Storage != "postgres" || cfg.DatabaseURL == "" {
+		log.Fatal("Courses are stored with their lessons and require STORAGE=postgres with DATABASE_URL")
+	}
+	if cfg.AIApiKey == "" {
+		log.Fatal("AI_API_KEY is required")
+	}
+
+	st, err := store.NewPostgresStore(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer st.Close()
+
+	aiClient := ai.NewClient(cfg.AIProvider, cfg.AIBaseURL, cfg.AIApiKey, cfg.AIModel)
+	svc := service.NewService(st, aiClient)
+
+	c, err := course.NewBuilder(svc, st).Build(context.Background(), course.Options{
+		Dir:      *dir,
+		ID:       *id,
+		Name:     *name,
+		Level:    *level,
+		Language: *language,
+		Force:    *force,
+	})
+	if err != nil {
+		log.Fatalf("Course build failed: %v", err)
+	}
+
+	log.Printf("Course %q (%s): %d generated, %d unchanged, %d removed, %d failed",
+		c.Name, c.ID, len(c.LastBuild.Generated), len(c.LastBuild.Unchanged), len(c.LastBuild.Removed), len(c.LastBuild.Failed))
+	for _, failure := range c.LastBuild.Failed {
+		log.Printf("  failed: %s", failure)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c); err != nil {
+		log.Fatalf("Failed to encode course: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/config"
+	"learnforge/internal/eval"
+)
+
+func main() {
+	datasetPath := flag.String("dataset", "eval/golden.json", "path to the golden dataset")
+	outPath := flag.String("out", "eval-report.json", "where to write the JSON report")
+	markdownPath := flag.String("markdown", "", "where to write the Markdown report (stdout if empty)")
+	baselinePath := flag.String("baseline", "", "previous JSON report to compare against")
+	threshold := flag.Float64("threshold", 0.05, "score drop that counts as a regression")
+	provider := flag.String("provider", "", "AI provider override (openai or gemini)")
+	model := flag.String("model", "", "AI model override")
+	instructions := flag.String("instructions", "", "extra prompt instructions to evaluate")
+	useJudge := flag.Bool("judge", false, "score outputs with an LLM-as-judge rubric")
+	judgeModel := flag.String("judge-model", "", "model used for judging (defaults to the generation model)")
+	flag.Parse()
+
+	log.SetFlags(0)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.AIApiKey == "" {
+		log.Fatal("AI_API_KEY is required")
+	}
+
+	if *provider != "" {
+		cfg.AIProvider = *provider
+	}
+	if *model != "" {
+		cfg.AIModel = *model
+	}
+
+	ds, err := eval.LoadDataset(*datasetPath)
+	if err != nil {
+		log.Fatalf("Failed to load dataset: %v", err)
+	}
+
+	client := ai.NewClient(cfg.AIProvider, cfg.AIBaseURL, cfg.AIApiKey, cfg.AIModel)
+
+	var judge *eval.Judge
+	if *useJudge {
+		judgeClient := client
+		if *judgeModel != "" {
+			judgeClient = ai.NewClient(cfg.AIProvider, cfg.AIBaseURL, cfg.AIApiKey, *judgeModel)
+		}
+		completer, ok := judgeClient.(ai.Completer)
+		if !ok {
+			log.Fatalf("Provider %s does not support judging", cfg.AIProvider)
+		}
+		judge = eval.NewJudge(completer)
+	}
+
+	report := eval.NewRunner(client, judge, *instructions).Run(context.Background(), ds)
+	if report.Provider == "" {
+		report.Provider = cfg.AIProvider
+		report.Model = cfg.AIModel
+	}
+
+	if err := report.WriteJSON(*outPath); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	markdown := report.Markdown()
+
+	var comparison *eval.Comparison
+	if *baselinePath != "" {
+		baseline, err := eval.LoadReport(*baselinePath)
+		if err != nil {
+			log.Fatalf("Failed to load baseline: %v", err)
+		}
+		comparison = eval.Compare(baseline, report, *threshold)
+		markdown += comparison.Markdown()
+	}
+
+	if *markdownPath != "" {
+		if err := os.WriteFile(*markdownPath, []byte(markdown), 0o644); err != nil {
+			log.Fatalf("Failed to write Markdown report: %v", err)
+		}
+	} else {
+		os.Stdout.WriteString(markdown)
+	}
+
+	if comparison != nil && len(comparison.Regressions) > 0 {
+		log.Printf("%d regression(s) against baseline", len(comparison.Regressions))
+		os.Exit(1)
+	}
+}
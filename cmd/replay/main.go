@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/config"
+	"learnforge/internal/replay"
+	"learnforge/internal/store"
+)
+
+func main() {
+	startStr := flag.String("start", "", "start date (YYYY-MM-DD, defaults to 7 days ago)")
+	endStr := flag.String("end", "", "end date, exclusive (YYYY-MM-DD, defaults to now)")
+	topic := flag.String("topic", "", "only replay results with this topic")
+	limit := flag.Int("limit", 20, "maximum number of requests to replay")
+	provider := flag.String("provider", "", "candidate provider (defaults to AI_PROVIDER)")
+	model := flag.String("model", "", "candidate model (required)")
+	promptVersion := flag.String("prompt-version", "", "label for the candidate prompt")
+	instructions := flag.String("instructions", "", "extra prompt instructions for the candidate")
+	inputCost := flag.Float64("input-cost", 0, "candidate USD per 1K input tokens")
+	outputCost := flag.Float64("output-cost", 0, "candidate USD per 1K output tokens")
+	baselineInputCost := flag.Float64("baseline-input-cost", 0, "production USD per 1K input tokens")
+	baselineOutputCost := flag.Float64("baseline-output-cost", 0, "production USD per 1K output tokens")
+	outPath := flag.String("out", "replay-report.json", "where to write the JSON report")
+	markdownPath := flag.String("markdown", "", "where to write the Markdown report (stdout if empty)")
+	flag.Parse()
+
+	log.SetFlags(0)
+
+	if *model == "" {
+		log.Fatal("-model is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Storage != "postgres" || cfg.DatabaseURL == "" {
+		log.Fatal("Replay reads stored results and requires STORAGE=postgres with DATABASE_URL")
+	}
+	if cfg.AIApiKey == "" {
+		log.Fatal("AI_API_KEY is required")
+	}
+
+	st, err := store.NewPostgresStore(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer st.Close()
+
+	opts := replay.Options{
+		Topic: *topic,
+		Limit: *limit,
+		BaselinePricing: replay.Pricing{
+			InputCostPer1K:  *baselineInputCost,
+			OutputCostPer1K: *baselineOutputCost,
+		},
+	}
+	if *startStr != "" {
+		if opts.Start, err = time.Parse("2006-01-02", *startStr); err != nil {
+			log.Fatalf("Invalid -start: %v", err)
+		}
+	}
+	if *endStr != "" {
+		if opts.End, err = time.Parse("2006-01-02", *endStr); err != nil {
+			log.Fatalf("Invalid -end: %v", err)
+		}
+	}
+
+	candidateProvider := *provider
+	if candidateProvider == "" {
+		candidateProvider = cfg.AIProvider
+	}
+	candidate := replay.Candidate{
+		Provider:      candidateProvider,
+		Model:         *model,
+		PromptVersion: *promptVersion,
+		Instructions:  *instructions,
+		Pricing: replay.Pricing{
+			InputCostPer1K:  *inputCost,
+			OutputCostPer1K: *outputCost,
+		},
+	}
+	client := ai.NewClient(candidateProvider, cfg.ProviderBaseURL(candidateProvider), cfg.AIApiKey, *model)
+
+	report, err := replay.NewRunner(st, client, candidate,
+		replay.WithLimiter(ai.NewLimiter(cfg.AIRequestsPerMinute, cfg.AIMaxConcurrentRequests)),
+	).Run(context.Background(), opts)
+	if err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode report: %v", err)
+	}
+	if err := os.WriteFile(*outPath, data, 0o644); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	if *markdownPath != "" {
+		if err := os.WriteFile(*markdownPath, []byte(report.Markdown()), 0o644); err != nil {
+			log.Fatalf("Failed to write Markdown report: %v", err)
+		}
+	} else {
+		os.Stdout.WriteString(report.Markdown())
+	}
+}
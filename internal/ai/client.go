@@ -11,10 +11,39 @@ type Client interface {
 	GenerateMeme(ctx context.Context, topic, question string) (string, error)
 }
 
+// Completer is implemented by clients that can answer free-form prompts
+// with a JSON object. It is used for judging and verification passes.
+type Completer interface {
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// ModelNamer is implemented by clients that know which model they call.
+type ModelNamer interface {
+	ModelName() string
+}
+
+// ModelName returns the model c calls, or "" if c does not say.
+func ModelName(c Client) string {
+	if namer, ok := c.(ModelNamer); ok {
+		return namer.ModelName()
+	}
+	return ""
+}
+
 type ProcessRequest struct {
-	Text     string
-	Mode     string // lesson, flashcards, quiz
-	Topic    *string
-	Level    *string
-	Language string
+	Text          string
+	Mode          string // lesson, flashcards, quiz
+	Topic         *string
+	Level         *string
+	Language      string
+	PromptVersion string // label recorded in Meta; defaults to DefaultPromptVersion
+	Instructions  string // extra guidance appended to the prompt
+}
+
+// NewClient creates a client for the given provider ("openai" or "gemini").
+func NewClient(provider, baseURL, apiKey, model string) Client {
+	if provider == "gemini" {
+		return NewGeminiClient(apiKey, model)
+	}
+	return NewOpenAIClient(baseURL, apiKey, model)
 }
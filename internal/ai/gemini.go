@@ -33,8 +33,12 @@ func NewGeminiClient(apiKey, model string) *GeminiClient {
 	}
 }
 
+func (c *GeminiClient) ModelName() string {
+	return c.model
+}
+
 func (c *GeminiClient) ProcessText(ctx context.Context, req *ProcessRequest) (*domain.ProcessResponse, error) {
-	prompt := c.buildPrompt(req)
+	prompt := buildPrompt(req)
 	apiReq := c.createAPIRequest(prompt)
 
 	var resp *domain.ProcessResponse
@@ -61,53 +65,6 @@ func (c *GeminiClient) ProcessText(ctx context.Context, req *ProcessRequest) (*d
 	return resp, nil
 }
 
-func (c *GeminiClient) buildPrompt(req *ProcessRequest) string {
-	var promptBuilder bytes.Buffer
-
-	promptBuilder.WriteString("You are an educational content generator. Process the following text and create structured learning content.\n\n")
-	promptBuilder.WriteString("Text to process:\n")
-	promptBuilder.WriteString(req.Text)
-	promptBuilder.WriteString("\n\n")
-
-	switch req.Mode {
-	case "flashcards":
-		promptBuilder.WriteString("Generate flashcards (question-answer pairs) from this text.\n")
-	case "quiz":
-		promptBuilder.WriteString("Generate quiz questions with multiple choice answers from this text.\n")
-	default:
-		promptBuilder.WriteString("Generate a comprehensive lesson with summary, key points, flashcards, and quiz questions.\n")
-	}
-
-	if req.Topic != nil {
-		promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n", *req.Topic))
-	} else {
-		promptBuilder.WriteString("Infer the topic from the text and provide your confidence (0.0-1.0).\n")
-	}
-
-	if req.Level != nil {
-		promptBuilder.WriteString(fmt.Sprintf("Difficulty level: %s\n", *req.Level))
-	}
-
-	if req.Language != "" && req.Language != "en" {
-		promptBuilder.WriteString(fmt.Sprintf("Language: %s\n", req.Language))
-	}
-
-	promptBuilder.WriteString("\n")
-	promptBuilder.WriteString("IMPORTANT: Respond ONLY with valid JSON matching this exact schema:\n")
-	promptBuilder.WriteString(`{
-  "topic": "string",
-  "topic_source": "user" or "inferred",
-  "topic_confidence": 0.0-1.0,
-  "summary": "string",
-  "key_points": ["string"],
-  "flashcards": [{"q": "string", "a": "string"}],
-  "quiz": [{"q": "string", "choices": ["string"], "answer": "string"}]
-}`)
-	promptBuilder.WriteString("\n\nDo not include any text outside the JSON. Return only the JSON object.")
-
-	return promptBuilder.String()
-}
-
 func (c *GeminiClient) createAPIRequest(prompt string) map[string]interface{} {
 	return map[string]interface{}{
 		"contents": []map[string]interface{}{
@@ -126,7 +83,49 @@ func (c *GeminiClient) createAPIRequest(prompt string) map[string]interface{} {
 	}
 }
 
-func (c *GeminiClient) makeRequest(ctx context.Context, apiReq map[string]interface{}) (*domain.ProcessResponse, error) {
+// Complete sends a free-form prompt and returns the model's JSON reply.
+func (c *GeminiClient) Complete(ctx context.Context, prompt string) (string, error) {
+	apiReq := c.createAPIRequest(prompt)
+	var resp *geminiResponse
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			time.Sleep(500 * time.Millisecond)
+		}
+
+		resp, err = c.generate(ctx, apiReq)
+		if err == nil {
+			break
+		}
+
+		if !isRetryableError(err) {
+			return "", err
+		}
+	}
+
+	if err != nil {
+		return "", domain.NewDomainError(domain.ErrorCodeUpstreamError, "failed to complete prompt with Gemini", err)
+	}
+
+	return resp.Candidates[0].Content.Parts[0].Text, nil
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content struct {
+			Parts []struct {
+				Text string `json:"text"`
+			} `json:"parts"`
+		} `json:"content"`
+	} `json:"candidates"`
+	Model         string `json:"model,omitempty"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+func (c *GeminiClient) generate(ctx context.Context, apiReq map[string]interface{}) (*geminiResponse, error) {
 	url := fmt.Sprintf("%s/v1beta/models/%s:generateContent?key=%s", c.baseURL, c.model, c.apiKey)
 
 	reqBody, err := json.Marshal(apiReq)
@@ -152,17 +151,7 @@ func (c *GeminiClient) makeRequest(ctx context.Context, apiReq map[string]interf
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var apiResp struct {
-		Candidates []struct {
-			Content struct {
-				Parts []struct {
-					Text string `json:"text"`
-				} `json:"parts"`
-			} `json:"content"`
-		} `json:"candidates"`
-		Model string `json:"model,omitempty"`
-	}
-
+	var apiResp geminiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
@@ -171,6 +160,15 @@ func (c *GeminiClient) makeRequest(ctx context.Context, apiReq map[string]interf
 		return nil, fmt.Errorf("no content in response")
 	}
 
+	return &apiResp, nil
+}
+
+func (c *GeminiClient) makeRequest(ctx context.Context, apiReq map[string]interface{}) (*domain.ProcessResponse, error) {
+	apiResp, err := c.generate(ctx, apiReq)
+	if err != nil {
+		return nil, err
+	}
+
 	var content struct {
 		Topic           string             `json:"topic"`
 		TopicSource     string             `json:"topic_source"`
@@ -211,8 +209,10 @@ func (c *GeminiClient) makeRequest(ctx context.Context, apiReq map[string]interf
 		Flashcards:      content.Flashcards,
 		Quiz:            content.Quiz,
 		Meta: domain.Meta{
-			Model:    modelName,
-			Provider: "gemini",
+			Model:            modelName,
+			Provider:         "gemini",
+			PromptTokens:     apiResp.UsageMetadata.PromptTokenCount,
+			CompletionTokens: apiResp.UsageMetadata.CandidatesTokenCount,
 		},
 		CreatedAt: time.Now(),
 	}
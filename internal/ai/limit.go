@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+// Limiter bounds outbound model calls by rate and by concurrency, so bulk
+// work stays within the provider's quota. A nil *Limiter does not limit.
+type Limiter struct {
+	slots chan struct{} // nil when concurrency is unlimited
+
+	mu       sync.Mutex
+	interval time.Duration // between calls; zero when the rate is unlimited
+	next     time.Time     // earliest start of the next call
+}
+
+// NewLimiter allows requestsPerMinute calls per minute with at most
+// maxConcurrent in flight. Zero disables either limit; with both zero it
+// returns nil.
+func NewLimiter(requestsPerMinute, maxConcurrent int) *Limiter {
+	if requestsPerMinute <= 0 && maxConcurrent <= 0 {
+		return nil
+	}
+	l := &Limiter{}
+	if requestsPerMinute > 0 {
+		l.interval = time.Minute / time.Duration(requestsPerMinute)
+	}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// Acquire waits for a call slot. The caller must call release once the
+// call returns. Waiting is bounded by ctx.
+func (l *Limiter) Acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, rateLimitError(ctx)
+		}
+	}
+	release = func() {
+		if l.slots != nil {
+			<-l.slots
+		}
+	}
+
+	if wait := l.reserve(); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, rateLimitError(ctx)
+		}
+	}
+	return release, nil
+}
+
+// reserve claims the next start time and returns how long to wait for it.
+// Calls are spaced evenly rather than allowed in bursts.
+func (l *Limiter) reserve() time.Duration {
+	if l.interval == 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	return wait
+}
+
+func rateLimitError(ctx context.Context) error {
+	return domain.NewDomainError(domain.ErrorCodeUpstreamTimeout, "timed out waiting for the model rate limit", ctx.Err())
+}
@@ -30,8 +30,12 @@ func NewOpenAIClient(baseURL, apiKey, model string) *OpenAIClient {
 	}
 }
 
+func (c *OpenAIClient) ModelName() string {
+	return c.model
+}
+
 func (c *OpenAIClient) ProcessText(ctx context.Context, req *ProcessRequest) (*domain.ProcessResponse, error) {
-	prompt := c.buildPrompt(req)
+	prompt := buildPrompt(req)
 	apiReq := c.createAPIRequest(prompt)
 	var resp *domain.ProcessResponse
 	var err error
@@ -57,53 +61,6 @@ func (c *OpenAIClient) ProcessText(ctx context.Context, req *ProcessRequest) (*d
 	return resp, nil
 }
 
-func (c *OpenAIClient) buildPrompt(req *ProcessRequest) string {
-	var promptBuilder bytes.Buffer
-
-	promptBuilder.WriteString("You are an educational content generator. Process the following text and create structured learning content.\n\n")
-	promptBuilder.WriteString("Text to process:\n")
-	promptBuilder.WriteString(req.Text)
-	promptBuilder.WriteString("\n\n")
-
-	switch req.Mode {
-	case "flashcards":
-		promptBuilder.WriteString("Generate flashcards (question-answer pairs) from this text.\n")
-	case "quiz":
-		promptBuilder.WriteString("Generate quiz questions with multiple choice answers from this text.\n")
-	default:
-		promptBuilder.WriteString("Generate a comprehensive lesson with summary, key points, flashcards, and quiz questions.\n")
-	}
-
-	if req.Topic != nil {
-		promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n", *req.Topic))
-	} else {
-		promptBuilder.WriteString("Infer the topic from the text and provide your confidence (0.0-1.0).\n")
-	}
-
-	if req.Level != nil {
-		promptBuilder.WriteString(fmt.Sprintf("Difficulty level: %s\n", *req.Level))
-	}
-
-	if req.Language != "" && req.Language != "en" {
-		promptBuilder.WriteString(fmt.Sprintf("Language: %s\n", req.Language))
-	}
-
-	promptBuilder.WriteString("\n")
-	promptBuilder.WriteString("IMPORTANT: Respond ONLY with valid JSON matching this exact schema:\n")
-	promptBuilder.WriteString(`{
-  "topic": "string",
-  "topic_source": "user" or "inferred",
-  "topic_confidence": 0.0-1.0,
-  "summary": "string",
-  "key_points": ["string"],
-  "flashcards": [{"q": "string", "a": "string"}],
-  "quiz": [{"q": "string", "choices": ["string"], "answer": "string"}]
-}`)
-	promptBuilder.WriteString("\n\nDo not include any text outside the JSON. Return only the JSON object.")
-
-	return promptBuilder.String()
-}
-
 func (c *OpenAIClient) createAPIRequest(prompt string) map[string]interface{} {
 	return map[string]interface{}{
 		"model": c.model,
@@ -120,7 +77,47 @@ func (c *OpenAIClient) createAPIRequest(prompt string) map[string]interface{} {
 	}
 }
 
-func (c *OpenAIClient) makeRequest(ctx context.Context, apiReq map[string]interface{}) (*domain.ProcessResponse, error) {
+// Complete sends a free-form prompt and returns the model's JSON reply.
+func (c *OpenAIClient) Complete(ctx context.Context, prompt string) (string, error) {
+	apiReq := c.createAPIRequest(prompt)
+	var resp *openAIChatResponse
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			time.Sleep(500 * time.Millisecond)
+		}
+
+		resp, err = c.chat(ctx, apiReq)
+		if err == nil {
+			break
+		}
+
+		if !isRetryableError(err) {
+			return "", err
+		}
+	}
+
+	if err != nil {
+		return "", domain.NewDomainError(domain.ErrorCodeUpstreamError, "failed to complete prompt with AI", err)
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+	Model string `json:"model"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+func (c *OpenAIClient) chat(ctx context.Context, apiReq map[string]interface{}) (*openAIChatResponse, error) {
 	reqBody, err := json.Marshal(apiReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -145,15 +142,7 @@ func (c *OpenAIClient) makeRequest(ctx context.Context, apiReq map[string]interf
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var apiResp struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-		Model string `json:"model"`
-	}
-
+	var apiResp openAIChatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
@@ -162,6 +151,15 @@ func (c *OpenAIClient) makeRequest(ctx context.Context, apiReq map[string]interf
 		return nil, fmt.Errorf("no choices in response")
 	}
 
+	return &apiResp, nil
+}
+
+func (c *OpenAIClient) makeRequest(ctx context.Context, apiReq map[string]interface{}) (*domain.ProcessResponse, error) {
+	apiResp, err := c.chat(ctx, apiReq)
+	if err != nil {
+		return nil, err
+	}
+
 	var content struct {
 		Topic           string             `json:"topic"`
 		TopicSource     string             `json:"topic_source"`
@@ -196,8 +194,10 @@ func (c *OpenAIClient) makeRequest(ctx context.Context, apiReq map[string]interf
 		Flashcards:      content.Flashcards,
 		Quiz:            content.Quiz,
 		Meta: domain.Meta{
-			Model:    apiResp.Model,
-			Provider: "openai-compatible",
+			Model:            apiResp.Model,
+			Provider:         "openai-compatible",
+			PromptTokens:     apiResp.Usage.PromptTokens,
+			CompletionTokens: apiResp.Usage.CompletionTokens,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -229,10 +229,10 @@ func (c *OpenAIClient) generateMemeDALLE(ctx context.Context, topic, question st
 	}
 
 	apiReq := map[string]interface{}{
-		"model":  "dall-e-3",
-		"prompt": prompt,
-		"n":      1,
-		"size":   "1024x1024",
+		"model":   "dall-e-3",
+		"prompt":  prompt,
+		"n":       1,
+		"size":    "1024x1024",
 		"quality": "standard", // Use standard quality for faster generation
 	}
 
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DefaultPromptVersion identifies the built-in prompt template.
+const DefaultPromptVersion = "v2"
+
+func buildPrompt(req *ProcessRequest) string {
+	var promptBuilder bytes.Buffer
+
+	promptBuilder.WriteString("You are an educational content generator. Process the following text and create structured learning content.\n\n")
+	promptBuilder.WriteString("Text to process:\n")
+	promptBuilder.WriteString(req.Text)
+	promptBuilder.WriteString("\n\n")
+
+	switch req.Mode {
+	case "flashcards":
+		promptBuilder.WriteString("Generate flashcards (question-answer pairs) from this text.\n")
+	case "quiz":
+		promptBuilder.WriteString("Generate quiz questions with multiple choice answers from this text.\n")
+	default:
+		promptBuilder.WriteString("Generate a comprehensive lesson with summary, key points, flashcards, and quiz questions.\n")
+	}
+
+	if req.Topic != nil {
+		promptBuilder.WriteString(fmt.Sprintf("Topic: %s\n", *req.Topic))
+	} else {
+		promptBuilder.WriteString("Infer the topic from the text and provide your confidence (0.0-1.0).\n")
+	}
+
+	if req.Level != nil {
+		promptBuilder.WriteString(fmt.Sprintf("Difficulty level: %s\n", *req.Level))
+	}
+
+	if req.Language != "" && req.Language != "en" {
+		promptBuilder.WriteString(fmt.Sprintf("Language: %s\n", req.Language))
+	}
+
+	if req.Instructions != "" {
+		promptBuilder.WriteString("\nAdditional instructions:\n")
+		promptBuilder.WriteString(req.Instructions)
+		promptBuilder.WriteString("\n")
+	}
+
+	promptBuilder.WriteString("\n")
+	promptBuilder.WriteString("IMPORTANT: Respond ONLY with valid JSON matching this exact schema:\n")
+	promptBuilder.WriteString(`{
+  "topic": "string",
+  "topic_source": "user" or "inferred",
+  "topic_confidence": 0.0-1.0,
+  "summary": "string",
+  "key_points": ["string"],
+  "flashcards": [{"q": "string", "a": "string"}],
+  "quiz": [{"q": "string", "choices": ["string"], "answer": "string", "explanation": "one sentence on why the answer is right"}]
+}`)
+	promptBuilder.WriteString("\n\nDo not include any text outside the JSON. Return only the JSON object.")
+
+	return promptBuilder.String()
+}
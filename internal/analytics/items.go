@@ -0,0 +1,301 @@
+// Package analytics computes classical item statistics of quiz questions
+// from recorded attempts and flags the ones that do not work: too easy,
+// too hard, keyed wrong or with distractors nobody picks.
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+// Flag thresholds
+const (
+	DefaultMinResponses = 20
+
+	tooEasyPValue    = 0.95
+	tooHardPValue    = 0.25 // around guessing with four choices
+	minDistractorUse = 0.05
+)
+
+// Analyzer computes item statistics.
+type Analyzer struct {
+	attempts     store.AttemptStore
+	results      store.Store
+	minResponses int
+}
+
+// Option configures an Analyzer.
+type Option func(*Analyzer)
+
+// WithMinResponses sets how many responses an item needs before it is
+// flagged.
+func WithMinResponses(n int) Option {
+	return func(a *Analyzer) {
+		if n > 0 {
+			a.minResponses = n
+		}
+	}
+}
+
+func NewAnalyzer(attempts store.AttemptStore, results store.Store, opts ...Option) *Analyzer {
+	a := &Analyzer{
+		attempts:     attempts,
+		results:      results,
+		minResponses: DefaultMinResponses,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// QuizStats returns the statistics of every item of result id's quiz.
+func (a *Analyzer) QuizStats(ctx context.Context, id string) (*domain.QuizStats, error) {
+	stored, err := a.results.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var resp domain.ProcessResponse
+	if err := json.Unmarshal(stored.ResponseJSON, &resp); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+	}
+	attempts, err := a.attempts.ListResultAttempts(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	first := firstAttempts(attempts)
+	stats := &domain.QuizStats{
+		ResultID: id,
+		Topic:    resp.Topic,
+		Learners: len(first),
+		Items:    make([]domain.ItemStats, 0, len(resp.Quiz)),
+	}
+	for _, item := range resp.Quiz {
+		stats.Items = append(stats.Items, a.itemStats(id, resp.Topic, item, first))
+	}
+	return stats, nil
+}
+
+// Flagged returns the flagged items of results attempted in [from, to),
+// worst first.
+func (a *Analyzer) Flagged(ctx context.Context, from, to time.Time) ([]domain.ItemStats, error) {
+	ids, err := a.attempts.ListAttemptedResults(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	flagged := []domain.ItemStats{}
+	for _, id := range ids {
+		stats, err := a.QuizStats(ctx, id)
+		if domain.HasCode(err, domain.ErrorCodeNotFound) {
+			continue // result deleted since
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range stats.Items {
+			if len(item.Flags) > 0 {
+				flagged = append(flagged, item)
+			}
+		}
+	}
+	sort.SliceStable(flagged, func(i, j int) bool {
+		if len(flagged[i].Flags) != len(flagged[j].Flags) {
+			return len(flagged[i].Flags) > len(flagged[j].Flags)
+		}
+		return flagged[i].Responses > flagged[j].Responses
+	})
+	return flagged, nil
+}
+
+// PValues returns the p-values of the quiz items of resultIDs that have
+// enough responses to be flagged. Items with fewer are left out, as are
+// deleted results.
+func (a *Analyzer) PValues(ctx context.Context, resultIDs []string) (map[domain.ExamItemRef]float64, error) {
+	pValues := make(map[domain.ExamItemRef]float64)
+	for _, id := range resultIDs {
+		stats, err := a.QuizStats(ctx, id)
+		if domain.HasCode(err, domain.ErrorCodeNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range stats.Items {
+			if item.Responses >= a.minResponses {
+				pValues[domain.ExamItemRef{ResultID: id, ItemID: item.ItemID}] = item.PValue
+			}
+		}
+	}
+	return pValues, nil
+}
+
+// response is one learner's answer to an item with their score on the
+// rest of the quiz.
+type response struct {
+	answer  domain.AttemptAnswer
+	restSum int
+}
+
+func (a *Analyzer) itemStats(resultID, topic string, item domain.QuizItem, attempts []*domain.Attempt) domain.ItemStats {
+	stats := domain.ItemStats{
+		ResultID: resultID,
+		ItemID:   item.ID,
+		Topic:    topic,
+		Question: item.Q,
+	}
+
+	// Responses scored against another answer key, from before the item
+	// was corrected, would skew every statistic.
+	var responses []response
+	for _, attempt := range attempts {
+		for _, ans := range attempt.Answers {
+			if ans.ItemID != item.ID || !domain.SameChoice(ans.CorrectAnswer, item.Answer) {
+				continue
+			}
+			rest := attempt.Correct
+			if ans.Correct {
+				rest--
+			}
+			responses = append(responses, response{answer: ans, restSum: rest})
+		}
+	}
+
+	stats.Choices = make([]domain.ChoiceStats, len(item.Choices))
+	for i, choice := range item.Choices {
+		stats.Choices[i] = domain.ChoiceStats{Choice: choice, Correct: domain.SameChoice(choice, item.Answer)}
+	}
+	stats.Responses = len(responses)
+	if stats.Responses == 0 {
+		return stats
+	}
+
+	correct, answered := 0, 0
+	var times []int64
+	for _, r := range responses {
+		if r.answer.Correct {
+			correct++
+		}
+		if r.answer.Skipped {
+			stats.Skipped++
+			continue
+		}
+		answered++
+		for i, choice := range item.Choices {
+			if domain.SameChoice(r.answer.Answer, choice) {
+				stats.Choices[i].Count++
+				break
+			}
+		}
+		if r.answer.TimeMS > 0 {
+			times = append(times, r.answer.TimeMS)
+		}
+	}
+	stats.PValue = float64(correct) / float64(stats.Responses)
+	stats.Discrimination = pointBiserial(responses)
+	if answered > 0 {
+		for i := range stats.Choices {
+			stats.Choices[i].Rate = float64(stats.Choices[i].Count) / float64(answered)
+		}
+	}
+	stats.MedianTimeMS, stats.MeanTimeMS = timing(times)
+
+	if stats.Responses >= a.minResponses {
+		stats.Flags = flags(stats)
+	}
+	return stats
+}
+
+func flags(stats domain.ItemStats) []string {
+	var flags []string
+	switch {
+	case stats.PValue > tooEasyPValue:
+		flags = append(flags, domain.FlagTooEasy)
+	case stats.PValue < tooHardPValue:
+		flags = append(flags, domain.FlagTooHard)
+	}
+	if stats.Discrimination != nil && *stats.Discrimination < 0 {
+		flags = append(flags, domain.FlagNegativeDiscrimination)
+	}
+	for _, choice := range stats.Choices {
+		if !choice.Correct && choice.Rate < minDistractorUse {
+			flags = append(flags, domain.FlagNonFunctioningDistractor)
+			break
+		}
+	}
+	return flags
+}
+
+// pointBiserial correlates an item's score with the rest of the quiz,
+// leaving the item out of the total so it does not correlate with
+// itself. It is nil when either varies not at all.
+func pointBiserial(responses []response) *float64 {
+	n := float64(len(responses))
+	var sum, sumCorrect float64
+	correct := 0
+	for _, r := range responses {
+		sum += float64(r.restSum)
+		if r.answer.Correct {
+			correct++
+			sumCorrect += float64(r.restSum)
+		}
+	}
+	if correct == 0 || correct == len(responses) {
+		return nil
+	}
+	mean := sum / n
+	var variance float64
+	for _, r := range responses {
+		d := float64(r.restSum) - mean
+		variance += d * d
+	}
+	sd := math.Sqrt(variance / n)
+	if sd == 0 {
+		return nil
+	}
+
+	p := float64(correct) / n
+	meanCorrect := sumCorrect / float64(correct)
+	meanWrong := (sum - sumCorrect) / (n - float64(correct))
+	r := (meanCorrect - meanWrong) / sd * math.Sqrt(p*(1-p))
+	r = math.Round(r*1000) / 1000
+	return &r
+}
+
+// timing returns the median and mean of times.
+func timing(times []int64) (median, mean int64) {
+	if len(times) == 0 {
+		return 0, 0
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	var sum int64
+	for _, t := range times {
+		sum += t
+	}
+	mid := len(times) / 2
+	median = times[mid]
+	if len(times)%2 == 0 {
+		median = (times[mid-1] + times[mid]) / 2
+	}
+	return median, sum / int64(len(times))
+}
+
+// firstAttempts keeps each learner's first attempt, oldest first. Later
+// attempts are made knowing the answers.
+func firstAttempts(attempts []*domain.Attempt) []*domain.Attempt {
+	seen := make(map[string]bool)
+	var first []*domain.Attempt
+	for _, attempt := range attempts {
+		if !seen[attempt.LearnerID] {
+			seen[attempt.LearnerID] = true
+			first = append(first, attempt)
+		}
+	}
+	return first
+}
@@ -0,0 +1,97 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"learnforge/internal/attempt"
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+func TestAnalyzer_FlagsBrokenItems(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	quiz := []domain.QuizItem{
+		{ID: "easy", Q: "Trivial?", Choices: []string{"Yes", "No", "Maybe"}, Answer: "Yes"},
+		{ID: "miskeyed", Q: "Keyed wrong?", Choices: []string{"X", "Y"}, Answer: "X"},
+		{ID: "good1", Q: "Fair?", Choices: []string{"M", "N"}, Answer: "M"},
+		{ID: "good2", Q: "Also fair?", Choices: []string{"K", "L"}, Answer: "K"},
+		{ID: "good3", Q: "Fair too?", Choices: []string{"P", "Q"}, Answer: "P"},
+	}
+	data, _ := json.Marshal(domain.ProcessResponse{Topic: "Raft", Quiz: quiz})
+	if err := st.Save(ctx, &domain.StoredResult{ID: "raft", Topic: "Raft", ResponseJSON: data, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Strong learners answer the fair items right but "miss" the miskeyed
+	// one; weak learners the other way round.
+	recorder := attempt.NewRecorder(st, st)
+	submit := func(learner string, answers ...string) {
+		t.Helper()
+		var in []attempt.Answer
+		for i, a := range answers {
+			in = append(in, attempt.Answer{ItemID: quiz[i].ID, Answer: a, TimeMS: int64(1000 * (i + 1))})
+		}
+		if _, err := recorder.Submit(ctx, "raft", learner, in, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, learner := range []string{"s1", "s2", "s3"} {
+		submit(learner, "Yes", "Y", "M", "K", "P")
+	}
+	for _, learner := range []string{"w1", "w2", "w3"} {
+		submit(learner, "Yes", "X", "N", "L", "Q")
+	}
+	submit("w1", "Yes", "X", "M", "K", "P") // retakes do not count
+
+	a := NewAnalyzer(st, st, WithMinResponses(5))
+	stats, err := a.QuizStats(ctx, "raft")
+	if err != nil {
+		t.Fatalf("QuizStats: %v", err)
+	}
+	if stats.Learners != 6 || len(stats.Items) != 5 {
+		t.Fatalf("learners=%d items=%d, want 6 and 5", stats.Learners, len(stats.Items))
+	}
+
+	easy, miskeyed, good := stats.Items[0], stats.Items[1], stats.Items[2]
+	if easy.PValue != 1 || easy.Discrimination != nil || !hasFlags(easy, domain.FlagTooEasy, domain.FlagNonFunctioningDistractor) {
+		t.Errorf("easy = %+v", easy)
+	}
+	if easy.MedianTimeMS != 1000 || easy.Choices[0].Rate != 1 || easy.Choices[1].Rate != 0 {
+		t.Errorf("easy timing or choices = %d %+v", easy.MedianTimeMS, easy.Choices)
+	}
+	if miskeyed.PValue != 0.5 || miskeyed.Discrimination == nil || *miskeyed.Discrimination >= 0 || !hasFlags(miskeyed, domain.FlagNegativeDiscrimination) {
+		t.Errorf("miskeyed = %+v", miskeyed)
+	}
+	if good.Discrimination == nil || *good.Discrimination <= 0 || len(good.Flags) != 0 {
+		t.Errorf("good = %+v", good)
+	}
+
+	flagged, err := a.Flagged(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Flagged: %v", err)
+	}
+	if len(flagged) != 2 || flagged[0].ItemID != "easy" || flagged[1].ItemID != "miskeyed" {
+		t.Errorf("flagged = %+v, want easy then miskeyed", flagged)
+	}
+
+	strict := NewAnalyzer(st, st, WithMinResponses(10))
+	if flagged, _ := strict.Flagged(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour)); len(flagged) != 0 {
+		t.Errorf("items with too few responses should not be flagged: %+v", flagged)
+	}
+}
+
+func hasFlags(stats domain.ItemStats, want ...string) bool {
+	if len(stats.Flags) != len(want) {
+		return false
+	}
+	for i := range want {
+		if stats.Flags[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,352 @@
+// Package attempt scores learners' answers to stored quizzes on the server
+// and keeps every attempt, so progress survives the browser and mastery
+// can be tracked per topic.
+package attempt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/experiment"
+	"learnforge/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// Bounds of history listings
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 500
+)
+
+// maxAnswerTime rejects per-question times that cannot be real.
+const maxAnswerTime = 24 * time.Hour
+
+// Recorder scores and stores quiz attempts.
+type Recorder struct {
+	attempts       store.AttemptStore
+	results        store.Store
+	reviewRequired bool
+	experiments    *experiment.Manager
+	now            func() time.Time
+}
+
+// Option configures a Recorder.
+type Option func(*Recorder)
+
+// WithReviewRequired only lets learners attempt published results.
+func WithReviewRequired(required bool) Option {
+	return func(r *Recorder) {
+		r.reviewRequired = required
+	}
+}
+
+// WithExperiments reports quiz scores as outcomes of the experiment variant
+// that generated the quiz.
+func WithExperiments(experiments *experiment.Manager) Option {
+	return func(r *Recorder) {
+		r.experiments = experiments
+	}
+}
+
+func NewRecorder(attempts store.AttemptStore, results store.Store, opts ...Option) *Recorder {
+	r := &Recorder{
+		attempts: attempts,
+		results:  results,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Answer is a learner's answer to one quiz item. TimeMS is how long the
+// learner took to answer.
+type Answer struct {
+	ItemID string `json:"item_id"`
+	Answer string `json:"answer"`
+	TimeMS int64  `json:"time_ms"`
+}
+
+// Submit scores answers to the quiz of result id and stores the attempt.
+// Items without an answer count as skipped and wrong. learner restricts
+// attempts to results learners can see.
+func (r *Recorder) Submit(ctx context.Context, id, learnerID string, answers []Answer, learner bool) (*domain.Attempt, error) {
+	if err := checkSubmission(learnerID, answers); err != nil {
+		return nil, err
+	}
+
+	stored, err := r.results.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if learner && r.reviewRequired && stored.Status != domain.ReviewPublished {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "result not found", nil)
+	}
+	var resp domain.ProcessResponse
+	if err := json.Unmarshal(stored.ResponseJSON, &resp); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+	}
+	if len(resp.Quiz) == 0 {
+		return nil, domain.InvalidArgument("the result has no quiz")
+	}
+
+	attempt := &domain.Attempt{ResultID: id, Topic: resp.Topic}
+	questions := make([]question, len(resp.Quiz))
+	for i, item := range resp.Quiz {
+		questions[i] = question{id: item.ID, q: item.Q, answer: item.Answer, explanation: explanation(item)}
+	}
+	attempt, err = r.record(ctx, attempt, learnerID, questions, answers)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.experiments.RecordOutcome(ctx, stored.Experiment, stored.Variant, id, domain.OutcomeQuizScore, attempt.Score); err != nil {
+		log.Printf(`{"level":"warn","msg":"Failed to record experiment outcome","result_id":"%s","error":"%v"}`, id, err)
+	}
+	return attempt, nil
+}
+
+// SubmitExam scores answers to a variant of exam and stores the attempt.
+// Answers name the exam's question IDs and may give a choice by its
+// letter in the variant. learner leaves the correct answers and
+// explanations out of the returned attempt, so submitting cannot reveal
+// the answer key.
+func (r *Recorder) SubmitExam(ctx context.Context, exam *domain.Exam, variant int, learnerID string, answers []Answer, learner bool) (*domain.Attempt, error) {
+	if err := checkSubmission(learnerID, answers); err != nil {
+		return nil, err
+	}
+	if variant < 1 || variant > len(exam.Variants) {
+		return nil, domain.InvalidArgument(fmt.Sprintf("variant must be between 1 and %d", len(exam.Variants)))
+	}
+
+	items := make(map[string]domain.QuizItem, len(exam.Items))
+	for _, item := range exam.Items {
+		items[item.ID] = item.Item
+	}
+	attempt := &domain.Attempt{ExamID: exam.ID, Variant: variant, Topic: exam.Title}
+	var questions []question
+	for _, q := range exam.Variants[variant-1].Questions {
+		questions = append(questions, question{
+			id:          q.ItemID,
+			q:           q.Q,
+			answer:      q.Answer,
+			explanation: explanation(items[q.ItemID]),
+			choices:     q.Choices,
+		})
+	}
+	attempt, err := r.record(ctx, attempt, learnerID, questions, answers)
+	if err != nil || !learner {
+		return attempt, err
+	}
+	return withoutExamAnswers(attempt), nil
+}
+
+// question is a quiz item as an attempt scores it. choices are set when
+// answers may name a choice by its letter.
+type question struct {
+	id, q, answer, explanation string
+	choices                    []string
+}
+
+// record scores answers to questions into attempt and stores it.
+func (r *Recorder) record(ctx context.Context, attempt *domain.Attempt, learnerID string, questions []question, answers []Answer) (*domain.Attempt, error) {
+	inQuiz := make(map[string]bool, len(questions))
+	for _, q := range questions {
+		inQuiz[q.id] = true
+	}
+	byItem := make(map[string]Answer, len(answers))
+	for i, a := range answers {
+		if a.ItemID == "" || !inQuiz[a.ItemID] {
+			return nil, domain.InvalidArgument(fmt.Sprintf("answers[%d]: item_id must be an item of the quiz", i))
+		}
+		if _, ok := byItem[a.ItemID]; ok {
+			return nil, domain.InvalidArgument(fmt.Sprintf("answers[%d]: item %s is answered twice", i, a.ItemID))
+		}
+		if a.TimeMS < 0 || a.TimeMS > maxAnswerTime.Milliseconds() {
+			return nil, domain.InvalidArgument(fmt.Sprintf("answers[%d]: time_ms must be between 0 and %d", i, maxAnswerTime.Milliseconds()))
+		}
+		byItem[a.ItemID] = a
+	}
+
+	attempt.ID = uuid.New().String()
+	attempt.LearnerID = learnerID
+	attempt.Total = len(questions)
+	attempt.CreatedAt = r.now().UTC()
+	for _, q := range questions {
+		a, answered := byItem[q.id]
+		scored := domain.AttemptAnswer{
+			ItemID:        q.id,
+			Question:      q.q,
+			Answer:        a.Answer,
+			Skipped:       !answered || strings.TrimSpace(a.Answer) == "",
+			CorrectAnswer: q.answer,
+			Explanation:   q.explanation,
+			TimeMS:        a.TimeMS,
+		}
+		scored.Correct = !scored.Skipped && domain.SameChoice(byLetter(a.Answer, q.choices), q.answer)
+		if scored.Correct {
+			attempt.Correct++
+		}
+		attempt.DurationMS += a.TimeMS
+		attempt.Answers = append(attempt.Answers, scored)
+	}
+	attempt.Score = float64(attempt.Correct) / float64(attempt.Total)
+
+	if err := r.attempts.SaveAttempt(ctx, attempt); err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+func checkSubmission(learnerID string, answers []Answer) error {
+	if strings.TrimSpace(learnerID) == "" {
+		return domain.InvalidArgument("X-User-ID is required to record an attempt")
+	}
+	if len(answers) == 0 {
+		return domain.InvalidArgument("answers must not be empty")
+	}
+	return nil
+}
+
+// byLetter resolves an answer given as a choice letter, such as "B" or
+// "B.", to the choice.
+func byLetter(answer string, choices []string) string {
+	letter := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(answer)), ".")
+	for i, choice := range choices {
+		if letter == domain.ChoiceLetter(i) {
+			return choice
+		}
+	}
+	return answer
+}
+
+// History returns a learner's latest attempts, up to limit, with mastery
+// by topic over all of them. learner leaves the answer keys out of exam
+// attempts, as SubmitExam does.
+func (r *Recorder) History(ctx context.Context, learnerID string, limit int, learner bool) (*domain.LearnerHistory, error) {
+	switch {
+	case limit == 0:
+		limit = DefaultHistoryLimit
+	case limit < 0 || limit > MaxHistoryLimit:
+		return nil, domain.InvalidArgument("limit must be between 1 and 500")
+	}
+	attempts, err := r.attempts.ListAttempts(ctx, learnerID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &domain.LearnerHistory{
+		LearnerID: learnerID,
+		Attempts:  attempts,
+		Topics:    mastery(attempts),
+	}
+	if len(history.Attempts) > limit {
+		history.Attempts = history.Attempts[:limit]
+	}
+	if history.Attempts == nil {
+		history.Attempts = []*domain.Attempt{}
+	}
+	if learner {
+		for i, attempt := range history.Attempts {
+			history.Attempts[i] = withoutExamAnswers(attempt)
+		}
+	}
+	return history, nil
+}
+
+// withoutExamAnswers returns a copy of an exam attempt without the correct
+// answers and explanations. Quiz attempts are returned as they are.
+func withoutExamAnswers(attempt *domain.Attempt) *domain.Attempt {
+	if attempt.ExamID == "" {
+		return attempt
+	}
+	copied := *attempt
+	copied.Answers = make([]domain.AttemptAnswer, len(attempt.Answers))
+	for i, a := range attempt.Answers {
+		a.CorrectAnswer, a.Explanation = "", ""
+		copied.Answers[i] = a
+	}
+	return &copied
+}
+
+// mastery summarizes attempts, newest first, by topic. Only the latest
+// answer to each question counts, so retaking a quiz replaces old
+// mistakes.
+func mastery(attempts []*domain.Attempt) []domain.TopicMastery {
+	type topicStats struct {
+		domain.TopicMastery
+		answered map[string]bool
+	}
+	byTopic := make(map[string]*topicStats)
+	var keys []string
+	for _, attempt := range attempts {
+		key := strings.ToLower(strings.TrimSpace(attempt.Topic))
+		stats, ok := byTopic[key]
+		if !ok {
+			stats = &topicStats{
+				TopicMastery: domain.TopicMastery{Topic: attempt.Topic, LastAttemptAt: attempt.CreatedAt},
+				answered:     make(map[string]bool),
+			}
+			byTopic[key] = stats
+			keys = append(keys, key)
+		}
+		stats.Attempts++
+		for _, a := range attempt.Answers {
+			question := source(attempt) + "/" + a.ItemID
+			if stats.answered[question] || a.Skipped {
+				continue
+			}
+			stats.answered[question] = true
+			stats.Questions++
+			if a.Correct {
+				stats.Correct++
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	topics := make([]domain.TopicMastery, 0, len(keys))
+	for _, key := range keys {
+		stats := byTopic[key]
+		if stats.Questions > 0 {
+			stats.Mastery = float64(stats.Correct) / float64(stats.Questions)
+		}
+		topics = append(topics, stats.TopicMastery)
+	}
+	return topics
+}
+
+// source returns the result or exam an attempt answered.
+func source(attempt *domain.Attempt) string {
+	if attempt.ExamID != "" {
+		return "exam:" + attempt.ExamID
+	}
+	return attempt.ResultID
+}
+
+// explanation returns why item's answer is right: the generated
+// explanation, or where the source covers it.
+func explanation(item domain.QuizItem) string {
+	if item.Explanation != "" {
+		return item.Explanation
+	}
+	if item.Ref == nil {
+		return ""
+	}
+	switch {
+	case item.Ref.Section != "" && item.Ref.Timestamp != "":
+		return fmt.Sprintf("Covered in %q at %s.", item.Ref.Section, item.Ref.Timestamp)
+	case item.Ref.Section != "":
+		return fmt.Sprintf("Covered in %q.", item.Ref.Section)
+	case item.Ref.Timestamp != "":
+		return fmt.Sprintf("Covered at %s in the recording.", item.Ref.Timestamp)
+	}
+	return ""
+}
@@ -0,0 +1,190 @@
+package attempt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/experiment"
+	"learnforge/internal/store"
+	"learnforge/internal/store/storetest"
+)
+
+func TestRecorder_SubmitScoresAnswers(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft"}, domain.ProcessResponse{Topic: "Raft", Quiz: []domain.QuizItem{
+		{ID: "q1", Q: "Who accepts writes?", Choices: []string{"A. Leader", "B. Follower"}, Answer: "A. Leader", Explanation: "Only the leader appends to the log."},
+		{ID: "q2", Q: "What wins an election?", Choices: []string{"A majority", "All votes"}, Answer: "A majority", Ref: &domain.SourceRef{Section: "Elections"}},
+		{ID: "q3", Q: "What replicates entries?", Choices: []string{"AppendEntries", "RequestVote"}, Answer: "AppendEntries"},
+	}})
+	r := NewRecorder(st, st)
+
+	attempt, err := r.Submit(ctx, "raft", "ana", []Answer{
+		{ItemID: "q1", Answer: "leader", TimeMS: 4000},
+		{ItemID: "q2", Answer: "All votes", TimeMS: 9000},
+	}, true)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if attempt.Correct != 1 || attempt.Total != 3 || attempt.DurationMS != 13000 {
+		t.Errorf("correct=%d total=%d duration=%d, want 1, 3 and 13000", attempt.Correct, attempt.Total, attempt.DurationMS)
+	}
+	q1, q2, q3 := attempt.Answers[0], attempt.Answers[1], attempt.Answers[2]
+	if !q1.Correct || q1.Explanation != "Only the leader appends to the log." {
+		t.Errorf("q1 = %+v, want correct with its explanation", q1)
+	}
+	if q2.Correct || q2.CorrectAnswer != "A majority" || q2.Explanation != `Covered in "Elections".` {
+		t.Errorf("q2 = %+v, want wrong with the source section as explanation", q2)
+	}
+	if !q3.Skipped || q3.Correct {
+		t.Errorf("q3 = %+v, want skipped", q3)
+	}
+
+	for name, answers := range map[string][]Answer{
+		"unknown item":   {{ItemID: "q9", Answer: "x"}},
+		"answered twice": {{ItemID: "q1", Answer: "Leader"}, {ItemID: "q1", Answer: "Follower"}},
+		"negative time":  {{ItemID: "q1", Answer: "Leader", TimeMS: -1}},
+	} {
+		if _, err := r.Submit(ctx, "raft", "ana", answers, true); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := r.Submit(ctx, "raft", "", []Answer{{ItemID: "q1", Answer: "Leader"}}, true); err == nil {
+		t.Error("expected attempts to need a learner")
+	}
+}
+
+func TestRecorder_HistoryMastery(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft"}, domain.ProcessResponse{Topic: "Raft", Quiz: []domain.QuizItem{
+		{ID: "q1", Q: "Who accepts writes?", Choices: []string{"Leader", "Follower"}, Answer: "Leader"},
+		{ID: "q2", Q: "What wins an election?", Choices: []string{"A majority", "All votes"}, Answer: "A majority"},
+	}})
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "dns"}, domain.ProcessResponse{Topic: "DNS", Quiz: []domain.QuizItem{
+		{ID: "d1", Q: "Which record maps a name to IPv6?", Choices: []string{"AAAA", "MX"}, Answer: "AAAA"},
+	}})
+
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	r := NewRecorder(st, st)
+	r.now = func() time.Time { now = now.Add(time.Minute); return now }
+
+	submit := func(id string, answers ...Answer) {
+		t.Helper()
+		if _, err := r.Submit(ctx, id, "ana", answers, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	submit("raft", Answer{ItemID: "q1", Answer: "Follower"}, Answer{ItemID: "q2", Answer: "All votes"})
+	submit("raft", Answer{ItemID: "q1", Answer: "Leader"}, Answer{ItemID: "q2", Answer: "All votes"})
+	submit("dns", Answer{ItemID: "d1", Answer: "AAAA"})
+
+	history, err := r.History(ctx, "ana", 2, true)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history.Attempts) != 2 || history.Attempts[0].ResultID != "dns" {
+		t.Fatalf("attempts = %+v, want the two newest, dns first", history.Attempts)
+	}
+	if len(history.Topics) != 2 {
+		t.Fatalf("topics = %+v, want DNS and Raft", history.Topics)
+	}
+	dns, raft := history.Topics[0], history.Topics[1]
+	if dns.Topic != "DNS" || dns.Mastery != 1 {
+		t.Errorf("DNS = %+v, want full mastery", dns)
+	}
+	if raft.Attempts != 2 || raft.Questions != 2 || raft.Correct != 1 || raft.Mastery != 0.5 {
+		t.Errorf("Raft = %+v, want the latest answers to count: 1 of 2", raft)
+	}
+
+	other, err := r.History(ctx, "ben", 0, true)
+	if err != nil || len(other.Attempts) != 0 || len(other.Topics) != 0 {
+		t.Errorf("ben's history = %+v, %v, want empty", other, err)
+	}
+}
+
+func TestRecorder_SubmitExamScoresVariantLetters(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	exam := &domain.Exam{
+		ID:    "q3",
+		Title: "Consensus Q3",
+		Items: []domain.ExamItem{
+			{ID: "q1", ResultID: "raft", Item: domain.QuizItem{ID: "r1", Q: "Who accepts writes?", Answer: "Leader", Explanation: "Only the leader appends."}},
+			{ID: "q2", ResultID: "paxos", Item: domain.QuizItem{ID: "p1", Q: "What does a proposer send first?", Answer: "Prepare"}},
+		},
+		Variants: []domain.ExamVariant{{Number: 1, Label: "A", Questions: []domain.ExamQuestion{
+			{Number: 1, ItemID: "q2", Q: "What does a proposer send first?", Choices: []string{"Accept", "Prepare"}, Answer: "Prepare"},
+			{Number: 2, ItemID: "q1", Q: "Who accepts writes?", Choices: []string{"Follower", "Leader"}, Answer: "Leader"},
+		}}},
+	}
+	r := NewRecorder(st, st)
+
+	attempt, err := r.SubmitExam(ctx, exam, 1, "ana", []Answer{
+		{ItemID: "q2", Answer: "b."},
+		{ItemID: "q1", Answer: "A"},
+	}, false)
+	if err != nil {
+		t.Fatalf("SubmitExam: %v", err)
+	}
+	if attempt.ExamID != "q3" || attempt.ResultID != "" || attempt.Correct != 1 || attempt.Total != 2 {
+		t.Errorf("attempt = %+v, want 1 of 2 correct on exam q3", attempt)
+	}
+	if first := attempt.Answers[0]; first.ItemID != "q2" || !first.Correct {
+		t.Errorf("first answer = %+v, want q2 answered correctly by letter", first)
+	}
+	if second := attempt.Answers[1]; second.Correct || second.Explanation != "Only the leader appends." {
+		t.Errorf("second answer = %+v, want wrong with the item's explanation", second)
+	}
+	if _, err := r.SubmitExam(ctx, exam, 2, "ana", []Answer{{ItemID: "q1", Answer: "A"}}, false); err == nil {
+		t.Error("SubmitExam to a missing variant succeeded, want an error")
+	}
+	if ids, _ := st.ListAttemptedResults(ctx, time.Time{}, time.Now().Add(time.Hour)); len(ids) != 0 {
+		t.Errorf("attempted results = %v, want exam attempts left out", ids)
+	}
+
+	learnerCopy, err := r.SubmitExam(ctx, exam, 1, "ben", []Answer{{ItemID: "q1", Answer: "B"}}, true)
+	if err != nil {
+		t.Fatalf("SubmitExam as a learner: %v", err)
+	}
+	history, err := r.History(ctx, "ben", 0, true)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	for _, a := range append(learnerCopy.Answers, history.Attempts[0].Answers...) {
+		if a.CorrectAnswer != "" || a.Explanation != "" {
+			t.Errorf("learner sees the answer to %s: %+v", a.ItemID, a)
+		}
+	}
+	if full, _ := r.History(ctx, "ben", 0, false); full.Attempts[0].Answers[1].CorrectAnswer != "Leader" {
+		t.Errorf("admin history = %+v, want the stored answers", full.Attempts[0].Answers)
+	}
+}
+
+func TestRecorder_SubmitRecordsExperimentOutcomes(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft", Experiment: "model", Variant: "candidate"}, domain.ProcessResponse{Topic: "Raft", Quiz: []domain.QuizItem{
+		{ID: "q1", Q: "Who accepts writes?", Choices: []string{"Leader", "Follower"}, Answer: "Leader"},
+		{ID: "q2", Q: "What wins an election?", Choices: []string{"A majority", "All votes"}, Answer: "A majority"},
+	}})
+	experiments := experiment.NewManager(st, []*experiment.Experiment{{
+		Name:     "model",
+		Variants: []*experiment.Variant{{Name: "candidate", Percent: 100}},
+	}})
+	r := NewRecorder(st, st, WithExperiments(experiments))
+
+	if _, err := r.Submit(ctx, "raft", "ana", []Answer{{ItemID: "q1", Answer: "Leader"}}, true); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	report, err := experiments.Report(ctx, "model")
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if v := report.Variants[0]; v.Outcomes != 1 || v.AvgQuizScore != 0.5 {
+		t.Errorf("variant report = %+v, want one quiz score of 0.5", v)
+	}
+}
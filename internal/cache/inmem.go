@@ -33,8 +33,9 @@ func (c *InMemCache) Get(ctx context.Context, key string) (string, error) {
 		return "", ErrNotFound
 	}
 
+	// Expired items are removed by cleanup; deleting here would need the
+	// write lock.
 	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
-		delete(c.items, key)
 		return "", ErrNotFound
 	}
 
@@ -21,7 +21,89 @@ type Config struct {
 	SlackWebhookURL      string `yaml:"slack_webhook_url"`
 	SlackErrorWebhookURL string `yaml:"slack_error_webhook_url"`
 	SummaryAPIKey        string `yaml:"summary_api_key"`
+	AdminAPIKey          string `yaml:"admin_api_key"`
 	RedisURL             string `yaml:"redis_url"`
+	VerifyMode           string `yaml:"verify_mode"` // off, mark, drop, regenerate
+	VerifyProvider       string `yaml:"verify_provider"`
+	VerifyModel          string `yaml:"verify_model"`
+	FetchMaxBytes        int    `yaml:"fetch_max_bytes"`
+	DocsRoot             string `yaml:"docs_root"` // directories under it can be built into courses
+
+	// Background job workers for /v1/jobs. JobTimeoutSeconds bounds one
+	// attempt; a job not heartbeated within JobVisibilityTimeoutSeconds is
+	// handed to another worker.
+	JobWorkers                  int `yaml:"job_workers"`
+	JobMaxAttempts              int `yaml:"job_max_attempts"`
+	JobTimeoutSeconds           int `yaml:"job_timeout_seconds"`
+	JobVisibilityTimeoutSeconds int `yaml:"job_visibility_timeout_seconds"`
+	BatchMaxItems               int `yaml:"batch_max_items"`
+
+	// Outbound model call limits, shared by synchronous requests, jobs and
+	// batches. Zero means unlimited.
+	AIRequestsPerMinute     int `yaml:"ai_requests_per_minute"`
+	AIMaxConcurrentRequests int `yaml:"ai_max_concurrent_requests"`
+
+	// WebhookSecret signs deliveries to request callback URLs; callback_url
+	// is rejected when it is empty. Registered endpoints have their own
+	// secrets.
+	WebhookSecret string `yaml:"webhook_secret"`
+
+	// IdempotencyTTLHours is how long an idempotency key returns its
+	// original result.
+	IdempotencyTTLHours int `yaml:"idempotency_ttl_hours"`
+
+	// GenerationCacheTTLSeconds is how long generated content is reused
+	// for identical requests; -1 disables the cache. Per-mode overrides go
+	// in GenerationCacheModeTTLSeconds (keys: lesson, flashcards, quiz).
+	GenerationCacheTTLSeconds     int            `yaml:"generation_cache_ttl_seconds"`
+	GenerationCacheModeTTLSeconds map[string]int `yaml:"generation_cache_mode_ttl_seconds"`
+
+	// ReviewRequired hides results from learners until a reviewer
+	// publishes them. Review notifications go to SlackReviewWebhookURL,
+	// or to SlackWebhookURL when it is empty.
+	ReviewRequired        bool   `yaml:"review_required"`
+	SlackReviewWebhookURL string `yaml:"slack_review_webhook_url"`
+
+	// SRSScheduler schedules flashcard reviews: fsrs or sm2.
+	SRSScheduler string `yaml:"srs_scheduler"`
+
+	// ItemStatsMinResponses is how many first attempts a quiz item needs
+	// before item analytics flags it.
+	ItemStatsMinResponses int `yaml:"item_stats_min_responses"`
+
+	// FeedbackMinDownvotes is how many learners must rate an item down,
+	// and more than rated it up, before it counts as low-rated.
+	FeedbackMinDownvotes int `yaml:"feedback_min_downvotes"`
+
+	// UploadLimits overrides the per-format upload size limit in bytes.
+	// Keys: pdf, docx, markdown, html, text.
+	UploadLimits map[string]int64 `yaml:"upload_limits"`
+
+	Experiments []ExperimentConfig `yaml:"experiments"`
+}
+
+// ExperimentConfig describes an A/B experiment over /v1/process traffic.
+// Variant percentages are cumulative across all enabled experiments and
+// must not exceed 100; unassigned traffic uses the default AI settings.
+type ExperimentConfig struct {
+	Name     string          `yaml:"name"`
+	Enabled  bool            `yaml:"enabled"`
+	Variants []VariantConfig `yaml:"variants"`
+}
+
+// VariantConfig overrides the default AI settings for a share of traffic.
+// Empty fields fall back to the top-level AI configuration.
+type VariantConfig struct {
+	Name            string  `yaml:"name"`
+	Percent         int     `yaml:"percent"`
+	Provider        string  `yaml:"provider"`
+	BaseURL         string  `yaml:"base_url"`
+	APIKey          string  `yaml:"api_key"`
+	Model           string  `yaml:"model"`
+	PromptVersion   string  `yaml:"prompt_version"`
+	Instructions    string  `yaml:"instructions"`
+	InputCostPer1K  float64 `yaml:"input_cost_per_1k"`
+	OutputCostPer1K float64 `yaml:"output_cost_per_1k"`
 }
 
 func Load() (*Config, error) {
@@ -83,16 +165,92 @@ func Load() (*Config, error) {
 	if cfg.SlackErrorWebhookURL == "" {
 		cfg.SlackErrorWebhookURL = getEnv("SLACK_ERROR_WEBHOOK_URL", "")
 	}
+	if cfg.SlackReviewWebhookURL == "" {
+		cfg.SlackReviewWebhookURL = getEnv("SLACK_REVIEW_WEBHOOK_URL", cfg.SlackWebhookURL)
+	}
+	if !cfg.ReviewRequired {
+		cfg.ReviewRequired, _ = strconv.ParseBool(getEnv("REVIEW_REQUIRED", "false"))
+	}
+	if cfg.SRSScheduler == "" {
+		cfg.SRSScheduler = getEnv("SRS_SCHEDULER", "fsrs")
+	}
 	if cfg.SummaryAPIKey == "" {
 		cfg.SummaryAPIKey = getEnv("SUMMARY_API_KEY", "")
 	}
+	if cfg.AdminAPIKey == "" {
+		cfg.AdminAPIKey = getEnv("ADMIN_API_KEY", "")
+	}
 	if cfg.RedisURL == "" {
 		cfg.RedisURL = getEnv("REDIS_URL", "")
 	}
+	if cfg.VerifyMode == "" {
+		cfg.VerifyMode = getEnv("VERIFY_MODE", "off")
+	}
+	if cfg.VerifyProvider == "" {
+		cfg.VerifyProvider = getEnv("VERIFY_PROVIDER", cfg.AIProvider)
+	}
+	if cfg.VerifyModel == "" {
+		cfg.VerifyModel = getEnv("VERIFY_MODEL", cfg.AIModel)
+	}
+	if cfg.DocsRoot == "" {
+		cfg.DocsRoot = getEnv("DOCS_ROOT", "")
+	}
+
+	if cfg.FetchMaxBytes == 0 {
+		cfg.FetchMaxBytes = getEnvInt("FETCH_MAX_BYTES", 5<<20)
+	}
+	if cfg.WebhookSecret == "" {
+		cfg.WebhookSecret = getEnv("WEBHOOK_SECRET", "")
+	}
+	if cfg.ItemStatsMinResponses == 0 {
+		cfg.ItemStatsMinResponses = getEnvInt("ITEM_STATS_MIN_RESPONSES", 20)
+	}
+	if cfg.FeedbackMinDownvotes == 0 {
+		cfg.FeedbackMinDownvotes = getEnvInt("FEEDBACK_MIN_DOWNVOTES", 3)
+	}
+	if cfg.JobWorkers == 0 {
+		cfg.JobWorkers = getEnvInt("JOB_WORKERS", 4)
+	}
+	if cfg.JobMaxAttempts == 0 {
+		cfg.JobMaxAttempts = getEnvInt("JOB_MAX_ATTEMPTS", 3)
+	}
+	if cfg.JobTimeoutSeconds == 0 {
+		cfg.JobTimeoutSeconds = getEnvInt("JOB_TIMEOUT_SECONDS", 300)
+	}
+	if cfg.JobVisibilityTimeoutSeconds == 0 {
+		cfg.JobVisibilityTimeoutSeconds = getEnvInt("JOB_VISIBILITY_TIMEOUT_SECONDS", 120)
+	}
+	if cfg.GenerationCacheTTLSeconds == 0 {
+		cfg.GenerationCacheTTLSeconds = getEnvInt("GENERATION_CACHE_TTL_SECONDS", 7*24*3600)
+	}
+	if cfg.IdempotencyTTLHours == 0 {
+		cfg.IdempotencyTTLHours = getEnvInt("IDEMPOTENCY_TTL_HOURS", 24)
+	}
+	if cfg.BatchMaxItems == 0 {
+		cfg.BatchMaxItems = getEnvInt("BATCH_MAX_ITEMS", 1000)
+	}
+	if cfg.AIRequestsPerMinute == 0 {
+		cfg.AIRequestsPerMinute = getEnvInt("AI_REQUESTS_PER_MINUTE", 0)
+	}
+	if cfg.AIMaxConcurrentRequests == 0 {
+		cfg.AIMaxConcurrentRequests = getEnvInt("AI_MAX_CONCURRENT_REQUESTS", 0)
+	}
 
 	return &cfg, nil
 }
 
+// ProviderBaseURL returns the API base URL for provider, reusing AIBaseURL
+// when provider is the configured default.
+func (c *Config) ProviderBaseURL(provider string) string {
+	if provider == "" || provider == c.AIProvider {
+		return c.AIBaseURL
+	}
+	if provider == "gemini" {
+		return "https://generativelanguage.googleapis.com"
+	}
+	return "https://api.openai.com"
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,269 @@
+package course
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+// Processor generates a lesson from text; *service.Service satisfies it.
+type Processor interface {
+	ProcessText(ctx context.Context, req *domain.ProcessRequest) (*domain.ProcessResponse, error)
+}
+
+// Options control a course build.
+type Options struct {
+	Dir      string
+	ID       string // defaults to a slug of Name
+	Name     string // defaults to the directory name
+	Level    string
+	Language string
+	// Force regenerates every document even when its content is unchanged.
+	Force bool
+}
+
+// Builder generates and updates courses.
+type Builder struct {
+	processor Processor
+	store     store.CourseStore
+	now       func() time.Time
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func NewBuilder(processor Processor, store store.CourseStore) *Builder {
+	return &Builder{
+		processor: processor,
+		store:     store,
+		now:       time.Now,
+		running:   make(map[string]bool),
+	}
+}
+
+type plan struct {
+	root  string
+	id    string
+	name  string
+	paths []string
+}
+
+// prepare validates opts and lists the documents to build.
+func (b *Builder) prepare(opts Options) (*plan, error) {
+	if opts.Dir == "" {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "dir is required", nil)
+	}
+	if opts.Level != "" && !domain.ValidateLevel(&opts.Level) {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "level must be one of: beginner, intermediate, advanced", nil)
+	}
+	root, err := filepath.Abs(opts.Dir)
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "invalid dir", err)
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, fmt.Sprintf("%s is not a directory", opts.Dir), err)
+	}
+
+	paths, err := Discover(root)
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to read docs directory", err)
+	}
+	if len(paths) == 0 {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "no Markdown or AsciiDoc documents found", nil)
+	}
+
+	p := &plan{root: root, id: opts.ID, name: opts.Name, paths: paths}
+	if p.name == "" {
+		p.name = filepath.Base(root)
+	}
+	if p.id == "" {
+		p.id = Slug(p.name)
+	}
+	if p.id == "" {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "course id or name is required", nil)
+	}
+	return p, nil
+}
+
+// acquire marks a course as building, failing if a build is already
+// running for it in this process.
+func (b *Builder) acquire(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.running[id] {
+		return domain.NewDomainError(domain.ErrorCodeConflict, "a build is already running for this course", nil)
+	}
+	b.running[id] = true
+	return nil
+}
+
+func (b *Builder) release(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.running, id)
+}
+
+// Build generates one lesson per document under opts.Dir and saves them as
+// a course. When the course already exists, only documents whose content
+// hash changed are regenerated; lessons for deleted documents are removed.
+// A document that fails to generate keeps its previous lesson, if any.
+func (b *Builder) Build(ctx context.Context, opts Options) (*domain.Course, error) {
+	p, err := b.prepare(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.acquire(p.id); err != nil {
+		return nil, err
+	}
+	defer b.release(p.id)
+
+	return b.run(ctx, p, opts)
+}
+
+// Start validates opts and builds the course in the background, returning
+// the course ID. Progress is visible through the stored course once the
+// build finishes.
+func (b *Builder) Start(opts Options) (string, error) {
+	p, err := b.prepare(opts)
+	if err != nil {
+		return "", err
+	}
+	if err := b.acquire(p.id); err != nil {
+		return "", err
+	}
+
+	go func() {
+		defer b.release(p.id)
+		course, err := b.run(context.Background(), p, opts)
+		if err != nil {
+			log.Printf(`{"level":"error","msg":"Course build failed","course":"%s","error":"%v"}`, p.id, err)
+			return
+		}
+		log.Printf(`{"level":"info","msg":"Course built","course":"%s","generated":%d,"unchanged":%d,"removed":%d,"failed":%d}`,
+			p.id, len(course.LastBuild.Generated), len(course.LastBuild.Unchanged), len(course.LastBuild.Removed), len(course.LastBuild.Failed))
+	}()
+	return p.id, nil
+}
+
+func (b *Builder) run(ctx context.Context, p *plan, opts Options) (*domain.Course, error) {
+	root, paths := p.root, p.paths
+	now := b.now().UTC()
+	course := &domain.Course{ID: p.id, CreatedAt: now}
+	existing, err := b.store.GetCourse(ctx, p.id)
+	if err == nil {
+		*course = *existing
+	} else if !domain.HasCode(err, domain.ErrorCodeNotFound) {
+		return nil, err
+	}
+
+	previous := make(map[string]domain.CourseLesson, len(course.Lessons))
+	for _, lesson := range course.Lessons {
+		previous[lesson.Path] = lesson
+	}
+
+	build := &domain.CourseBuildLog{}
+	lessons := make([]domain.CourseLesson, 0, len(paths))
+	for _, path := range paths {
+		prev, existed := previous[path]
+		delete(previous, path)
+
+		doc, err := LoadDocument(root, path)
+		if err != nil {
+			build.Failed = append(build.Failed, fmt.Sprintf("%s: %v", path, err))
+			if existed {
+				lessons = append(lessons, prev)
+			}
+			continue
+		}
+
+		lesson := domain.CourseLesson{
+			Path:        path,
+			Title:       doc.Title,
+			ContentHash: doc.ContentHash,
+			CommitHash:  gitCommit(ctx, root, path),
+			Anchors:     doc.Anchors,
+		}
+		if existed && prev.ContentHash == doc.ContentHash && !opts.Force {
+			lesson.ResultID = prev.ResultID
+			lesson.UpdatedAt = prev.UpdatedAt
+			lessons = append(lessons, lesson)
+			build.Unchanged = append(build.Unchanged, path)
+			continue
+		}
+
+		resultID, err := b.generate(ctx, doc, opts)
+		if err != nil {
+			build.Failed = append(build.Failed, fmt.Sprintf("%s: %v", path, err))
+			if existed {
+				lessons = append(lessons, prev)
+			}
+			continue
+		}
+		lesson.ResultID = resultID
+		lesson.UpdatedAt = now
+		lessons = append(lessons, lesson)
+		build.Generated = append(build.Generated, path)
+	}
+	for path := range previous {
+		build.Removed = append(build.Removed, path)
+	}
+	sort.Strings(build.Removed)
+
+	course.Name = p.name
+	course.Root = root
+	course.CommitHash = gitCommit(ctx, root, "")
+	course.Lessons = lessons
+	course.LastBuild = build
+	course.UpdatedAt = now
+
+	if err := b.store.SaveCourse(ctx, course); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to save course", err)
+	}
+	return course, nil
+}
+
+func (b *Builder) generate(ctx context.Context, doc *Document, opts Options) (string, error) {
+	req := &domain.ProcessRequest{
+		Text:     doc.Text,
+		Mode:     "lesson",
+		Language: opts.Language,
+	}
+	if opts.Level != "" {
+		req.Level = &opts.Level
+	}
+
+	resp, err := b.processor.ProcessText(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// Slug turns a course name into an ID: lowercase letters and digits joined
+// by single hyphens.
+func Slug(name string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if sep && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			sep = false
+		} else {
+			sep = true
+		}
+	}
+	return b.String()
+}
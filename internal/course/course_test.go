@@ -0,0 +1,122 @@
+package course
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+type countingProcessor struct {
+	calls int
+}
+
+func (p *countingProcessor) ProcessText(ctx context.Context, req *domain.ProcessRequest) (*domain.ProcessResponse, error) {
+	p.calls++
+	return &domain.ProcessResponse{ID: fmt.Sprintf("result-%d", p.calls)}, nil
+}
+
+func writeFile(tb testing.TB, path, content string) {
+	tb.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		tb.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func TestLoadDocument_Anchors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "guide.md"), "---\ntitle: ignored\n---\n# Getting Started\n\n## Install `go`\n\n```sh\n# not a heading\n```\n\n## Install `go`\n\nSetup\n-----\n")
+	writeFile(t, filepath.Join(dir, "ops.adoc"), "= Operations Guide\n:toc:\n\n[[deploys]]\n== Deploying to Production\n\n== On-call & Paging\n\nSee <<deploys,the deploy section>> and link:https://example.com[the wiki].\n")
+
+	doc, err := LoadDocument(dir, "guide.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMD := []string{"getting-started", "install-go", "install-go-1", "setup"}
+	if len(doc.Anchors) != len(wantMD) {
+		t.Fatalf("markdown anchors = %+v", doc.Anchors)
+	}
+	for i, want := range wantMD {
+		if doc.Anchors[i].Anchor != want {
+			t.Errorf("anchor %d = %q, want %q", i, doc.Anchors[i].Anchor, want)
+		}
+	}
+	if doc.Title != "Getting Started" {
+		t.Errorf("title = %q", doc.Title)
+	}
+
+	doc, err = LoadDocument(dir, "ops.adoc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAdoc := []string{"_operations_guide", "deploys", "_on_call_paging"}
+	if len(doc.Anchors) != len(wantAdoc) {
+		t.Fatalf("asciidoc anchors = %+v", doc.Anchors)
+	}
+	for i, want := range wantAdoc {
+		if doc.Anchors[i].Anchor != want {
+			t.Errorf("anchor %d = %q, want %q", i, doc.Anchors[i].Anchor, want)
+		}
+	}
+	wantText := "# Operations Guide\n\n## Deploying to Production\n\n## On-call & Paging\n\nSee the deploy section and the wiki."
+	if doc.Text != wantText {
+		t.Errorf("text = %q, want %q", doc.Text, wantText)
+	}
+}
+
+func TestBuilder_RegeneratesOnlyChangedDocuments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "b.md"), "# B\n\nSecond page.")
+	writeFile(t, filepath.Join(dir, "README.md"), "# Overview\n\nStart here.")
+	writeFile(t, filepath.Join(dir, "sub", "c.adoc"), "= C\n\nThird page.")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a doc")
+	writeFile(t, filepath.Join(dir, ".github", "x.md"), "# hidden")
+
+	proc := &countingProcessor{}
+	st := store.NewInMemStore()
+	builder := NewBuilder(proc, st)
+	ctx := context.Background()
+
+	course, err := builder.Build(ctx, Options{Dir: dir, Name: "Engineering Onboarding"})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if course.ID != "engineering-onboarding" {
+		t.Errorf("id = %q", course.ID)
+	}
+	var paths []string
+	for _, l := range course.Lessons {
+		paths = append(paths, l.Path)
+	}
+	if len(paths) != 3 || paths[0] != "README.md" || paths[1] != "b.md" || paths[2] != "sub/c.adoc" {
+		t.Errorf("lessons = %v", paths)
+	}
+	if proc.calls != 3 {
+		t.Errorf("first build generated %d lessons, want 3", proc.calls)
+	}
+
+	writeFile(t, filepath.Join(dir, "b.md"), "# B\n\nSecond page, edited.")
+	os.Remove(filepath.Join(dir, "sub", "c.adoc"))
+
+	course, err = builder.Build(ctx, Options{Dir: dir, Name: "Engineering Onboarding"})
+	if err != nil {
+		t.Fatalf("rebuild: %v", err)
+	}
+	if proc.calls != 4 {
+		t.Errorf("rebuild generated %d lessons, want 1", proc.calls-3)
+	}
+	log := course.LastBuild
+	if len(log.Generated) != 1 || log.Generated[0] != "b.md" || len(log.Unchanged) != 1 || len(log.Removed) != 1 {
+		t.Errorf("build log = %+v", log)
+	}
+	if course.Lessons[0].ResultID != "result-1" || course.Lessons[1].ResultID != "result-4" {
+		t.Errorf("result ids = %s, %s", course.Lessons[0].ResultID, course.Lessons[1].ResultID)
+	}
+}
@@ -0,0 +1,320 @@
+// Package course builds courses of generated lessons from a directory of
+// Markdown and AsciiDoc documents.
+package course
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"learnforge/internal/domain"
+)
+
+// maxDocumentBytes skips generated or vendored files that are far larger
+// than any hand-written page.
+const maxDocumentBytes = 1 << 20
+
+var docExtensions = map[string]bool{
+	".md": true, ".markdown": true, ".adoc": true, ".asciidoc": true, ".asc": true,
+}
+
+// skippedDirs never contain documentation worth turning into lessons.
+var skippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true,
+}
+
+// Document is a source document converted to Markdown for generation.
+type Document struct {
+	Path        string // slash-separated, relative to the root
+	Title       string
+	Text        string
+	Anchors     []domain.HeadingAnchor
+	ContentHash string
+}
+
+// Discover returns the relative paths of all documents under root, with
+// README and index pages ahead of their siblings.
+func Discover(root string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !docExtensions[strings.ToLower(filepath.Ext(path))] || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := filepath.Dir(paths[i]), filepath.Dir(paths[j])
+		if di != dj {
+			return paths[i] < paths[j]
+		}
+		ii, ij := isIndexPage(paths[i]), isIndexPage(paths[j])
+		if ii != ij {
+			return ii
+		}
+		return paths[i] < paths[j]
+	})
+	return paths, nil
+}
+
+func isIndexPage(path string) bool {
+	base := strings.ToLower(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	return base == "readme" || base == "index"
+}
+
+// LoadDocument reads and converts the document at rel under root.
+func LoadDocument(root, rel string) (*Document, error) {
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxDocumentBytes {
+		return nil, fmt.Errorf("%s is larger than %d bytes", rel, maxDocumentBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(data)
+	doc := &Document{Path: rel, ContentHash: hex.EncodeToString(hash[:])}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	switch strings.ToLower(filepath.Ext(rel)) {
+	case ".adoc", ".asciidoc", ".asc":
+		doc.Text, doc.Anchors = convertAsciiDoc(text)
+	default:
+		doc.Text, doc.Anchors = parseMarkdown(text)
+	}
+
+	for _, a := range doc.Anchors {
+		if a.Level == 1 {
+			doc.Title = a.Text
+			break
+		}
+	}
+	if doc.Title == "" {
+		doc.Title = strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+	}
+	return doc, nil
+}
+
+var (
+	atxHeading      = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	setextUnderline = regexp.MustCompile(`^(=+|-+)\s*$`)
+)
+
+// parseMarkdown strips front matter and collects ATX and setext headings
+// with GitHub-style anchors. Headings inside fenced code blocks are
+// ignored.
+func parseMarkdown(text string) (string, []domain.HeadingAnchor) {
+	if strings.HasPrefix(text, "---\n") {
+		if end := strings.Index(text[4:], "\n---\n"); end >= 0 {
+			text = text[4+end+5:]
+		}
+	}
+
+	lines := strings.Split(text, "\n")
+	slugs := newSlugger()
+	var anchors []domain.HeadingAnchor
+	inFence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if inFence != "" {
+			if strings.HasPrefix(trimmed, inFence) {
+				inFence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = trimmed[:3]
+			continue
+		}
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			anchors = append(anchors, domain.HeadingAnchor{Level: len(m[1]), Text: m[2], Anchor: slugs.github(m[2])})
+			continue
+		}
+		if i > 0 && setextUnderline.MatchString(line) && strings.TrimSpace(lines[i-1]) != "" && !atxHeading.MatchString(lines[i-1]) {
+			level := 1
+			if line[0] == '-' {
+				level = 2
+			}
+			heading := strings.TrimSpace(lines[i-1])
+			anchors = append(anchors, domain.HeadingAnchor{Level: level, Text: heading, Anchor: slugs.github(heading)})
+		}
+	}
+	return strings.TrimSpace(text), anchors
+}
+
+var (
+	adocHeading     = regexp.MustCompile(`^(={1,6})\s+(.+?)\s*$`)
+	adocBlockAnchor = regexp.MustCompile(`^\[(?:\[([^\],]+)(?:,[^\]]*)?\]|#([^\].,%]+)[^\]]*)\]\s*$`)
+	adocAttribute   = regexp.MustCompile(`^:[!\w-]+!?:`)
+	adocDelimiter   = regexp.MustCompile(`^(-{4,}|\.{4,}|={4,}|\*{4,}|_{4,}|\+{4,}|\|===)\s*$`)
+	adocXref        = regexp.MustCompile(`<<[^,>]+,\s*([^>]+)>>`)
+	adocLink        = regexp.MustCompile(`(?:(?:link|xref):[^\s\[]+|https?://[^\s\[]+)\[([^\]]*)\]`)
+)
+
+// convertAsciiDoc turns AsciiDoc into Markdown-like text: section titles
+// become "#" headings, attribute entries, comments and block delimiters
+// are dropped, and cross references keep their link text. Anchors follow
+// Asciidoctor's default "_section_title" ids unless set explicitly.
+func convertAsciiDoc(text string) (string, []domain.HeadingAnchor) {
+	var out []string
+	var anchors []domain.HeadingAnchor
+	slugs := newSlugger()
+	pendingID := ""
+	inComment := false
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "////" {
+			inComment = !inComment
+			continue
+		}
+		if inComment || strings.HasPrefix(trimmed, "//") || adocAttribute.MatchString(trimmed) || adocDelimiter.MatchString(trimmed) {
+			continue
+		}
+		if m := adocBlockAnchor.FindStringSubmatch(trimmed); m != nil {
+			pendingID = m[1] + m[2]
+			continue
+		}
+		if m := adocHeading.FindStringSubmatch(line); m != nil {
+			level := len(m[1])
+			title := m[2]
+			id := pendingID
+			if id == "" {
+				id = slugs.asciidoc(title)
+			}
+			anchors = append(anchors, domain.HeadingAnchor{Level: level, Text: title, Anchor: id})
+			out = append(out, strings.Repeat("#", level)+" "+title)
+			pendingID = ""
+			continue
+		}
+		pendingID = ""
+		line = adocXref.ReplaceAllString(line, "$1")
+		line = adocLink.ReplaceAllString(line, "$1")
+		out = append(out, line)
+	}
+	return strings.TrimSpace(collapseBlankLines(strings.Join(out, "\n"))), anchors
+}
+
+func collapseBlankLines(s string) string {
+	for strings.Contains(s, "\n\n\n") {
+		s = strings.ReplaceAll(s, "\n\n\n", "\n\n")
+	}
+	return s
+}
+
+// slugger generates unique heading anchors within one document.
+type slugger struct {
+	seen map[string]int
+}
+
+func newSlugger() *slugger {
+	return &slugger{seen: map[string]int{}}
+}
+
+// occurrence counts how often slug was generated before.
+func (s *slugger) occurrence(slug string) int {
+	n := s.seen[slug]
+	s.seen[slug] = n + 1
+	return n
+}
+
+// github mirrors GitHub's heading anchors: lowercase, punctuation removed,
+// spaces turned into hyphens, duplicates suffixed with -1, -2, ...
+func (s *slugger) github(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(stripInlineMarkdown(heading)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	slug := b.String()
+	if n := s.occurrence(slug); n > 0 {
+		return fmt.Sprintf("%s-%d", slug, n)
+	}
+	return slug
+}
+
+// asciidoc mirrors Asciidoctor's default section ids: "_" prefix and
+// separator, duplicates suffixed with _2, _3, ...
+func (s *slugger) asciidoc(title string) string {
+	var b strings.Builder
+	b.WriteRune('_')
+	lastSep := true
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			b.WriteRune(r)
+			lastSep = false
+		} else if !lastSep {
+			b.WriteRune('_')
+			lastSep = true
+		}
+	}
+	id := strings.TrimSuffix(b.String(), "_")
+	if id == "" {
+		id = "_"
+	}
+	if n := s.occurrence(id); n > 0 {
+		return fmt.Sprintf("%s_%d", id, n+1)
+	}
+	return id
+}
+
+var inlineMarkdown = regexp.MustCompile("`|\\*\\*|__|\\[([^\\]]*)\\]\\([^)]*\\)")
+
+func stripInlineMarkdown(s string) string {
+	return inlineMarkdown.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "[") {
+			return m[1:strings.Index(m, "]")]
+		}
+		return ""
+	})
+}
+
+// gitCommit returns the last commit touching rel, or HEAD when rel is
+// empty. It returns "" when root is not inside a git work tree or git is
+// unavailable.
+func gitCommit(ctx context.Context, root, rel string) string {
+	args := []string{"-C", root, "log", "-1", "--format=%H"}
+	if rel != "" {
+		args = append(args, "--", filepath.FromSlash(rel))
+	}
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
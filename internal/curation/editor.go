@@ -0,0 +1,456 @@
+// Package curation lets instructors correct generated results. Every save
+// is a new revision, so edits can be audited and reverted.
+package curation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+	"learnforge/internal/webhook"
+)
+
+// Editor applies edits to stored results.
+type Editor struct {
+	results   store.Store
+	revisions store.RevisionStore
+	webhooks  *webhook.Dispatcher
+	now       func() time.Time
+
+	// mu serializes edits within this instance; AddRevision rejects
+	// concurrent edits from other instances.
+	mu sync.Mutex
+}
+
+// Option configures optional Editor dependencies.
+type Option func(*Editor)
+
+// WithWebhooks sends result.updated events for every saved edit.
+func WithWebhooks(d *webhook.Dispatcher) Option {
+	return func(e *Editor) {
+		e.webhooks = d
+	}
+}
+
+func NewEditor(results store.Store, revisions store.RevisionStore, opts ...Option) *Editor {
+	e := &Editor{
+		results:   results,
+		revisions: revisions,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Patch replaces the given parts of a result. Nil fields are left alone.
+// Flashcards and quiz items keep their IDs and source references when
+// their id matches an existing item; items without an id are new.
+type Patch struct {
+	Summary    *string             `json:"summary,omitempty"`
+	KeyPoints  *[]string           `json:"key_points,omitempty"`
+	Flashcards *[]domain.Flashcard `json:"flashcards,omitempty"`
+	Quiz       *[]domain.QuizItem  `json:"quiz,omitempty"`
+}
+
+// Update applies patch to result id on behalf of author.
+func (e *Editor) Update(ctx context.Context, id, author string, patch Patch) (*domain.ProcessResponse, error) {
+	if patch.Summary == nil && patch.KeyPoints == nil && patch.Flashcards == nil && patch.Quiz == nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "patch must change at least one of: summary, key_points, flashcards, quiz", nil)
+	}
+
+	return e.edit(ctx, id, author, func(resp *domain.ProcessResponse) ([]string, error) {
+		var changes []string
+		if patch.Summary != nil {
+			if strings.TrimSpace(*patch.Summary) == "" {
+				return nil, domain.InvalidArgument("summary must not be empty")
+			}
+			resp.Summary = strings.TrimSpace(*patch.Summary)
+			changes = append(changes, "summary")
+		}
+		if patch.KeyPoints != nil {
+			for i, kp := range *patch.KeyPoints {
+				if strings.TrimSpace(kp) == "" {
+					return nil, domain.InvalidArgument(fmt.Sprintf("key_points[%d] must not be empty", i))
+				}
+			}
+			// References are positional and no longer line up.
+			resp.KeyPoints = *patch.KeyPoints
+			resp.KeyPointRefs = nil
+			changes = append(changes, "key_points")
+		}
+		if patch.Flashcards != nil {
+			cards, err := mergeFlashcards(resp.Flashcards, *patch.Flashcards)
+			if err != nil {
+				return nil, err
+			}
+			resp.Flashcards = cards
+			changes = append(changes, "flashcards")
+		}
+		if patch.Quiz != nil {
+			items, err := mergeQuiz(resp.Quiz, *patch.Quiz)
+			if err != nil {
+				return nil, err
+			}
+			resp.Quiz = items
+			changes = append(changes, "quiz")
+		}
+		return changes, nil
+	})
+}
+
+// AddFlashcard appends a flashcard and returns it with its new ID.
+func (e *Editor) AddFlashcard(ctx context.Context, id, author string, card domain.Flashcard) (*domain.Flashcard, error) {
+	card.ID = domain.NewItemID()
+	if err := domain.ValidateFlashcard(card); err != nil {
+		return nil, err
+	}
+	_, err := e.edit(ctx, id, author, func(resp *domain.ProcessResponse) ([]string, error) {
+		resp.Flashcards = append(resp.Flashcards, card)
+		return []string{"flashcard " + card.ID + " added"}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// UpdateFlashcard replaces the question and answer of a flashcard.
+func (e *Editor) UpdateFlashcard(ctx context.Context, id, itemID, author string, card domain.Flashcard) (*domain.Flashcard, error) {
+	if err := domain.ValidateFlashcard(card); err != nil {
+		return nil, err
+	}
+	var updated domain.Flashcard
+	_, err := e.edit(ctx, id, author, func(resp *domain.ProcessResponse) ([]string, error) {
+		for i := range resp.Flashcards {
+			if resp.Flashcards[i].ID == itemID {
+				resp.Flashcards[i].Q = card.Q
+				resp.Flashcards[i].A = card.A
+				updated = resp.Flashcards[i]
+				return []string{"flashcard " + itemID + " updated"}, nil
+			}
+		}
+		return nil, itemNotFound("flashcard")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteFlashcard removes a flashcard.
+func (e *Editor) DeleteFlashcard(ctx context.Context, id, itemID, author string) error {
+	_, err := e.edit(ctx, id, author, func(resp *domain.ProcessResponse) ([]string, error) {
+		for i := range resp.Flashcards {
+			if resp.Flashcards[i].ID == itemID {
+				resp.Flashcards = append(resp.Flashcards[:i], resp.Flashcards[i+1:]...)
+				return []string{"flashcard " + itemID + " deleted"}, nil
+			}
+		}
+		return nil, itemNotFound("flashcard")
+	})
+	return err
+}
+
+// AddQuizItem appends a quiz item and returns it with its new ID.
+func (e *Editor) AddQuizItem(ctx context.Context, id, author string, item domain.QuizItem) (*domain.QuizItem, error) {
+	item = curatedQuizItem(item)
+	item.ID = domain.NewItemID()
+	if err := domain.ValidateQuizItem(item); err != nil {
+		return nil, err
+	}
+	_, err := e.edit(ctx, id, author, func(resp *domain.ProcessResponse) ([]string, error) {
+		resp.Quiz = append(resp.Quiz, item)
+		return []string{"quiz item " + item.ID + " added"}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateQuizItem replaces the question, choices, answer and explanation of
+// a quiz item. A review flag from answer verification is cleared, since a
+// person has now checked the item.
+func (e *Editor) UpdateQuizItem(ctx context.Context, id, itemID, author string, item domain.QuizItem) (*domain.QuizItem, error) {
+	item = curatedQuizItem(item)
+	if err := domain.ValidateQuizItem(item); err != nil {
+		return nil, err
+	}
+	var updated domain.QuizItem
+	_, err := e.edit(ctx, id, author, func(resp *domain.ProcessResponse) ([]string, error) {
+		for i := range resp.Quiz {
+			if resp.Quiz[i].ID == itemID {
+				item.ID = itemID
+				item.Ref = resp.Quiz[i].Ref
+				resp.Quiz[i] = item
+				updated = item
+				return []string{"quiz item " + itemID + " updated"}, nil
+			}
+		}
+		return nil, itemNotFound("quiz item")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeleteQuizItem removes a quiz item.
+func (e *Editor) DeleteQuizItem(ctx context.Context, id, itemID, author string) error {
+	_, err := e.edit(ctx, id, author, func(resp *domain.ProcessResponse) ([]string, error) {
+		for i := range resp.Quiz {
+			if resp.Quiz[i].ID == itemID {
+				resp.Quiz = append(resp.Quiz[:i], resp.Quiz[i+1:]...)
+				return []string{"quiz item " + itemID + " deleted"}, nil
+			}
+		}
+		return nil, itemNotFound("quiz item")
+	})
+	return err
+}
+
+// Revisions lists a result's revisions without their content. A result
+// that was never edited has a single generated revision.
+func (e *Editor) Revisions(ctx context.Context, id string) ([]*domain.ResultRevision, error) {
+	stored, current, err := e.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := e.revisions.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if base := baseRevision(revisions, stored, current); base != nil {
+		revisions = append(revisions, base)
+	}
+	for _, rev := range revisions {
+		rev.Content = nil
+	}
+	return revisions, nil
+}
+
+// Revision returns one revision with its content.
+func (e *Editor) Revision(ctx context.Context, id string, number int) (*domain.ResultRevision, error) {
+	rev, err := e.revisions.GetRevision(ctx, id, number)
+	if err == nil || !domain.HasCode(err, domain.ErrorCodeNotFound) {
+		return rev, err
+	}
+	// The generated content is not recorded until the first edit.
+	stored, current, err := e.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := e.revisions.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if base := baseRevision(revisions, stored, current); base != nil && base.Number == number {
+		return base, nil
+	}
+	return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "revision not found", nil)
+}
+
+// Revert restores the content of an earlier revision as a new revision.
+func (e *Editor) Revert(ctx context.Context, id string, number int, author string) (*domain.ProcessResponse, error) {
+	target, err := e.Revision(ctx, id, number)
+	if err != nil {
+		return nil, err
+	}
+	return e.save(ctx, id, author, func(resp *domain.ProcessResponse) (*domain.ResultRevision, error) {
+		restored := *target.Content
+		// Identity and provenance belong to the result, not the revision.
+		restored.ID = resp.ID
+		restored.DocumentID = resp.DocumentID
+		restored.Revision = resp.Revision
+		*resp = restored
+		return &domain.ResultRevision{Action: domain.RevisionReverted, RevertedTo: number}, nil
+	})
+}
+
+// edit runs fn on the current content and saves the result as a new
+// revision.
+func (e *Editor) edit(ctx context.Context, id, author string, fn func(resp *domain.ProcessResponse) ([]string, error)) (*domain.ProcessResponse, error) {
+	return e.save(ctx, id, author, func(resp *domain.ProcessResponse) (*domain.ResultRevision, error) {
+		changes, err := fn(resp)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.ResultRevision{Action: domain.RevisionEdited, Changes: changes}, nil
+	})
+}
+
+func (e *Editor) save(ctx context.Context, id, author string, fn func(resp *domain.ProcessResponse) (*domain.ResultRevision, error)) (*domain.ProcessResponse, error) {
+	if strings.TrimSpace(author) == "" {
+		return nil, domain.InvalidArgument("an author is required to edit results")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	stored, current, err := e.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Learners see published content as approved; reopen it first.
+	if stored.Status == domain.ReviewPublished {
+		return nil, domain.NewDomainError(domain.ErrorCodeConflict, "published results must be reopened for review before editing", nil)
+	}
+	revisions, err := e.revisions.ListRevisions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Record the content the edit starts from if no revision holds it:
+	// the generated content on the first edit, or a regenerated document.
+	if base := baseRevision(revisions, stored, current); base != nil {
+		if err := e.revisions.AddRevision(ctx, base, nil); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, base)
+	}
+
+	edited := copyResponse(current)
+	rev, err := fn(edited)
+	if err != nil {
+		return nil, err
+	}
+
+	responseJSON, err := json.Marshal(edited)
+	if err != nil {
+		return nil, err
+	}
+	updated := *stored
+	updated.ResponseJSON = responseJSON
+
+	rev.ResultID = id
+	rev.Number = revisions[len(revisions)-1].Number + 1
+	rev.Author = author
+	rev.CreatedAt = e.now().UTC()
+	rev.Content = edited
+	if err := e.revisions.AddRevision(ctx, rev, &updated); err != nil {
+		return nil, err
+	}
+	e.webhooks.Emit(ctx, webhook.ResultEvent(domain.EventResultUpdated, edited, ""), "")
+	return edited, nil
+}
+
+func (e *Editor) load(ctx context.Context, id string) (*domain.StoredResult, *domain.ProcessResponse, error) {
+	stored, err := e.results.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	var resp domain.ProcessResponse
+	if err := json.Unmarshal(stored.ResponseJSON, &resp); err != nil {
+		return nil, nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+	}
+	return stored, &resp, nil
+}
+
+// baseRevision returns a generated revision for the current content when
+// the latest revision does not match it, or nil when it does.
+func baseRevision(revisions []*domain.ResultRevision, stored *domain.StoredResult, current *domain.ProcessResponse) *domain.ResultRevision {
+	number := 1
+	createdAt := stored.CreatedAt
+	if len(revisions) > 0 {
+		latest := revisions[len(revisions)-1]
+		// Round-trip both through JSON so stored and in-memory copies
+		// compare equal.
+		if latest.Content != nil && reflect.DeepEqual(copyResponse(latest.Content), copyResponse(current)) {
+			return nil
+		}
+		number = latest.Number + 1
+		createdAt = current.CreatedAt
+		if createdAt.Before(latest.CreatedAt) {
+			createdAt = latest.CreatedAt
+		}
+	}
+	return &domain.ResultRevision{
+		ResultID:  stored.ID,
+		Number:    number,
+		Action:    domain.RevisionGenerated,
+		CreatedAt: createdAt.UTC(),
+		Content:   copyResponse(current),
+	}
+}
+
+func copyResponse(resp *domain.ProcessResponse) *domain.ProcessResponse {
+	data, _ := json.Marshal(resp)
+	var copied domain.ProcessResponse
+	_ = json.Unmarshal(data, &copied)
+	return &copied
+}
+
+func mergeFlashcards(existing, cards []domain.Flashcard) ([]domain.Flashcard, error) {
+	byID := make(map[string]domain.Flashcard, len(existing))
+	for _, card := range existing {
+		byID[card.ID] = card
+	}
+	merged := make([]domain.Flashcard, 0, len(cards))
+	seen := make(map[string]bool)
+	for i, card := range cards {
+		if err := domain.ValidateFlashcard(card); err != nil {
+			return nil, domain.InvalidArgument(fmt.Sprintf("flashcards[%d]: %s", i, domain.Message(err)))
+		}
+		if card.ID == "" {
+			card.ID = domain.NewItemID()
+		} else if seen[card.ID] {
+			return nil, domain.InvalidArgument(fmt.Sprintf("flashcards[%d]: id %q is repeated", i, card.ID))
+		} else if prev, ok := byID[card.ID]; ok {
+			card.Ref = prev.Ref
+		} else {
+			return nil, domain.InvalidArgument(fmt.Sprintf("flashcards[%d]: unknown id %q", i, card.ID))
+		}
+		seen[card.ID] = true
+		merged = append(merged, card)
+	}
+	return merged, nil
+}
+
+func mergeQuiz(existing, items []domain.QuizItem) ([]domain.QuizItem, error) {
+	byID := make(map[string]domain.QuizItem, len(existing))
+	for _, item := range existing {
+		byID[item.ID] = item
+	}
+	merged := make([]domain.QuizItem, 0, len(items))
+	seen := make(map[string]bool)
+	for i, item := range items {
+		item = curatedQuizItem(item)
+		if err := domain.ValidateQuizItem(item); err != nil {
+			return nil, domain.InvalidArgument(fmt.Sprintf("quiz[%d]: %s", i, domain.Message(err)))
+		}
+		if item.ID == "" {
+			item.ID = domain.NewItemID()
+		} else if seen[item.ID] {
+			return nil, domain.InvalidArgument(fmt.Sprintf("quiz[%d]: id %q is repeated", i, item.ID))
+		} else if prev, ok := byID[item.ID]; ok {
+			item.Ref = prev.Ref
+			// Unchanged items keep their verification flag.
+			if prev.Q == item.Q && prev.Answer == item.Answer && reflect.DeepEqual(prev.Choices, item.Choices) {
+				item.NeedsReview, item.ReviewNote = prev.NeedsReview, prev.ReviewNote
+			}
+		} else {
+			return nil, domain.InvalidArgument(fmt.Sprintf("quiz[%d]: unknown id %q", i, item.ID))
+		}
+		seen[item.ID] = true
+		merged = append(merged, item)
+	}
+	return merged, nil
+}
+
+// curatedQuizItem drops fields that only answer verification sets.
+func curatedQuizItem(item domain.QuizItem) domain.QuizItem {
+	item.NeedsReview = false
+	item.ReviewNote = ""
+	return item
+}
+
+func itemNotFound(kind string) error {
+	return domain.NewDomainError(domain.ErrorCodeNotFound, kind+" not found", nil)
+}
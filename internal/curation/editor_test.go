@@ -0,0 +1,131 @@
+package curation
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+	"learnforge/internal/store/storetest"
+	"learnforge/internal/webhook"
+)
+
+// draft is a generated result with a quiz item flagged for review.
+func draft() domain.ProcessResponse {
+	return domain.ProcessResponse{
+		ID:         "result-1",
+		Summary:    "Raft elects a leader.",
+		KeyPoints:  []string{"Leaders send heartbeats"},
+		Flashcards: []domain.Flashcard{{ID: "card-1", Q: "What does Raft elect?", A: "A follower", Ref: &domain.SourceRef{SectionID: "s1"}}},
+		Quiz: []domain.QuizItem{{
+			ID: "quiz-1", Q: "Who sends heartbeats?", Choices: []string{"Leader", "Follower"}, Answer: "Follower",
+			NeedsReview: true, ReviewNote: "answer looks wrong",
+		}},
+		CreatedAt: time.Now().Add(-time.Hour),
+	}
+}
+
+func TestEditor_EditsCreateRevisions(t *testing.T) {
+	st := store.NewInMemStore()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "result-1", CreatedAt: time.Now().Add(-time.Hour)}, draft())
+	editor := NewEditor(st, st)
+	ctx := context.Background()
+
+	if _, err := editor.UpdateQuizItem(ctx, "result-1", "quiz-1", "", domain.QuizItem{}); err == nil {
+		t.Error("expected edits without an author to be rejected")
+	}
+	if _, err := editor.UpdateQuizItem(ctx, "result-1", "quiz-1", "ana", domain.QuizItem{Q: "Who sends heartbeats?", Choices: []string{"Leader"}, Answer: "Leader"}); err == nil {
+		t.Error("expected a quiz item with one choice to be rejected")
+	}
+
+	item, err := editor.UpdateQuizItem(ctx, "result-1", "quiz-1", "ana", domain.QuizItem{
+		Q: "Who sends heartbeats?", Choices: []string{"Leader", "Follower"}, Answer: "Leader",
+	})
+	if err != nil || item.ID != "quiz-1" || item.NeedsReview {
+		t.Fatalf("UpdateQuizItem: %+v, %v", item, err)
+	}
+
+	summary := "Raft elects a leader with randomized timeouts."
+	resp, err := editor.Update(ctx, "result-1", "ben", Patch{
+		Summary:    &summary,
+		Flashcards: &[]domain.Flashcard{{ID: "card-1", Q: "What does Raft elect?", A: "A leader"}, {Q: "What triggers an election?", A: "A timeout"}},
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if resp.Summary != summary || len(resp.Flashcards) != 2 || resp.Flashcards[0].Ref == nil || resp.Flashcards[1].ID == "" {
+		t.Errorf("unexpected patched result: %+v", resp)
+	}
+	if _, err := editor.Update(ctx, "result-1", "ben", Patch{Flashcards: &[]domain.Flashcard{{ID: "nope", Q: "Q", A: "A"}}}); err == nil {
+		t.Error("expected an unknown flashcard id to be rejected")
+	}
+	if _, err := editor.Update(ctx, "result-1", "ben", Patch{Flashcards: &[]domain.Flashcard{{ID: "card-1", Q: "Q", A: "A"}, {ID: "card-1", Q: "Q2", A: "A2"}}}); err == nil {
+		t.Error("expected a repeated flashcard id to be rejected")
+	}
+	if _, err := editor.Update(ctx, "result-1", "ben", Patch{Quiz: &[]domain.QuizItem{
+		{ID: "quiz-1", Q: "Q1", Choices: []string{"A", "B"}, Answer: "A"}, {ID: "quiz-1", Q: "Q2", Choices: []string{"A", "B"}, Answer: "B"},
+	}}); err == nil {
+		t.Error("expected a repeated quiz item id to be rejected")
+	}
+
+	revisions, err := editor.Revisions(ctx, "result-1")
+	if err != nil || len(revisions) != 3 {
+		t.Fatalf("expected generated + 2 edits, got %d: %v", len(revisions), err)
+	}
+	if revisions[0].Action != domain.RevisionGenerated || revisions[1].Author != "ana" || revisions[2].Author != "ben" || revisions[2].Content != nil {
+		t.Errorf("unexpected revisions: %+v %+v %+v", revisions[0], revisions[1], revisions[2])
+	}
+
+	reverted, err := editor.Revert(ctx, "result-1", 1, "ana")
+	if err != nil || reverted.Summary != "Raft elects a leader." || reverted.Quiz[0].Answer != "Follower" || reverted.ID != "result-1" {
+		t.Fatalf("Revert: %+v, %v", reverted, err)
+	}
+	stored, _ := st.Get(ctx, "result-1")
+	var current domain.ProcessResponse
+	_ = json.Unmarshal(stored.ResponseJSON, &current)
+	if current.Summary != "Raft elects a leader." {
+		t.Errorf("revert was not saved: %q", current.Summary)
+	}
+	if last, _ := editor.Revision(ctx, "result-1", 4); last == nil || last.Action != domain.RevisionReverted || last.RevertedTo != 1 {
+		t.Errorf("expected revision 4 to record the revert, got %+v", last)
+	}
+}
+
+func TestEditor_UnEditedResultHasGeneratedRevision(t *testing.T) {
+	st := store.NewInMemStore()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "result-1", CreatedAt: time.Now().Add(-time.Hour)}, draft())
+	editor := NewEditor(st, st)
+
+	rev, err := editor.Revision(context.Background(), "result-1", 1)
+	if err != nil || rev.Action != domain.RevisionGenerated || rev.Content == nil || rev.Content.Summary != "Raft elects a leader." {
+		t.Fatalf("expected the generated content as revision 1, got %+v, %v", rev, err)
+	}
+	if _, err := editor.Revision(context.Background(), "result-1", 2); err == nil {
+		t.Error("expected not found for a revision that does not exist")
+	}
+}
+
+func TestEditor_EditsSendResultUpdated(t *testing.T) {
+	st := store.NewInMemStore()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "result-1", CreatedAt: time.Now().Add(-time.Hour)}, draft())
+	ctx := context.Background()
+	if err := st.SaveWebhook(ctx, &domain.WebhookEndpoint{ID: "hook-1", URL: "https://lms.example.com/hooks", Secret: "s", CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	editor := NewEditor(st, st, WithWebhooks(webhook.NewDispatcher(st, webhook.Options{})))
+
+	if err := editor.DeleteFlashcard(ctx, "result-1", "card-1", "ana"); err != nil {
+		t.Fatalf("DeleteFlashcard: %v", err)
+	}
+
+	deliveries, err := st.ListDeliveries(ctx, "hook-1", "", 0)
+	if err != nil || len(deliveries) != 1 || deliveries[0].EventType != domain.EventResultUpdated {
+		t.Fatalf("deliveries = %+v, %v, want one result.updated event", deliveries, err)
+	}
+	var event domain.WebhookEvent
+	if err := json.Unmarshal(deliveries[0].Payload, &event); err != nil || event.Data.ResultID != "result-1" || event.Data.Result == nil || len(event.Data.Result.Flashcards) != 0 {
+		t.Errorf("unexpected payload %s: %v", deliveries[0].Payload, err)
+	}
+}
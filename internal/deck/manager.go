@@ -0,0 +1,641 @@
+// Package deck manages user-defined decks: ordered, tagged collections of
+// flashcards and quiz items drawn from many stored results.
+package deck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/feedback"
+	"learnforge/internal/store"
+	"learnforge/internal/textutil"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxItems bounds the size of one deck.
+	MaxItems = 2000
+
+	// duplicateSimilarity is the Jaccard similarity of content words above
+	// which two items count as the same card.
+	duplicateSimilarity = 0.8
+)
+
+// Manager creates and edits decks. Only a deck's owner may change it;
+// anyone may read or copy it.
+type Manager struct {
+	decks          store.DeckStore
+	results        store.Store
+	feedback       *feedback.Service
+	reviewRequired bool
+	now            func() time.Time
+
+	// mu serializes read-modify-write cycles on decks.
+	mu sync.Mutex
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithReviewRequired hides items of unpublished results from learners.
+func WithReviewRequired(required bool) Option {
+	return func(m *Manager) {
+		m.reviewRequired = required
+	}
+}
+
+// WithFeedback marks items that learners rated down, and leaves them out
+// of decks that exclude them.
+func WithFeedback(feedback *feedback.Service) Option {
+	return func(m *Manager) {
+		m.feedback = feedback
+	}
+}
+
+func NewManager(decks store.DeckStore, results store.Store, opts ...Option) *Manager {
+	m := &Manager{
+		decks:   decks,
+		results: results,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// ItemRef selects items of a stored result. Without an item ID it selects
+// every item of Kind, or every item when Kind is empty too.
+type ItemRef struct {
+	ResultID string   `json:"result_id"`
+	Kind     string   `json:"kind,omitempty"`
+	ItemID   string   `json:"item_id,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// Input describes a new deck.
+type Input struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Items       []ItemRef `json:"items,omitempty"`
+
+	ExcludeLowRated bool `json:"exclude_low_rated,omitempty"`
+}
+
+// Patch changes a deck's details. Nil fields are left alone.
+type Patch struct {
+	Name        *string   `json:"name,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Tags        *[]string `json:"tags,omitempty"`
+
+	ExcludeLowRated *bool `json:"exclude_low_rated,omitempty"`
+}
+
+// CopyRequest copies items of one deck into another. An empty target
+// creates a new deck named Name; no item IDs copies every item.
+type CopyRequest struct {
+	TargetDeckID string   `json:"target_deck_id,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	ItemIDs      []string `json:"item_ids,omitempty"`
+}
+
+// Duplicate is an item that was left out because the deck already had a
+// near-identical one.
+type Duplicate struct {
+	ItemID      string `json:"item_id"`
+	ResultID    string `json:"result_id"`
+	DuplicateOf string `json:"duplicate_of"`
+}
+
+// Change is a deck after an edit, with what the edit did.
+type Change struct {
+	Deck       *domain.Deck `json:"deck"`
+	Added      int          `json:"added"`
+	Removed    int          `json:"removed,omitempty"`
+	Duplicates []Duplicate  `json:"duplicates,omitempty"`
+}
+
+// Create stores a new deck owned by owner.
+func (m *Manager) Create(ctx context.Context, owner string, in Input) (*Change, error) {
+	if err := requireUser(owner); err != nil {
+		return nil, err
+	}
+	now := m.now().UTC()
+	d := &domain.Deck{
+		ID:          uuid.New().String(),
+		Owner:       owner,
+		Description: strings.TrimSpace(in.Description),
+		Tags:        normalizeTags(in.Tags),
+		Items:       []domain.DeckItem{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+
+		ExcludeLowRated: in.ExcludeLowRated,
+	}
+	if err := setName(d, in.Name); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	change, err := m.add(ctx, d, in.Items)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.save(ctx, d); err != nil {
+		return nil, err
+	}
+	return m.resolved(ctx, change, false)
+}
+
+// Get returns a deck with its items' content. learner hides items of
+// results that learners cannot see.
+func (m *Manager) Get(ctx context.Context, id string, learner bool) (*domain.Deck, error) {
+	d, err := m.decks.GetDeck(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.resolve(ctx, d, learner); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// List returns decks without their items' content. Empty filters match
+// everything.
+func (m *Manager) List(ctx context.Context, owner, tag string) ([]*domain.Deck, error) {
+	decks, err := m.decks.ListDecks(ctx, owner, tag)
+	if err != nil {
+		return nil, err
+	}
+	if decks == nil {
+		decks = []*domain.Deck{}
+	}
+	return decks, nil
+}
+
+// Update changes a deck's name, description, tags or whether it excludes
+// low-rated items.
+func (m *Manager) Update(ctx context.Context, id, user string, patch Patch) (*domain.Deck, error) {
+	change, err := m.edit(ctx, id, user, func(d *domain.Deck) (*Change, error) {
+		if patch.Name != nil {
+			if err := setName(d, *patch.Name); err != nil {
+				return nil, err
+			}
+		}
+		if patch.Description != nil {
+			d.Description = strings.TrimSpace(*patch.Description)
+		}
+		if patch.Tags != nil {
+			d.Tags = normalizeTags(*patch.Tags)
+		}
+		if patch.ExcludeLowRated != nil {
+			d.ExcludeLowRated = *patch.ExcludeLowRated
+		}
+		return &Change{Deck: d}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change.Deck, nil
+}
+
+// Delete removes a deck.
+func (m *Manager) Delete(ctx context.Context, id, user string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, err := m.decks.GetDeck(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := checkOwner(d, user); err != nil {
+		return err
+	}
+	return m.decks.DeleteDeck(ctx, id)
+}
+
+// AddItems appends the referenced items, leaving out near-duplicates of
+// items already in the deck.
+func (m *Manager) AddItems(ctx context.Context, id, user string, refs []ItemRef) (*Change, error) {
+	if len(refs) == 0 {
+		return nil, domain.InvalidArgument("items must not be empty")
+	}
+	return m.edit(ctx, id, user, func(d *domain.Deck) (*Change, error) {
+		return m.add(ctx, d, refs)
+	})
+}
+
+// RemoveItem removes an item from a deck.
+func (m *Manager) RemoveItem(ctx context.Context, id, user, itemID string) (*Change, error) {
+	return m.edit(ctx, id, user, func(d *domain.Deck) (*Change, error) {
+		i := indexOf(d.Items, itemID)
+		if i < 0 {
+			return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "deck item not found", nil)
+		}
+		d.Items = append(d.Items[:i], d.Items[i+1:]...)
+		return &Change{Deck: d, Removed: 1}, nil
+	})
+}
+
+// TagItem replaces the tags of a deck item.
+func (m *Manager) TagItem(ctx context.Context, id, user, itemID string, tags []string) (*Change, error) {
+	return m.edit(ctx, id, user, func(d *domain.Deck) (*Change, error) {
+		i := indexOf(d.Items, itemID)
+		if i < 0 {
+			return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "deck item not found", nil)
+		}
+		d.Items[i].Tags = normalizeTags(tags)
+		return &Change{Deck: d}, nil
+	})
+}
+
+// Reorder puts a deck's items in the order of itemIDs, which must list
+// every item exactly once.
+func (m *Manager) Reorder(ctx context.Context, id, user string, itemIDs []string) (*Change, error) {
+	return m.edit(ctx, id, user, func(d *domain.Deck) (*Change, error) {
+		if len(itemIDs) != len(d.Items) {
+			return nil, domain.InvalidArgument(fmt.Sprintf("item_ids must list all %d items of the deck", len(d.Items)))
+		}
+		ordered := make([]domain.DeckItem, 0, len(d.Items))
+		seen := make(map[string]bool, len(itemIDs))
+		for _, itemID := range itemIDs {
+			i := indexOf(d.Items, itemID)
+			if i < 0 || seen[itemID] {
+				return nil, domain.InvalidArgument(fmt.Sprintf("item_ids: %q is unknown or listed twice", itemID))
+			}
+			seen[itemID] = true
+			ordered = append(ordered, d.Items[i])
+		}
+		d.Items = ordered
+		return &Change{Deck: d}, nil
+	})
+}
+
+// Dedupe removes items that are near-identical to an earlier item of the
+// deck, for example after the underlying results were edited.
+func (m *Manager) Dedupe(ctx context.Context, id, user string) (*Change, error) {
+	return m.edit(ctx, id, user, func(d *domain.Deck) (*Change, error) {
+		content, err := m.content(ctx, d.Items)
+		if err != nil {
+			return nil, err
+		}
+		change := &Change{Deck: d}
+		kept := make([]domain.DeckItem, 0, len(d.Items))
+		for _, item := range d.Items {
+			if dup := findDuplicate(kept, content, item); dup != "" {
+				change.Duplicates = append(change.Duplicates, Duplicate{ItemID: item.ItemID, ResultID: item.ResultID, DuplicateOf: dup})
+				continue
+			}
+			kept = append(kept, item)
+		}
+		change.Removed = len(d.Items) - len(kept)
+		d.Items = kept
+		return change, nil
+	})
+}
+
+// Copy copies items of deck id into another deck owned by user, leaving
+// out near-duplicates, or into a new deck.
+func (m *Manager) Copy(ctx context.Context, id, user string, req CopyRequest) (*Change, error) {
+	if err := requireUser(user); err != nil {
+		return nil, err
+	}
+	source, err := m.decks.GetDeck(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	items := source.Items
+	if len(req.ItemIDs) > 0 {
+		items = nil
+		for _, itemID := range req.ItemIDs {
+			i := indexOf(source.Items, itemID)
+			if i < 0 {
+				return nil, domain.InvalidArgument(fmt.Sprintf("item_ids: %q is not in the deck", itemID))
+			}
+			items = append(items, source.Items[i])
+		}
+	}
+	refs := make([]ItemRef, 0, len(items))
+	for _, item := range items {
+		refs = append(refs, ItemRef{ResultID: item.ResultID, Kind: item.Kind, ItemID: item.ItemID, Tags: item.Tags})
+	}
+
+	if req.TargetDeckID == "" {
+		name := req.Name
+		if strings.TrimSpace(name) == "" {
+			name = source.Name + " (copy)"
+		}
+		return m.Create(ctx, user, Input{
+			Name:            name,
+			Description:     source.Description,
+			Tags:            source.Tags,
+			Items:           refs,
+			ExcludeLowRated: source.ExcludeLowRated,
+		})
+	}
+	if req.TargetDeckID == id {
+		return nil, domain.InvalidArgument("target_deck_id must differ from the source deck")
+	}
+	if len(refs) == 0 {
+		return nil, domain.InvalidArgument("the source deck has no items")
+	}
+	return m.AddItems(ctx, req.TargetDeckID, user, refs)
+}
+
+// edit loads deck id, checks that user owns it, applies fn and saves it.
+func (m *Manager) edit(ctx context.Context, id, user string, fn func(d *domain.Deck) (*Change, error)) (*Change, error) {
+	if err := requireUser(user); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, err := m.decks.GetDeck(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkOwner(d, user); err != nil {
+		return nil, err
+	}
+	change, err := fn(d)
+	if err != nil {
+		return nil, err
+	}
+	d.UpdatedAt = m.now().UTC()
+	if err := m.save(ctx, d); err != nil {
+		return nil, err
+	}
+	return m.resolved(ctx, change, false)
+}
+
+// add appends the items refs select to d.
+func (m *Manager) add(ctx context.Context, d *domain.Deck, refs []ItemRef) (*Change, error) {
+	content, err := m.content(ctx, d.Items)
+	if err != nil {
+		return nil, err
+	}
+	change := &Change{Deck: d}
+	for i, ref := range refs {
+		selected, err := m.selectItems(ctx, ref, content)
+		if err != nil {
+			return nil, domain.InvalidArgument(fmt.Sprintf("items[%d]: %s", i, domain.Message(err)))
+		}
+		for _, item := range selected {
+			if dup := findDuplicate(d.Items, content, item); dup != "" {
+				change.Duplicates = append(change.Duplicates, Duplicate{ItemID: item.ItemID, ResultID: item.ResultID, DuplicateOf: dup})
+				continue
+			}
+			if len(d.Items) >= MaxItems {
+				return nil, domain.InvalidArgument(fmt.Sprintf("a deck holds at most %d items", MaxItems))
+			}
+			d.Items = append(d.Items, item)
+			change.Added++
+		}
+	}
+	return change, nil
+}
+
+// selectItems returns the deck items ref selects and records their
+// content.
+func (m *Manager) selectItems(ctx context.Context, ref ItemRef, content map[string]string) ([]domain.DeckItem, error) {
+	if ref.ResultID == "" {
+		return nil, domain.InvalidArgument("result_id is required")
+	}
+	if ref.Kind != "" && ref.Kind != domain.DeckFlashcard && ref.Kind != domain.DeckQuizItem {
+		return nil, domain.InvalidArgument("kind must be one of: flashcard, quiz")
+	}
+	resp, _, err := m.load(ctx, ref.ResultID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []domain.DeckItem
+	tags := normalizeTags(ref.Tags)
+	if ref.Kind != domain.DeckQuizItem {
+		for _, card := range resp.Flashcards {
+			if card.ID != "" && (ref.ItemID == "" || ref.ItemID == card.ID) {
+				items = append(items, domain.DeckItem{Kind: domain.DeckFlashcard, ResultID: ref.ResultID, ItemID: card.ID, Tags: tags})
+				content[card.ID] = card.Q + "\n" + card.A
+			}
+		}
+	}
+	if ref.Kind != domain.DeckFlashcard {
+		for _, quiz := range resp.Quiz {
+			if quiz.ID != "" && (ref.ItemID == "" || ref.ItemID == quiz.ID) {
+				items = append(items, domain.DeckItem{Kind: domain.DeckQuizItem, ResultID: ref.ResultID, ItemID: quiz.ID, Tags: tags})
+				content[quiz.ID] = quiz.Q + "\n" + quiz.Answer
+			}
+		}
+	}
+	if ref.ItemID != "" && len(items) == 0 {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "item not found in result", nil)
+	}
+	return items, nil
+}
+
+// content returns the comparable text of items by item ID. Items whose
+// result or item is gone are left out.
+func (m *Manager) content(ctx context.Context, items []domain.DeckItem) (map[string]string, error) {
+	content := make(map[string]string, len(items))
+	byResult := make(map[string]*domain.ProcessResponse)
+	for _, item := range items {
+		resp, ok := byResult[item.ResultID]
+		if !ok {
+			var err error
+			resp, _, err = m.load(ctx, item.ResultID)
+			if err != nil && !domain.HasCode(err, domain.ErrorCodeNotFound) {
+				return nil, err
+			}
+			byResult[item.ResultID] = resp
+		}
+		if resp == nil {
+			continue
+		}
+		if card := findFlashcard(resp, item.ItemID); card != nil && item.Kind == domain.DeckFlashcard {
+			content[item.ItemID] = card.Q + "\n" + card.A
+		}
+		if quiz := findQuizItem(resp, item.ItemID); quiz != nil && item.Kind == domain.DeckQuizItem {
+			content[item.ItemID] = quiz.Q + "\n" + quiz.Answer
+		}
+	}
+	return content, nil
+}
+
+// resolve fills in the content of d's items and marks the low-rated ones,
+// leaving them out when d excludes them.
+func (m *Manager) resolve(ctx context.Context, d *domain.Deck, learner bool) error {
+	type loaded struct {
+		resp   *domain.ProcessResponse
+		status string
+	}
+	byResult := make(map[string]*loaded)
+	for i := range d.Items {
+		item := &d.Items[i]
+		l, ok := byResult[item.ResultID]
+		if !ok {
+			resp, status, err := m.load(ctx, item.ResultID)
+			if err != nil && !domain.HasCode(err, domain.ErrorCodeNotFound) {
+				return err
+			}
+			l = &loaded{resp: resp, status: status}
+			byResult[item.ResultID] = l
+		}
+		if l.resp == nil || (learner && m.reviewRequired && l.status != domain.ReviewPublished) {
+			item.Missing = true
+			continue
+		}
+		item.Topic = l.resp.Topic
+		switch item.Kind {
+		case domain.DeckFlashcard:
+			item.Flashcard = findFlashcard(l.resp, item.ItemID)
+			item.Missing = item.Flashcard == nil
+		case domain.DeckQuizItem:
+			item.Quiz = findQuizItem(l.resp, item.ItemID)
+			item.Missing = item.Quiz == nil
+		}
+	}
+
+	if m.feedback == nil {
+		return nil
+	}
+	resultIDs := make([]string, 0, len(byResult))
+	for id := range byResult {
+		resultIDs = append(resultIDs, id)
+	}
+	lowRated, err := m.feedback.LowRated(ctx, resultIDs)
+	if err != nil {
+		return err
+	}
+	kept := d.Items[:0]
+	for _, item := range d.Items {
+		item.LowRated = lowRated[feedback.Key(item.ResultID, item.Kind, item.ItemID)]
+		if !item.LowRated || !d.ExcludeLowRated {
+			kept = append(kept, item)
+		}
+	}
+	d.Items = kept
+	return nil
+}
+
+// save stores d without the content filled in by resolve, so decks keep
+// following edits to their results.
+func (m *Manager) save(ctx context.Context, d *domain.Deck) error {
+	for i := range d.Items {
+		item := &d.Items[i]
+		item.Flashcard, item.Quiz, item.Topic, item.Missing, item.LowRated = nil, nil, "", false, false
+	}
+	return m.decks.SaveDeck(ctx, d)
+}
+
+func (m *Manager) resolved(ctx context.Context, change *Change, learner bool) (*Change, error) {
+	if err := m.resolve(ctx, change.Deck, learner); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+func (m *Manager) load(ctx context.Context, resultID string) (*domain.ProcessResponse, string, error) {
+	stored, err := m.results.Get(ctx, resultID)
+	if err != nil {
+		return nil, "", err
+	}
+	var resp domain.ProcessResponse
+	if err := json.Unmarshal(stored.ResponseJSON, &resp); err != nil {
+		return nil, "", domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+	}
+	return &resp, stored.Status, nil
+}
+
+// findDuplicate returns the ID of an item in items that is the same as
+// item or near-identical to it, or "".
+func findDuplicate(items []domain.DeckItem, content map[string]string, item domain.DeckItem) string {
+	text, ok := content[item.ItemID]
+	for _, existing := range items {
+		if existing.ItemID == item.ItemID {
+			return existing.ItemID
+		}
+		if !ok || existing.Kind != item.Kind {
+			continue
+		}
+		if other, ok := content[existing.ItemID]; ok && textutil.Jaccard(text, other) >= duplicateSimilarity {
+			return existing.ItemID
+		}
+	}
+	return ""
+}
+
+func findFlashcard(resp *domain.ProcessResponse, id string) *domain.Flashcard {
+	for i := range resp.Flashcards {
+		if resp.Flashcards[i].ID == id {
+			card := resp.Flashcards[i]
+			return &card
+		}
+	}
+	return nil
+}
+
+func findQuizItem(resp *domain.ProcessResponse, id string) *domain.QuizItem {
+	for i := range resp.Quiz {
+		if resp.Quiz[i].ID == id {
+			item := resp.Quiz[i]
+			return &item
+		}
+	}
+	return nil
+}
+
+func indexOf(items []domain.DeckItem, itemID string) int {
+	for i, item := range items {
+		if item.ItemID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+func setName(d *domain.Deck, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.InvalidArgument("name is required")
+	}
+	d.Name = name
+	return nil
+}
+
+// normalizeTags lowercases, trims and deduplicates tags.
+func normalizeTags(tags []string) []string {
+	var out []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+func requireUser(user string) error {
+	if strings.TrimSpace(user) == "" {
+		return domain.InvalidArgument("X-User-ID is required to change decks")
+	}
+	return nil
+}
+
+func checkOwner(d *domain.Deck, user string) error {
+	if d.Owner != user {
+		return domain.NewDomainError(domain.ErrorCodeConflict, "only the deck's owner can change it; copy it instead", nil)
+	}
+	return nil
+}
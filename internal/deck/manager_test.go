@@ -0,0 +1,167 @@
+package deck
+
+import (
+	"context"
+	"testing"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+	"learnforge/internal/store/storetest"
+)
+
+func newTestManager(t *testing.T, opts ...Option) (*Manager, *store.InMemStore) {
+	st := store.NewInMemStore()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft", Status: domain.ReviewPublished}, domain.ProcessResponse{
+		Topic: "Raft",
+		Flashcards: []domain.Flashcard{
+			{ID: "raft-c1", Q: "What does the Raft leader replicate to followers?", A: "Log entries"},
+			{ID: "raft-c2", Q: "What triggers a Raft election?", A: "An election timeout"},
+		},
+		Quiz: []domain.QuizItem{
+			{ID: "raft-q1", Q: "Which node accepts client writes?", Choices: []string{"Leader", "Follower"}, Answer: "Leader"},
+		},
+	})
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "paxos", Status: domain.ReviewDraft}, domain.ProcessResponse{
+		Topic: "Consensus",
+		Flashcards: []domain.Flashcard{
+			{ID: "cons-c1", Q: "What does the Raft leader replicate to its followers?", A: "Log entries"},
+			{ID: "cons-c2", Q: "Who proposes values in Paxos?", A: "Proposers"},
+		},
+	})
+	return NewManager(st, st, opts...), st
+}
+
+func itemIDs(d *domain.Deck) []string {
+	var ids []string
+	for _, item := range d.Items {
+		ids = append(ids, item.ItemID)
+	}
+	return ids
+}
+
+func equalIDs(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestManager_CreateMergesResultsWithoutDuplicates(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+
+	change, err := m.Create(ctx, "ana", Input{
+		Name: "Consensus",
+		Tags: []string{"Distributed", "distributed "},
+		Items: []ItemRef{
+			{ResultID: "raft"},
+			{ResultID: "paxos", Kind: domain.DeckFlashcard},
+			{ResultID: "raft", ItemID: "raft-c2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if want := []string{"raft-c1", "raft-c2", "raft-q1", "cons-c2"}; !equalIDs(itemIDs(change.Deck), want) {
+		t.Errorf("items = %v, want %v", itemIDs(change.Deck), want)
+	}
+	if len(change.Duplicates) != 2 || change.Duplicates[0].ItemID != "cons-c1" || change.Duplicates[0].DuplicateOf != "raft-c1" {
+		t.Errorf("duplicates = %+v, want cons-c1 as a duplicate of raft-c1 and raft-c2 again", change.Duplicates)
+	}
+	if len(change.Deck.Tags) != 1 || change.Deck.Tags[0] != "distributed" {
+		t.Errorf("tags = %v, want [distributed]", change.Deck.Tags)
+	}
+	if item := change.Deck.Items[2]; item.Quiz == nil || item.Quiz.Answer != "Leader" || item.Topic != "Raft" {
+		t.Errorf("quiz item was not resolved: %+v", item)
+	}
+
+	if _, err := m.Create(ctx, "ana", Input{Name: "Bad", Items: []ItemRef{{ResultID: "raft", ItemID: "nope"}}}); err == nil {
+		t.Error("expected an unknown item to be rejected")
+	}
+	if _, err := m.Create(ctx, "", Input{Name: "Anonymous"}); err == nil {
+		t.Error("expected decks to need an owner")
+	}
+}
+
+func TestManager_EditsAreOwnerOnly(t *testing.T) {
+	m, _ := newTestManager(t)
+	ctx := context.Background()
+
+	change, err := m.Create(ctx, "ana", Input{Name: "Raft", Items: []ItemRef{{ResultID: "raft"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := change.Deck.ID
+
+	if _, err := m.RemoveItem(ctx, id, "ben", "raft-c1"); err == nil {
+		t.Error("expected someone else's deck not to be editable")
+	}
+	if _, err := m.Reorder(ctx, id, "ana", []string{"raft-q1", "raft-c1"}); err == nil {
+		t.Error("expected a partial order to be rejected")
+	}
+	reordered, err := m.Reorder(ctx, id, "ana", []string{"raft-q1", "raft-c2", "raft-c1"})
+	if err != nil {
+		t.Fatalf("Reorder: %v", err)
+	}
+	if want := []string{"raft-q1", "raft-c2", "raft-c1"}; !equalIDs(itemIDs(reordered.Deck), want) {
+		t.Errorf("items = %v, want %v", itemIDs(reordered.Deck), want)
+	}
+	if _, err := m.TagItem(ctx, id, "ana", "raft-c2", []string{"Hard"}); err != nil {
+		t.Fatalf("TagItem: %v", err)
+	}
+
+	copied, err := m.Copy(ctx, id, "ben", CopyRequest{ItemIDs: []string{"raft-c2"}})
+	if err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	if copied.Deck.Owner != "ben" || copied.Deck.Name != "Raft (copy)" || !equalIDs(itemIDs(copied.Deck), []string{"raft-c2"}) {
+		t.Errorf("unexpected copy: %+v", copied.Deck)
+	}
+	if tags := copied.Deck.Items[0].Tags; len(tags) != 1 || tags[0] != "hard" {
+		t.Errorf("copied item tags = %v, want [hard]", tags)
+	}
+
+	if err := m.Delete(ctx, id, "ben"); err == nil {
+		t.Error("expected someone else's deck not to be deletable")
+	}
+	if err := m.Delete(ctx, id, "ana"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := m.Get(ctx, id, false); err == nil {
+		t.Error("expected the deleted deck to be gone")
+	}
+}
+
+func TestManager_GetHidesUnpublishedItemsFromLearners(t *testing.T) {
+	m, _ := newTestManager(t, WithReviewRequired(true))
+	ctx := context.Background()
+
+	change, err := m.Create(ctx, "ana", Input{Name: "Mixed", Items: []ItemRef{{ResultID: "raft", ItemID: "raft-c2"}, {ResultID: "paxos", ItemID: "cons-c2"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := m.Get(ctx, change.Deck.ID, true)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if d.Items[0].Missing || d.Items[0].Flashcard == nil {
+		t.Errorf("published item should be visible: %+v", d.Items[0])
+	}
+	if !d.Items[1].Missing || d.Items[1].Flashcard != nil {
+		t.Errorf("draft item should be hidden from learners: %+v", d.Items[1])
+	}
+
+	d, err = m.Get(ctx, change.Deck.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Items[1].Missing {
+		t.Error("admins should see draft items")
+	}
+}
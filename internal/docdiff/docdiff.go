@@ -0,0 +1,146 @@
+// Package docdiff splits documents into sections and compares revisions
+// so only content derived from changed sections has to be regenerated.
+package docdiff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"learnforge/internal/textutil"
+)
+
+// Section is a heading and the text under it, or a paragraph when the
+// document has no headings.
+type Section struct {
+	ID    string // content hash; identical sections share an ID plus a suffix
+	Title string
+	Text  string
+}
+
+var heading = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+
+// Split divides Markdown-like text into sections at headings. Text without
+// headings is split into paragraphs instead.
+func Split(text string) []Section {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	hasHeadings := false
+	for _, line := range lines {
+		if heading.MatchString(line) {
+			hasHeadings = true
+			break
+		}
+	}
+
+	var sections []Section
+	var title string
+	var body []string
+	flush := func() {
+		content := strings.TrimSpace(strings.Join(body, "\n"))
+		if content != "" || title != "" {
+			sections = append(sections, Section{Title: title, Text: content})
+		}
+		title, body = "", nil
+	}
+
+	for _, line := range lines {
+		if hasHeadings {
+			if m := heading.FindStringSubmatch(line); m != nil {
+				flush()
+				title = m[1]
+				continue
+			}
+		} else if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	seen := map[string]int{}
+	for i := range sections {
+		id := hash(sections[i].Title + "\n" + sections[i].Text)
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id = fmt.Sprintf("%s-%d", id, n)
+		}
+		sections[i].ID = id
+	}
+	return sections
+}
+
+// hash identifies a section by its content, ignoring whitespace changes.
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(s), " ")))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Plan describes how a new revision differs from the previous one.
+type Plan struct {
+	Sections  []Section // all sections of the new revision, in order
+	Changed   []Section // sections that are new or edited
+	Unchanged map[string]bool
+	Removed   int
+}
+
+// Diff compares section lists by content hash: a section with the same
+// hash in both revisions is unchanged, whatever its position.
+func Diff(previous, current []Section) Plan {
+	prev := make(map[string]bool, len(previous))
+	for _, s := range previous {
+		prev[s.ID] = true
+	}
+
+	plan := Plan{Sections: current, Unchanged: map[string]bool{}}
+	for _, s := range current {
+		if prev[s.ID] {
+			plan.Unchanged[s.ID] = true
+			delete(prev, s.ID)
+		} else {
+			plan.Changed = append(plan.Changed, s)
+		}
+	}
+	plan.Removed = len(prev)
+	return plan
+}
+
+// ChangedRatio is the share of the new revision's sections that changed.
+func (p Plan) ChangedRatio() float64 {
+	if len(p.Sections) == 0 {
+		return 0
+	}
+	return float64(len(p.Changed)) / float64(len(p.Sections))
+}
+
+// Text joins sections back into Markdown.
+func Text(sections []Section) string {
+	parts := make([]string, 0, len(sections))
+	for _, s := range sections {
+		part := s.Text
+		if s.Title != "" {
+			part = strings.TrimSpace("## " + s.Title + "\n\n" + s.Text)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Assign returns the section whose words best cover text. It returns
+// false when text shares no content words with any section.
+func Assign(text string, sections []Section) (Section, bool) {
+	best, bestScore := -1, 0.0
+	for i, s := range sections {
+		score := textutil.Coverage(text, textutil.WordSet(s.Title+" "+s.Text))
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 || len(textutil.ContentWords(text)) == 0 {
+		return Section{}, false
+	}
+	return sections[best], true
+}
@@ -0,0 +1,30 @@
+package docdiff
+
+import "testing"
+
+func TestSplitAndDiff(t *testing.T) {
+	prev := Split("Intro line.\n\n# Setup\n\nInstall the tools.\n\n## Usage ##\n\nRun   the binary.")
+	if len(prev) != 3 || prev[0].Title != "" || prev[1].Title != "Setup" || prev[2].Title != "Usage" {
+		t.Fatalf("unexpected sections: %+v", prev)
+	}
+
+	// Whitespace-only edits and reordering keep sections unchanged.
+	next := Split("# Usage\n\nRun the binary.\n\n# Setup\n\nInstall the tools first.")
+	plan := Diff(prev, next)
+	if len(plan.Changed) != 1 || plan.Changed[0].Title != "Setup" {
+		t.Errorf("changed = %+v", plan.Changed)
+	}
+	if !plan.Unchanged[next[0].ID] || plan.Removed != 2 {
+		t.Errorf("unchanged = %v, removed = %d", plan.Unchanged, plan.Removed)
+	}
+
+	paragraphs := Split("First paragraph.\n\nSecond paragraph.\n\nFirst paragraph.")
+	if len(paragraphs) != 3 || paragraphs[0].ID == paragraphs[2].ID {
+		t.Errorf("duplicate paragraphs need distinct ids: %+v", paragraphs)
+	}
+
+	sec, ok := Assign("Which tools must be installed?", next)
+	if !ok || sec.Title != "Setup" {
+		t.Errorf("Assign = %+v %v", sec, ok)
+	}
+}
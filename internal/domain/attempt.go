@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Attempt is a learner's scored submission of answers to a stored quiz,
+// or to a variant of an exam. Exam attempts have no ResultID; their
+// answers use the exam's question IDs.
+type Attempt struct {
+	ID         string          `json:"id"`
+	ResultID   string          `json:"result_id,omitempty"`
+	ExamID     string          `json:"exam_id,omitempty"`
+	Variant    int             `json:"variant,omitempty"` // of the exam
+	LearnerID  string          `json:"learner_id"`
+	Topic      string          `json:"topic"`
+	Answers    []AttemptAnswer `json:"answers"` // one per quiz item, in quiz order
+	Correct    int             `json:"correct"`
+	Total      int             `json:"total"`
+	Score      float64         `json:"score"`       // Correct / Total, 0 to 1
+	DurationMS int64           `json:"duration_ms"` // sum of the answers' times
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AttemptAnswer is the answer to one quiz item, scored. CorrectAnswer and
+// Explanation are copied from the item as it was when the attempt was made.
+type AttemptAnswer struct {
+	ItemID        string `json:"item_id"`
+	Question      string `json:"question"`
+	Answer        string `json:"answer,omitempty"`
+	Skipped       bool   `json:"skipped,omitempty"`
+	Correct       bool   `json:"correct"`
+	CorrectAnswer string `json:"correct_answer,omitempty"` // left out of exam attempts learners see
+	Explanation   string `json:"explanation,omitempty"`
+	TimeMS        int64  `json:"time_ms"`
+}
+
+// TopicMastery summarizes a learner's attempts on one topic. Mastery is
+// the share of the topic's questions whose latest answer was correct.
+type TopicMastery struct {
+	Topic         string    `json:"topic"`
+	Attempts      int       `json:"attempts"`
+	Questions     int       `json:"questions"`
+	Correct       int       `json:"correct"`
+	Mastery       float64   `json:"mastery"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+}
+
+// LearnerHistory is a learner's past attempts with mastery by topic.
+type LearnerHistory struct {
+	LearnerID string         `json:"learner_id"`
+	Attempts  []*Attempt     `json:"attempts"` // newest first
+	Topics    []TopicMastery `json:"topics"`   // by topic
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// Course groups lessons generated from a directory of documents
+type Course struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Root       string          `json:"root"`                  // directory the course was built from
+	CommitHash string          `json:"commit_hash,omitempty"` // HEAD of the docs repository at build time
+	Lessons    []CourseLesson  `json:"lessons"`
+	LastBuild  *CourseBuildLog `json:"last_build,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// CourseLesson is one generated lesson, backed by a stored result
+type CourseLesson struct {
+	Path        string          `json:"path"` // relative to the course root
+	Title       string          `json:"title"`
+	ContentHash string          `json:"content_hash"`          // hex SHA-256 of the document
+	CommitHash  string          `json:"commit_hash,omitempty"` // last commit touching the document
+	ResultID    string          `json:"result_id"`
+	Anchors     []HeadingAnchor `json:"anchors,omitempty"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// HeadingAnchor is a heading of a source document and its link fragment
+type HeadingAnchor struct {
+	Level  int    `json:"level"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor"`
+}
+
+// CourseBuildLog summarizes what a course build changed
+type CourseBuildLog struct {
+	Generated []string `json:"generated"`
+	Unchanged []string `json:"unchanged"`
+	Removed   []string `json:"removed"`
+	Failed    []string `json:"failed,omitempty"` // "path: error"
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// Deck item kinds
+const (
+	DeckFlashcard = "flashcard"
+	DeckQuizItem  = "quiz"
+)
+
+// Deck is a user-defined collection of flashcards and quiz items drawn
+// from any number of stored results. Items are references, so edits to a
+// result show up in every deck that uses its items.
+type Deck struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Owner       string     `json:"owner"` // X-User-ID of the creator
+	Tags        []string   `json:"tags,omitempty"`
+	Items       []DeckItem `json:"items"` // in study order
+
+	// ExcludeLowRated leaves items that learners rated down out when the
+	// deck is read or studied.
+	ExcludeLowRated bool `json:"exclude_low_rated,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DeckItem references one flashcard or quiz item of a stored result.
+// Item IDs are unique within a deck.
+type DeckItem struct {
+	Kind     string   `json:"kind"` // flashcard, quiz
+	ResultID string   `json:"result_id"`
+	ItemID   string   `json:"item_id"`
+	Tags     []string `json:"tags,omitempty"`
+
+	// Filled in when a deck is read. Missing is set when the item was
+	// removed from its result or is not visible to the reader.
+	Flashcard *Flashcard `json:"flashcard,omitempty"`
+	Quiz      *QuizItem  `json:"quiz,omitempty"`
+	Topic     string     `json:"topic,omitempty"`
+	Missing   bool       `json:"missing,omitempty"`
+	LowRated  bool       `json:"low_rated,omitempty"`
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrorCode represents different error types
 type ErrorCode string
@@ -11,6 +14,7 @@ const (
 	ErrorCodeUpstreamTimeout ErrorCode = "upstream_timeout"
 	ErrorCodeUpstreamError   ErrorCode = "upstream_error"
 	ErrorCodeNotFound        ErrorCode = "not_found"
+	ErrorCodeConflict        ErrorCode = "conflict"
 )
 
 // DomainError represents a domain-level error
@@ -40,3 +44,34 @@ func NewDomainError(code ErrorCode, message string, err error) *DomainError {
 	}
 }
 
+// InvalidArgument creates an invalid_argument error for a bad request
+func InvalidArgument(message string) *DomainError {
+	return NewDomainError(ErrorCodeInvalidArgument, message, nil)
+}
+
+// HasCode reports whether err is or wraps a domain error with code
+func HasCode(err error, code ErrorCode) bool {
+	var domainErr *DomainError
+	return errors.As(err, &domainErr) && domainErr.Code == code
+}
+
+// CodeOf returns the code of the domain error err is or wraps, and
+// ErrorCodeInternal for any other error
+func CodeOf(err error) ErrorCode {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Code
+	}
+	return ErrorCodeInternal
+}
+
+// Message returns the message of the domain error err is or wraps, and
+// err.Error() for any other error
+func Message(err error) string {
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return domainErr.Message
+	}
+	return err.Error()
+}
+
@@ -0,0 +1,112 @@
+package domain
+
+import "time"
+
+// Quiz question types
+const (
+	QuestionMultipleChoice = "multiple_choice"
+	QuestionTrueFalse      = "true_false"
+)
+
+// Exam is a fixed set of quiz items drawn from stored results, in one or
+// more randomized variants. Items are copied when the exam is built, so
+// later edits to the results do not change its answer keys.
+type Exam struct {
+	ID        string        `json:"id"`
+	Title     string        `json:"title"`
+	CreatedBy string        `json:"created_by,omitempty"` // X-User-ID of the author
+	Spec      ExamSpec      `json:"spec"`
+	Seed      int64         `json:"seed"` // rebuilding the spec with it picks the same items from the same bank
+	Items     []ExamItem    `json:"items"`
+	Variants  []ExamVariant `json:"variants"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// ExamSpec constrains which quiz items an exam draws. Empty fields match
+// everything.
+type ExamSpec struct {
+	// Topics is the topic mix. Topics without a count share Count evenly.
+	Topics []TopicQuota `json:"topics,omitempty"`
+	Count  int          `json:"count,omitempty"`
+
+	// MinLevel and MaxLevel bound the requested level of the results
+	// items are drawn from; results without a level are left out when
+	// either is set.
+	MinLevel string   `json:"min_level,omitempty"`
+	MaxLevel string   `json:"max_level,omitempty"`
+	Types    []string `json:"types,omitempty"` // multiple_choice, true_false
+
+	// MinPValue and MaxPValue bound the share of learners who answered
+	// items correctly, see ItemStats. Items without enough responses for
+	// a reliable p-value are left out when either is set.
+	MinPValue *float64 `json:"min_p_value,omitempty"`
+	MaxPValue *float64 `json:"max_p_value,omitempty"`
+
+	ExcludeItems   []ExamItemRef `json:"exclude_items,omitempty"`
+	ExcludeResults []string      `json:"exclude_results,omitempty"`
+
+	// ExcludeLowRated leaves out items that learners rated down.
+	ExcludeLowRated bool `json:"exclude_low_rated,omitempty"`
+
+	Variants int    `json:"variants,omitempty"` // 1 by default
+	Seed     *int64 `json:"seed,omitempty"`     // random when not set
+}
+
+// TopicQuota is how many items of an exam come from results on Topic.
+type TopicQuota struct {
+	Topic string `json:"topic"`
+	Count int    `json:"count,omitempty"`
+}
+
+// ExamItemRef identifies a quiz item of a stored result.
+type ExamItemRef struct {
+	ResultID string `json:"result_id"`
+	ItemID   string `json:"item_id"`
+}
+
+// ExamItem is a quiz item as it was when the exam was built. ID is its
+// question ID within the exam, the same in every variant.
+type ExamItem struct {
+	ID       string   `json:"id"`
+	ResultID string   `json:"result_id"`
+	Topic    string   `json:"topic"`
+	Level    string   `json:"level,omitempty"`
+	PValue   *float64 `json:"p_value,omitempty"` // when the spec bounds it
+	Type     string   `json:"type"`
+	Item     QuizItem `json:"item"`
+}
+
+// ExamVariant is one ordering of an exam's items and their choices.
+type ExamVariant struct {
+	Number    int            `json:"number"` // 1-based
+	Label     string         `json:"label"`  // A, B, ...
+	Questions []ExamQuestion `json:"questions"`
+}
+
+// ExamQuestion is an exam item as it appears in a variant. Answer and
+// AnswerLabel are left out of the copies learners see.
+type ExamQuestion struct {
+	Number      int      `json:"number"` // 1-based position in the variant
+	ItemID      string   `json:"item_id"`
+	Topic       string   `json:"topic"`
+	Type        string   `json:"type"`
+	Q           string   `json:"q"`
+	Choices     []string `json:"choices"` // without letter labels, shown as A, B, ...
+	Answer      string   `json:"answer,omitempty"`
+	AnswerLabel string   `json:"answer_label,omitempty"`
+}
+
+// AnswerKeyEntry is the correct answer to one question of a variant.
+type AnswerKeyEntry struct {
+	Number       int    `json:"number"`
+	ItemID       string `json:"item_id"`
+	AnswerLabel  string `json:"answer_label"`
+	Answer       string `json:"answer"`
+	ResultID     string `json:"result_id"`
+	SourceItemID string `json:"source_item_id"`
+}
+
+// ChoiceLetter returns the letter shown in front of the i-th choice.
+func ChoiceLetter(i int) string {
+	return string(rune('A' + i))
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Experiment event kinds
+const (
+	ExperimentEventGeneration = "generation"
+	ExperimentEventOutcome    = "outcome"
+)
+
+// Learner outcome kinds
+const (
+	OutcomeFeedback  = "feedback"
+	OutcomeQuizScore = "quiz_score"
+)
+
+// ExperimentEvent records a single observation for an experiment variant:
+// either a generation attempt or a learner outcome for a generated result.
+type ExperimentEvent struct {
+	ID               string    `json:"id"`
+	Experiment       string    `json:"experiment"`
+	Variant          string    `json:"variant"`
+	Kind             string    `json:"kind"` // generation, outcome
+	ResultID         string    `json:"result_id,omitempty"`
+	Success          bool      `json:"success"`
+	LatencyMS        int64     `json:"latency_ms"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	CostUSD          float64   `json:"cost_usd"`
+	OutcomeKind      string    `json:"outcome_kind,omitempty"` // feedback, quiz_score
+	Score            float64   `json:"score,omitempty"`        // 0.0-1.0
+	CreatedAt        time.Time `json:"created_at"`
+}
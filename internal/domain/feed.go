@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// FeedSubscription turns new articles of an RSS or Atom feed into lessons
+type FeedSubscription struct {
+	ID          string  `json:"id"`
+	URL         string  `json:"url"`
+	Title       string  `json:"title,omitempty"` // taken from the feed on first poll
+	Topic       *string `json:"topic,omitempty"`
+	Level       *string `json:"level,omitempty"`
+	Mode        string  `json:"mode,omitempty"`
+	Language    string  `json:"language,omitempty"`
+	Schedule    string  `json:"schedule"`     // cron expression, UTC
+	NotifySlack bool    `json:"notify_slack"` // post new lessons to the summary channel
+
+	// SeenGUIDs is the poll cursor: entries already turned into lessons,
+	// most recent first.
+	SeenGUIDs    []string     `json:"seen_guids,omitempty"`
+	Recent       []FeedLesson `json:"recent,omitempty"` // latest generated lessons, newest first
+	LastPolledAt *time.Time   `json:"last_polled_at,omitempty"`
+	LastError    string       `json:"last_error,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// FeedLesson records a lesson generated from a feed entry
+type FeedLesson struct {
+	GUID        string     `json:"guid"`
+	Title       string     `json:"title"`
+	Link        string     `json:"link,omitempty"`
+	ResultID    string     `json:"result_id"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,97 @@
+package domain
+
+import "time"
+
+// Parts of a result that take feedback
+const (
+	FeedbackSummary   = "summary"
+	FeedbackFlashcard = "flashcard"
+	FeedbackQuiz      = "quiz"
+)
+
+// Feedback ratings
+const (
+	FeedbackUp   = "up"
+	FeedbackDown = "down"
+)
+
+// Feedback categories
+const (
+	FeedbackWrongAnswer = "wrong_answer"
+	FeedbackAmbiguous   = "ambiguous"
+	FeedbackOffTopic    = "off_topic"
+	FeedbackTypo        = "typo"
+)
+
+var ValidFeedbackCategories = []string{FeedbackWrongAnswer, FeedbackAmbiguous, FeedbackOffTopic, FeedbackTypo}
+
+// Feedback is a learner's rating of a result's summary or one of its
+// items. A learner has one feedback per item; giving it again replaces
+// it. PromptVersion, Provider and Model are copied from the result so
+// feedback can be compared across prompts and models.
+type Feedback struct {
+	ID         string   `json:"id"`
+	ResultID   string   `json:"result_id"`
+	Kind       string   `json:"kind"`              // summary, flashcard, quiz
+	ItemID     string   `json:"item_id,omitempty"` // empty for the summary
+	LearnerID  string   `json:"learner_id"`
+	Rating     string   `json:"rating"` // up, down
+	Categories []string `json:"categories,omitempty"`
+	Comment    string   `json:"comment,omitempty"`
+
+	PromptVersion string    `json:"prompt_version,omitempty"`
+	Provider      string    `json:"provider,omitempty"`
+	Model         string    `json:"model,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// FeedbackCounts tallies ratings and categories.
+type FeedbackCounts struct {
+	Up         int            `json:"up"`
+	Down       int            `json:"down"`
+	Categories map[string]int `json:"categories,omitempty"`
+}
+
+// Add counts f.
+func (c *FeedbackCounts) Add(f *Feedback) {
+	if f.Rating == FeedbackUp {
+		c.Up++
+	} else {
+		c.Down++
+	}
+	for _, category := range f.Categories {
+		if c.Categories == nil {
+			c.Categories = make(map[string]int)
+		}
+		c.Categories[category]++
+	}
+}
+
+// ItemFeedback is the feedback on one part of a result. LowRated is set
+// when enough learners rated it down, see FEEDBACK_MIN_DOWNVOTES.
+type ItemFeedback struct {
+	ResultID string `json:"result_id"`
+	Kind     string `json:"kind"`
+	ItemID   string `json:"item_id,omitempty"`
+	FeedbackCounts
+	LowRated bool `json:"low_rated,omitempty"`
+}
+
+// FeedbackGroup is the feedback on results generated with one prompt
+// version and model.
+type FeedbackGroup struct {
+	PromptVersion string `json:"prompt_version"`
+	Provider      string `json:"provider"`
+	Model         string `json:"model"`
+	Results       int    `json:"results"` // results that got feedback
+	FeedbackCounts
+}
+
+// FeedbackReport aggregates the feedback given in [From, To).
+type FeedbackReport struct {
+	From     time.Time       `json:"from"`
+	To       time.Time       `json:"to"`
+	Totals   FeedbackCounts  `json:"totals"`
+	Groups   []FeedbackGroup `json:"groups"`    // most rated down first
+	LowRated []ItemFeedback  `json:"low_rated"` // most rated down first
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// IdempotencyStatus is the state of an idempotency key
+type IdempotencyStatus string
+
+const (
+	IdempotencyPending   IdempotencyStatus = "pending"   // a request holding the key is running
+	IdempotencyCompleted IdempotencyStatus = "completed" // ResultID holds the result
+)
+
+// IdempotencyRecord remembers which request used a key, so a retry gets
+// the original result and a different request reusing the key is rejected.
+type IdempotencyRecord struct {
+	Scope       string            `json:"scope"` // the caller the key belongs to
+	Key         string            `json:"key"`
+	Fingerprint string            `json:"fingerprint"` // hash of the request body
+	Status      IdempotencyStatus `json:"status"`
+	ResultID    string            `json:"result_id,omitempty"`
+	// LockedUntil bounds a pending claim, so a key held by a crashed
+	// instance can be taken over.
+	LockedUntil time.Time `json:"locked_until"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,47 @@
+package domain
+
+// Item analytics flags
+const (
+	FlagTooEasy                  = "too_easy"
+	FlagTooHard                  = "too_hard"
+	FlagNegativeDiscrimination   = "negative_discrimination" // stronger learners miss it more; often a wrong key
+	FlagNonFunctioningDistractor = "non_functioning_distractor"
+)
+
+// ItemStats are classical test statistics of one quiz item, from each
+// learner's first attempt at its quiz.
+type ItemStats struct {
+	ResultID  string `json:"result_id"`
+	ItemID    string `json:"item_id"`
+	Topic     string `json:"topic"`
+	Question  string `json:"question"`
+	Responses int    `json:"responses"` // skipped ones included
+	Skipped   int    `json:"skipped"`
+
+	// PValue is the share of responses that were correct. Discrimination
+	// is the point-biserial correlation between the item and the rest of
+	// the quiz; nil when every response scored the same.
+	PValue         float64       `json:"p_value"`
+	Discrimination *float64      `json:"point_biserial,omitempty"`
+	Choices        []ChoiceStats `json:"choices"`
+	MedianTimeMS   int64         `json:"median_time_ms"`
+	MeanTimeMS     int64         `json:"mean_time_ms"`
+	Flags          []string      `json:"flags,omitempty"` // only with enough responses
+}
+
+// ChoiceStats is how often a choice was selected among answered
+// responses.
+type ChoiceStats struct {
+	Choice  string  `json:"choice"`
+	Correct bool    `json:"correct"`
+	Count   int     `json:"count"`
+	Rate    float64 `json:"rate"`
+}
+
+// QuizStats are the item statistics of a result's quiz.
+type QuizStats struct {
+	ResultID string      `json:"result_id"`
+	Topic    string      `json:"topic"`
+	Learners int         `json:"learners"`
+	Items    []ItemStats `json:"items"`
+}
@@ -0,0 +1,87 @@
+package domain
+
+import "time"
+
+// JobStatus is the state of an asynchronous processing job
+type JobStatus string
+
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"   // permanent error, not retried
+	JobDead      JobStatus = "dead"     // retries exhausted
+	JobCanceled  JobStatus = "canceled" // canceled before it finished
+)
+
+// Terminal reports whether the job will not run again.
+func (s JobStatus) Terminal() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobDead || s == JobCanceled
+}
+
+// Job is a ProcessRequest queued for background processing
+type Job struct {
+	ID              string         `json:"id"`
+	Status          JobStatus      `json:"status"`
+	Request         ProcessRequest `json:"request"`
+	UserID          string         `json:"-"`                  // kept for experiment assignment
+	APIKeyHash      string         `json:"-"`                  // the caller's scope, see ProcessRequest.KeyHash
+	Progress        string         `json:"progress,omitempty"` // last stage reported while running
+	Attempts        int            `json:"attempts"`
+	MaxAttempts     int            `json:"max_attempts"`
+	ResultID        string         `json:"result_id,omitempty"`
+	Error           string         `json:"error,omitempty"` // last attempt's error
+	CancelRequested bool           `json:"cancel_requested,omitempty"`
+	AvailableAt     time.Time      `json:"available_at"` // not claimed before this time (retry backoff)
+	StartedAt       *time.Time     `json:"started_at,omitempty"`
+	FinishedAt      *time.Time     `json:"finished_at,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+
+	// BatchID and BatchIndex place the job within a batch, in submission
+	// order.
+	BatchID    string `json:"batch_id,omitempty"`
+	BatchIndex int    `json:"batch_index,omitempty"`
+
+	// LeaseID identifies the worker's claim; LockedUntil is when the
+	// claim expires and the job becomes visible to other workers again.
+	LeaseID     string     `json:"-"`
+	LockedUntil *time.Time `json:"-"`
+}
+
+// Batch groups jobs submitted together. Each request runs as its own job.
+type Batch struct {
+	ID        string            `json:"id"`
+	Status    string            `json:"status"` // running, completed (every item finished)
+	Total     int               `json:"total"`
+	Counts    map[JobStatus]int `json:"counts"` // items per job status
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// Batch statuses
+const (
+	BatchRunning   = "running"
+	BatchCompleted = "completed"
+)
+
+// BatchItem is the state of one request of a batch
+type BatchItem struct {
+	Index    int       `json:"index"`
+	JobID    string    `json:"job_id"`
+	Status   JobStatus `json:"status"`
+	Attempts int       `json:"attempts"`
+	ResultID string    `json:"result_id,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Item summarizes a batch job without its request.
+func (j *Job) Item() BatchItem {
+	return BatchItem{
+		Index:    j.BatchIndex,
+		JobID:    j.ID,
+		Status:   j.Status,
+		Attempts: j.Attempts,
+		ResultID: j.ResultID,
+		Error:    j.Error,
+	}
+}
@@ -1,51 +1,168 @@
 package domain
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // ProcessRequest represents the incoming request to process text
 type ProcessRequest struct {
-	Text          string  `json:"text"`
-	Mode          string  `json:"mode,omitempty"` // lesson, flashcards, quiz
-	Topic         *string `json:"topic,omitempty"`
-	Level         *string `json:"level,omitempty"` // beginner, intermediate, advanced
-	Language      string  `json:"language,omitempty"`
-	GenerateMeme  bool    `json:"generate_meme,omitempty"` // whether to generate a meme
+	Text           string  `json:"text"`
+	SourceURL      string  `json:"source_url,omitempty"` // fetched and extracted when text is empty
+	Mode           string  `json:"mode,omitempty"`       // lesson, flashcards, quiz
+	Topic          *string `json:"topic,omitempty"`
+	Level          *string `json:"level,omitempty"` // beginner, intermediate, advanced
+	Language       string  `json:"language,omitempty"`
+	GenerateMeme   bool    `json:"generate_meme,omitempty"` // whether to generate a meme
 	IdempotencyKey *string `json:"idempotency_key,omitempty"`
+
+	// Sources are synthesized into one lesson whose items cite them. Text
+	// is replaced by the combined sources so stored requests can be
+	// replayed.
+	Sources []SourceDocument `json:"sources,omitempty"`
+
+	// DocumentID identifies a source document across edits. Resubmitting
+	// the same document updates its result, regenerating only the items
+	// derived from changed sections.
+	DocumentID string `json:"document_id,omitempty"`
+
+	// CallbackURL receives this request's webhook events, signed with the
+	// server's webhook secret.
+	CallbackURL string `json:"callback_url,omitempty"`
+
+	// APIKey and UserID identify the caller. They are taken from request
+	// headers and never persisted. APIKeyHash stands in for APIKey where
+	// the request outlives the HTTP call, as in queued jobs.
+	APIKey     string `json:"-"`
+	APIKeyHash string `json:"-"`
+	UserID     string `json:"-"`
+}
+
+// KeyHash returns the hex SHA-256 of the caller's API key, or "" when the
+// caller sent none.
+func (r *ProcessRequest) KeyHash() string {
+	if r.APIKey == "" {
+		return r.APIKeyHash
+	}
+	hash := sha256.Sum256([]byte(r.APIKey))
+	return hex.EncodeToString(hash[:])
 }
 
 // ProcessResponse represents the structured learning content response
 type ProcessResponse struct {
-	ID              string    `json:"id"`
-	Topic           string    `json:"topic"`
-	TopicSource     string    `json:"topic_source"`     // user, inferred
-	TopicConfidence float64   `json:"topic_confidence"` // 0.0-1.0
-	Summary         string    `json:"summary"`
-	KeyPoints       []string  `json:"key_points"`
-	Flashcards      []Flashcard `json:"flashcards"`
-	Quiz            []QuizItem  `json:"quiz"`
-	MemeURL         *string   `json:"meme_url,omitempty"` // URL to generated meme image
-	Meta            Meta      `json:"meta"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              string       `json:"id"`
+	DocumentID      string       `json:"document_id,omitempty"`
+	Revision        int          `json:"revision,omitempty"` // 1 for the first submission of a document
+	Topic           string       `json:"topic"`
+	TopicSource     string       `json:"topic_source"`     // user, inferred
+	TopicConfidence float64      `json:"topic_confidence"` // 0.0-1.0
+	Summary         string       `json:"summary"`
+	KeyPoints       []string     `json:"key_points"`
+	KeyPointRefs    []*SourceRef `json:"key_point_refs,omitempty"` // aligned with KeyPoints; nil where unlinked
+	Flashcards      []Flashcard  `json:"flashcards"`
+	Quiz            []QuizItem   `json:"quiz"`
+	MemeURL         *string      `json:"meme_url,omitempty"` // URL to generated meme image
+	Source          *SourceInfo  `json:"source,omitempty"`   // where the text came from, for ingested content
+	Sources         []SourceInfo `json:"sources,omitempty"`  // one per request source, in order
+	Meta            Meta         `json:"meta"`
+	Status          string       `json:"status,omitempty"` // review status, filled in when the result is read
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+// SourceDocument is one of several texts combined into a single lesson
+type SourceDocument struct {
+	Title string `json:"title,omitempty"`
+	Text  string `json:"text,omitempty"`
+	URL   string `json:"url,omitempty"` // fetched when text is empty
+}
+
+// SourceInfo describes ingested source content
+type SourceInfo struct {
+	URL          string     `json:"url,omitempty"`
+	CanonicalURL string     `json:"canonical_url,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	FetchedAt    *time.Time `json:"fetched_at,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	FileSHA256   string     `json:"file_sha256,omitempty"` // hex SHA-256 of the uploaded bytes
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+}
+
+// SourceRef links a generated item back to where it came from in the source
+type SourceRef struct {
+	Timestamp    string `json:"timestamp,omitempty"` // "12:34" into a recording
+	StartSeconds int    `json:"start_seconds,omitempty"`
+	Section      string `json:"section,omitempty"`      // heading of the document section
+	SectionID    string `json:"section_id,omitempty"`   // content hash of the section
+	Source       string `json:"source,omitempty"`       // title of the request source the item came from
+	SourceIndex  int    `json:"source_index,omitempty"` // 1-based position in the request's sources
 }
 
 // Flashcard represents a question-answer pair
 type Flashcard struct {
-	Q string `json:"q"`
-	A string `json:"a"`
+	ID  string     `json:"id,omitempty"` // stable across document revisions
+	Q   string     `json:"q"`
+	A   string     `json:"a"`
+	Ref *SourceRef `json:"ref,omitempty"`
 }
 
 // QuizItem represents a quiz question with multiple choice
 type QuizItem struct {
-	Q       string   `json:"q"`
-	Choices []string `json:"choices"`
-	Answer  string   `json:"answer"`
+	ID          string   `json:"id,omitempty"` // stable across document revisions
+	Q           string   `json:"q"`
+	Choices     []string `json:"choices"`
+	Answer      string   `json:"answer"`
+	Explanation string   `json:"explanation,omitempty"`  // why the answer is right
+	NeedsReview bool     `json:"needs_review,omitempty"` // flagged by answer verification
+	ReviewNote  string   `json:"review_note,omitempty"`
+
+	Ref *SourceRef `json:"ref,omitempty"`
+}
+
+// NewItemID returns a short random ID for a flashcard or quiz item.
+func NewItemID() string {
+	id := uuid.New()
+	return hex.EncodeToString(id[:6])
 }
 
 // Meta contains processing metadata
 type Meta struct {
-	Model        string  `json:"model"`
-	Provider     string  `json:"provider"`
-	ProcessingMS int64   `json:"processing_ms"`
+	Model            string  `json:"model"`
+	Provider         string  `json:"provider"`
+	ProcessingMS     int64   `json:"processing_ms"`
+	PromptVersion    string  `json:"prompt_version,omitempty"`
+	PromptTokens     int     `json:"prompt_tokens,omitempty"`
+	CompletionTokens int     `json:"completion_tokens,omitempty"`
+	CostUSD          float64 `json:"cost_usd,omitempty"`
+	Experiment       string  `json:"experiment,omitempty"`
+	Variant          string  `json:"variant,omitempty"`
+	Cache            string  `json:"cache,omitempty"` // generation cache: hit, miss, bypass
+
+	Verification *Verification `json:"verification,omitempty"`
+	Diff         *RevisionDiff `json:"diff,omitempty"`
+}
+
+// Verification summarizes the answer-checking pass over quiz items
+type Verification struct {
+	Action      string `json:"action"` // mark, drop, regenerate
+	Checked     int    `json:"checked"`
+	Flagged     int    `json:"flagged"`
+	Dropped     int    `json:"dropped"`
+	Regenerated int    `json:"regenerated"`
+	Error       string `json:"error,omitempty"`
+}
+
+// RevisionDiff summarizes how a document revision was regenerated
+type RevisionDiff struct {
+	Sections         int  `json:"sections"`
+	ChangedSections  int  `json:"changed_sections"`
+	RemovedSections  int  `json:"removed_sections"`
+	KeptItems        int  `json:"kept_items"`
+	RegeneratedItems int  `json:"regenerated_items"`
+	FullRegeneration bool `json:"full_regeneration,omitempty"` // summary regenerated from the whole document
 }
 
 // StoredResult represents a result stored in the database
@@ -56,6 +173,15 @@ type StoredResult struct {
 	Topic           string
 	TopicSource     string
 	TopicConfidence float64
-	CreatedAt       time.Time
-}
+	Experiment      string
+	Variant         string
+	Status          string // review status; see ReviewDraft
+
+	// Filterable request settings and provider, see ResultQuery.
+	Mode     string
+	Level    string
+	Language string
+	Provider string
 
+	CreatedAt time.Time
+}
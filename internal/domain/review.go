@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// Review statuses of a result. Results are generated as drafts; only
+// published results are shown to learners when review is required.
+const (
+	ReviewDraft     = "draft"
+	ReviewInReview  = "in_review"
+	ReviewPublished = "published"
+	ReviewRejected  = "rejected"
+)
+
+// Review actions
+const (
+	ReviewSubmit  = "submit"  // draft or rejected -> in_review
+	ReviewApprove = "approve" // in_review -> published
+	ReviewReject  = "reject"  // in_review -> rejected
+	ReviewReopen  = "reopen"  // back to draft, e.g. to edit published content
+	ReviewComment = "comment" // no status change
+)
+
+// ReviewEvent is one entry in a result's review audit trail.
+type ReviewEvent struct {
+	ID        string    `json:"id"`
+	ResultID  string    `json:"result_id"`
+	Action    string    `json:"action"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Actor     string    `json:"actor"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ValidateReviewStatus reports whether status is a known review status.
+func ValidateReviewStatus(status string) bool {
+	switch status {
+	case ReviewDraft, ReviewInReview, ReviewPublished, ReviewRejected:
+		return true
+	}
+	return false
+}
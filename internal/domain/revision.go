@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// Revision actions
+const (
+	RevisionGenerated = "generated" // content as produced by the model
+	RevisionEdited    = "edited"
+	RevisionReverted  = "reverted"
+)
+
+// ResultRevision is one saved state of a result's content. The first
+// revision of a result is its generated content; every edit or revert
+// adds one.
+type ResultRevision struct {
+	ResultID   string    `json:"result_id"`
+	Number     int       `json:"number"`
+	Action     string    `json:"action"`
+	Author     string    `json:"author,omitempty"`
+	Changes    []string  `json:"changes,omitempty"`     // what the edit touched, e.g. "summary"
+	RevertedTo int       `json:"reverted_to,omitempty"` // for reverts
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Content is the full result as of this revision. Listings omit it.
+	Content *ProcessResponse `json:"content,omitempty"`
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// Result list sort orders
+const (
+	SortNewest    = "newest"
+	SortOldest    = "oldest"
+	SortTopic     = "topic"
+	SortRelevance = "relevance" // requires a search query
+)
+
+// ResultQuery selects stored results. Empty fields match everything.
+type ResultQuery struct {
+	Topic    string // case-insensitive exact match
+	Mode     string
+	Level    string
+	Language string
+	Provider string
+	Status   string    // review status
+	From     time.Time // created at or after; zero is unbounded
+	To       time.Time // created before; zero is unbounded
+	Search   string    // full-text query over the summary, key points and items
+	Sort     string
+	Cursor   *ResultCursor // continue after this position
+	Limit    int
+}
+
+// ResultCursor is the position of the last result of a page in its sort
+// order.
+type ResultCursor struct {
+	Sort   string `json:"s"`
+	Key    string `json:"k,omitempty"` // created_at (RFC 3339) or topic of the last result
+	ID     string `json:"id,omitempty"`
+	Offset int    `json:"o,omitempty"` // results already returned, for relevance order
+}
+
+// ResultOverview is a stored result as listed, without its items.
+type ResultOverview struct {
+	ID         string    `json:"id"`
+	DocumentID string    `json:"document_id,omitempty"`
+	Topic      string    `json:"topic"`
+	Mode       string    `json:"mode"`
+	Level      string    `json:"level,omitempty"`
+	Language   string    `json:"language"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model"`
+	Status     string    `json:"status"`
+	Summary    string    `json:"summary"`
+	Flashcards int       `json:"flashcards"`
+	QuizItems  int       `json:"quiz_items"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ResultPage is one page of a result listing.
+type ResultPage struct {
+	Results    []ResultOverview `json:"results"`
+	NextCursor string           `json:"next_cursor,omitempty"` // empty on the last page
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Spaced-repetition ratings, from forgotten to effortless recall
+const (
+	RatingAgain = "again"
+	RatingHard  = "hard"
+	RatingGood  = "good"
+	RatingEasy  = "easy"
+)
+
+// ValidateRating reports whether rating is a known rating.
+func ValidateRating(rating string) bool {
+	switch rating {
+	case RatingAgain, RatingHard, RatingGood, RatingEasy:
+		return true
+	}
+	return false
+}
+
+// CardState is one learner's spaced-repetition state for one flashcard.
+// Which fields are used depends on the scheduler that last rated it.
+type CardState struct {
+	LearnerID string `json:"learner_id"`
+	ResultID  string `json:"result_id"`
+	CardID    string `json:"card_id"`
+	Scheduler string `json:"scheduler"` // sm2, fsrs
+
+	Reps         int     `json:"reps"`   // successful reviews in a row
+	Lapses       int     `json:"lapses"` // times rated again after being learned
+	IntervalDays int     `json:"interval_days"`
+	Ease         float64 `json:"ease,omitempty"`       // SM-2 ease factor
+	Stability    float64 `json:"stability,omitempty"`  // FSRS, in days
+	Difficulty   float64 `json:"difficulty,omitempty"` // FSRS, 1 to 10
+
+	LastRating string    `json:"last_rating"`
+	LastReview time.Time `json:"last_review"`
+	Due        time.Time `json:"due"`
+}
+
+// DueCard is a flashcard to study, with the learner's state for it. State
+// is nil for cards the learner has not studied yet.
+type DueCard struct {
+	ResultID  string     `json:"result_id"`
+	Topic     string     `json:"topic"`
+	Flashcard Flashcard  `json:"flashcard"`
+	State     *CardState `json:"state,omitempty"`
+}
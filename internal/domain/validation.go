@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"regexp"
+	"strings"
+)
+
 var (
 	ValidModes  = []string{"lesson", "flashcards", "quiz"}
 	ValidLevels = []string{"beginner", "intermediate", "advanced"}
@@ -29,3 +34,77 @@ func ValidateLevel(level *string) bool {
 	return false
 }
 
+// ValidateFlashcard checks that a flashcard has both a question and an answer.
+func ValidateFlashcard(card Flashcard) error {
+	if strings.TrimSpace(card.Q) == "" {
+		return NewDomainError(ErrorCodeInvalidArgument, "flashcard question is required", nil)
+	}
+	if strings.TrimSpace(card.A) == "" {
+		return NewDomainError(ErrorCodeInvalidArgument, "flashcard answer is required", nil)
+	}
+	return nil
+}
+
+// ValidateQuizItem checks that a quiz item has a question, at least two
+// distinct choices, and an answer that is one of the choices.
+func ValidateQuizItem(item QuizItem) error {
+	if strings.TrimSpace(item.Q) == "" {
+		return NewDomainError(ErrorCodeInvalidArgument, "quiz question is required", nil)
+	}
+	if len(item.Choices) < 2 {
+		return NewDomainError(ErrorCodeInvalidArgument, "quiz item needs at least two choices", nil)
+	}
+
+	seen := make(map[string]bool)
+	answerFound := false
+	for _, choice := range item.Choices {
+		normalized := NormalizeText(choice)
+		if normalized == "" {
+			return NewDomainError(ErrorCodeInvalidArgument, "quiz choices must not be empty", nil)
+		}
+		if seen[normalized] {
+			return NewDomainError(ErrorCodeInvalidArgument, "quiz choices must be distinct", nil)
+		}
+		seen[normalized] = true
+		if choice == item.Answer {
+			answerFound = true
+		}
+	}
+
+	if !answerFound {
+		return NewDomainError(ErrorCodeInvalidArgument, "quiz answer must be one of the choices", nil)
+	}
+	return nil
+}
+
+// choiceLabel matches letter labels like "B. " that models sometimes put
+// in front of choices and answers.
+var choiceLabel = regexp.MustCompile(`^[A-Z]\.\s*`)
+
+// SameChoice reports whether two quiz answers name the same choice,
+// ignoring letter labels, case and extra whitespace.
+func SameChoice(a, b string) bool {
+	return NormalizeText(ChoiceText(a)) == NormalizeText(ChoiceText(b))
+}
+
+// ChoiceText returns a choice or answer without its letter label.
+func ChoiceText(choice string) string {
+	return choiceLabel.ReplaceAllString(strings.TrimSpace(choice), "")
+}
+
+// QuestionType returns whether item is a true/false or a multiple choice
+// question.
+func QuestionType(item QuizItem) string {
+	if len(item.Choices) == 2 {
+		a, b := NormalizeText(ChoiceText(item.Choices[0])), NormalizeText(ChoiceText(item.Choices[1]))
+		if (a == "true" && b == "false") || (a == "false" && b == "true") {
+			return QuestionTrueFalse
+		}
+	}
+	return QuestionMultipleChoice
+}
+
+// NormalizeText lowercases and collapses whitespace for comparisons.
+func NormalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
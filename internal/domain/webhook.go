@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook event types
+const (
+	EventResultCompleted = "result.completed"
+	EventResultUpdated   = "result.updated" // a resubmitted document or a curator edit changed a result
+	EventResultFailed    = "result.failed"  // a request failed, or a background job ran out of attempts
+)
+
+// ValidateEventType checks if the event type is valid
+func ValidateEventType(t string) bool {
+	return t == EventResultCompleted || t == EventResultUpdated || t == EventResultFailed
+}
+
+// WebhookEndpoint receives signed events for every result
+type WebhookEndpoint struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Secret      string    `json:"secret,omitempty"` // only returned when the endpoint is registered
+	Events      []string  `json:"events"`           // subscribed event types; empty means all
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Subscribed reports whether the endpoint wants events of type t.
+func (e *WebhookEndpoint) Subscribed(t string) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, event := range e.Events {
+		if event == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is the body of a webhook delivery
+type WebhookEvent struct {
+	ID        string           `json:"id"`
+	Type      string           `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      WebhookEventData `json:"data"`
+}
+
+// WebhookEventData describes the result an event is about
+type WebhookEventData struct {
+	ResultID   string           `json:"result_id,omitempty"`
+	JobID      string           `json:"job_id,omitempty"`
+	DocumentID string           `json:"document_id,omitempty"`
+	Revision   int              `json:"revision,omitempty"`
+	Error      *WebhookError    `json:"error,omitempty"`  // result.failed only
+	Result     *ProcessResponse `json:"result,omitempty"` // result.completed and result.updated
+}
+
+// WebhookError is the error that failed a job
+type WebhookError struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed" // retries exhausted
+)
+
+// WebhookDelivery is one event sent to one URL, with every attempt made
+type WebhookDelivery struct {
+	ID            string            `json:"id"`
+	EventID       string            `json:"event_id"`
+	EventType     string            `json:"event_type"`
+	EndpointID    string            `json:"endpoint_id,omitempty"` // empty for a request's callback_url
+	URL           string            `json:"url"`
+	Payload       json.RawMessage   `json:"payload"`
+	Status        DeliveryStatus    `json:"status"`
+	Attempts      []DeliveryAttempt `json:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	DeliveredAt   *time.Time        `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// DeliveryAttempt records one HTTP request of a delivery
+type DeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	Replay     bool      `json:"replay,omitempty"` // triggered through the replay endpoint
+}
@@ -0,0 +1,169 @@
+package eval
+
+import (
+	"fmt"
+	"math"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/textutil"
+)
+
+// Check is the outcome of a single structural assertion.
+type Check struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// StructuralChecks verifies item counts, item validity and duplicates.
+func StructuralChecks(c Case, resp *domain.ProcessResponse) []Check {
+	minKeyPoints, minFlashcards, minQuiz := c.minimums()
+	var checks []Check
+
+	if c.mode() == "lesson" {
+		checks = append(checks, Check{
+			Name:   "summary_present",
+			Passed: resp.Summary != "",
+		})
+	}
+
+	checks = append(checks,
+		countCheck("key_points_count", len(resp.KeyPoints), minKeyPoints),
+		countCheck("flashcards_count", len(resp.Flashcards), minFlashcards),
+		countCheck("quiz_count", len(resp.Quiz), minQuiz),
+	)
+
+	invalid := 0
+	var detail string
+	for i, card := range resp.Flashcards {
+		if err := domain.ValidateFlashcard(card); err != nil {
+			invalid++
+			detail = fmt.Sprintf("flashcard %d: %s", i, domain.Message(err))
+		}
+	}
+	checks = append(checks, Check{Name: "flashcards_valid", Passed: invalid == 0, Detail: detail})
+
+	invalid, detail = 0, ""
+	for i, item := range resp.Quiz {
+		if err := domain.ValidateQuizItem(item); err != nil {
+			invalid++
+			detail = fmt.Sprintf("quiz item %d: %s", i, domain.Message(err))
+		}
+	}
+	checks = append(checks, Check{Name: "quiz_answer_in_choices", Passed: invalid == 0, Detail: detail})
+
+	questions := make([]string, 0, len(resp.Flashcards)+len(resp.Quiz))
+	for _, card := range resp.Flashcards {
+		questions = append(questions, card.Q)
+	}
+	for _, item := range resp.Quiz {
+		questions = append(questions, item.Q)
+	}
+	dup := firstDuplicate(questions)
+	checks = append(checks, Check{Name: "no_duplicate_questions", Passed: dup == "", Detail: dup})
+
+	dup = firstDuplicate(resp.KeyPoints)
+	checks = append(checks, Check{Name: "no_duplicate_key_points", Passed: dup == "", Detail: dup})
+
+	return checks
+}
+
+func countCheck(name string, got, min int) Check {
+	return Check{
+		Name:   name,
+		Passed: got >= min,
+		Detail: fmt.Sprintf("got %d, want at least %d", got, min),
+	}
+}
+
+func firstDuplicate(values []string) string {
+	seen := make(map[string]bool)
+	for _, v := range values {
+		n := domain.NormalizeText(v)
+		if n == "" {
+			continue
+		}
+		if seen[n] {
+			return v
+		}
+		seen[n] = true
+	}
+	return ""
+}
+
+// StructuralScore is the share of passed checks.
+func StructuralScore(checks []Check) float64 {
+	if len(checks) == 0 {
+		return 1
+	}
+	passed := 0
+	for _, c := range checks {
+		if c.Passed {
+			passed++
+		}
+	}
+	return float64(passed) / float64(len(checks))
+}
+
+// GroundingScore measures how much of the generated content is supported by
+// the source text: the average share of content words in each key point,
+// flashcard answer and quiz answer that also appear in the source.
+func GroundingScore(source string, resp *domain.ProcessResponse) float64 {
+	reference := textutil.WordSet(source)
+
+	var statements []string
+	statements = append(statements, resp.KeyPoints...)
+	for _, card := range resp.Flashcards {
+		statements = append(statements, card.A)
+	}
+	for _, item := range resp.Quiz {
+		statements = append(statements, item.Answer)
+	}
+	if len(statements) == 0 {
+		return 0
+	}
+
+	var total float64
+	for _, s := range statements {
+		total += textutil.Coverage(s, reference)
+	}
+	return total / float64(len(statements))
+}
+
+// gradeBands maps a requested level to its target Flesch-Kincaid grade range.
+var gradeBands = map[string][2]float64{
+	"beginner":     {0, 8},
+	"intermediate": {6, 12},
+	"advanced":     {9, 18},
+}
+
+// ReadabilityScore compares the grade level of the generated prose with the
+// requested level. It returns the grade and a score that drops by 0.15 for
+// every grade outside the target band. Without a level the score is 1.
+func ReadabilityScore(level *string, resp *domain.ProcessResponse) (grade, score float64) {
+	text := resp.Summary
+	for _, kp := range resp.KeyPoints {
+		text += "\n" + kp
+	}
+	for _, card := range resp.Flashcards {
+		text += "\n" + card.A
+	}
+
+	grade = textutil.GradeLevel(text)
+	if level == nil || *level == "" {
+		return grade, 1
+	}
+
+	band, ok := gradeBands[*level]
+	if !ok {
+		return grade, 1
+	}
+
+	var distance float64
+	if grade < band[0] {
+		distance = band[0] - grade
+	} else if grade > band[1] {
+		distance = grade - band[1]
+	}
+	return grade, math.Max(0, 1-0.15*distance)
+}
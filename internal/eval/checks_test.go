@@ -0,0 +1,65 @@
+package eval
+
+import (
+	"testing"
+
+	"learnforge/internal/domain"
+)
+
+func TestStructuralChecks(t *testing.T) {
+	c := Case{Name: "quiz", Text: "source", Mode: "quiz", MinQuiz: 2}
+
+	resp := &domain.ProcessResponse{
+		Quiz: []domain.QuizItem{
+			{Q: "What is Raft?", Choices: []string{"A consensus algorithm", "A database"}, Answer: "A consensus algorithm"},
+			{Q: "What is Raft?", Choices: []string{"Leader", "Follower"}, Answer: "Candidate"},
+		},
+	}
+
+	failed := make(map[string]bool)
+	for _, check := range StructuralChecks(c, resp) {
+		if !check.Passed {
+			failed[check.Name] = true
+		}
+	}
+
+	if !failed["quiz_answer_in_choices"] {
+		t.Error("Expected answer-not-in-choices to fail")
+	}
+	if !failed["no_duplicate_questions"] {
+		t.Error("Expected duplicate questions to fail")
+	}
+	if failed["quiz_count"] {
+		t.Error("Expected quiz count to pass")
+	}
+}
+
+func TestGroundingScore(t *testing.T) {
+	source := "Chlorophyll absorbs light energy in the chloroplasts."
+
+	grounded := &domain.ProcessResponse{KeyPoints: []string{"Chlorophyll absorbs light energy"}}
+	if got := GroundingScore(source, grounded); got != 1 {
+		t.Errorf("Expected fully grounded score 1, got %v", got)
+	}
+
+	ungrounded := &domain.ProcessResponse{KeyPoints: []string{"Mitochondria produce glucose"}}
+	if got := GroundingScore(source, ungrounded); got != 0 {
+		t.Errorf("Expected ungrounded score 0, got %v", got)
+	}
+}
+
+func TestCompareFlagsRegressions(t *testing.T) {
+	baseline := &Report{
+		Summary: Scores{Structural: 1, Grounding: 0.9, Readability: 1, Overall: 0.97},
+		Cases:   []CaseResult{{Name: "a", Scores: Scores{Structural: 1, Grounding: 0.9, Readability: 1, Overall: 0.97}}},
+	}
+	current := &Report{
+		Summary: Scores{Structural: 1, Grounding: 0.7, Readability: 1, Overall: 0.9},
+		Cases:   []CaseResult{{Name: "a", Scores: Scores{Structural: 1, Grounding: 0.7, Readability: 1, Overall: 0.9}}},
+	}
+
+	cmp := Compare(baseline, current, 0.05)
+	if len(cmp.Regressions) != 4 {
+		t.Errorf("Expected 4 regressions (grounding and overall, summary and case), got %d: %+v", len(cmp.Regressions), cmp.Regressions)
+	}
+}
@@ -0,0 +1,89 @@
+// Package eval scores generated learning content against a golden dataset
+// so prompt and model changes can be compared before they ship.
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"learnforge/internal/domain"
+)
+
+// Dataset is a named set of source texts to evaluate.
+type Dataset struct {
+	Name  string `json:"name"`
+	Cases []Case `json:"cases"`
+}
+
+// Case is a single source text with optional generation settings and
+// minimum item counts. Zero minimums fall back to mode defaults.
+type Case struct {
+	Name          string  `json:"name"`
+	Text          string  `json:"text"`
+	Mode          string  `json:"mode,omitempty"`
+	Topic         *string `json:"topic,omitempty"`
+	Level         *string `json:"level,omitempty"`
+	Language      string  `json:"language,omitempty"`
+	MinKeyPoints  int     `json:"min_key_points,omitempty"`
+	MinFlashcards int     `json:"min_flashcards,omitempty"`
+	MinQuiz       int     `json:"min_quiz,omitempty"`
+}
+
+// LoadDataset reads a dataset from a JSON file.
+func LoadDataset(path string) (*Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %w", err)
+	}
+
+	var ds Dataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, fmt.Errorf("failed to parse dataset: %w", err)
+	}
+
+	for i, c := range ds.Cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("case %d: name is required", i)
+		}
+		if c.Text == "" {
+			return nil, fmt.Errorf("case %s: text is required", c.Name)
+		}
+		if !domain.ValidateMode(c.Mode) {
+			return nil, fmt.Errorf("case %s: invalid mode %q", c.Name, c.Mode)
+		}
+		if !domain.ValidateLevel(c.Level) {
+			return nil, fmt.Errorf("case %s: invalid level %q", c.Name, *c.Level)
+		}
+	}
+
+	return &ds, nil
+}
+
+func (c Case) mode() string {
+	if c.Mode == "" {
+		return "lesson"
+	}
+	return c.Mode
+}
+
+func (c Case) minimums() (keyPoints, flashcards, quiz int) {
+	switch c.mode() {
+	case "flashcards":
+		flashcards = 3
+	case "quiz":
+		quiz = 3
+	default:
+		keyPoints, flashcards, quiz = 3, 3, 3
+	}
+	if c.MinKeyPoints > 0 {
+		keyPoints = c.MinKeyPoints
+	}
+	if c.MinFlashcards > 0 {
+		flashcards = c.MinFlashcards
+	}
+	if c.MinQuiz > 0 {
+		quiz = c.MinQuiz
+	}
+	return keyPoints, flashcards, quiz
+}
@@ -0,0 +1,84 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/domain"
+)
+
+// JudgeScores is an LLM-as-judge rubric on a 1-5 scale.
+type JudgeScores struct {
+	Accuracy float64 `json:"accuracy"`
+	Clarity  float64 `json:"clarity"`
+	Coverage float64 `json:"coverage"`
+	LevelFit float64 `json:"level_fit"`
+	Comments string  `json:"comments,omitempty"`
+}
+
+// Normalized maps the rubric average onto 0.0-1.0.
+func (j *JudgeScores) Normalized() float64 {
+	avg := (j.Accuracy + j.Clarity + j.Coverage + j.LevelFit) / 4
+	score := (avg - 1) / 4
+	if score < 0 {
+		return 0
+	}
+	if score > 1 {
+		return 1
+	}
+	return score
+}
+
+// Judge grades generated content with a second model.
+type Judge struct {
+	completer ai.Completer
+}
+
+func NewJudge(completer ai.Completer) *Judge {
+	return &Judge{completer: completer}
+}
+
+func (j *Judge) Score(ctx context.Context, c Case, resp *domain.ProcessResponse) (*JudgeScores, error) {
+	generated, err := json.Marshal(struct {
+		Summary    string             `json:"summary"`
+		KeyPoints  []string           `json:"key_points"`
+		Flashcards []domain.Flashcard `json:"flashcards"`
+		Quiz       []domain.QuizItem  `json:"quiz"`
+	}{resp.Summary, resp.KeyPoints, resp.Flashcards, resp.Quiz})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal generated content: %w", err)
+	}
+
+	level := "unspecified"
+	if c.Level != nil && *c.Level != "" {
+		level = *c.Level
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are grading AI-generated study material against its source text.\n\n")
+	prompt.WriteString("Source text:\n")
+	prompt.WriteString(c.Text)
+	prompt.WriteString("\n\nGenerated material (JSON):\n")
+	prompt.Write(generated)
+	prompt.WriteString(fmt.Sprintf("\n\nRequested difficulty level: %s\n\n", level))
+	prompt.WriteString("Score each criterion from 1 (poor) to 5 (excellent):\n")
+	prompt.WriteString("- accuracy: every statement and quiz answer is correct according to the source\n")
+	prompt.WriteString("- clarity: questions are unambiguous and well written\n")
+	prompt.WriteString("- coverage: the material covers the important ideas of the source\n")
+	prompt.WriteString("- level_fit: language and depth match the requested level\n\n")
+	prompt.WriteString(`Respond ONLY with JSON: {"accuracy": 1-5, "clarity": 1-5, "coverage": 1-5, "level_fit": 1-5, "comments": "string"}`)
+
+	reply, err := j.completer.Complete(ctx, prompt.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var scores JudgeScores
+	if err := json.Unmarshal([]byte(reply), &scores); err != nil {
+		return nil, fmt.Errorf("failed to parse judge reply: %w", err)
+	}
+	return &scores, nil
+}
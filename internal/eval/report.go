@@ -0,0 +1,256 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Scores are normalized to 0.0-1.0; higher is better.
+type Scores struct {
+	Structural  float64  `json:"structural"`
+	Grounding   float64  `json:"grounding"`
+	Readability float64  `json:"readability"`
+	Judge       *float64 `json:"judge,omitempty"`
+	Overall     float64  `json:"overall"`
+}
+
+func (s Scores) overall() float64 {
+	total := s.Structural + s.Grounding + s.Readability
+	n := 3.0
+	if s.Judge != nil {
+		total += *s.Judge
+		n++
+	}
+	return total / n
+}
+
+func (s Scores) metrics() map[string]float64 {
+	m := map[string]float64{
+		"structural":  s.Structural,
+		"grounding":   s.Grounding,
+		"readability": s.Readability,
+		"overall":     s.Overall,
+	}
+	if s.Judge != nil {
+		m["judge"] = *s.Judge
+	}
+	return m
+}
+
+type CaseResult struct {
+	Name       string       `json:"name"`
+	Error      string       `json:"error,omitempty"`
+	Provider   string       `json:"provider,omitempty"`
+	Model      string       `json:"model,omitempty"`
+	LatencyMS  int64        `json:"latency_ms"`
+	Checks     []Check      `json:"checks,omitempty"`
+	GradeLevel float64      `json:"grade_level"`
+	Judge      *JudgeScores `json:"judge,omitempty"`
+	JudgeError string       `json:"judge_error,omitempty"`
+	Scores     Scores       `json:"scores"`
+}
+
+type Report struct {
+	Dataset     string       `json:"dataset"`
+	Provider    string       `json:"provider"`
+	Model       string       `json:"model"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Cases       []CaseResult `json:"cases"`
+	Failures    int          `json:"failures"`
+	Summary     Scores       `json:"summary"`
+}
+
+// summarize averages scores over cases that produced output. Failed cases
+// count as zero so that a model that errors cannot score well.
+func summarize(cases []CaseResult) Scores {
+	var sum Scores
+	var judgeSum float64
+	judged := 0
+	for _, c := range cases {
+		sum.Structural += c.Scores.Structural
+		sum.Grounding += c.Scores.Grounding
+		sum.Readability += c.Scores.Readability
+		sum.Overall += c.Scores.Overall
+		if c.Scores.Judge != nil {
+			judgeSum += *c.Scores.Judge
+			judged++
+		}
+	}
+	if len(cases) == 0 {
+		return sum
+	}
+
+	n := float64(len(cases))
+	summary := Scores{
+		Structural:  sum.Structural / n,
+		Grounding:   sum.Grounding / n,
+		Readability: sum.Readability / n,
+		Overall:     sum.Overall / n,
+	}
+	if judged > 0 {
+		avg := judgeSum / float64(judged)
+		summary.Judge = &avg
+	}
+	return summary
+}
+
+// LoadReport reads a report previously written with WriteJSON.
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+	return &report, nil
+}
+
+func (r *Report) WriteJSON(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Evaluation report: %s\n\n", r.Dataset))
+	b.WriteString(fmt.Sprintf("- Provider: %s\n- Model: %s\n- Generated: %s\n\n", r.Provider, r.Model, r.GeneratedAt.Format(time.RFC3339)))
+
+	b.WriteString("| Metric | Score |\n|---|---|\n")
+	writeMetricRows(&b, r.Summary.metrics())
+
+	b.WriteString("\n## Cases\n\n")
+	b.WriteString("| Case | Structural | Grounding | Readability (grade) | Judge | Overall | Notes |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, c := range r.Cases {
+		judge := "-"
+		if c.Scores.Judge != nil {
+			judge = fmt.Sprintf("%.2f", *c.Scores.Judge)
+		}
+		notes := c.Error
+		if notes == "" {
+			var failed []string
+			for _, check := range c.Checks {
+				if !check.Passed {
+					failed = append(failed, check.Name)
+				}
+			}
+			notes = strings.Join(failed, ", ")
+		}
+		b.WriteString(fmt.Sprintf("| %s | %.2f | %.2f | %.2f (%.1f) | %s | %.2f | %s |\n",
+			c.Name, c.Scores.Structural, c.Scores.Grounding, c.Scores.Readability, c.GradeLevel, judge, c.Scores.Overall, notes))
+	}
+	return b.String()
+}
+
+func writeMetricRows(b *strings.Builder, metrics map[string]float64) {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(fmt.Sprintf("| %s | %.3f |\n", name, metrics[name]))
+	}
+}
+
+// MetricDelta compares one metric between a baseline and the current run.
+type MetricDelta struct {
+	Case     string  `json:"case,omitempty"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	Delta    float64 `json:"delta"`
+}
+
+// Comparison is the diff of a report against a saved baseline.
+type Comparison struct {
+	Threshold   float64       `json:"threshold"`
+	Summary     []MetricDelta `json:"summary"`
+	Regressions []MetricDelta `json:"regressions"`
+}
+
+// Compare diffs current against baseline. Any summary or per-case metric
+// that drops by more than threshold is reported as a regression.
+func Compare(baseline, current *Report, threshold float64) *Comparison {
+	cmp := &Comparison{Threshold: threshold}
+
+	cmp.Summary = deltas("", baseline.Summary.metrics(), current.Summary.metrics())
+	for _, d := range cmp.Summary {
+		if d.Delta < -threshold {
+			cmp.Regressions = append(cmp.Regressions, d)
+		}
+	}
+
+	baseCases := make(map[string]CaseResult)
+	for _, c := range baseline.Cases {
+		baseCases[c.Name] = c
+	}
+	for _, c := range current.Cases {
+		base, ok := baseCases[c.Name]
+		if !ok {
+			continue
+		}
+		for _, d := range deltas(c.Name, base.Scores.metrics(), c.Scores.metrics()) {
+			if d.Delta < -threshold {
+				cmp.Regressions = append(cmp.Regressions, d)
+			}
+		}
+	}
+
+	return cmp
+}
+
+func deltas(caseName string, baseline, current map[string]float64) []MetricDelta {
+	var names []string
+	for name := range current {
+		if _, ok := baseline[name]; ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var out []MetricDelta
+	for _, name := range names {
+		out = append(out, MetricDelta{
+			Case:     caseName,
+			Metric:   name,
+			Baseline: baseline[name],
+			Current:  current[name],
+			Delta:    current[name] - baseline[name],
+		})
+	}
+	return out
+}
+
+func (c *Comparison) Markdown() string {
+	var b strings.Builder
+	b.WriteString("\n## Comparison with baseline\n\n")
+	b.WriteString("| Metric | Baseline | Current | Delta |\n|---|---|---|---|\n")
+	for _, d := range c.Summary {
+		b.WriteString(fmt.Sprintf("| %s | %.3f | %.3f | %+.3f |\n", d.Metric, d.Baseline, d.Current, d.Delta))
+	}
+
+	if len(c.Regressions) == 0 {
+		b.WriteString(fmt.Sprintf("\nNo regressions beyond %.2f.\n", c.Threshold))
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("\n### Regressions (drop > %.2f)\n\n", c.Threshold))
+	for _, d := range c.Regressions {
+		scope := "summary"
+		if d.Case != "" {
+			scope = d.Case
+		}
+		b.WriteString(fmt.Sprintf("- %s / %s: %.3f → %.3f (%+.3f)\n", scope, d.Metric, d.Baseline, d.Current, d.Delta))
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package eval
+
+import (
+	"context"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/domain"
+)
+
+// Runner generates content for every case in a dataset and scores it.
+type Runner struct {
+	client       ai.Client
+	judge        *Judge
+	instructions string
+	timeout      time.Duration
+}
+
+func NewRunner(client ai.Client, judge *Judge, instructions string) *Runner {
+	return &Runner{
+		client:       client,
+		judge:        judge,
+		instructions: instructions,
+		timeout:      60 * time.Second,
+	}
+}
+
+func (r *Runner) Run(ctx context.Context, ds *Dataset) *Report {
+	report := &Report{
+		Dataset:     ds.Name,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	for _, c := range ds.Cases {
+		result := r.runCase(ctx, c)
+		if report.Model == "" && result.Model != "" {
+			report.Model = result.Model
+			report.Provider = result.Provider
+		}
+		if result.Error != "" {
+			report.Failures++
+		}
+		report.Cases = append(report.Cases, result)
+	}
+
+	report.Summary = summarize(report.Cases)
+	return report
+}
+
+func (r *Runner) runCase(ctx context.Context, c Case) CaseResult {
+	result := CaseResult{Name: c.Name}
+
+	language := c.Language
+	if language == "" {
+		language = "en"
+	}
+
+	caseCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := r.client.ProcessText(caseCtx, &ai.ProcessRequest{
+		Text:          c.Text,
+		Mode:          c.mode(),
+		Topic:         c.Topic,
+		Level:         c.Level,
+		Language:      language,
+		PromptVersion: ai.DefaultPromptVersion,
+		Instructions:  r.instructions,
+	})
+	result.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Model = resp.Meta.Model
+	result.Provider = resp.Meta.Provider
+	r.score(ctx, c, resp, &result)
+	return result
+}
+
+func (r *Runner) score(ctx context.Context, c Case, resp *domain.ProcessResponse, result *CaseResult) {
+	result.Checks = StructuralChecks(c, resp)
+	result.Scores.Structural = StructuralScore(result.Checks)
+	result.Scores.Grounding = GroundingScore(c.Text, resp)
+	result.GradeLevel, result.Scores.Readability = ReadabilityScore(c.Level, resp)
+
+	if r.judge != nil {
+		judgeCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+
+		scores, err := r.judge.Score(judgeCtx, c, resp)
+		if err != nil {
+			result.JudgeError = err.Error()
+		} else {
+			result.Judge = scores
+			normalized := scores.Normalized()
+			result.Scores.Judge = &normalized
+		}
+	}
+
+	result.Scores.Overall = result.Scores.overall()
+}
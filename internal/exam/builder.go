@@ -0,0 +1,579 @@
+// Package exam assembles exams from the quiz items of stored results:
+// it draws items that match a topic mix, difficulty range and question
+// types, and shuffles them into variants with their own answer keys.
+// Difficulty is the level requested for the result, and optionally the
+// p-value of items learners have answered.
+package exam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"learnforge/internal/analytics"
+	"learnforge/internal/domain"
+	"learnforge/internal/feedback"
+	"learnforge/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// Bounds of an exam
+const (
+	MaxQuestions = 200
+	MaxVariants  = 26 // labeled A to Z
+	maxTitle     = 200
+)
+
+// candidatePageSize is how many results are loaded at a time when
+// collecting an exam's candidate items.
+const candidatePageSize = 100
+
+// Builder builds and stores exams.
+type Builder struct {
+	exams          store.ExamStore
+	results        store.Store
+	feedback       *feedback.Service
+	stats          *analytics.Analyzer
+	reviewRequired bool
+	pageSize       int
+	now            func() time.Time
+}
+
+// Option configures a Builder.
+type Option func(*Builder)
+
+// WithReviewRequired only draws items from published results.
+func WithReviewRequired(required bool) Option {
+	return func(b *Builder) {
+		b.reviewRequired = required
+	}
+}
+
+// WithFeedback lets exams leave out items that learners rated down.
+func WithFeedback(feedback *feedback.Service) Option {
+	return func(b *Builder) {
+		b.feedback = feedback
+	}
+}
+
+// WithItemStats lets exams bound the p-values of the items they draw.
+func WithItemStats(stats *analytics.Analyzer) Option {
+	return func(b *Builder) {
+		b.stats = stats
+	}
+}
+
+func NewBuilder(exams store.ExamStore, results store.Store, opts ...Option) *Builder {
+	b := &Builder{
+		exams:    exams,
+		results:  results,
+		pageSize: candidatePageSize,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Input is a request to build an exam.
+type Input struct {
+	Title string `json:"title"`
+	domain.ExamSpec
+}
+
+// Build draws the exam's items, shuffles them into variants and stores the
+// exam. It fails when fewer items match a quota than it asks for.
+func (b *Builder) Build(ctx context.Context, user string, in Input) (*domain.Exam, error) {
+	title := strings.TrimSpace(in.Title)
+	if title == "" || len(title) > maxTitle {
+		return nil, domain.InvalidArgument(fmt.Sprintf("title is required and must be at most %d characters", maxTitle))
+	}
+	spec := in.ExamSpec
+	quotas, err := prepareSpec(&spec)
+	if err != nil {
+		return nil, err
+	}
+	if spec.ExcludeLowRated && b.feedback == nil {
+		return nil, domain.InvalidArgument("exclude_low_rated is not available without learner feedback")
+	}
+	if boundsPValue(spec) && b.stats == nil {
+		return nil, domain.InvalidArgument("min_p_value and max_p_value are not available without item statistics")
+	}
+
+	now := b.now().UTC()
+	seed := now.UnixNano()
+	if spec.Seed != nil {
+		seed = *spec.Seed
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	candidates, err := b.candidates(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	items, err := draw(candidates, quotas, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	exam := &domain.Exam{
+		ID:        uuid.New().String(),
+		Title:     title,
+		CreatedBy: user,
+		Spec:      spec,
+		Seed:      seed,
+		Items:     items,
+		CreatedAt: now,
+	}
+	for n := 1; n <= spec.Variants; n++ {
+		exam.Variants = append(exam.Variants, shuffle(n, items, rng))
+	}
+	if err := b.exams.SaveExam(ctx, exam); err != nil {
+		return nil, err
+	}
+	return exam, nil
+}
+
+// Get returns an exam with its answer keys.
+func (b *Builder) Get(ctx context.Context, id string) (*domain.Exam, error) {
+	return b.exams.GetExam(ctx, id)
+}
+
+// List returns all exams, newest first.
+func (b *Builder) List(ctx context.Context) ([]*domain.Exam, error) {
+	exams, err := b.exams.ListExams(ctx)
+	if exams == nil && err == nil {
+		exams = []*domain.Exam{}
+	}
+	return exams, err
+}
+
+func (b *Builder) Delete(ctx context.Context, id string) error {
+	return b.exams.DeleteExam(ctx, id)
+}
+
+// Variant returns variant n of exam id. Without answers, the answers are
+// left out so learners can take it.
+func (b *Builder) Variant(ctx context.Context, id string, n int, answers bool) (*domain.ExamVariant, error) {
+	exam, err := b.exams.GetExam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := variantOf(exam, n)
+	if err != nil {
+		return nil, err
+	}
+	if !answers {
+		for i := range variant.Questions {
+			variant.Questions[i].Answer = ""
+			variant.Questions[i].AnswerLabel = ""
+		}
+	}
+	return variant, nil
+}
+
+// AnswerKey returns the answer key of variant n of exam id, in question
+// order.
+func (b *Builder) AnswerKey(ctx context.Context, id string, n int) ([]domain.AnswerKeyEntry, error) {
+	exam, err := b.exams.GetExam(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	variant, err := variantOf(exam, n)
+	if err != nil {
+		return nil, err
+	}
+	return answerKey(exam, variant), nil
+}
+
+// Printable returns variant n of exam id as plain text for printing, with
+// the answer key on a page of its own when answers is set.
+func (b *Builder) Printable(ctx context.Context, id string, n int, answers bool) (string, error) {
+	exam, err := b.exams.GetExam(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	variant, err := variantOf(exam, n)
+	if err != nil {
+		return "", err
+	}
+	return printable(exam, variant, answers), nil
+}
+
+// prepareSpec validates spec, fills in its defaults and returns the
+// number of items to draw per topic. The empty topic draws from all
+// topics.
+func prepareSpec(spec *domain.ExamSpec) ([]domain.TopicQuota, error) {
+	for _, level := range []string{spec.MinLevel, spec.MaxLevel} {
+		if !domain.ValidateLevel(&level) {
+			return nil, domain.InvalidArgument("min_level and max_level must be one of: beginner, intermediate, advanced")
+		}
+	}
+	if spec.MinLevel != "" && spec.MaxLevel != "" && levelRank(spec.MinLevel) > levelRank(spec.MaxLevel) {
+		return nil, domain.InvalidArgument("min_level must not be above max_level")
+	}
+	for _, p := range []*float64{spec.MinPValue, spec.MaxPValue} {
+		if p != nil && (*p < 0 || *p > 1) {
+			return nil, domain.InvalidArgument("min_p_value and max_p_value must be between 0 and 1")
+		}
+	}
+	if spec.MinPValue != nil && spec.MaxPValue != nil && *spec.MinPValue > *spec.MaxPValue {
+		return nil, domain.InvalidArgument("min_p_value must not be above max_p_value")
+	}
+	for _, t := range spec.Types {
+		if t != domain.QuestionMultipleChoice && t != domain.QuestionTrueFalse {
+			return nil, domain.InvalidArgument("types must be one of: multiple_choice, true_false")
+		}
+	}
+	switch {
+	case spec.Variants == 0:
+		spec.Variants = 1
+	case spec.Variants < 0 || spec.Variants > MaxVariants:
+		return nil, domain.InvalidArgument(fmt.Sprintf("variants must be between 1 and %d", MaxVariants))
+	}
+	for i, ref := range spec.ExcludeItems {
+		if ref.ResultID == "" || ref.ItemID == "" {
+			return nil, domain.InvalidArgument(fmt.Sprintf("exclude_items[%d]: result_id and item_id are required", i))
+		}
+	}
+
+	if len(spec.Topics) == 0 {
+		if spec.Count < 1 || spec.Count > MaxQuestions {
+			return nil, domain.InvalidArgument(fmt.Sprintf("count must be between 1 and %d", MaxQuestions))
+		}
+		return []domain.TopicQuota{{Count: spec.Count}}, nil
+	}
+
+	quotas := make([]domain.TopicQuota, len(spec.Topics))
+	seen := make(map[string]bool)
+	counted, sum := 0, 0
+	for i, quota := range spec.Topics {
+		quota.Topic = strings.TrimSpace(quota.Topic)
+		key := strings.ToLower(quota.Topic)
+		if key == "" || seen[key] {
+			return nil, domain.InvalidArgument(fmt.Sprintf("topics[%d]: topic is required and must be distinct", i))
+		}
+		seen[key] = true
+		if quota.Count < 0 {
+			return nil, domain.InvalidArgument(fmt.Sprintf("topics[%d]: count must not be negative", i))
+		}
+		if quota.Count > 0 {
+			counted++
+			sum += quota.Count
+		}
+		quotas[i] = quota
+	}
+	switch {
+	case counted == len(quotas):
+		if spec.Count != 0 && spec.Count != sum {
+			return nil, domain.InvalidArgument("count must equal the sum of the topic counts")
+		}
+		spec.Count = sum
+	case counted > 0:
+		return nil, domain.InvalidArgument("either every topic or none must have a count")
+	default:
+		if spec.Count < len(quotas) {
+			return nil, domain.InvalidArgument("count must be at least the number of topics")
+		}
+		// Spread the count evenly, earlier topics taking the remainder.
+		for i := range quotas {
+			quotas[i].Count = spec.Count / len(quotas)
+			if i < spec.Count%len(quotas) {
+				quotas[i].Count++
+			}
+		}
+	}
+	if spec.Count > MaxQuestions {
+		return nil, domain.InvalidArgument(fmt.Sprintf("count must be between 1 and %d", MaxQuestions))
+	}
+	spec.Topics = quotas
+	return quotas, nil
+}
+
+// candidates returns the items spec allows, by lowercased topic, in a
+// stable order so a seed always draws the same items from the same bank.
+// Without topics, all items are under the empty topic. Results are
+// loaded a page at a time.
+func (b *Builder) candidates(ctx context.Context, spec domain.ExamSpec) (map[string][]domain.ExamItem, error) {
+	query := domain.ResultQuery{Sort: domain.SortOldest, Limit: b.pageSize}
+	if b.reviewRequired {
+		query.Status = domain.ReviewPublished
+	}
+	topics := []string{""}
+	if len(spec.Topics) > 0 {
+		topics = topics[:0]
+		for _, quota := range spec.Topics {
+			topics = append(topics, quota.Topic)
+		}
+	}
+
+	f := filter{
+		spec:            spec,
+		excludedItems:   make(map[string]bool, len(spec.ExcludeItems)),
+		excludedResults: make(map[string]bool, len(spec.ExcludeResults)),
+		types:           make(map[string]bool, len(spec.Types)),
+	}
+	for _, ref := range spec.ExcludeItems {
+		f.excludedItems[ref.ResultID+"/"+ref.ItemID] = true
+	}
+	for _, id := range spec.ExcludeResults {
+		f.excludedResults[id] = true
+	}
+	for _, t := range spec.Types {
+		f.types[t] = true
+	}
+
+	candidates := make(map[string][]domain.ExamItem, len(topics))
+	for _, topic := range topics {
+		key := strings.ToLower(topic)
+		query.Topic = topic
+		query.Cursor = nil
+		for {
+			stored, err := b.results.ListResults(ctx, query)
+			if err != nil {
+				return nil, err
+			}
+			items, err := b.match(ctx, f, stored)
+			if err != nil {
+				return nil, err
+			}
+			candidates[key] = append(candidates[key], items...)
+			if len(stored) < query.Limit {
+				break
+			}
+			last := stored[len(stored)-1]
+			query.Cursor = &domain.ResultCursor{Sort: query.Sort, Key: last.CreatedAt.UTC().Format(time.RFC3339Nano), ID: last.ID}
+		}
+	}
+	return candidates, nil
+}
+
+// filter selects the items of a spec.
+type filter struct {
+	spec            domain.ExamSpec
+	excludedItems   map[string]bool // result ID + "/" + item ID
+	excludedResults map[string]bool
+	types           map[string]bool
+}
+
+// match returns the items of a page of results that f selects.
+func (b *Builder) match(ctx context.Context, f filter, stored []*domain.StoredResult) ([]domain.ExamItem, error) {
+	spec := f.spec
+	lowRated, err := b.lowRated(ctx, spec, stored)
+	if err != nil {
+		return nil, err
+	}
+	pValues, err := b.pValues(ctx, spec, stored)
+	if err != nil {
+		return nil, err
+	}
+	var items []domain.ExamItem
+	for _, result := range stored {
+		if f.excludedResults[result.ID] || !inRange(result.Level, spec.MinLevel, spec.MaxLevel) {
+			continue
+		}
+		var resp domain.ProcessResponse
+		if err := json.Unmarshal(result.ResponseJSON, &resp); err != nil {
+			return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+		}
+		for _, item := range resp.Quiz {
+			// Items flagged by answer verification may be keyed wrong.
+			if item.ID == "" || item.NeedsReview || f.excludedItems[result.ID+"/"+item.ID] || domain.ValidateQuizItem(item) != nil {
+				continue
+			}
+			if lowRated[feedback.Key(result.ID, domain.FeedbackQuiz, item.ID)] {
+				continue
+			}
+			t := domain.QuestionType(item)
+			if len(f.types) > 0 && !f.types[t] {
+				continue
+			}
+			var pValue *float64
+			if boundsPValue(spec) {
+				p, ok := pValues[domain.ExamItemRef{ResultID: result.ID, ItemID: item.ID}]
+				if !ok || (spec.MinPValue != nil && p < *spec.MinPValue) || (spec.MaxPValue != nil && p > *spec.MaxPValue) {
+					continue
+				}
+				pValue = &p
+			}
+			items = append(items, domain.ExamItem{
+				ResultID: result.ID,
+				Topic:    resp.Topic,
+				Level:    result.Level,
+				PValue:   pValue,
+				Type:     t,
+				Item:     item,
+			})
+		}
+	}
+	return items, nil
+}
+
+// lowRated returns the low-rated items of results when spec excludes
+// them.
+func (b *Builder) lowRated(ctx context.Context, spec domain.ExamSpec, results []*domain.StoredResult) (map[string]bool, error) {
+	if !spec.ExcludeLowRated {
+		return nil, nil
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return b.feedback.LowRated(ctx, ids)
+}
+
+// pValues returns the p-values of the items of results when spec bounds
+// them.
+func (b *Builder) pValues(ctx context.Context, spec domain.ExamSpec, results []*domain.StoredResult) (map[domain.ExamItemRef]float64, error) {
+	if !boundsPValue(spec) {
+		return nil, nil
+	}
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return b.stats.PValues(ctx, ids)
+}
+
+func boundsPValue(spec domain.ExamSpec) bool {
+	return spec.MinPValue != nil || spec.MaxPValue != nil
+}
+
+// draw picks each quota's items at random, skipping questions already
+// drawn from another result, and numbers them.
+func draw(candidates map[string][]domain.ExamItem, quotas []domain.TopicQuota, rng *rand.Rand) ([]domain.ExamItem, error) {
+	var items []domain.ExamItem
+	drawn := make(map[string]bool)
+	for _, quota := range quotas {
+		pool := candidates[strings.ToLower(quota.Topic)]
+		picked := 0
+		for _, i := range rng.Perm(len(pool)) {
+			if picked == quota.Count {
+				break
+			}
+			question := domain.NormalizeText(pool[i].Item.Q)
+			if drawn[question] {
+				continue
+			}
+			drawn[question] = true
+			items = append(items, pool[i])
+			picked++
+		}
+		if picked < quota.Count {
+			if quota.Topic == "" {
+				return nil, domain.InvalidArgument(fmt.Sprintf("only %d questions match; %d were asked for", picked, quota.Count))
+			}
+			return nil, domain.InvalidArgument(fmt.Sprintf("only %d questions on %q match; %d were asked for", picked, quota.Topic, quota.Count))
+		}
+	}
+	for i := range items {
+		items[i].ID = fmt.Sprintf("q%d", i+1)
+	}
+	return items, nil
+}
+
+// shuffle returns variant n: the items in random order, each with its
+// choices shuffled.
+func shuffle(n int, items []domain.ExamItem, rng *rand.Rand) domain.ExamVariant {
+	variant := domain.ExamVariant{Number: n, Label: domain.ChoiceLetter(n - 1)}
+	for i, j := range rng.Perm(len(items)) {
+		item := items[j]
+		choices := shuffleChoices(item, rng)
+		q := domain.ExamQuestion{
+			Number:  i + 1,
+			ItemID:  item.ID,
+			Topic:   item.Topic,
+			Type:    item.Type,
+			Q:       item.Item.Q,
+			Choices: choices,
+		}
+		for k, choice := range choices {
+			if domain.SameChoice(choice, item.Item.Answer) {
+				q.Answer, q.AnswerLabel = choice, domain.ChoiceLetter(k)
+				break
+			}
+		}
+		variant.Questions = append(variant.Questions, q)
+	}
+	return variant
+}
+
+// shuffleChoices returns item's choices without letter labels in random
+// order. True/false choices keep their order, and choices that refer to
+// the others, like "All of the above", stay last.
+func shuffleChoices(item domain.ExamItem, rng *rand.Rand) []string {
+	var choices, last []string
+	for _, choice := range item.Item.Choices {
+		text := domain.ChoiceText(choice)
+		if referential(text) {
+			last = append(last, text)
+		} else {
+			choices = append(choices, text)
+		}
+	}
+	if item.Type != domain.QuestionTrueFalse {
+		rng.Shuffle(len(choices), func(i, j int) { choices[i], choices[j] = choices[j], choices[i] })
+	}
+	return append(choices, last...)
+}
+
+func referential(choice string) bool {
+	c := domain.NormalizeText(choice)
+	return strings.HasSuffix(c, "of the above") || strings.HasPrefix(c, "both ")
+}
+
+func answerKey(exam *domain.Exam, variant *domain.ExamVariant) []domain.AnswerKeyEntry {
+	sources := make(map[string]domain.ExamItem, len(exam.Items))
+	for _, item := range exam.Items {
+		sources[item.ID] = item
+	}
+	key := make([]domain.AnswerKeyEntry, 0, len(variant.Questions))
+	for _, q := range variant.Questions {
+		key = append(key, domain.AnswerKeyEntry{
+			Number:       q.Number,
+			ItemID:       q.ItemID,
+			AnswerLabel:  q.AnswerLabel,
+			Answer:       q.Answer,
+			ResultID:     sources[q.ItemID].ResultID,
+			SourceItemID: sources[q.ItemID].Item.ID,
+		})
+	}
+	return key
+}
+
+// variantOf returns a copy of variant n of exam.
+func variantOf(exam *domain.Exam, n int) (*domain.ExamVariant, error) {
+	if n < 1 || n > len(exam.Variants) {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "exam variant not found", nil)
+	}
+	variant := exam.Variants[n-1]
+	variant.Questions = append([]domain.ExamQuestion(nil), variant.Questions...)
+	return &variant, nil
+}
+
+// inRange reports whether level lies within [min, max]. Unknown levels
+// only match an open range.
+func inRange(level, min, max string) bool {
+	if min == "" && max == "" {
+		return true
+	}
+	rank := levelRank(level)
+	if rank < 0 {
+		return false
+	}
+	return (min == "" || rank >= levelRank(min)) && (max == "" || rank <= levelRank(max))
+}
+
+func levelRank(level string) int {
+	for i, l := range domain.ValidLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,270 @@
+package exam
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"learnforge/internal/analytics"
+	"learnforge/internal/attempt"
+	"learnforge/internal/domain"
+	"learnforge/internal/feedback"
+	"learnforge/internal/store"
+	"learnforge/internal/store/storetest"
+)
+
+// questions returns n multiple choice items with distinct questions.
+func questions(prefix string, n int) []domain.QuizItem {
+	items := make([]domain.QuizItem, n)
+	for i := range items {
+		items[i] = domain.QuizItem{
+			ID:      fmt.Sprintf("%s%d", prefix, i),
+			Q:       fmt.Sprintf("%s question %d?", prefix, i),
+			Choices: []string{"A. Right", "B. Wrong", "C. Also wrong", "D. None of the above"},
+			Answer:  "A. Right",
+		}
+	}
+	return items
+}
+
+func TestBuilder_BuildDrawsTopicMixIntoVariants(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft", Level: "intermediate"}, domain.ProcessResponse{Topic: "Raft", Quiz: questions("raft", 6)})
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "paxos", Level: "advanced"}, domain.ProcessResponse{Topic: "Paxos", Quiz: questions("paxos", 6)})
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "intro", Level: "beginner"}, domain.ProcessResponse{Topic: "Raft", Quiz: questions("intro", 6)})
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "tf", Level: "advanced"}, domain.ProcessResponse{Topic: "Paxos", Quiz: []domain.QuizItem{
+		{ID: "t1", Q: "Paxos tolerates f failures with 2f+1 acceptors.", Choices: []string{"True", "False"}, Answer: "True"},
+	}})
+	b := NewBuilder(st, st)
+
+	seed := int64(7)
+	exam, err := b.Build(ctx, "ops", Input{Title: "Consensus Q3", ExamSpec: domain.ExamSpec{
+		Topics:       []domain.TopicQuota{{Topic: "raft", Count: 4}, {Topic: "Paxos", Count: 3}},
+		MinLevel:     "intermediate",
+		Types:        []string{domain.QuestionMultipleChoice},
+		ExcludeItems: []domain.ExamItemRef{{ResultID: "raft", ItemID: "raft0"}},
+		Variants:     3,
+		Seed:         &seed,
+	}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(exam.Items) != 7 || len(exam.Variants) != 3 {
+		t.Fatalf("got %d items in %d variants, want 7 in 3", len(exam.Items), len(exam.Variants))
+	}
+	byTopic := make(map[string]int)
+	for _, item := range exam.Items {
+		byTopic[item.Topic]++
+		switch {
+		case item.ResultID == "intro":
+			t.Errorf("drew %s from a beginner result", item.ID)
+		case item.Item.ID == "raft0":
+			t.Errorf("drew excluded item raft0")
+		case item.Type != domain.QuestionMultipleChoice:
+			t.Errorf("drew %s of type %s", item.ID, item.Type)
+		}
+	}
+	if byTopic["Raft"] != 4 || byTopic["Paxos"] != 3 {
+		t.Errorf("topic mix = %v, want 4 Raft and 3 Paxos", byTopic)
+	}
+
+	for _, variant := range exam.Variants {
+		seen := make(map[string]bool)
+		for _, q := range variant.Questions {
+			seen[q.ItemID] = true
+			if q.Answer != "Right" || q.Choices[q.AnswerLabel[0]-'A'] != "Right" {
+				t.Errorf("variant %s question %d: answer %s %q does not match its choices %v", variant.Label, q.Number, q.AnswerLabel, q.Answer, q.Choices)
+			}
+			if q.Choices[3] != "None of the above" {
+				t.Errorf("variant %s question %d: choices %v, want None of the above last", variant.Label, q.Number, q.Choices)
+			}
+		}
+		if len(seen) != len(exam.Items) {
+			t.Errorf("variant %s has %d distinct items, want %d", variant.Label, len(seen), len(exam.Items))
+		}
+	}
+
+	again, err := b.Build(ctx, "ops", Input{Title: "Consensus Q3", ExamSpec: exam.Spec})
+	if err != nil {
+		t.Fatalf("Build with the same seed: %v", err)
+	}
+	for i := range exam.Items {
+		if again.Items[i].ResultID != exam.Items[i].ResultID || again.Items[i].Item.ID != exam.Items[i].Item.ID {
+			t.Fatalf("rebuilding with seed %d drew different items", seed)
+		}
+	}
+}
+
+func TestBuilder_BuildRejectsUnsatisfiableSpecs(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft", Level: "intermediate"}, domain.ProcessResponse{Topic: "Raft", Quiz: questions("raft", 3)})
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "copy", Level: "intermediate"}, domain.ProcessResponse{Topic: "Raft", Quiz: questions("raft", 3)}) // same questions
+	b := NewBuilder(st, st)
+
+	for name, spec := range map[string]domain.ExamSpec{
+		"too few questions":  {Topics: []domain.TopicQuota{{Topic: "Raft", Count: 4}}},
+		"unknown topic":      {Topics: []domain.TopicQuota{{Topic: "Zab"}}, Count: 1},
+		"mixed counts":       {Topics: []domain.TopicQuota{{Topic: "Raft", Count: 1}, {Topic: "Zab"}}},
+		"count mismatch":     {Topics: []domain.TopicQuota{{Topic: "Raft", Count: 1}}, Count: 2},
+		"inverted levels":    {Count: 1, MinLevel: "advanced", MaxLevel: "beginner"},
+		"unknown type":       {Count: 1, Types: []string{"essay"}},
+		"too many variants":  {Count: 1, Variants: MaxVariants + 1},
+		"no count":           {},
+		"level out of range": {Count: 1, MinLevel: "advanced"},
+	} {
+		if _, err := b.Build(ctx, "ops", Input{Title: "Exam", ExamSpec: spec}); err == nil {
+			t.Errorf("%s: Build succeeded, want an error", name)
+		}
+	}
+}
+
+func TestBuilder_VariantHidesAnswersAndPrints(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft"}, domain.ProcessResponse{Topic: "Raft", Quiz: questions("raft", 3)})
+	b := NewBuilder(st, st)
+
+	exam, err := b.Build(ctx, "ops", Input{Title: "Raft basics", ExamSpec: domain.ExamSpec{Count: 3, Variants: 2}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	sheet, err := b.Variant(ctx, exam.ID, 2, false)
+	if err != nil {
+		t.Fatalf("Variant: %v", err)
+	}
+	for _, q := range sheet.Questions {
+		if q.Answer != "" || q.AnswerLabel != "" {
+			t.Errorf("learner copy of question %d shows its answer", q.Number)
+		}
+	}
+	if _, err := b.Variant(ctx, exam.ID, 3, false); err == nil {
+		t.Error("Variant 3 of 2 succeeded, want not found")
+	}
+
+	key, err := b.AnswerKey(ctx, exam.ID, 2)
+	if err != nil {
+		t.Fatalf("AnswerKey: %v", err)
+	}
+	text, err := b.Printable(ctx, exam.ID, 2, true)
+	if err != nil {
+		t.Fatalf("Printable: %v", err)
+	}
+	for _, want := range []string{"Raft basics", "Variant B, 3 questions", "Answer key, variant B",
+		fmt.Sprintf("%3d. %s  Right", key[0].Number, key[0].AnswerLabel)} {
+		if !strings.Contains(text, want) {
+			t.Errorf("printable version lacks %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestBuilder_BuildExcludesLowRatedItems(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft"}, domain.ProcessResponse{Topic: "Raft", Quiz: questions("raft", 3)})
+	fb := feedback.NewService(st, st, feedback.WithMinDownvotes(1))
+	if _, err := fb.Submit(ctx, "raft", "ana", feedback.Input{Kind: domain.FeedbackQuiz, ItemID: "raft1", Rating: domain.FeedbackDown}, true); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := domain.ExamSpec{Count: 2, ExcludeLowRated: true}
+	if _, err := NewBuilder(st, st).Build(ctx, "ops", Input{Title: "Raft", ExamSpec: spec}); err == nil {
+		t.Error("Build excluding low-rated items without feedback succeeded, want an error")
+	}
+	exam, err := NewBuilder(st, st, WithFeedback(fb)).Build(ctx, "ops", Input{Title: "Raft", ExamSpec: spec})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	for _, item := range exam.Items {
+		if item.Item.ID == "raft1" {
+			t.Error("drew low-rated item raft1")
+		}
+	}
+	spec.Count = 3
+	if _, err := NewBuilder(st, st, WithFeedback(fb)).Build(ctx, "ops", Input{Title: "Raft", ExamSpec: spec}); err == nil {
+		t.Error("Build of 3 questions with 1 of 3 low-rated succeeded, want an error")
+	}
+}
+
+func TestBuilder_BuildBoundsPValues(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft"}, domain.ProcessResponse{Topic: "Raft", Quiz: questions("raft", 4)})
+	recorder := attempt.NewRecorder(st, st)
+	for i, answers := range [][]attempt.Answer{
+		{{ItemID: "raft0", Answer: "A. Right"}, {ItemID: "raft1", Answer: "A. Right"}, {ItemID: "raft2", Answer: "B. Wrong"}},
+		{{ItemID: "raft0", Answer: "A. Right"}, {ItemID: "raft1", Answer: "B. Wrong"}, {ItemID: "raft2", Answer: "B. Wrong"}},
+	} {
+		if _, err := recorder.Submit(ctx, "raft", fmt.Sprintf("learner%d", i), answers, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := analytics.NewAnalyzer(st, st, analytics.WithMinResponses(2))
+
+	lo, hi := 0.3, 0.7
+	spec := domain.ExamSpec{Count: 1, MinPValue: &lo, MaxPValue: &hi}
+	if _, err := NewBuilder(st, st).Build(ctx, "ops", Input{Title: "Raft", ExamSpec: spec}); err == nil {
+		t.Error("Build bounding p-values without item statistics succeeded, want an error")
+	}
+	exam, err := NewBuilder(st, st, WithItemStats(stats)).Build(ctx, "ops", Input{Title: "Raft", ExamSpec: spec})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if item := exam.Items[0]; item.Item.ID != "raft1" || item.PValue == nil || *item.PValue != 0.5 {
+		t.Errorf("drew %+v, want raft1 with p-value 0.5", item)
+	}
+	// Nobody has answered paxos, so its p-values are unknown.
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "paxos"}, domain.ProcessResponse{Topic: "Paxos", Quiz: questions("paxos", 2)})
+	spec = domain.ExamSpec{Topics: []domain.TopicQuota{{Topic: "Paxos", Count: 1}}, MaxPValue: &hi}
+	if _, err := NewBuilder(st, st, WithItemStats(stats)).Build(ctx, "ops", Input{Title: "Paxos", ExamSpec: spec}); err == nil {
+		t.Error("Build from items without p-values succeeded, want an error")
+	}
+}
+
+// pagingStore records the limit of every ListResults call.
+type pagingStore struct {
+	store.Store
+	limits []int
+}
+
+func (s *pagingStore) ListResults(ctx context.Context, query domain.ResultQuery) ([]*domain.StoredResult, error) {
+	s.limits = append(s.limits, query.Limit)
+	return s.Store.ListResults(ctx, query)
+}
+
+func TestBuilder_BuildPagesThroughResults(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		storetest.SaveResult(t, st, domain.StoredResult{ID: fmt.Sprintf("raft%d", i)}, domain.ProcessResponse{Topic: "Raft", Quiz: questions(fmt.Sprintf("raft%d-", i), 2)})
+	}
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "paxos"}, domain.ProcessResponse{Topic: "Paxos", Quiz: questions("paxos", 2)})
+	results := &pagingStore{Store: st}
+	b := NewBuilder(st, results)
+	b.pageSize = 2
+
+	exam, err := b.Build(ctx, "ops", Input{Title: "Raft", ExamSpec: domain.ExamSpec{
+		Topics:       []domain.TopicQuota{{Topic: "Raft", Count: 9}},
+		ExcludeItems: []domain.ExamItemRef{{ResultID: "raft4", ItemID: "raft4-1"}},
+	}})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	drawn := make(map[string]bool)
+	for _, item := range exam.Items {
+		drawn[item.Item.ID] = true
+	}
+	if len(drawn) != 9 || drawn["raft4-1"] || drawn["paxos0"] {
+		t.Errorf("drew %v, want every raft item but the excluded one", drawn)
+	}
+	if len(results.limits) != 3 {
+		t.Errorf("listed results %d times, want 3 pages", len(results.limits))
+	}
+	for _, limit := range results.limits {
+		if limit != 2 {
+			t.Errorf("listed results with limit %d, want 2", limit)
+		}
+	}
+}
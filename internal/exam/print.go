@@ -0,0 +1,38 @@
+package exam
+
+import (
+	"fmt"
+	"strings"
+
+	"learnforge/internal/domain"
+)
+
+// pageBreak is a form feed, which starts a new page when plain text is
+// printed.
+const pageBreak = "\f"
+
+// printable renders a variant as a question sheet, followed by its
+// answer key when answers is set.
+func printable(exam *domain.Exam, variant *domain.ExamVariant, answers bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", exam.Title)
+	fmt.Fprintf(&b, "Variant %s, %d questions\n\n", variant.Label, len(variant.Questions))
+	b.WriteString("Name: ______________________________    Date: ______________\n\n")
+	b.WriteString("Circle the letter of the one best answer to each question.\n")
+
+	for _, q := range variant.Questions {
+		fmt.Fprintf(&b, "\n%d. %s\n", q.Number, q.Q)
+		for i, choice := range q.Choices {
+			fmt.Fprintf(&b, "   %s. %s\n", domain.ChoiceLetter(i), choice)
+		}
+	}
+
+	if answers {
+		fmt.Fprintf(&b, "%s%s\n", pageBreak, exam.Title)
+		fmt.Fprintf(&b, "Answer key, variant %s\n\n", variant.Label)
+		for _, entry := range answerKey(exam, variant) {
+			fmt.Fprintf(&b, "%3d. %s  %s\n", entry.Number, entry.AnswerLabel, entry.Answer)
+		}
+	}
+	return b.String()
+}
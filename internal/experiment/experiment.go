@@ -0,0 +1,218 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/config"
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// Experiment splits a share of traffic between named variants.
+type Experiment struct {
+	Name     string
+	Variants []*Variant
+}
+
+// Variant is one arm of an experiment with its own AI client and prompt.
+type Variant struct {
+	Name            string
+	Percent         int
+	Provider        string
+	Model           string
+	PromptVersion   string
+	Instructions    string
+	InputCostPer1K  float64
+	OutputCostPer1K float64
+	Client          ai.Client
+}
+
+// Assignment is the experiment arm chosen for a single request.
+type Assignment struct {
+	Experiment string
+	Variant    *Variant
+}
+
+// Cost returns the token cost of a generation in USD.
+func (v *Variant) Cost(promptTokens, completionTokens int) float64 {
+	return float64(promptTokens)/1000*v.InputCostPer1K + float64(completionTokens)/1000*v.OutputCostPer1K
+}
+
+// FromConfig builds experiments from configuration. Variants without
+// provider or model overrides reuse the default client.
+func FromConfig(cfg *config.Config, defaultClient ai.Client) ([]*Experiment, error) {
+	var experiments []*Experiment
+	total := 0
+
+	for _, ec := range cfg.Experiments {
+		if !ec.Enabled {
+			continue
+		}
+		if ec.Name == "" {
+			return nil, fmt.Errorf("experiment name is required")
+		}
+
+		exp := &Experiment{Name: ec.Name}
+		for _, vc := range ec.Variants {
+			if vc.Name == "" {
+				return nil, fmt.Errorf("experiment %s: variant name is required", ec.Name)
+			}
+			if vc.Percent < 0 {
+				return nil, fmt.Errorf("experiment %s: variant %s has negative percent", ec.Name, vc.Name)
+			}
+			total += vc.Percent
+
+			variant := &Variant{
+				Name:            vc.Name,
+				Percent:         vc.Percent,
+				Provider:        vc.Provider,
+				Model:           vc.Model,
+				PromptVersion:   vc.PromptVersion,
+				Instructions:    vc.Instructions,
+				InputCostPer1K:  vc.InputCostPer1K,
+				OutputCostPer1K: vc.OutputCostPer1K,
+				Client:          defaultClient,
+			}
+			if variant.Provider == "" {
+				variant.Provider = cfg.AIProvider
+			}
+			if variant.Model == "" {
+				variant.Model = cfg.AIModel
+			}
+			if variant.PromptVersion == "" {
+				variant.PromptVersion = ai.DefaultPromptVersion
+			}
+
+			if vc.Provider != "" || vc.Model != "" || vc.BaseURL != "" || vc.APIKey != "" {
+				baseURL := vc.BaseURL
+				if baseURL == "" && variant.Provider == cfg.AIProvider {
+					baseURL = cfg.AIBaseURL
+				}
+				apiKey := vc.APIKey
+				if apiKey == "" {
+					apiKey = cfg.AIApiKey
+				}
+				variant.Client = ai.NewClient(variant.Provider, baseURL, apiKey, variant.Model)
+			}
+
+			exp.Variants = append(exp.Variants, variant)
+		}
+		experiments = append(experiments, exp)
+	}
+
+	if total > 100 {
+		return nil, fmt.Errorf("experiment variants cover %d%% of traffic, must not exceed 100%%", total)
+	}
+
+	return experiments, nil
+}
+
+// Manager assigns requests to experiment variants and records outcomes.
+type Manager struct {
+	store       store.ExperimentStore
+	experiments []*Experiment
+}
+
+func NewManager(store store.ExperimentStore, experiments []*Experiment) *Manager {
+	return &Manager{
+		store:       store,
+		experiments: experiments,
+	}
+}
+
+// Experiments returns the active experiments.
+func (m *Manager) Experiments() []*Experiment {
+	return m.experiments
+}
+
+// Experiment returns the active experiment with the given name.
+func (m *Manager) Experiment(name string) (*Experiment, bool) {
+	for _, exp := range m.experiments {
+		if exp.Name == name {
+			return exp, true
+		}
+	}
+	return nil, false
+}
+
+// Assign picks a variant for the subject, or nil when the request falls
+// outside every experiment. The same subject always lands in the same
+// bucket; an empty subject is assigned at random.
+func (m *Manager) Assign(subject string) *Assignment {
+	if m == nil || len(m.experiments) == 0 {
+		return nil
+	}
+
+	bucket := bucketFor(subject)
+	offset := 0
+	for _, exp := range m.experiments {
+		for _, variant := range exp.Variants {
+			offset += variant.Percent
+			if bucket < offset {
+				return &Assignment{Experiment: exp.Name, Variant: variant}
+			}
+		}
+	}
+	return nil
+}
+
+func bucketFor(subject string) int {
+	if subject == "" {
+		return rand.Intn(100)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(subject))
+	return int(h.Sum32() % 100)
+}
+
+// RecordGeneration stores the outcome of a generation made under an assignment.
+func (m *Manager) RecordGeneration(ctx context.Context, a *Assignment, resultID string, latency time.Duration, meta *domain.Meta, genErr error) error {
+	if m == nil || a == nil {
+		return nil
+	}
+
+	event := &domain.ExperimentEvent{
+		ID:         uuid.New().String(),
+		Experiment: a.Experiment,
+		Variant:    a.Variant.Name,
+		Kind:       domain.ExperimentEventGeneration,
+		ResultID:   resultID,
+		Success:    genErr == nil,
+		LatencyMS:  latency.Milliseconds(),
+		CreatedAt:  time.Now(),
+	}
+	if meta != nil {
+		event.PromptTokens = meta.PromptTokens
+		event.CompletionTokens = meta.CompletionTokens
+		event.CostUSD = meta.CostUSD
+	}
+
+	return m.store.SaveExperimentEvent(ctx, event)
+}
+
+// RecordOutcome stores a learner outcome (feedback or quiz score, 0.0-1.0)
+// for a result generated under an experiment.
+func (m *Manager) RecordOutcome(ctx context.Context, experiment, variant, resultID, kind string, score float64) error {
+	if m == nil || experiment == "" {
+		return nil
+	}
+
+	return m.store.SaveExperimentEvent(ctx, &domain.ExperimentEvent{
+		ID:          uuid.New().String(),
+		Experiment:  experiment,
+		Variant:     variant,
+		Kind:        domain.ExperimentEventOutcome,
+		ResultID:    resultID,
+		Success:     true,
+		OutcomeKind: kind,
+		Score:       score,
+		CreatedAt:   time.Now(),
+	})
+}
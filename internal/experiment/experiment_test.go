@@ -0,0 +1,58 @@
+package experiment
+
+import (
+	"testing"
+
+	"learnforge/internal/config"
+	"learnforge/internal/store"
+)
+
+func TestManager_AssignIsSticky(t *testing.T) {
+	m := NewManager(store.NewInMemStore(), []*Experiment{{
+		Name: "model",
+		Variants: []*Variant{
+			{Name: "control", Percent: 50},
+			{Name: "candidate", Percent: 50},
+		},
+	}})
+
+	first := m.Assign("user:alice")
+	if first == nil {
+		t.Fatal("Expected an assignment when variants cover all traffic")
+	}
+	for i := 0; i < 10; i++ {
+		if got := m.Assign("user:alice"); got.Variant.Name != first.Variant.Name {
+			t.Fatalf("Expected sticky variant %s, got %s", first.Variant.Name, got.Variant.Name)
+		}
+	}
+}
+
+func TestManager_AssignOutsideExperiment(t *testing.T) {
+	m := NewManager(store.NewInMemStore(), []*Experiment{{
+		Name:     "model",
+		Variants: []*Variant{{Name: "candidate", Percent: 0}},
+	}})
+
+	if got := m.Assign("user:alice"); got != nil {
+		t.Errorf("Expected no assignment, got %+v", got)
+	}
+
+	var nilManager *Manager
+	if got := nilManager.Assign("user:alice"); got != nil {
+		t.Errorf("Expected nil manager to assign nothing, got %+v", got)
+	}
+}
+
+func TestFromConfig_RejectsOverAllocation(t *testing.T) {
+	cfg := &config.Config{
+		AIProvider: "openai",
+		Experiments: []config.ExperimentConfig{
+			{Name: "a", Enabled: true, Variants: []config.VariantConfig{{Name: "x", Percent: 60}}},
+			{Name: "b", Enabled: true, Variants: []config.VariantConfig{{Name: "y", Percent: 50}}},
+		},
+	}
+
+	if _, err := FromConfig(cfg, nil); err == nil {
+		t.Fatal("Expected error when variants exceed 100% of traffic")
+	}
+}
@@ -0,0 +1,147 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"learnforge/internal/domain"
+)
+
+// Report aggregates experiment events per variant.
+type Report struct {
+	Experiment string          `json:"experiment"`
+	Variants   []VariantReport `json:"variants"`
+}
+
+type VariantReport struct {
+	Variant       string  `json:"variant"`
+	Provider      string  `json:"provider,omitempty"`
+	Model         string  `json:"model,omitempty"`
+	PromptVersion string  `json:"prompt_version,omitempty"`
+	Percent       int     `json:"percent"`
+	Requests      int     `json:"requests"`
+	Failures      int     `json:"failures"`
+	FailureRate   float64 `json:"failure_rate"`
+	AvgLatencyMS  float64 `json:"avg_latency_ms"`
+	P95LatencyMS  int64   `json:"p95_latency_ms"`
+	TotalTokens   int     `json:"total_tokens"`
+	TotalCostUSD  float64 `json:"total_cost_usd"`
+	AvgCostUSD    float64 `json:"avg_cost_usd"`
+	Outcomes      int     `json:"outcomes"`
+	AvgFeedback   float64 `json:"avg_feedback"`
+	AvgQuizScore  float64 `json:"avg_quiz_score"`
+}
+
+// Report builds per-variant statistics for the named experiment.
+func (m *Manager) Report(ctx context.Context, name string) (*Report, error) {
+	exp, ok := m.Experiment(name)
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "experiment not found", nil)
+	}
+
+	events, err := m.store.ListExperimentEvents(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list experiment events: %w", err)
+	}
+
+	return buildReport(exp, events), nil
+}
+
+func buildReport(exp *Experiment, events []*domain.ExperimentEvent) *Report {
+	type acc struct {
+		latencies        []int64
+		failures         int
+		tokens           int
+		cost             float64
+		feedback, quiz   float64
+		nFeedback, nQuiz int
+	}
+
+	accs := make(map[string]*acc)
+	for _, variant := range exp.Variants {
+		accs[variant.Name] = &acc{}
+	}
+
+	for _, event := range events {
+		a, ok := accs[event.Variant]
+		if !ok {
+			// Variant was removed from the configuration; keep its history visible.
+			a = &acc{}
+			accs[event.Variant] = a
+		}
+
+		switch event.Kind {
+		case domain.ExperimentEventGeneration:
+			a.latencies = append(a.latencies, event.LatencyMS)
+			if !event.Success {
+				a.failures++
+			}
+			a.tokens += event.PromptTokens + event.CompletionTokens
+			a.cost += event.CostUSD
+		case domain.ExperimentEventOutcome:
+			if event.OutcomeKind == domain.OutcomeQuizScore {
+				a.quiz += event.Score
+				a.nQuiz++
+			} else {
+				a.feedback += event.Score
+				a.nFeedback++
+			}
+		}
+	}
+
+	report := &Report{Experiment: exp.Name}
+	for name, a := range accs {
+		vr := VariantReport{
+			Variant:      name,
+			Requests:     len(a.latencies),
+			Failures:     a.failures,
+			TotalTokens:  a.tokens,
+			TotalCostUSD: a.cost,
+			Outcomes:     a.nFeedback + a.nQuiz,
+		}
+		for _, variant := range exp.Variants {
+			if variant.Name == name {
+				vr.Provider = variant.Provider
+				vr.Model = variant.Model
+				vr.PromptVersion = variant.PromptVersion
+				vr.Percent = variant.Percent
+			}
+		}
+
+		if vr.Requests > 0 {
+			var sum int64
+			for _, l := range a.latencies {
+				sum += l
+			}
+			vr.AvgLatencyMS = float64(sum) / float64(vr.Requests)
+			vr.P95LatencyMS = percentile(a.latencies, 0.95)
+			vr.FailureRate = float64(a.failures) / float64(vr.Requests)
+			vr.AvgCostUSD = a.cost / float64(vr.Requests)
+		}
+		if a.nFeedback > 0 {
+			vr.AvgFeedback = a.feedback / float64(a.nFeedback)
+		}
+		if a.nQuiz > 0 {
+			vr.AvgQuizScore = a.quiz / float64(a.nQuiz)
+		}
+
+		report.Variants = append(report.Variants, vr)
+	}
+
+	sort.Slice(report.Variants, func(i, j int) bool {
+		return report.Variants[i].Variant < report.Variants[j].Variant
+	})
+
+	return report
+}
+
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
@@ -0,0 +1,123 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/ingest"
+	"learnforge/internal/store"
+)
+
+func TestParse(t *testing.T) {
+	atom := `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Security Advisories</title>
+  <entry>
+    <id>urn:advisory:2024-001</id>
+    <title>OpenSSL   update</title>
+    <link rel="alternate" href="https://example.com/a/1"/>
+    <updated>2024-03-01T10:00:00Z</updated>
+    <content type="html">&lt;p&gt;Patch now.&lt;/p&gt;</content>
+  </entry>
+</feed>`
+	feed, err := Parse([]byte(atom))
+	if err != nil {
+		t.Fatalf("Parse atom: %v", err)
+	}
+	e := feed.Entries[0]
+	if feed.Title != "Security Advisories" || e.GUID != "urn:advisory:2024-001" || e.Title != "OpenSSL update" ||
+		e.Link != "https://example.com/a/1" || e.Content != "<p>Patch now.</p>" || e.Published == nil {
+		t.Errorf("unexpected atom entry: %+v", e)
+	}
+
+	rss := `<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel><title>Blog</title>
+<item><title>First</title><link>https://example.com/1</link><description>Excerpt</description>
+<content:encoded><![CDATA[<p>Full text</p>]]></content:encoded><pubDate>Mon, 4 Mar 2024 09:00:00 +0000</pubDate></item>
+<item><title>No id</title><description>Body&nbsp;text</description></item>
+</channel></rss>`
+	feed, err = Parse([]byte(rss))
+	if err != nil {
+		t.Fatalf("Parse rss: %v", err)
+	}
+	if feed.Entries[0].GUID != "https://example.com/1" || feed.Entries[0].Content != "<p>Full text</p>" {
+		t.Errorf("unexpected rss entry: %+v", feed.Entries[0])
+	}
+	if !strings.HasPrefix(feed.Entries[1].GUID, "sha256:") {
+		t.Errorf("entry without guid or link should get a content hash, got %q", feed.Entries[1].GUID)
+	}
+
+	if _, err := Parse([]byte("<html><body>not a feed</body></html>")); err == nil {
+		t.Error("expected error for HTML page")
+	}
+}
+
+type recordingProcessor struct {
+	requests []*domain.ProcessRequest
+}
+
+func (p *recordingProcessor) ProcessText(ctx context.Context, req *domain.ProcessRequest) (*domain.ProcessResponse, error) {
+	p.requests = append(p.requests, req)
+	return &domain.ProcessResponse{ID: fmt.Sprintf("result-%d", len(p.requests))}, nil
+}
+
+func TestManager_Poll(t *testing.T) {
+	items := []string{"5", "4", "3", "2", "1"} // newest first
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Advisories</title>`)
+		for _, n := range items {
+			fmt.Fprintf(w, `<item><guid>adv-%s</guid><title>Advisory %s</title><description>%s</description></item>`,
+				n, n, strings.Repeat("Upgrade the affected library immediately. ", 20))
+		}
+		fmt.Fprint(w, `</channel></rss>`)
+	}))
+	defer srv.Close()
+
+	processor := &recordingProcessor{}
+	st := store.NewInMemStore()
+	m := NewManager(processor, ingest.NewFetcher(ingest.FetchOptions{AllowPrivateNetworks: true}), st, nil)
+	ctx := context.Background()
+
+	sub, err := m.Subscribe(ctx, SubscribeRequest{URL: srv.URL, Mode: "quiz", Schedule: "0 9 * * 1"})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if sub.Title != "Advisories" || sub.Mode != "quiz" {
+		t.Errorf("unexpected subscription: %+v", sub)
+	}
+
+	_, lessons, err := m.Poll(ctx, sub.ID)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(lessons) != backfillEntries || lessons[0].Title != "Advisory 3" || lessons[2].Title != "Advisory 5" {
+		t.Fatalf("first poll should backfill the newest entries oldest first, got %+v", lessons)
+	}
+	req := processor.requests[0]
+	if req.Mode != "quiz" || !strings.HasPrefix(req.Text, "# Advisory 3\n\n") || req.DocumentID == "" {
+		t.Errorf("unexpected process request: %+v", req)
+	}
+
+	items = append([]string{"6"}, items...)
+	_, lessons, err = m.Poll(ctx, sub.ID)
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(lessons) != 1 || lessons[0].GUID != "adv-6" {
+		t.Errorf("second poll should only process the new entry, got %+v", lessons)
+	}
+
+	stored, _ := st.GetFeed(ctx, sub.ID)
+	if len(stored.SeenGUIDs) != 6 || len(stored.Recent) != 4 || stored.LastPolledAt == nil {
+		t.Errorf("unexpected cursor state: %d seen, %d recent", len(stored.SeenGUIDs), len(stored.Recent))
+	}
+
+	if _, err := m.Subscribe(ctx, SubscribeRequest{URL: srv.URL, Schedule: "every tuesday"}); err == nil {
+		t.Error("expected error for invalid schedule")
+	}
+}
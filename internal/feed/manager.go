@@ -0,0 +1,428 @@
+package feed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/ingest"
+	"learnforge/internal/slack"
+	"learnforge/internal/store"
+
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// DefaultSchedule polls hourly.
+	DefaultSchedule = "0 * * * *"
+
+	// backfillEntries limits how many existing articles become lessons on
+	// the first poll; older entries are marked as seen.
+	backfillEntries = 3
+	// maxEntriesPerPoll bounds the work of a single poll. Remaining entries
+	// are picked up by the next one.
+	maxEntriesPerPoll = 20
+	// maxSeenGUIDs must exceed the length of any feed, or entries that
+	// dropped out of the cursor would be generated again.
+	maxSeenGUIDs = 500
+	maxRecent    = 20
+
+	// minEntryChars is the shortest inline content worth generating from;
+	// shorter entries are excerpts, so the linked article is fetched.
+	minEntryChars = 600
+
+	pollTimeout = 10 * time.Minute
+)
+
+// Processor generates a lesson from text; *service.Service satisfies it.
+type Processor interface {
+	ProcessText(ctx context.Context, req *domain.ProcessRequest) (*domain.ProcessResponse, error)
+}
+
+// Manager stores feed subscriptions and polls each on its own cron
+// schedule.
+type Manager struct {
+	processor Processor
+	fetcher   *ingest.Fetcher
+	store     store.FeedStore
+	slack     *slack.Client
+	now       func() time.Time
+
+	cron    *cron.Cron
+	mu      sync.Mutex
+	entries map[string]cron.EntryID
+	polling map[string]bool
+}
+
+// NewManager returns a Manager. slackClient may be nil to disable
+// notifications.
+func NewManager(processor Processor, fetcher *ingest.Fetcher, store store.FeedStore, slackClient *slack.Client) *Manager {
+	return &Manager{
+		processor: processor,
+		fetcher:   fetcher,
+		store:     store,
+		slack:     slackClient,
+		now:       time.Now,
+		cron:      cron.New(cron.WithLocation(time.UTC)),
+		entries:   make(map[string]cron.EntryID),
+		polling:   make(map[string]bool),
+	}
+}
+
+// Start schedules every stored subscription and starts the scheduler.
+func (m *Manager) Start(ctx context.Context) error {
+	feeds, err := m.store.ListFeeds(ctx)
+	if err != nil {
+		return err
+	}
+	for _, feed := range feeds {
+		if err := m.schedule(feed); err != nil {
+			log.Printf(`{"level":"error","msg":"Failed to schedule feed","feed":"%s","error":"%v"}`, feed.ID, err)
+		}
+	}
+
+	m.cron.Start()
+	log.Printf(`{"level":"info","msg":"Feed scheduler started","feeds":%d}`, len(feeds))
+	return nil
+}
+
+func (m *Manager) Stop() {
+	ctx := m.cron.Stop()
+	<-ctx.Done()
+	log.Println(`{"level":"info","msg":"Feed scheduler stopped"}`)
+}
+
+// SubscribeRequest describes a new subscription.
+type SubscribeRequest struct {
+	URL         string  `json:"url"`
+	Topic       *string `json:"topic,omitempty"`
+	Level       *string `json:"level,omitempty"`
+	Mode        string  `json:"mode,omitempty"`
+	Language    string  `json:"language,omitempty"`
+	Schedule    string  `json:"schedule,omitempty"`
+	NotifySlack bool    `json:"notify_slack,omitempty"`
+}
+
+// Subscribe validates req, checks that the URL serves a feed, and
+// schedules polling. Lessons are generated from the first scheduled poll.
+func (m *Manager) Subscribe(ctx context.Context, req SubscribeRequest) (*domain.FeedSubscription, error) {
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "url must be an absolute http or https URL", err)
+	}
+	if !domain.ValidateMode(req.Mode) {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "mode must be one of: lesson, flashcards, quiz", nil)
+	}
+	if !domain.ValidateLevel(req.Level) {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "level must be one of: beginner, intermediate, advanced", nil)
+	}
+	if req.Schedule == "" {
+		req.Schedule = DefaultSchedule
+	}
+	if _, err := cron.ParseStandard(req.Schedule); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "schedule must be a cron expression", err)
+	}
+	if req.Mode == "" {
+		req.Mode = "lesson"
+	}
+
+	parsed, err := m.fetch(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now().UTC()
+	feed := &domain.FeedSubscription{
+		ID:          uuid.New().String(),
+		URL:         req.URL,
+		Title:       parsed.Title,
+		Topic:       req.Topic,
+		Level:       req.Level,
+		Mode:        req.Mode,
+		Language:    req.Language,
+		Schedule:    req.Schedule,
+		NotifySlack: req.NotifySlack,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := m.store.SaveFeed(ctx, feed); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to save feed subscription", err)
+	}
+	if err := m.schedule(feed); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to schedule feed", err)
+	}
+	return feed, nil
+}
+
+// Unsubscribe stops polling and deletes the subscription. Lessons already
+// generated are kept.
+func (m *Manager) Unsubscribe(ctx context.Context, id string) error {
+	if err := m.store.DeleteFeed(ctx, id); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if entry, ok := m.entries[id]; ok {
+		m.cron.Remove(entry)
+		delete(m.entries, id)
+	}
+	return nil
+}
+
+func (m *Manager) Get(ctx context.Context, id string) (*domain.FeedSubscription, error) {
+	return m.store.GetFeed(ctx, id)
+}
+
+func (m *Manager) List(ctx context.Context) ([]*domain.FeedSubscription, error) {
+	return m.store.ListFeeds(ctx)
+}
+
+func (m *Manager) schedule(feed *domain.FeedSubscription) error {
+	id := feed.ID
+	entry, err := m.cron.AddFunc(feed.Schedule, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pollTimeout)
+		defer cancel()
+		if _, lessons, err := m.Poll(ctx, id); err != nil {
+			log.Printf(`{"level":"error","msg":"Feed poll failed","feed":"%s","error":"%v"}`, id, err)
+		} else {
+			log.Printf(`{"level":"info","msg":"Feed polled","feed":"%s","lessons":%d}`, id, len(lessons))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if previous, ok := m.entries[id]; ok {
+		m.cron.Remove(previous)
+	}
+	m.entries[id] = entry
+	return nil
+}
+
+func (m *Manager) acquire(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.polling[id] {
+		return domain.NewDomainError(domain.ErrorCodeConflict, "feed is already being polled", nil)
+	}
+	m.polling[id] = true
+	return nil
+}
+
+func (m *Manager) release(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.polling, id)
+}
+
+// Poll fetches the feed and generates a lesson for every entry whose GUID
+// has not been seen, oldest first. Entries that fail with a permanent
+// error (such as an unreadable article) are marked as seen; others are
+// retried on the next poll.
+func (m *Manager) Poll(ctx context.Context, id string) (*domain.FeedSubscription, []domain.FeedLesson, error) {
+	if err := m.acquire(id); err != nil {
+		return nil, nil, err
+	}
+	defer m.release(id)
+
+	stored, err := m.store.GetFeed(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	sub := *stored
+	now := m.now().UTC()
+	sub.LastPolledAt = &now
+	sub.UpdatedAt = now
+
+	parsed, err := m.fetch(ctx, sub.URL)
+	if err != nil {
+		sub.LastError = err.Error()
+		_ = m.save(ctx, &sub)
+		return &sub, nil, err
+	}
+	if sub.Title == "" {
+		sub.Title = parsed.Title
+	}
+
+	seen := make(map[string]bool, len(sub.SeenGUIDs))
+	for _, guid := range sub.SeenGUIDs {
+		seen[guid] = true
+	}
+	var pending []Entry
+	for _, entry := range parsed.Entries {
+		if !seen[entry.GUID] {
+			seen[entry.GUID] = true
+			pending = append(pending, entry)
+		}
+	}
+	pending = oldestFirst(pending)
+
+	firstPoll := stored.LastPolledAt == nil && len(stored.SeenGUIDs) == 0
+	if firstPoll && len(pending) > backfillEntries {
+		for _, entry := range pending[:len(pending)-backfillEntries] {
+			sub.SeenGUIDs = markSeen(sub.SeenGUIDs, entry.GUID)
+		}
+		pending = pending[len(pending)-backfillEntries:]
+	}
+	if len(pending) > maxEntriesPerPoll {
+		pending = pending[:maxEntriesPerPoll]
+	}
+
+	var lessons []domain.FeedLesson
+	var failures []string
+	for _, entry := range pending {
+		resp, err := m.generate(ctx, &sub, entry)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", entry.Title, err))
+			if domain.HasCode(err, domain.ErrorCodeInvalidArgument) {
+				sub.SeenGUIDs = markSeen(sub.SeenGUIDs, entry.GUID)
+			}
+			continue
+		}
+		lesson := domain.FeedLesson{
+			GUID:        entry.GUID,
+			Title:       entry.Title,
+			Link:        entry.Link,
+			ResultID:    resp.ID,
+			PublishedAt: entry.Published,
+			CreatedAt:   m.now().UTC(),
+		}
+		lessons = append(lessons, lesson)
+		sub.SeenGUIDs = markSeen(sub.SeenGUIDs, entry.GUID)
+		sub.Recent = append([]domain.FeedLesson{lesson}, sub.Recent...)
+	}
+	if len(sub.Recent) > maxRecent {
+		sub.Recent = sub.Recent[:maxRecent]
+	}
+	sub.LastError = strings.Join(failures, "; ")
+
+	if err := m.save(ctx, &sub); err != nil {
+		return nil, nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to save feed subscription", err)
+	}
+
+	if sub.NotifySlack && m.slack != nil && len(lessons) > 0 {
+		if err := m.notify(ctx, &sub, lessons); err != nil {
+			log.Printf(`{"level":"error","msg":"Failed to send feed lessons to Slack","feed":"%s","error":"%v"}`, sub.ID, err)
+		}
+	}
+	return &sub, lessons, nil
+}
+
+// save stores the poll state unless the subscription was deleted while
+// the poll was running.
+func (m *Manager) save(ctx context.Context, sub *domain.FeedSubscription) error {
+	if _, err := m.store.GetFeed(ctx, sub.ID); err != nil {
+		return nil
+	}
+	return m.store.SaveFeed(ctx, sub)
+}
+
+func (m *Manager) fetch(ctx context.Context, rawURL string) (*Feed, error) {
+	page, err := m.fetcher.FetchFeed(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := Parse(page.Body)
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, err.Error(), nil)
+	}
+	return parsed, nil
+}
+
+// generate turns an entry into a lesson. Full-text entries are used as
+// is; excerpts are replaced by the linked article. The document ID makes
+// a retried entry update its earlier result instead of duplicating it.
+func (m *Manager) generate(ctx context.Context, sub *domain.FeedSubscription, entry Entry) (*domain.ProcessResponse, error) {
+	req := &domain.ProcessRequest{
+		Mode:       sub.Mode,
+		Topic:      sub.Topic,
+		Level:      sub.Level,
+		Language:   sub.Language,
+		DocumentID: "feed:" + sub.ID + ":" + guidHash(entry.GUID),
+	}
+
+	text := ingest.ExtractHTML([]byte("<html><body>" + entry.Content + "</body></html>")).Text
+	if len(text) < minEntryChars && entry.Link != "" {
+		req.SourceURL = entry.Link
+		resp, err := m.processor.ProcessText(ctx, req)
+		if err == nil || strings.TrimSpace(text) == "" {
+			return resp, err
+		}
+		req.SourceURL = ""
+	}
+	if strings.TrimSpace(text) == "" {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "entry has no content and no link", nil)
+	}
+
+	req.Text = text
+	if entry.Title != "" {
+		req.Text = "# " + entry.Title + "\n\n" + text
+	}
+	return m.processor.ProcessText(ctx, req)
+}
+
+func (m *Manager) notify(ctx context.Context, sub *domain.FeedSubscription, lessons []domain.FeedLesson) error {
+	var b strings.Builder
+	for _, lesson := range lessons {
+		title := lesson.Title
+		if lesson.Link != "" {
+			title = fmt.Sprintf("<%s|%s>", lesson.Link, lesson.Title)
+		}
+		fmt.Fprintf(&b, "• %s — `/v1/process/%s`\n", title, lesson.ResultID)
+	}
+	name := sub.Title
+	if name == "" {
+		name = sub.URL
+	}
+	return m.slack.SendSummary(ctx, fmt.Sprintf("New %s from %s", plural(sub.Mode), name), b.String())
+}
+
+func plural(mode string) string {
+	switch mode {
+	case "quiz":
+		return "quizzes"
+	case "flashcards":
+		return "flashcards"
+	default:
+		return "lessons"
+	}
+}
+
+// guidHash keeps document IDs short for feeds that use long URLs as GUIDs.
+func guidHash(guid string) string {
+	sum := sha256.Sum256([]byte(guid))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// oldestFirst orders entries by publication date when every entry has
+// one, and otherwise reverses feed order (feeds list newest entries first).
+func oldestFirst(entries []Entry) []Entry {
+	out := make([]Entry, len(entries))
+	dated := true
+	for i, e := range entries {
+		out[len(entries)-1-i] = e
+		dated = dated && e.Published != nil
+	}
+	if dated {
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Published.Before(*out[j].Published) })
+	}
+	return out
+}
+
+func markSeen(seen []string, guid string) []string {
+	seen = append([]string{guid}, seen...)
+	if len(seen) > maxSeenGUIDs {
+		seen = seen[:maxSeenGUIDs]
+	}
+	return seen
+}
@@ -0,0 +1,229 @@
+// Package feed polls RSS and Atom subscriptions and generates a lesson for
+// every new article.
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Feed is a parsed RSS 2.0, RSS 1.0 or Atom document.
+type Feed struct {
+	Title   string
+	Entries []Entry
+}
+
+// Entry is one article of a feed. Content is HTML.
+type Entry struct {
+	GUID      string
+	Title     string
+	Link      string
+	Content   string
+	Published *time.Time
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	// RSS 1.0 places items next to the channel rather than inside it.
+	Items []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	About       string `xml:"about,attr"`
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	Description string `xml:"description"`
+	Encoded     string `xml:"encoded"` // content:encoded
+	PubDate     string `xml:"pubDate"`
+	Date        string `xml:"date"` // dc:date
+}
+
+type atomFeed struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Summary   atomText `xml:"summary"`
+	Content   atomText `xml:"content"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+}
+
+// atomText holds escaped HTML or text, or inline XHTML markup.
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) html() string {
+	if t.Type == "xhtml" {
+		return t.Inner
+	}
+	return t.Text
+}
+
+// Parse decodes an RSS or Atom feed.
+func Parse(data []byte) (*Feed, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = charsetReader
+
+	var root xml.StartElement
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, errors.New("not an RSS or Atom feed")
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			root = start
+			break
+		}
+	}
+
+	switch root.Name.Local {
+	case "rss", "RDF":
+		var doc rssDocument
+		if err := d.DecodeElement(&doc, &root); err != nil {
+			return nil, fmt.Errorf("invalid RSS feed: %w", err)
+		}
+		items := append(doc.Channel.Items, doc.Items...)
+		feed := &Feed{Title: clean(doc.Channel.Title)}
+		for _, item := range items {
+			content := item.Encoded
+			if strings.TrimSpace(content) == "" {
+				content = item.Description
+			}
+			feed.Entries = append(feed.Entries, newEntry(
+				firstNonEmpty(item.GUID, item.About, item.Link),
+				item.Title, item.Link, content,
+				parseDate(firstNonEmpty(item.PubDate, item.Date)),
+			))
+		}
+		return feed, nil
+	case "feed":
+		var doc atomFeed
+		if err := d.DecodeElement(&doc, &root); err != nil {
+			return nil, fmt.Errorf("invalid Atom feed: %w", err)
+		}
+		feed := &Feed{Title: clean(doc.Title)}
+		for _, entry := range doc.Entries {
+			content := entry.Content.html()
+			if strings.TrimSpace(content) == "" {
+				content = entry.Summary.html()
+			}
+			feed.Entries = append(feed.Entries, newEntry(
+				entry.ID, entry.Title, atomLink(entry), content,
+				parseDate(firstNonEmpty(entry.Published, entry.Updated)),
+			))
+		}
+		return feed, nil
+	default:
+		return nil, errors.New("not an RSS or Atom feed")
+	}
+}
+
+// newEntry falls back to a content hash for entries without any
+// identifier so they are still deduplicated.
+func newEntry(guid, title, link, content string, published *time.Time) Entry {
+	e := Entry{
+		GUID:      clean(guid),
+		Title:     clean(title),
+		Link:      clean(link),
+		Content:   strings.TrimSpace(content),
+		Published: published,
+	}
+	if e.GUID == "" {
+		sum := sha256.Sum256([]byte(e.Title + "\n" + e.Content))
+		e.GUID = "sha256:" + hex.EncodeToString(sum[:])[:32]
+	}
+	return e
+}
+
+func atomLink(entry atomEntry) string {
+	for _, l := range entry.Links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return l.Href
+		}
+	}
+	if len(entry.Links) > 0 {
+		return entry.Links[0].Href
+	}
+	return ""
+}
+
+var dateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+func parseDate(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// charsetReader handles the legacy single-byte encodings feeds still
+// declare; Windows-1252 is decoded as Latin-1, which differs only in
+// punctuation.
+func charsetReader(label string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(label) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return input, nil
+	case "iso-8859-1", "latin1", "latin-1", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return strings.NewReader(string(runes)), nil
+	default:
+		return nil, fmt.Errorf("unsupported feed encoding %q", label)
+	}
+}
@@ -0,0 +1,358 @@
+// Package feedback collects learners' ratings of generated summaries,
+// flashcards and quiz items, and aggregates them per prompt version and
+// model so prompt changes can be judged by the people studying the
+// content.
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/experiment"
+	"learnforge/internal/store"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultMinDownvotes is how many learners must rate an item down,
+	// and more than rated it up, before it counts as low-rated.
+	DefaultMinDownvotes = 3
+
+	maxComment  = 1000
+	maxLowRated = 50 // listed in a report
+)
+
+// Service records and aggregates feedback.
+type Service struct {
+	feedback       store.FeedbackStore
+	results        store.Store
+	reviewRequired bool
+	minDownvotes   int
+	experiments    *experiment.Manager
+	now            func() time.Time
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithReviewRequired only takes learners' feedback on published results.
+func WithReviewRequired(required bool) Option {
+	return func(s *Service) {
+		s.reviewRequired = required
+	}
+}
+
+// WithMinDownvotes sets how many learners must rate an item down before
+// it counts as low-rated.
+func WithMinDownvotes(n int) Option {
+	return func(s *Service) {
+		if n > 0 {
+			s.minDownvotes = n
+		}
+	}
+}
+
+// WithExperiments reports ratings as outcomes of the experiment variant
+// that generated the result: 1 for up and 0 for down.
+func WithExperiments(experiments *experiment.Manager) Option {
+	return func(s *Service) {
+		s.experiments = experiments
+	}
+}
+
+func NewService(feedback store.FeedbackStore, results store.Store, opts ...Option) *Service {
+	s := &Service{
+		feedback:     feedback,
+		results:      results,
+		minDownvotes: DefaultMinDownvotes,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Input is a learner's feedback on the summary of a result, or on one of
+// its flashcards or quiz items.
+type Input struct {
+	Kind       string   `json:"kind"`
+	ItemID     string   `json:"item_id"`
+	Rating     string   `json:"rating"`
+	Categories []string `json:"categories"`
+	Comment    string   `json:"comment"`
+}
+
+// Key identifies an item across results in LowRated.
+func Key(resultID, kind, itemID string) string {
+	return resultID + "/" + kind + "/" + itemID
+}
+
+// Submit stores learnerID's feedback on result id, replacing their
+// earlier feedback on the same item. learner restricts feedback to
+// results learners can see.
+func (s *Service) Submit(ctx context.Context, id, learnerID string, in Input, learner bool) (*domain.Feedback, error) {
+	if strings.TrimSpace(learnerID) == "" {
+		return nil, domain.InvalidArgument("X-User-ID is required to give feedback")
+	}
+	if in.Rating != domain.FeedbackUp && in.Rating != domain.FeedbackDown {
+		return nil, domain.InvalidArgument("rating must be one of: up, down")
+	}
+	categories, err := normalizeCategories(in.Categories)
+	if err != nil {
+		return nil, err
+	}
+	comment := strings.TrimSpace(in.Comment)
+	if len(comment) > maxComment {
+		return nil, domain.InvalidArgument(fmt.Sprintf("comment must be at most %d characters", maxComment))
+	}
+
+	stored, err := s.results.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if learner && s.reviewRequired && stored.Status != domain.ReviewPublished {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "result not found", nil)
+	}
+	var resp domain.ProcessResponse
+	if err := json.Unmarshal(stored.ResponseJSON, &resp); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+	}
+	if err := checkItem(&resp, in.Kind, in.ItemID); err != nil {
+		return nil, err
+	}
+
+	feedback := &domain.Feedback{
+		ID:            uuid.New().String(),
+		ResultID:      id,
+		Kind:          in.Kind,
+		ItemID:        in.ItemID,
+		LearnerID:     learnerID,
+		Rating:        in.Rating,
+		Categories:    categories,
+		Comment:       comment,
+		PromptVersion: resp.Meta.PromptVersion,
+		Provider:      resp.Meta.Provider,
+		Model:         resp.Meta.Model,
+		CreatedAt:     s.now().UTC(),
+	}
+	if err := s.feedback.SaveFeedback(ctx, feedback); err != nil {
+		return nil, err
+	}
+	score := 0.0
+	if in.Rating == domain.FeedbackUp {
+		score = 1
+	}
+	if err := s.experiments.RecordOutcome(ctx, stored.Experiment, stored.Variant, id, domain.OutcomeFeedback, score); err != nil {
+		log.Printf(`{"level":"warn","msg":"Failed to record experiment outcome","result_id":"%s","error":"%v"}`, id, err)
+	}
+	return feedback, nil
+}
+
+// ResultFeedback returns the feedback on result id by item: the summary
+// first, then flashcards and quiz items in their order in the result.
+// Items without feedback are left out.
+func (s *Service) ResultFeedback(ctx context.Context, id string) ([]domain.ItemFeedback, error) {
+	stored, err := s.results.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var resp domain.ProcessResponse
+	if err := json.Unmarshal(stored.ResponseJSON, &resp); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+	}
+	all, err := s.feedback.ListResultFeedback(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	byItem := s.byItem(all)
+
+	keys := []string{Key(id, domain.FeedbackSummary, "")}
+	for _, fc := range resp.Flashcards {
+		keys = append(keys, Key(id, domain.FeedbackFlashcard, fc.ID))
+	}
+	for _, item := range resp.Quiz {
+		keys = append(keys, Key(id, domain.FeedbackQuiz, item.ID))
+	}
+	items := []domain.ItemFeedback{}
+	for _, key := range keys {
+		if item, ok := byItem[key]; ok {
+			items = append(items, *item)
+		}
+	}
+	return items, nil
+}
+
+// LowRated returns the keys, see Key, of the low-rated items of
+// resultIDs.
+func (s *Service) LowRated(ctx context.Context, resultIDs []string) (map[string]bool, error) {
+	lowRated := make(map[string]bool)
+	if len(resultIDs) == 0 {
+		return lowRated, nil
+	}
+	all, err := s.feedback.ListResultFeedback(ctx, resultIDs)
+	if err != nil {
+		return nil, err
+	}
+	for key, item := range s.byItem(all) {
+		if item.LowRated {
+			lowRated[key] = true
+		}
+	}
+	return lowRated, nil
+}
+
+// Report aggregates the feedback given in [from, to) per prompt version
+// and model, and lists the low-rated items that got some of it.
+func (s *Service) Report(ctx context.Context, from, to time.Time) (*domain.FeedbackReport, error) {
+	given, err := s.feedback.ListFeedback(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.FeedbackReport{
+		From:     from,
+		To:       to,
+		Groups:   []domain.FeedbackGroup{},
+		LowRated: []domain.ItemFeedback{},
+	}
+
+	groups := make(map[string]*domain.FeedbackGroup)
+	groupResults := make(map[string]map[string]bool)
+	var resultIDs []string
+	seenResults := make(map[string]bool)
+	for _, f := range given {
+		report.Totals.Add(f)
+		key := f.PromptVersion + "\x00" + f.Provider + "\x00" + f.Model
+		group, ok := groups[key]
+		if !ok {
+			group = &domain.FeedbackGroup{PromptVersion: f.PromptVersion, Provider: f.Provider, Model: f.Model}
+			groups[key] = group
+			groupResults[key] = make(map[string]bool)
+		}
+		group.Add(f)
+		if !groupResults[key][f.ResultID] {
+			groupResults[key][f.ResultID] = true
+			group.Results++
+		}
+		if !seenResults[f.ResultID] {
+			seenResults[f.ResultID] = true
+			resultIDs = append(resultIDs, f.ResultID)
+		}
+	}
+	for _, group := range groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if downShare(a.FeedbackCounts) != downShare(b.FeedbackCounts) {
+			return downShare(a.FeedbackCounts) > downShare(b.FeedbackCounts)
+		}
+		return a.PromptVersion+a.Provider+a.Model < b.PromptVersion+b.Provider+b.Model
+	})
+
+	// Whether an item is low-rated depends on all its feedback, not only
+	// on what was given in the period.
+	if len(resultIDs) > 0 {
+		all, err := s.feedback.ListResultFeedback(ctx, resultIDs)
+		if err != nil {
+			return nil, err
+		}
+		rated := make(map[string]bool)
+		for _, f := range given {
+			rated[Key(f.ResultID, f.Kind, f.ItemID)] = true
+		}
+		for key, item := range s.byItem(all) {
+			if item.LowRated && rated[key] {
+				report.LowRated = append(report.LowRated, *item)
+			}
+		}
+	}
+	sort.Slice(report.LowRated, func(i, j int) bool {
+		a, b := report.LowRated[i], report.LowRated[j]
+		if a.Down != b.Down {
+			return a.Down > b.Down
+		}
+		return Key(a.ResultID, a.Kind, a.ItemID) < Key(b.ResultID, b.Kind, b.ItemID)
+	})
+	if len(report.LowRated) > maxLowRated {
+		report.LowRated = report.LowRated[:maxLowRated]
+	}
+	return report, nil
+}
+
+// byItem tallies feedback by item key.
+func (s *Service) byItem(feedback []*domain.Feedback) map[string]*domain.ItemFeedback {
+	items := make(map[string]*domain.ItemFeedback)
+	for _, f := range feedback {
+		key := Key(f.ResultID, f.Kind, f.ItemID)
+		item, ok := items[key]
+		if !ok {
+			item = &domain.ItemFeedback{ResultID: f.ResultID, Kind: f.Kind, ItemID: f.ItemID}
+			items[key] = item
+		}
+		item.Add(f)
+	}
+	for _, item := range items {
+		item.LowRated = item.Down >= s.minDownvotes && item.Down > item.Up
+	}
+	return items
+}
+
+// checkItem checks that the result has the item feedback is given on.
+func checkItem(resp *domain.ProcessResponse, kind, itemID string) error {
+	switch kind {
+	case domain.FeedbackSummary:
+		if itemID != "" {
+			return domain.InvalidArgument("item_id must be empty for feedback on the summary")
+		}
+		return nil
+	case domain.FeedbackFlashcard:
+		for _, fc := range resp.Flashcards {
+			if itemID != "" && fc.ID == itemID {
+				return nil
+			}
+		}
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "flashcard not found", nil)
+	case domain.FeedbackQuiz:
+		for _, item := range resp.Quiz {
+			if itemID != "" && item.ID == itemID {
+				return nil
+			}
+		}
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "quiz item not found", nil)
+	}
+	return domain.InvalidArgument("kind must be one of: summary, flashcard, quiz")
+}
+
+func normalizeCategories(categories []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, category := range categories {
+		valid := false
+		for _, c := range domain.ValidFeedbackCategories {
+			valid = valid || c == category
+		}
+		if !valid {
+			return nil, domain.InvalidArgument("categories must be any of: wrong_answer, ambiguous, off_topic, typo")
+		}
+		if !seen[category] {
+			seen[category] = true
+			normalized = append(normalized, category)
+		}
+	}
+	return normalized, nil
+}
+
+func downShare(c domain.FeedbackCounts) float64 {
+	if c.Up+c.Down == 0 {
+		return 0
+	}
+	return float64(c.Down) / float64(c.Up+c.Down)
+}
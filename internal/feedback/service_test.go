@@ -0,0 +1,142 @@
+package feedback
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/experiment"
+	"learnforge/internal/store"
+	"learnforge/internal/store/storetest"
+)
+
+// raft is a result generated with promptVersion and model.
+func raft(promptVersion, model string) domain.ProcessResponse {
+	return domain.ProcessResponse{
+		Topic:      "Raft",
+		Summary:    "Raft elects a leader.",
+		Flashcards: []domain.Flashcard{{ID: "f1", Q: "What is a term?", A: "An election period"}},
+		Quiz:       []domain.QuizItem{{ID: "q1", Q: "Who accepts writes?", Choices: []string{"Leader", "Follower"}, Answer: "Leader"}},
+		Meta:       domain.Meta{PromptVersion: promptVersion, Provider: "openai", Model: model},
+	}
+}
+
+func TestService_SubmitValidatesAndReplaces(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft"}, raft("v2", "gpt-4o"))
+	s := NewService(st, st)
+
+	for name, in := range map[string]Input{
+		"unknown rating":     {Kind: domain.FeedbackSummary, Rating: "meh"},
+		"unknown category":   {Kind: domain.FeedbackQuiz, ItemID: "q1", Rating: domain.FeedbackDown, Categories: []string{"boring"}},
+		"unknown kind":       {Kind: "meme", Rating: domain.FeedbackUp},
+		"summary with item":  {Kind: domain.FeedbackSummary, ItemID: "q1", Rating: domain.FeedbackUp},
+		"missing flashcard":  {Kind: domain.FeedbackFlashcard, ItemID: "f9", Rating: domain.FeedbackUp},
+		"quiz without an id": {Kind: domain.FeedbackQuiz, Rating: domain.FeedbackUp},
+	} {
+		if _, err := s.Submit(ctx, "raft", "ana", in, true); err == nil {
+			t.Errorf("%s: Submit succeeded, want an error", name)
+		}
+	}
+	if _, err := s.Submit(ctx, "raft", "", Input{Kind: domain.FeedbackSummary, Rating: domain.FeedbackUp}, true); err == nil {
+		t.Error("Submit without a learner succeeded, want an error")
+	}
+
+	f, err := s.Submit(ctx, "raft", "ana", Input{Kind: domain.FeedbackQuiz, ItemID: "q1", Rating: domain.FeedbackDown, Categories: []string{domain.FeedbackTypo, domain.FeedbackTypo}}, true)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if f.PromptVersion != "v2" || f.Model != "gpt-4o" || len(f.Categories) != 1 {
+		t.Errorf("feedback = %+v, want the result's prompt version and model and one category", f)
+	}
+	if _, err := s.Submit(ctx, "raft", "ana", Input{Kind: domain.FeedbackQuiz, ItemID: "q1", Rating: domain.FeedbackUp}, true); err != nil {
+		t.Fatalf("Submit again: %v", err)
+	}
+	if _, err := s.Submit(ctx, "raft", "ana", Input{Kind: domain.FeedbackSummary, Rating: domain.FeedbackUp}, true); err != nil {
+		t.Fatalf("Submit on the summary: %v", err)
+	}
+
+	items, err := s.ResultFeedback(ctx, "raft")
+	if err != nil {
+		t.Fatalf("ResultFeedback: %v", err)
+	}
+	if len(items) != 2 || items[0].Kind != domain.FeedbackSummary || items[1].ItemID != "q1" {
+		t.Fatalf("items = %+v, want the summary then q1", items)
+	}
+	if q1 := items[1]; q1.Up != 1 || q1.Down != 0 || len(q1.Categories) != 0 {
+		t.Errorf("q1 = %+v, want the second feedback to replace the first", q1)
+	}
+}
+
+func TestService_ReportGroupsByPromptVersionAndFlagsLowRated(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "old"}, raft("v1", "gpt-4o"))
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "new"}, raft("v2", "gpt-4o"))
+	s := NewService(st, st, WithMinDownvotes(2))
+
+	down := Input{Kind: domain.FeedbackQuiz, ItemID: "q1", Rating: domain.FeedbackDown, Categories: []string{domain.FeedbackWrongAnswer}}
+	for _, learner := range []string{"ana", "ben", "cy"} {
+		if _, err := s.Submit(ctx, "old", learner, down, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, in := range []Input{
+		{Kind: domain.FeedbackQuiz, ItemID: "q1", Rating: domain.FeedbackUp},
+		{Kind: domain.FeedbackFlashcard, ItemID: "f1", Rating: domain.FeedbackDown},
+	} {
+		if _, err := s.Submit(ctx, "new", "ana", in, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	report, err := s.Report(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if report.Totals.Up != 1 || report.Totals.Down != 4 || report.Totals.Categories[domain.FeedbackWrongAnswer] != 3 {
+		t.Errorf("totals = %+v, want 1 up, 4 down and 3 wrong answers", report.Totals)
+	}
+	if len(report.Groups) != 2 || report.Groups[0].PromptVersion != "v1" || report.Groups[0].Down != 3 {
+		t.Errorf("groups = %+v, want v1 with 3 down first", report.Groups)
+	}
+	if len(report.LowRated) != 1 || report.LowRated[0].ResultID != "old" || report.LowRated[0].ItemID != "q1" {
+		t.Errorf("low rated = %+v, want only q1 of the old result", report.LowRated)
+	}
+
+	lowRated, err := s.LowRated(ctx, []string{"old", "new"})
+	if err != nil {
+		t.Fatalf("LowRated: %v", err)
+	}
+	if len(lowRated) != 1 || !lowRated[Key("old", domain.FeedbackQuiz, "q1")] {
+		t.Errorf("LowRated = %v, want only old/quiz/q1", lowRated)
+	}
+}
+
+func TestService_SubmitRecordsExperimentOutcomes(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	storetest.SaveResult(t, st, domain.StoredResult{ID: "raft", Experiment: "model", Variant: "candidate"}, raft("v2", "gpt-4o"))
+	experiments := experiment.NewManager(st, []*experiment.Experiment{{
+		Name:     "model",
+		Variants: []*experiment.Variant{{Name: "candidate", Percent: 100}},
+	}})
+	s := NewService(st, st, WithExperiments(experiments))
+
+	for learner, rating := range map[string]string{"ana": domain.FeedbackUp, "ben": domain.FeedbackDown, "cy": domain.FeedbackUp, "dee": domain.FeedbackUp} {
+		if _, err := s.Submit(ctx, "raft", learner, Input{Kind: domain.FeedbackSummary, Rating: rating}, true); err != nil {
+			t.Fatalf("Submit: %v", err)
+		}
+	}
+
+	report, err := experiments.Report(ctx, "model")
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if v := report.Variants[0]; v.Outcomes != 4 || v.AvgFeedback != 0.75 {
+		t.Errorf("variant report = %+v, want 4 outcomes averaging 0.75", v)
+	}
+}
@@ -0,0 +1,183 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxDOCXPartBytes bounds the decompressed size of a single part so a
+// small zip bomb cannot exhaust memory.
+const maxDOCXPartBytes = 50 << 20
+
+// ExtractDOCX returns the text of a Word document. Paragraphs styled as
+// Title or Heading 1-6 become Markdown headings and numbered or bulleted
+// paragraphs become list items.
+func ExtractDOCX(data []byte) (*Document, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("not a valid DOCX archive: %w", err)
+	}
+
+	body, err := readZipPart(zr, "word/document.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	if core, err := readZipPart(zr, "docProps/core.xml"); err == nil {
+		doc.Title = docxTitle(core)
+	}
+
+	paragraphs, err := docxParagraphs(body)
+	if err != nil {
+		return nil, err
+	}
+	if doc.Title == "" {
+		for _, p := range paragraphs {
+			if strings.HasPrefix(p, "# ") {
+				doc.Title = strings.TrimPrefix(p, "# ")
+				break
+			}
+		}
+	}
+	doc.Text = strings.Join(paragraphs, "\n\n")
+	return doc, nil
+}
+
+func readZipPart(zr *zip.Reader, name string) ([]byte, error) {
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err := io.ReadAll(io.LimitReader(rc, maxDOCXPartBytes+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxDOCXPartBytes {
+			return nil, fmt.Errorf("%s is too large", name)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("%s not found", name)
+}
+
+func docxTitle(core []byte) string {
+	dec := xml.NewDecoder(bytes.NewReader(core))
+	inTitle := false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return ""
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			inTitle = t.Name.Local == "title"
+		case xml.CharData:
+			if inTitle {
+				return strings.TrimSpace(string(t))
+			}
+		case xml.EndElement:
+			inTitle = false
+		}
+	}
+}
+
+// docxParagraphs walks w:p elements, collecting run text (w:t), tabs and
+// breaks, and the paragraph's style and numbering properties.
+func docxParagraphs(body []byte) ([]string, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+
+	var paragraphs []string
+	var buf strings.Builder
+	var style string
+	var listItem, inText, inPara bool
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed document.xml: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				inPara = true
+				buf.Reset()
+				style = ""
+				listItem = false
+			case "pStyle":
+				style = attr(t.Attr, "val")
+			case "numPr":
+				listItem = true
+			case "t":
+				inText = true
+			case "tab":
+				if inPara {
+					buf.WriteByte('\t')
+				}
+			case "br", "cr":
+				if inPara {
+					buf.WriteByte('\n')
+				}
+			}
+		case xml.CharData:
+			if inText {
+				buf.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				inPara = false
+				text := strings.TrimSpace(buf.String())
+				if text == "" {
+					continue
+				}
+				if level := docxHeadingLevel(style); level > 0 {
+					text = strings.Repeat("#", level) + " " + collapse(text)
+				} else if listItem || strings.HasPrefix(strings.ToLower(style), "list") {
+					text = "- " + text
+				}
+				paragraphs = append(paragraphs, text)
+			}
+		}
+	}
+
+	return paragraphs, nil
+}
+
+// docxHeadingLevel maps built-in style IDs ("Title", "Heading1",
+// "heading 2") to a Markdown heading level, or 0 for body text.
+func docxHeadingLevel(style string) int {
+	s := strings.ToLower(strings.ReplaceAll(style, " ", ""))
+	if s == "title" {
+		return 1
+	}
+	if !strings.HasPrefix(s, "heading") {
+		return 0
+	}
+	level, err := strconv.Atoi(strings.TrimPrefix(s, "heading"))
+	if err != nil || level < 1 {
+		return 0
+	}
+	if level > 6 {
+		level = 6
+	}
+	return level
+}
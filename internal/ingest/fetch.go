@@ -0,0 +1,279 @@
+// Package ingest turns external sources (web pages, uploaded documents,
+// transcripts) into plain text for generation.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+// FetchOptions limits what the fetcher is allowed to download.
+type FetchOptions struct {
+	MaxBytes     int64
+	MaxRedirects int
+	Timeout      time.Duration
+	// AllowPrivateNetworks disables the SSRF address checks. Only for tests
+	// and trusted deployments.
+	AllowPrivateNetworks bool
+}
+
+// DefaultFetchOptions are used for zero-valued option fields.
+var DefaultFetchOptions = FetchOptions{
+	MaxBytes:     5 << 20,
+	MaxRedirects: 5,
+	Timeout:      15 * time.Second,
+}
+
+var allowedContentTypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+	"text/plain":            true,
+	"text/markdown":         true,
+}
+
+// Page is a fetched web page.
+type Page struct {
+	URL         string
+	ContentType string
+	Body        []byte
+	FetchedAt   time.Time
+}
+
+// Fetcher downloads web pages while guarding against SSRF.
+type Fetcher struct {
+	opts   FetchOptions
+	client *http.Client
+}
+
+func NewFetcher(opts FetchOptions) *Fetcher {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultFetchOptions.MaxBytes
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = DefaultFetchOptions.MaxRedirects
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultFetchOptions.Timeout
+	}
+	return &Fetcher{
+		opts:   opts,
+		client: NewHTTPClient(opts),
+	}
+}
+
+// NewHTTPClient returns an HTTP client that refuses to connect to private,
+// loopback, link-local and other internal addresses, re-checking every
+// redirect hop and the resolved IP at dial time.
+func NewHTTPClient(opts FetchOptions) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+	}
+	if !opts.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("%w: %s", errBlockedAddress, host)
+			}
+			return nil
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	maxRedirects := opts.MaxRedirects
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("%w: stopped after %d redirects", errTooManyRedirects, maxRedirects)
+			}
+			return checkURL(req.URL)
+		},
+	}
+}
+
+var (
+	errBlockedAddress   = errors.New("destination address is not allowed")
+	errTooManyRedirects = errors.New("too many redirects")
+	errBadURL           = errors.New("invalid URL")
+)
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), which
+// net.IP.IsPrivate does not cover.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		if ip4[0] == 0 || sharedAddressSpace.Contains(ip4) || ip4.Equal(net.IPv4bcast) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: scheme must be http or https", errBadURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%w: host is required", errBadURL)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: credentials in URL are not allowed", errBadURL)
+	}
+	return nil
+}
+
+// feedContentTypes are accepted by FetchFeed. Plain XML is common for
+// feeds served by static hosts.
+var feedContentTypes = map[string]bool{
+	"application/rss+xml":  true,
+	"application/atom+xml": true,
+	"application/rdf+xml":  true,
+	"application/xml":      true,
+	"text/xml":             true,
+}
+
+// Fetch downloads rawURL. Invalid or blocked destinations and unsupported
+// content are reported as invalid_argument; transport failures as
+// upstream_error.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Page, error) {
+	return f.fetch(ctx, rawURL, "text/html, application/xhtml+xml, text/plain;q=0.9, text/markdown;q=0.9", allowedContentTypes)
+}
+
+// FetchFeed downloads an RSS or Atom feed with the same protections as
+// Fetch.
+func (f *Fetcher) FetchFeed(ctx context.Context, rawURL string) (*Page, error) {
+	return f.fetch(ctx, rawURL, "application/rss+xml, application/atom+xml, application/xml;q=0.9, text/xml;q=0.9", feedContentTypes)
+}
+
+func (f *Fetcher) fetch(ctx context.Context, rawURL, accept string, allowed map[string]bool) (*Page, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "source_url is not a valid URL", err)
+	}
+	if err := checkURL(u); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, err.Error(), nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "source_url is not a valid URL", err)
+	}
+	req.Header.Set("User-Agent", "LearnForge/1.0 (+content ingestion)")
+	req.Header.Set("Accept", accept)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedAddress) || errors.Is(err, errTooManyRedirects) || errors.Is(err, errBadURL) {
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "source_url is not allowed", err)
+		}
+		return nil, domain.NewDomainError(domain.ErrorCodeUpstreamError, "failed to fetch source_url", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, domain.NewDomainError(domain.ErrorCodeUpstreamError, fmt.Sprintf("source_url returned status %d", resp.StatusCode), nil)
+	}
+
+	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !allowed[contentType] {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument,
+			fmt.Sprintf("unsupported content type %q", resp.Header.Get("Content-Type")), nil)
+	}
+
+	if resp.ContentLength > f.opts.MaxBytes {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "source_url content is too large", nil)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.opts.MaxBytes+1))
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeUpstreamError, "failed to read source_url", err)
+	}
+	if int64(len(body)) > f.opts.MaxBytes {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "source_url content is too large", nil)
+	}
+
+	return &Page{
+		URL:         resp.Request.URL.String(),
+		ContentType: contentType,
+		Body:        body,
+		FetchedAt:   time.Now().UTC(),
+	}, nil
+}
+
+// Document is extracted, generation-ready text.
+type Document struct {
+	Title        string
+	Text         string
+	CanonicalURL string
+}
+
+// FetchDocument fetches rawURL and extracts its readable content.
+func (f *Fetcher) FetchDocument(ctx context.Context, rawURL string) (*Document, *Page, error) {
+	page, err := f.Fetch(ctx, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var doc *Document
+	if page.ContentType == "text/html" || page.ContentType == "application/xhtml+xml" {
+		doc = ExtractHTML(page.Body)
+	} else {
+		doc = &Document{Text: strings.TrimSpace(string(page.Body))}
+	}
+
+	doc.CanonicalURL = resolveCanonical(page.URL, doc.CanonicalURL)
+	if strings.TrimSpace(doc.Text) == "" {
+		return nil, nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "no readable content found at source_url", nil)
+	}
+	return doc, page, nil
+}
+
+func resolveCanonical(pageURL, canonical string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return pageURL
+	}
+	if canonical == "" {
+		base.Fragment = ""
+		return base.String()
+	}
+	ref, err := url.Parse(canonical)
+	if err != nil {
+		return base.String()
+	}
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return base.String()
+	}
+	resolved.Fragment = ""
+	return resolved.String()
+}
@@ -0,0 +1,241 @@
+package ingest
+
+import (
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// rawBlocks removes elements whose contents are not markup (scripts,
+// styles) and comments before tokenizing, since they routinely contain
+// characters that trip up the lenient XML tokenizer.
+var rawBlocks = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>|<noscript\b.*?</noscript\s*>|<template\b.*?</template\s*>|<!--.*?-->`)
+
+// skippedElements never contain article content.
+var skippedElements = map[string]bool{
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+	"iframe": true, "svg": true, "button": true, "select": true, "object": true,
+	"embed": true, "canvas": true, "dialog": true, "menu": true,
+}
+
+var skippedRoles = map[string]bool{
+	"navigation": true, "banner": true, "complementary": true, "contentinfo": true,
+	"search": true, "menu": true, "dialog": true, "alert": true,
+}
+
+// noiseTokens in class or id attributes mark boilerplate such as ads,
+// sidebars, cookie banners and share widgets.
+var noiseTokens = map[string]bool{
+	"ad": true, "ads": true, "advert": true, "advertisement": true, "banner": true,
+	"sidebar": true, "cookie": true, "cookies": true, "promo": true, "social": true,
+	"share": true, "sharing": true, "comments": true, "comment": true, "newsletter": true,
+	"related": true, "popup": true, "modal": true, "subscribe": true, "breadcrumb": true,
+	"breadcrumbs": true, "menu": true, "nav": true, "navbar": true, "footer": true,
+	"sponsored": true, "outbrain": true, "taboola": true,
+}
+
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true,
+	"li": true, "ul": true, "ol": true, "dl": true, "dt": true, "dd": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "blockquote": true, "table": true, "tr": true, "figcaption": true,
+	"br": true, "hr": true, "body": true,
+}
+
+type frame struct {
+	name string
+	skip bool
+	main bool
+}
+
+type block struct {
+	text string
+	main bool
+}
+
+type extractor struct {
+	stack     []frame
+	blocks    []block
+	buf       strings.Builder
+	prefix    string
+	title     strings.Builder
+	canonical string
+}
+
+// ExtractHTML returns the readable article content of an HTML page. Script,
+// navigation and boilerplate elements are dropped, headings are kept as
+// Markdown headings and list items as bullets. When the page marks its
+// content with <article> or <main>, only that content is returned.
+func ExtractHTML(body []byte) *Document {
+	cleaned := rawBlocks.ReplaceAllString(string(body), " ")
+
+	d := xml.NewDecoder(strings.NewReader(cleaned))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	e := &extractor{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			// Best effort: keep whatever was extracted before malformed input.
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			e.start(t)
+		case xml.EndElement:
+			e.end(strings.ToLower(t.Name.Local))
+		case xml.CharData:
+			e.text(string(t))
+		}
+	}
+	e.flush()
+
+	useMain := false
+	for _, b := range e.blocks {
+		if b.main {
+			useMain = true
+			break
+		}
+	}
+
+	var parts []string
+	for _, b := range e.blocks {
+		if !useMain || b.main {
+			parts = append(parts, b.text)
+		}
+	}
+
+	title := collapse(e.title.String())
+	if title == "" {
+		for _, p := range parts {
+			if strings.HasPrefix(p, "# ") {
+				title = strings.TrimPrefix(p, "# ")
+				break
+			}
+		}
+	}
+
+	return &Document{
+		Title:        title,
+		Text:         strings.Join(parts, "\n\n"),
+		CanonicalURL: e.canonical,
+	}
+}
+
+func (e *extractor) top() frame {
+	if len(e.stack) == 0 {
+		return frame{}
+	}
+	return e.stack[len(e.stack)-1]
+}
+
+func (e *extractor) start(t xml.StartElement) {
+	name := strings.ToLower(t.Name.Local)
+	parent := e.top()
+
+	f := frame{
+		name: name,
+		skip: parent.skip || skippedElements[name] || isNoise(t.Attr),
+		main: parent.main || name == "article" || name == "main",
+	}
+
+	if name == "link" && attr(t.Attr, "rel") == "canonical" {
+		e.canonical = attr(t.Attr, "href")
+	}
+
+	if blockElements[name] {
+		e.flush()
+		if !f.skip {
+			switch name {
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				e.prefix = strings.Repeat("#", int(name[1]-'0')) + " "
+			case "li":
+				e.prefix = "- "
+			}
+		}
+	}
+
+	e.stack = append(e.stack, f)
+}
+
+func (e *extractor) end(name string) {
+	if blockElements[name] {
+		e.flush()
+	}
+	if len(e.stack) > 0 {
+		e.stack = e.stack[:len(e.stack)-1]
+	}
+}
+
+func (e *extractor) text(s string) {
+	f := e.top()
+	if f.name == "title" {
+		e.title.WriteString(s)
+		return
+	}
+	if f.skip || e.inHead() {
+		return
+	}
+	e.buf.WriteString(s)
+}
+
+func (e *extractor) inHead() bool {
+	for _, f := range e.stack {
+		if f.name == "head" {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *extractor) flush() {
+	text := collapse(e.buf.String())
+	e.buf.Reset()
+	prefix := e.prefix
+	e.prefix = ""
+	if text == "" {
+		return
+	}
+	e.blocks = append(e.blocks, block{
+		text: prefix + text,
+		main: e.top().main,
+	})
+}
+
+func isNoise(attrs []xml.Attr) bool {
+	if skippedRoles[strings.ToLower(attr(attrs, "role"))] {
+		return true
+	}
+	if strings.EqualFold(attr(attrs, "aria-hidden"), "true") {
+		return true
+	}
+	for _, name := range []string{"class", "id"} {
+		for _, token := range strings.FieldsFunc(strings.ToLower(attr(attrs, name)), func(r rune) bool {
+			return r == ' ' || r == '-' || r == '_'
+		}) {
+			if noiseTokens[token] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func attr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if strings.EqualFold(a.Name.Local, name) {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
+
+func collapse(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,939 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	// maxPDFDecodedBytes bounds the decompressed size of all streams of a
+	// document, so a small upload cannot inflate to gigabytes. Content
+	// streams spend a few bytes of operators on every character shown, so
+	// this allows several times the text a plain-text upload may hold.
+	maxPDFDecodedBytes = 8 * defaultTextLimit
+	// maxPDFNesting bounds how deeply arrays may nest in content streams
+	// and CMaps, and how deep the page tree is followed.
+	maxPDFNesting = 64
+)
+
+var (
+	errPDFTooLarge = errors.New("PDF decompresses to more than the supported size")
+	errPDFNesting  = errors.New("PDF nests arrays too deeply")
+)
+
+var (
+	pdfObjectHeader = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfRefPattern   = regexp.MustCompile(`(\d+)\s+\d+\s+R`)
+	pdfTypePage     = regexp.MustCompile(`/Type\s*/Page\b`)
+	pdfTypeCatalog  = regexp.MustCompile(`/Type\s*/Catalog\b`)
+	pdfTypeObjStm   = regexp.MustCompile(`/Type\s*/ObjStm\b`)
+	pdfFilter       = regexp.MustCompile(`/Filter\s*\[?\s*/(\w+)`)
+	pdfFontEntry    = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s+(\d+)\s+\d+\s+R`)
+)
+
+type pdfObject struct {
+	dict []byte // object body, up to the stream keyword for streams
+	raw  []byte // encoded stream data, nil when not a stream
+}
+
+type pdfFile struct {
+	objects map[int]*pdfObject
+	cmaps   map[int]*toUnicode
+	decoded int   // bytes decoded from streams so far
+	err     error // first error that makes the document unreadable
+}
+
+// stream decodes the object's stream on demand so images and embedded
+// fonts are never inflated. It returns nil for non-stream objects and
+// unsupported filters, and once the document's streams have decoded to
+// more than maxPDFDecodedBytes.
+func (f *pdfFile) stream(o *pdfObject) []byte {
+	if o == nil || o.raw == nil || f.err != nil {
+		return nil
+	}
+	out := decodeStream(o.dict, o.raw, maxPDFDecodedBytes-f.decoded+1)
+	f.decoded += len(out)
+	if f.decoded > maxPDFDecodedBytes {
+		f.err = errPDFTooLarge
+		return nil
+	}
+	return out
+}
+
+// ExtractPDF returns the text layer of a PDF. Pages are read in document
+// order; lines set in a noticeably larger font than the body text become
+// Markdown headings. Scanned PDFs without a text layer yield empty text.
+// Only Flate-compressed and uncompressed streams are supported.
+func ExtractPDF(data []byte) (*Document, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, errors.New("missing PDF header")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return nil, errors.New("encrypted PDFs are not supported")
+	}
+
+	f := &pdfFile{objects: parsePDFObjects(data), cmaps: map[int]*toUnicode{}}
+	f.expandObjectStreams()
+
+	var lines []pdfLine
+	for _, page := range f.pages() {
+		fonts := f.pageFonts(page.resources)
+		for _, ref := range refsFor(page.dict, "Contents") {
+			if content := f.stream(f.objects[ref]); content != nil {
+				pageLines, err := interpretContent(content, fonts)
+				if err != nil {
+					return nil, err
+				}
+				lines = append(lines, pageLines...)
+			}
+		}
+		lines = append(lines, pdfLine{pageBreak: true})
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	doc := &Document{Title: f.infoTitle(data)}
+	doc.Text, doc.Title = layoutPDFLines(lines, doc.Title)
+	return doc, nil
+}
+
+// parsePDFObjects scans for "N G obj" headers rather than trusting the
+// xref table, which is frequently broken in real-world files.
+func parsePDFObjects(data []byte) map[int]*pdfObject {
+	objects := map[int]*pdfObject{}
+	headers := pdfObjectHeader.FindAllSubmatchIndex(data, -1)
+	for i, h := range headers {
+		num, err := strconv.Atoi(string(data[h[2]:h[3]]))
+		if err != nil {
+			continue
+		}
+		end := len(data)
+		if i+1 < len(headers) {
+			end = headers[i+1][0]
+		}
+		body := data[h[1]:end]
+		if idx := bytes.Index(body, []byte("endobj")); idx >= 0 {
+			body = body[:idx]
+		}
+
+		obj := &pdfObject{dict: body}
+		if idx := bytes.Index(body, []byte("stream")); idx >= 0 && bytes.Contains(body[:idx], []byte("<<")) {
+			obj.dict = body[:idx]
+			raw := body[idx+len("stream"):]
+			raw = bytes.TrimPrefix(raw, []byte("\r"))
+			raw = bytes.TrimPrefix(raw, []byte("\n"))
+			if length, ok := directInt(obj.dict, "Length"); ok && length >= 0 && length <= len(raw) {
+				raw = raw[:length]
+			} else if e := bytes.Index(raw, []byte("endstream")); e >= 0 {
+				raw = bytes.TrimRight(raw[:e], "\r\n")
+			}
+			obj.raw = raw
+		}
+		objects[num] = obj
+	}
+	return objects
+}
+
+// decodeStream decodes raw, inflating at most limit bytes.
+func decodeStream(dict, raw []byte, limit int) []byte {
+	filter := pdfFilter.FindSubmatch(dict)
+	if filter == nil {
+		return raw
+	}
+	if string(filter[1]) != "FlateDecode" || bytes.Contains(dict, []byte("/DecodeParms")) {
+		return nil
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(io.LimitReader(zr, int64(limit)))
+	if err != nil && len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// expandObjectStreams unpacks objects stored inside compressed object
+// streams (PDF 1.5+), where font and page dictionaries often live.
+func (f *pdfFile) expandObjectStreams() {
+	for _, num := range f.sortedObjectNumbers() {
+		obj := f.objects[num]
+		if obj.raw == nil || !pdfTypeObjStm.Match(obj.dict) {
+			continue
+		}
+		data := f.stream(obj)
+		n, _ := directInt(obj.dict, "N")
+		first, _ := directInt(obj.dict, "First")
+		if first <= 0 || first > len(data) {
+			continue
+		}
+		header := strings.Fields(string(data[:first]))
+		type entry struct{ num, offset int }
+		var entries []entry
+		for i := 0; i+1 < len(header) && len(entries) < n; i += 2 {
+			num, err1 := strconv.Atoi(header[i])
+			offset, err2 := strconv.Atoi(header[i+1])
+			if err1 != nil || err2 != nil {
+				break
+			}
+			entries = append(entries, entry{num, first + offset})
+		}
+		for i, e := range entries {
+			end := len(data)
+			if i+1 < len(entries) {
+				end = entries[i+1].offset
+			}
+			if e.offset > end || end > len(data) {
+				continue
+			}
+			if _, exists := f.objects[e.num]; !exists {
+				f.objects[e.num] = &pdfObject{dict: data[e.offset:end]}
+			}
+		}
+	}
+}
+
+type pdfPage struct {
+	dict      []byte
+	resources []byte
+}
+
+// pages walks the page tree from the catalog, falling back to object
+// order when the tree cannot be followed.
+func (f *pdfFile) pages() []pdfPage {
+	var pages []pdfPage
+	visited := map[int]bool{}
+
+	var walk func(num int, inherited []byte, depth int)
+	walk = func(num int, inherited []byte, depth int) {
+		obj := f.objects[num]
+		if obj == nil || visited[num] || depth > maxPDFNesting {
+			return
+		}
+		visited[num] = true
+		resources := f.resolveDict(obj.dict, "Resources")
+		if resources == nil {
+			resources = inherited
+		}
+		if pdfTypePage.Match(obj.dict) {
+			pages = append(pages, pdfPage{dict: obj.dict, resources: resources})
+			return
+		}
+		for _, kid := range refsFor(obj.dict, "Kids") {
+			walk(kid, resources, depth+1)
+		}
+	}
+
+	for _, num := range f.sortedObjectNumbers() {
+		if pdfTypeCatalog.Match(f.objects[num].dict) {
+			if root, ok := refFor(f.objects[num].dict, "Pages"); ok {
+				walk(root, nil, 0)
+			}
+			break
+		}
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	for _, num := range f.sortedObjectNumbers() {
+		obj := f.objects[num]
+		if pdfTypePage.Match(obj.dict) {
+			pages = append(pages, pdfPage{dict: obj.dict, resources: f.resolveDict(obj.dict, "Resources")})
+		}
+	}
+	return pages
+}
+
+func (f *pdfFile) sortedObjectNumbers() []int {
+	nums := make([]int, 0, len(f.objects))
+	for num := range f.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// resolveDict returns the dictionary stored under key, following an
+// indirect reference if needed.
+func (f *pdfFile) resolveDict(dict []byte, key string) []byte {
+	if inline := inlineDict(dict, key); inline != nil {
+		return inline
+	}
+	if num, ok := refFor(dict, key); ok && f.objects[num] != nil {
+		return f.objects[num].dict
+	}
+	return nil
+}
+
+// pageFonts maps the page's font resource names to their ToUnicode maps.
+func (f *pdfFile) pageFonts(resources []byte) map[string]*toUnicode {
+	fonts := map[string]*toUnicode{}
+	fontDict := f.resolveDict(resources, "Font")
+	if fontDict == nil {
+		return fonts
+	}
+	entries := pdfFontEntry.FindAllSubmatch(fontDict, -1)
+	for _, m := range entries {
+		num, _ := strconv.Atoi(string(m[2]))
+		fonts[string(m[1])] = f.cmapForFont(num)
+	}
+	return fonts
+}
+
+func (f *pdfFile) cmapForFont(num int) *toUnicode {
+	if cmap, ok := f.cmaps[num]; ok {
+		return cmap
+	}
+	var cmap *toUnicode
+	if font := f.objects[num]; font != nil {
+		if ref, ok := refFor(font.dict, "ToUnicode"); ok {
+			if data := f.stream(f.objects[ref]); data != nil {
+				var err error
+				if cmap, err = parseToUnicode(data); err != nil && f.err == nil {
+					f.err = err
+				}
+			}
+		}
+	}
+	f.cmaps[num] = cmap
+	return cmap
+}
+
+// infoTitle reads /Title from the document information dictionary
+// referenced by the trailer.
+func (f *pdfFile) infoTitle(data []byte) string {
+	info, ok := refFor(data, "Info")
+	if !ok || f.objects[info] == nil {
+		return ""
+	}
+	dict := f.objects[info].dict
+	idx := bytes.Index(dict, []byte("/Title"))
+	if idx < 0 {
+		return ""
+	}
+	lex := &pdfLexer{data: dict[idx+len("/Title"):]}
+	if tok, ok := lex.next(); ok && tok.kind == tokString {
+		return strings.TrimSpace(decodePDFString(tok.str, nil))
+	}
+	return ""
+}
+
+func refFor(dict []byte, key string) (int, bool) {
+	m := regexp.MustCompile(`/` + key + `\s+(\d+)\s+\d+\s+R`).FindSubmatch(dict)
+	if m == nil {
+		return 0, false
+	}
+	num, err := strconv.Atoi(string(m[1]))
+	return num, err == nil
+}
+
+func refsFor(dict []byte, key string) []int {
+	if num, ok := refFor(dict, key); ok {
+		return []int{num}
+	}
+	m := regexp.MustCompile(`/` + key + `\s*\[([^\]]*)\]`).FindSubmatch(dict)
+	if m == nil {
+		return nil
+	}
+	var nums []int
+	for _, r := range pdfRefPattern.FindAllSubmatch(m[1], -1) {
+		num, _ := strconv.Atoi(string(r[1]))
+		nums = append(nums, num)
+	}
+	return nums
+}
+
+func directInt(dict []byte, key string) (int, bool) {
+	m := regexp.MustCompile(`/` + key + `\s+(\d+)\b(\s+\d+\s+R)?`).FindSubmatch(dict)
+	if m == nil || len(m[2]) > 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(m[1]))
+	return n, err == nil
+}
+
+// inlineDict returns the balanced "<< ... >>" value of key, or nil.
+func inlineDict(dict []byte, key string) []byte {
+	loc := regexp.MustCompile(`/` + key + `\s*<<`).FindIndex(dict)
+	if loc == nil {
+		return nil
+	}
+	start := loc[1] - 2
+	depth := 0
+	for i := start; i+1 < len(dict); i++ {
+		switch {
+		case dict[i] == '<' && dict[i+1] == '<':
+			depth++
+			i++
+		case dict[i] == '>' && dict[i+1] == '>':
+			depth--
+			i++
+			if depth == 0 {
+				return dict[start : i+1]
+			}
+		}
+	}
+	return nil
+}
+
+// toUnicode is a parsed ToUnicode CMap.
+type toUnicode struct {
+	width int
+	m     map[uint32]string
+}
+
+func parseToUnicode(data []byte) (*toUnicode, error) {
+	cmap := &toUnicode{width: 1, m: map[uint32]string{}}
+	lex := &pdfLexer{data: data}
+
+	var operands []pdfToken
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		if tok.kind != tokOperator {
+			operands = append(operands, tok)
+			continue
+		}
+		switch tok.op {
+		case "endcodespacerange":
+			if len(operands) > 0 && len(operands[0].str) > 0 {
+				cmap.width = len(operands[0].str)
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				cmap.m[codeOf(operands[i].str)] = utf16String(operands[i+1].str)
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, hi := codeOf(operands[i].str), codeOf(operands[i+1].str)
+				dst := operands[i+2]
+				if hi < lo || hi-lo > 0xFFFF {
+					continue
+				}
+				for code := lo; code <= hi; code++ {
+					offset := int(code - lo)
+					if dst.kind == tokArray {
+						if offset < len(dst.array) {
+							cmap.m[code] = utf16String(dst.array[offset].str)
+						}
+						continue
+					}
+					base := utf16String(dst.str)
+					if base == "" {
+						continue
+					}
+					runes := []rune(base)
+					runes[len(runes)-1] += rune(offset)
+					cmap.m[code] = string(runes)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	if lex.err != nil {
+		return nil, lex.err
+	}
+	return cmap, nil
+}
+
+func codeOf(b []byte) uint32 {
+	var code uint32
+	for _, c := range b {
+		code = code<<8 | uint32(c)
+	}
+	return code
+}
+
+func utf16String(b []byte) string {
+	if len(b)%2 != 0 {
+		return string(b)
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodePDFString maps string bytes to text using the font's ToUnicode
+// map, a UTF-16 byte order mark, or Latin-1 as a last resort.
+func decodePDFString(b []byte, cmap *toUnicode) string {
+	if cmap != nil && len(cmap.m) > 0 {
+		var sb strings.Builder
+		for i := 0; i+cmap.width <= len(b); i += cmap.width {
+			sb.WriteString(cmap.m[codeOf(b[i:i+cmap.width])])
+		}
+		return sb.String()
+	}
+	if bytes.HasPrefix(b, []byte{0xFE, 0xFF}) {
+		return utf16String(b[2:])
+	}
+	runes := make([]rune, 0, len(b))
+	for _, c := range b {
+		r := rune(c)
+		if unicode.IsPrint(r) || r == ' ' {
+			runes = append(runes, r)
+		}
+	}
+	return string(runes)
+}
+
+type pdfLine struct {
+	text      string
+	size      float64
+	gap       float64 // vertical distance from the previous line
+	pageBreak bool
+}
+
+// interpretContent runs the text operators of a page content stream and
+// returns the lines of text it draws.
+func interpretContent(data []byte, fonts map[string]*toUnicode) ([]pdfLine, error) {
+	var lines []pdfLine
+	var cur strings.Builder
+	var curSize, y, lineY, prevY float64
+	havePrev := false
+	fontSize, scale, leading := 12.0, 1.0, 0.0
+	var cmap *toUnicode
+
+	emit := func() {
+		if text := strings.TrimSpace(cur.String()); text != "" {
+			line := pdfLine{text: text, size: curSize}
+			if havePrev {
+				line.gap = math.Abs(prevY - lineY)
+			}
+			lines = append(lines, line)
+			prevY, havePrev = lineY, true
+		}
+		cur.Reset()
+		curSize = 0
+	}
+	// moveTo starts a new line when the baseline changes; moves along
+	// the same baseline separate words.
+	moveTo := func(newY float64) {
+		if math.Abs(newY-lineY) > 1 {
+			emit()
+			lineY = newY
+		} else if cur.Len() > 0 && !strings.HasSuffix(cur.String(), " ") {
+			cur.WriteByte(' ')
+		}
+		y = newY
+	}
+	show := func(b []byte) {
+		cur.WriteString(decodePDFString(b, cmap))
+		if size := fontSize * scale; size > curSize {
+			curSize = size
+		}
+	}
+
+	lex := &pdfLexer{data: data}
+	var operands []pdfToken
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		if tok.kind != tokOperator {
+			operands = append(operands, tok)
+			continue
+		}
+		nums := numbers(operands)
+		switch tok.op {
+		case "Tf":
+			if len(operands) >= 2 && operands[0].kind == tokName {
+				cmap = fonts[operands[0].op]
+			}
+			if len(nums) > 0 && nums[len(nums)-1] > 0 {
+				fontSize = nums[len(nums)-1]
+			}
+		case "TL":
+			if len(nums) > 0 {
+				leading = nums[0]
+			}
+		case "BT":
+			y, scale = 0, 1
+		case "Tm":
+			if len(nums) == 6 {
+				if s := math.Hypot(nums[2], nums[3]); s > 0 {
+					scale = s
+				}
+				moveTo(nums[5])
+			}
+		case "Td", "TD":
+			if len(nums) == 2 {
+				moveTo(y + nums[1]*scale)
+				if tok.op == "TD" {
+					leading = -nums[1]
+				}
+			}
+		case "T*":
+			moveTo(y - leadingOr(leading, fontSize)*scale)
+		case "Tj":
+			if s := lastString(operands); s != nil {
+				show(s)
+			}
+		case "'", "\"":
+			moveTo(y - leadingOr(leading, fontSize)*scale)
+			if s := lastString(operands); s != nil {
+				show(s)
+			}
+		case "TJ":
+			if len(operands) > 0 && operands[len(operands)-1].kind == tokArray {
+				for _, el := range operands[len(operands)-1].array {
+					switch el.kind {
+					case tokString:
+						show(el.str)
+					case tokNumber:
+						if el.num < -200 {
+							cur.WriteByte(' ')
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	if lex.err != nil {
+		return nil, lex.err
+	}
+	emit()
+	return lines, nil
+}
+
+func leadingOr(leading, fontSize float64) float64 {
+	if leading > 0 {
+		return leading
+	}
+	return fontSize * 1.2
+}
+
+func numbers(tokens []pdfToken) []float64 {
+	var nums []float64
+	for _, t := range tokens {
+		if t.kind == tokNumber {
+			nums = append(nums, t.num)
+		}
+	}
+	return nums
+}
+
+func lastString(tokens []pdfToken) []byte {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].kind == tokString {
+			return tokens[i].str
+		}
+	}
+	return nil
+}
+
+// layoutPDFLines joins lines into paragraphs and marks headings by font
+// size relative to the dominant body size.
+func layoutPDFLines(lines []pdfLine, title string) (string, string) {
+	weights := map[float64]int{}
+	for _, l := range lines {
+		weights[math.Round(l.size)] += len(l.text)
+	}
+	var body float64
+	best := -1
+	for size, w := range weights {
+		if w > best || (w == best && size < body) {
+			body, best = size, w
+		}
+	}
+
+	var blocks []string
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, joinPDFParagraph(para))
+			para = nil
+		}
+	}
+	for _, l := range lines {
+		if l.pageBreak {
+			flush()
+			continue
+		}
+		if body > 0 && l.size >= body*1.25 && len(l.text) <= 120 {
+			flush()
+			level := 3
+			switch {
+			case l.size >= body*1.8:
+				level = 1
+			case l.size >= body*1.4:
+				level = 2
+			}
+			heading := collapse(l.text)
+			blocks = append(blocks, strings.Repeat("#", level)+" "+heading)
+			if title == "" && level == 1 {
+				title = heading
+			}
+			continue
+		}
+		if l.gap > l.size*1.6 && l.size > 0 {
+			flush()
+		}
+		para = append(para, l.text)
+	}
+	flush()
+	return strings.Join(blocks, "\n\n"), title
+}
+
+func joinPDFParagraph(lines []string) string {
+	var sb strings.Builder
+	for i, l := range lines {
+		l = collapse(l)
+		if i > 0 {
+			prev := sb.String()
+			if strings.HasSuffix(prev, "-") && len(prev) > 1 && unicode.IsLetter(rune(prev[len(prev)-2])) {
+				sb.Reset()
+				sb.WriteString(strings.TrimSuffix(prev, "-"))
+			} else {
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(l)
+	}
+	return sb.String()
+}
+
+type pdfTokenKind int
+
+const (
+	tokNumber pdfTokenKind = iota
+	tokString
+	tokName
+	tokArray
+	tokDict
+	tokOperator
+)
+
+type pdfToken struct {
+	kind  pdfTokenKind
+	num   float64
+	str   []byte
+	op    string // operator or name
+	array []pdfToken
+}
+
+// pdfLexer tokenizes content streams and CMaps. It stops with err set
+// when arrays nest deeper than maxPDFNesting.
+type pdfLexer struct {
+	data  []byte
+	pos   int
+	depth int
+	err   error
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func (l *pdfLexer) next() (pdfToken, bool) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '(':
+			return pdfToken{kind: tokString, str: l.literal()}, true
+		case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+			l.pos += 2
+			l.skipDict()
+			return pdfToken{kind: tokDict}, true
+		case c == '<':
+			return pdfToken{kind: tokString, str: l.hex()}, true
+		case c == '[':
+			if l.depth >= maxPDFNesting {
+				l.err = errPDFNesting
+				l.pos = len(l.data)
+				return pdfToken{}, false
+			}
+			l.pos++
+			l.depth++
+			var arr []pdfToken
+			for {
+				tok, ok := l.next()
+				if !ok || (tok.kind == tokOperator && tok.op == "]") {
+					break
+				}
+				arr = append(arr, tok)
+			}
+			l.depth--
+			if l.err != nil {
+				return pdfToken{}, false
+			}
+			return pdfToken{kind: tokArray, array: arr}, true
+		case c == ']' || c == '>' || c == '{' || c == '}' || c == ')':
+			l.pos++
+			return pdfToken{kind: tokOperator, op: string(c)}, true
+		case c == '/':
+			l.pos++
+			return pdfToken{kind: tokName, op: l.word()}, true
+		default:
+			w := l.word()
+			if w == "" {
+				l.pos++
+				continue
+			}
+			if n, err := strconv.ParseFloat(w, 64); err == nil {
+				return pdfToken{kind: tokNumber, num: n}, true
+			}
+			if w == "BI" {
+				l.skipInlineImage()
+				continue
+			}
+			return pdfToken{kind: tokOperator, op: w}, true
+		}
+	}
+	return pdfToken{}, false
+}
+
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) literal() []byte {
+	l.pos++ // (
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (l *pdfLexer) hex() []byte {
+	l.pos++ // <
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		c := l.data[l.pos]
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++ // >
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	for i := range out {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		out[i] = byte(v)
+	}
+	return out
+}
+
+func (l *pdfLexer) skipDict() {
+	depth := 1
+	for l.pos+1 < len(l.data) && depth > 0 {
+		switch {
+		case l.data[l.pos] == '<' && l.data[l.pos+1] == '<':
+			depth++
+			l.pos += 2
+		case l.data[l.pos] == '>' && l.data[l.pos+1] == '>':
+			depth--
+			l.pos += 2
+		case l.data[l.pos] == '(':
+			l.literal()
+		default:
+			l.pos++
+		}
+	}
+}
+
+// skipInlineImage skips "BI ... ID <binary> EI", whose binary data would
+// otherwise be tokenized as text operators.
+func (l *pdfLexer) skipInlineImage() {
+	idx := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if idx < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += idx + 2
+	for l.pos+2 <= len(l.data) {
+		idx := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if idx < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		end := l.pos + idx
+		l.pos = end + 2
+		if end > 0 && isPDFSpace(l.data[end-1]) && (l.pos >= len(l.data) || isPDFSpace(l.data[l.pos])) {
+			return
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"learnforge/internal/textutil"
+)
+
+// Cue is one timed caption.
+type Cue struct {
+	Start   time.Duration
+	End     time.Duration
+	Speaker string
+	Text    string
+}
+
+// Transcript is a parsed SubRip or WebVTT subtitle file.
+type Transcript struct {
+	Cues []Cue
+}
+
+var (
+	cueTiming = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})\s*-->\s*((?:\d+:)?\d{1,2}:\d{2}[.,]\d{1,3})`)
+	cueTags   = regexp.MustCompile(`</?[^>]*>`)
+	vttVoice  = regexp.MustCompile(`^<v(?:\.[^\s>]*)?\s+([^>]+)>`)
+)
+
+// ParseTranscript parses SubRip (.srt) and WebVTT (.vtt) subtitles. Cue
+// numbers, settings, styling tags and NOTE/STYLE/REGION blocks are dropped;
+// WebVTT voice tags become the cue speaker.
+func ParseTranscript(data []byte) (*Transcript, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+
+	t := &Transcript{}
+	var cur *Cue
+	var lines []string
+	skipBlock := false
+
+	flush := func() {
+		if cur != nil {
+			cur.Text = collapse(strings.Join(lines, " "))
+			if cur.Text != "" {
+				t.Cues = append(t.Cues, *cur)
+			}
+		}
+		cur, lines, skipBlock = nil, nil, false
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			flush()
+			continue
+		}
+		if skipBlock {
+			continue
+		}
+		if cur == nil {
+			if m := cueTiming.FindStringSubmatch(line); m != nil {
+				start, err1 := parseCueTime(m[1])
+				end, err2 := parseCueTime(m[2])
+				if err1 != nil || err2 != nil {
+					return nil, fmt.Errorf("invalid cue timing %q", line)
+				}
+				cur = &Cue{Start: start, End: end}
+				continue
+			}
+			// Header lines, cue numbers and identifiers precede the timing.
+			if strings.HasPrefix(line, "NOTE") || line == "STYLE" || line == "REGION" {
+				skipBlock = true
+			}
+			continue
+		}
+		if m := vttVoice.FindStringSubmatch(line); m != nil && cur.Speaker == "" {
+			cur.Speaker = strings.TrimSpace(m[1])
+		}
+		lines = append(lines, cueTags.ReplaceAllString(line, ""))
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(t.Cues) == 0 {
+		return nil, fmt.Errorf("no cues found")
+	}
+	return t, nil
+}
+
+func parseCueTime(s string) (time.Duration, error) {
+	s = strings.Replace(s, ",", ".", 1)
+	parts := strings.Split(s, ":")
+	var total float64
+	for _, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return 0, err
+		}
+		total = total*60 + v
+	}
+	return time.Duration(total * float64(time.Second)), nil
+}
+
+// FormatTimestamp renders d as "m:ss", or "h:mm:ss" past the hour.
+func FormatTimestamp(d time.Duration) string {
+	total := int(d / time.Second)
+	h, m, s := total/3600, total%3600/60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// paragraphPause is the silence between cues that starts a new paragraph.
+const paragraphPause = 3 * time.Second
+
+// Text joins the cues into paragraphs, breaking on long pauses and speaker
+// changes. Lines repeated by rolling captions are emitted once.
+func (t *Transcript) Text() string {
+	var paragraphs []string
+	var para []string
+	var last string
+	flush := func() {
+		if len(para) > 0 {
+			paragraphs = append(paragraphs, strings.Join(para, " "))
+			para = nil
+		}
+	}
+	for i, c := range t.Cues {
+		if i > 0 {
+			prev := t.Cues[i-1]
+			if c.Start-prev.End >= paragraphPause || (c.Speaker != "" && c.Speaker != prev.Speaker) {
+				flush()
+			}
+		}
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, last))
+		if text == "" || c.Text == last {
+			continue
+		}
+		if len(para) == 0 && c.Speaker != "" {
+			text = c.Speaker + ": " + text
+		}
+		para = append(para, text)
+		last = c.Text
+	}
+	flush()
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// locateWindow is the number of consecutive cues matched at once, since a
+// generated item usually paraphrases several short captions.
+const locateWindow = 3
+
+// minLocateCoverage is the share of an item's content words that must
+// appear in a cue window for the item to be linked to it.
+const minLocateCoverage = 0.35
+
+// Locate returns the start of the cue window that best covers text, or
+// false when no window covers enough of it.
+func (t *Transcript) Locate(text string) (time.Duration, bool) {
+	if len(textutil.ContentWords(text)) == 0 {
+		return 0, false
+	}
+	best, bestScore := -1, minLocateCoverage
+	for i := range t.Cues {
+		var window []string
+		for _, c := range t.Cues[i:min(i+locateWindow, len(t.Cues))] {
+			window = append(window, c.Text)
+		}
+		score := textutil.Coverage(text, textutil.WordSet(strings.Join(window, " ")))
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	// Windows overlap, so the best one may start a cue or two before the
+	// discussion; link to the first cue that shares a word with text.
+	words := textutil.WordSet(text)
+	for _, c := range t.Cues[best:min(best+locateWindow, len(t.Cues))] {
+		for _, w := range textutil.ContentWords(c.Text) {
+			if words[w] {
+				return c.Start, true
+			}
+		}
+	}
+	return t.Cues[best].Start, true
+}
@@ -0,0 +1,254 @@
+package ingest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"learnforge/internal/domain"
+)
+
+// Format is a supported upload document format.
+type Format string
+
+const (
+	FormatPDF      Format = "pdf"
+	FormatDOCX     Format = "docx"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatText     Format = "text"
+	FormatSRT      Format = "srt"
+	FormatVTT      Format = "vtt"
+)
+
+// UploadLimits caps the upload size in bytes per format.
+type UploadLimits map[Format]int64
+
+// defaultTextLimit is the default upload limit of text formats.
+const defaultTextLimit = 2 << 20
+
+// DefaultUploadLimits are applied to formats missing from configured limits.
+var DefaultUploadLimits = UploadLimits{
+	FormatPDF:      20 << 20,
+	FormatDOCX:     10 << 20,
+	FormatHTML:     5 << 20,
+	FormatMarkdown: defaultTextLimit,
+	FormatText:     defaultTextLimit,
+	FormatSRT:      defaultTextLimit,
+	FormatVTT:      defaultTextLimit,
+}
+
+var extensionFormats = map[string]Format{
+	".pdf":      FormatPDF,
+	".docx":     FormatDOCX,
+	".md":       FormatMarkdown,
+	".markdown": FormatMarkdown,
+	".html":     FormatHTML,
+	".htm":      FormatHTML,
+	".xhtml":    FormatHTML,
+	".txt":      FormatText,
+	".text":     FormatText,
+	".srt":      FormatSRT,
+	".vtt":      FormatVTT,
+}
+
+var mimeFormats = map[string]Format{
+	"application/pdf": FormatPDF,
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": FormatDOCX,
+	"text/markdown":         FormatMarkdown,
+	"text/x-markdown":       FormatMarkdown,
+	"text/html":             FormatHTML,
+	"application/xhtml+xml": FormatHTML,
+	"text/plain":            FormatText,
+	"application/x-subrip":  FormatSRT,
+	"text/vtt":              FormatVTT,
+}
+
+// Upload is a file submitted for processing.
+type Upload struct {
+	FileName string
+	MimeType string // as declared by the client, may be empty
+	Data     []byte
+}
+
+// ExtractedFile is an upload converted to generation-ready text.
+type ExtractedFile struct {
+	Document
+	Format   Format
+	MimeType string
+	SHA256   string
+
+	// Transcript keeps the cue timings of subtitle uploads so generated
+	// items can link back to the recording.
+	Transcript *Transcript
+}
+
+// Extractor converts uploaded documents to text.
+type Extractor struct {
+	limits UploadLimits
+}
+
+// NewExtractor returns an Extractor enforcing limits, falling back to
+// DefaultUploadLimits for formats without a configured limit.
+func NewExtractor(limits UploadLimits) *Extractor {
+	merged := UploadLimits{}
+	for format, max := range DefaultUploadLimits {
+		merged[format] = max
+	}
+	for format, max := range limits {
+		if max > 0 {
+			merged[format] = max
+		}
+	}
+	return &Extractor{limits: merged}
+}
+
+// MaxBytes returns the largest per-format limit, which bounds the size of
+// any acceptable upload.
+func (e *Extractor) MaxBytes() int64 {
+	var max int64
+	for _, limit := range e.limits {
+		if limit > max {
+			max = limit
+		}
+	}
+	return max
+}
+
+// Extract detects the upload's format and extracts its text. Unsupported,
+// oversized or unreadable files are reported as invalid_argument.
+func (e *Extractor) Extract(upload *Upload) (*ExtractedFile, error) {
+	if len(upload.Data) == 0 {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "uploaded file is empty", nil)
+	}
+
+	format, mimeType, ok := detectFormat(upload)
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument,
+			"unsupported file type: upload PDF, DOCX, Markdown, HTML, plain text or SRT/VTT subtitles", nil)
+	}
+	if limit := e.limits[format]; int64(len(upload.Data)) > limit {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument,
+			fmt.Sprintf("%s files are limited to %d bytes", format, limit), nil)
+	}
+
+	var doc *Document
+	var transcript *Transcript
+	var err error
+	switch format {
+	case FormatPDF:
+		doc, err = ExtractPDF(upload.Data)
+	case FormatDOCX:
+		doc, err = ExtractDOCX(upload.Data)
+	case FormatHTML:
+		doc = ExtractHTML(upload.Data)
+	case FormatSRT, FormatVTT:
+		transcript, err = ParseTranscript(upload.Data)
+		if err == nil {
+			doc = &Document{Text: transcript.Text()}
+		}
+	default:
+		if !utf8.Valid(upload.Data) {
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "text files must be UTF-8 encoded", nil)
+		}
+		doc = &Document{Text: strings.TrimSpace(strings.ReplaceAll(string(upload.Data), "\r\n", "\n"))}
+	}
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument,
+			fmt.Sprintf("failed to read %s file", format), err)
+	}
+	if strings.TrimSpace(doc.Text) == "" {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "no extractable text found in uploaded file", nil)
+	}
+	doc.CanonicalURL = ""
+
+	hash := sha256.Sum256(upload.Data)
+	return &ExtractedFile{
+		Document: *doc,
+		Format:   format,
+		MimeType: mimeType,
+		SHA256:   hex.EncodeToString(hash[:]),
+
+		Transcript: transcript,
+	}, nil
+}
+
+// detectFormat trusts the file content over the declared MIME type and
+// extension where the content is recognizable, so a renamed binary cannot
+// be processed as text.
+func detectFormat(upload *Upload) (Format, string, bool) {
+	data := upload.Data
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return FormatPDF, "application/pdf", true
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		if extensionFormats[strings.ToLower(filepath.Ext(upload.FileName))] == FormatDOCX ||
+			declaredFormat(upload.MimeType) == FormatDOCX {
+			return FormatDOCX, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", true
+		}
+		return "", "", false
+	}
+
+	sniffed := http.DetectContentType(data)
+	if !strings.HasPrefix(sniffed, "text/") {
+		return "", "", false
+	}
+
+	if bytes.HasPrefix(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), []byte("WEBVTT")) {
+		return FormatVTT, "text/vtt", true
+	}
+	if looksLikeSRT(data) {
+		return FormatSRT, "application/x-subrip", true
+	}
+
+	format, ok := extensionFormats[strings.ToLower(filepath.Ext(upload.FileName))]
+	if !ok {
+		format = declaredFormat(upload.MimeType)
+	}
+	if format == "" || format == FormatPDF || format == FormatDOCX {
+		if strings.HasPrefix(sniffed, "text/html") {
+			format = FormatHTML
+		} else {
+			format = FormatText
+		}
+	}
+
+	switch format {
+	case FormatHTML:
+		return format, "text/html", true
+	case FormatMarkdown:
+		return format, "text/markdown", true
+	case FormatSRT:
+		return format, "application/x-subrip", true
+	case FormatVTT:
+		return format, "text/vtt", true
+	default:
+		return format, "text/plain", true
+	}
+}
+
+// looksLikeSRT reports whether a cue timing line appears among the first
+// few lines, as in "1\n00:00:01,000 --> 00:00:04,000".
+func looksLikeSRT(data []byte) bool {
+	lines := strings.SplitN(string(data[:min(len(data), 512)]), "\n", 5)
+	for _, line := range lines {
+		if cueTiming.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func declaredFormat(mimeType string) Format {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return ""
+	}
+	return mimeFormats[mediaType]
+}
@@ -0,0 +1,216 @@
+package ingest
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"learnforge/internal/domain"
+)
+
+// buildPDF assembles a minimal PDF from object bodies numbered from 1.
+// Object 1 must be the catalog. No xref table is written; the extractor
+// does not rely on one.
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	for i, obj := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Root 1 0 R /Info %d 0 R >>\n%%%%EOF\n", len(objects))
+	return buf.Bytes()
+}
+
+func flateStream(content string) string {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write([]byte(content))
+	zw.Close()
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", buf.Len(), buf.String())
+}
+
+func TestExtractPDF(t *testing.T) {
+	content := `BT /F1 24 Tf 72 720 Td (Photosynthesis) Tj ET
+BT /F1 12 Tf 72 690 Td (Plants convert light into chem-) Tj 0 -14 Td (ical energy.) Tj ET
+BT /F1 12 Tf 72 640 Td [(Chlorophyll)-400(absorbs light.)] TJ ET
+BT /F2 12 Tf 72 600 Td <01020301> Tj ET`
+	cmap := `/CIDInit /ProcSet findresource begin
+1 begincodespacerange <00> <FF> endcodespacerange
+2 beginbfchar <01> <0048> <02> <0069> endbfchar
+1 beginbfrange <03> <03> <0021> endbfrange
+endcmap`
+
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		flateStream(content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Font /Subtype /Type0 /ToUnicode 7 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(cmap), cmap),
+		"<< /Title (Plant Biology) /Producer (test) >>",
+	)
+
+	doc, err := ExtractPDF(pdf)
+	if err != nil {
+		t.Fatalf("ExtractPDF: %v", err)
+	}
+	want := "# Photosynthesis\n\nPlants convert light into chemical energy.\n\nChlorophyll absorbs light.\n\nHi!H"
+	if doc.Text != want {
+		t.Errorf("text = %q, want %q", doc.Text, want)
+	}
+	if doc.Title != "Plant Biology" {
+		t.Errorf("title = %q", doc.Title)
+	}
+}
+
+func TestExtractPDF_RejectsHostileInput(t *testing.T) {
+	nested := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		flateStream(strings.Repeat("[", 1<<20)),
+		"<< >>",
+	)
+	if _, err := ExtractPDF(nested); err != errPDFNesting {
+		t.Errorf("ExtractPDF of deeply nested arrays: err = %v, want %v", err, errPDFNesting)
+	}
+
+	// A single stream of zeros just past the budget compresses to a few
+	// kilobytes.
+	bomb := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		flateStream(string(make([]byte, maxPDFDecodedBytes+1))),
+		"<< >>",
+	)
+	if len(bomb) > 64<<10 {
+		t.Fatalf("test PDF is %d bytes, want a highly compressed stream", len(bomb))
+	}
+	if _, err := ExtractPDF(bomb); err != errPDFTooLarge {
+		t.Errorf("ExtractPDF of a stream inflating past the document budget: err = %v, want %v", err, errPDFTooLarge)
+	}
+
+	// Every page shows the same stream, which fits the budget once but
+	// not five times.
+	pages := 5
+	var kids []string
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", ""}
+	for i := 0; i < pages; i++ {
+		kids = append(kids, fmt.Sprintf("%d 0 R", i+3))
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>", pages+3))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages)
+	objects = append(objects, flateStream(string(make([]byte, maxPDFDecodedBytes/4))), "<< >>")
+	if _, err := ExtractPDF(buildPDF(objects...)); err != errPDFTooLarge {
+		t.Errorf("ExtractPDF of streams inflating past the document budget: err = %v, want %v", err, errPDFTooLarge)
+	}
+}
+
+func TestExtractDOCX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("word/document.xml")
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Cell Biology</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Cells are the </w:t></w:r><w:r><w:t>basic unit of life.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>Nucleus</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Organelles</w:t></w:r></w:p>
+<w:p></w:p>
+</w:body></w:document>`))
+	zw.Close()
+
+	doc, err := ExtractDOCX(buf.Bytes())
+	if err != nil {
+		t.Fatalf("ExtractDOCX: %v", err)
+	}
+	want := "# Cell Biology\n\nCells are the basic unit of life.\n\n- Nucleus\n\n## Organelles"
+	if doc.Text != want {
+		t.Errorf("text = %q, want %q", doc.Text, want)
+	}
+	if doc.Title != "Cell Biology" {
+		t.Errorf("title = %q", doc.Title)
+	}
+}
+
+func TestExtractor_Extract(t *testing.T) {
+	e := NewExtractor(UploadLimits{FormatMarkdown: 64})
+
+	file, err := e.Extract(&Upload{FileName: "notes.md", Data: []byte("# Notes\r\n\r\nMitochondria.")})
+	if err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	if file.Format != FormatMarkdown || file.MimeType != "text/markdown" {
+		t.Errorf("format = %s, mime = %s", file.Format, file.MimeType)
+	}
+	if file.Text != "# Notes\n\nMitochondria." {
+		t.Errorf("text = %q", file.Text)
+	}
+	if len(file.SHA256) != 64 {
+		t.Errorf("sha256 = %q", file.SHA256)
+	}
+
+	file, err = e.Extract(&Upload{FileName: "page", MimeType: "application/octet-stream", Data: []byte("<html><body><h1>Title</h1><p>Body</p></body></html>")})
+	if err != nil {
+		t.Fatalf("Extract html: %v", err)
+	}
+	if file.Format != FormatHTML {
+		t.Errorf("sniffed format = %s, want html", file.Format)
+	}
+
+	_, err = e.Extract(&Upload{FileName: "big.md", Data: []byte(strings.Repeat("a", 65))})
+	assertCode(t, err, domain.ErrorCodeInvalidArgument)
+
+	_, err = e.Extract(&Upload{FileName: "fake.txt", Data: []byte{0x00, 0x01, 0x02, 0xff, 0xfe}})
+	assertCode(t, err, domain.ErrorCodeInvalidArgument)
+
+	_, err = e.Extract(&Upload{FileName: "archive.zip", Data: []byte("PK\x03\x04rest")})
+	assertCode(t, err, domain.ErrorCodeInvalidArgument)
+}
+
+func TestParseTranscript(t *testing.T) {
+	srt := "1\r\n00:00:01,000 --> 00:00:04,000\r\nWelcome to the talk on <i>consensus</i>.\r\n\r\n" +
+		"2\r\n00:00:04,500 --> 00:00:08,000\r\nRaft elects a leader\r\nusing randomized timeouts.\r\n\r\n" +
+		"3\r\n00:12:34,000 --> 00:12:40,000\r\nLog replication sends entries to followers.\r\n"
+	vtt := "WEBVTT\n\nNOTE recorded live\nsecond line\n\n" +
+		"intro\n00:01.000 --> 00:04.000 align:start\n<v Alice>Welcome to the talk on consensus.</v>\n\n" +
+		"00:04.500 --> 00:08.000\n<v Alice>Raft elects a leader using randomized timeouts.\n\n" +
+		"12:34.000 --> 12:40.000\n<v Bob>Log replication sends entries to followers.\n"
+
+	for name, data := range map[string]string{"srt": srt, "vtt": vtt} {
+		tr, err := ParseTranscript([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: ParseTranscript: %v", name, err)
+		}
+		if len(tr.Cues) != 3 {
+			t.Fatalf("%s: got %d cues, want 3", name, len(tr.Cues))
+		}
+		if tr.Cues[1].Text != "Raft elects a leader using randomized timeouts." {
+			t.Errorf("%s: cue text = %q", name, tr.Cues[1].Text)
+		}
+
+		at, ok := tr.Locate("Followers receive log entries through replication")
+		if !ok || FormatTimestamp(at) != "12:34" {
+			t.Errorf("%s: Locate = %v %v, want 12:34", name, at, ok)
+		}
+		if _, ok := tr.Locate("Photosynthesis in plants"); ok {
+			t.Errorf("%s: unrelated text should not be located", name)
+		}
+	}
+
+	tr, _ := ParseTranscript([]byte(vtt))
+	want := "Alice: Welcome to the talk on consensus. Raft elects a leader using randomized timeouts.\n\nBob: Log replication sends entries to followers."
+	if got := tr.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+
+	file, err := NewExtractor(nil).Extract(&Upload{FileName: "talk.txt", Data: []byte(srt)})
+	if err != nil || file.Format != FormatSRT || file.Transcript == nil {
+		t.Errorf("SRT content should be detected regardless of extension: %v", err)
+	}
+}
@@ -0,0 +1,437 @@
+// Package jobs processes requests in the background. Jobs live in a
+// durable queue; workers lease them for a visibility timeout, so a job
+// whose worker dies is picked up again once the lease expires.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/service"
+	"learnforge/internal/store"
+	"learnforge/internal/webhook"
+
+	"github.com/google/uuid"
+)
+
+// Processor generates a result; *service.Service satisfies it.
+type Processor interface {
+	ValidateRequest(req *domain.ProcessRequest) error
+	ProcessText(ctx context.Context, req *domain.ProcessRequest) (*domain.ProcessResponse, error)
+}
+
+// Config tunes the worker pool. Zero values fall back to the defaults.
+type Config struct {
+	Workers     int
+	MaxAttempts int
+	// VisibilityTimeout is how long a claimed job stays invisible to
+	// other workers without a heartbeat.
+	VisibilityTimeout time.Duration
+	// JobTimeout bounds a single attempt, including the model call.
+	JobTimeout   time.Duration
+	PollInterval time.Duration
+	// RetryBackoff is the delay before the first retry; it doubles with
+	// every further attempt up to maxBackoff.
+	RetryBackoff time.Duration
+	// MaxBatchItems caps the number of requests in one batch.
+	MaxBatchItems int
+}
+
+const (
+	DefaultWorkers           = 4
+	DefaultMaxAttempts       = 3
+	DefaultVisibilityTimeout = 2 * time.Minute
+	DefaultJobTimeout        = 5 * time.Minute
+	DefaultMaxBatchItems     = 1000
+
+	defaultPollInterval = time.Second
+	defaultRetryBackoff = 5 * time.Second
+	maxBackoff          = 10 * time.Minute
+)
+
+// Pool runs queued jobs on a fixed number of workers.
+type Pool struct {
+	processor Processor
+	store     store.JobStore
+	cfg       Config
+	webhooks  *webhook.Dispatcher
+	now       func() time.Time
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// Option configures optional Pool dependencies.
+type Option func(*Pool)
+
+// WithWebhooks sends result.failed events for jobs that fail permanently
+// or run out of attempts.
+func WithWebhooks(d *webhook.Dispatcher) Option {
+	return func(p *Pool) {
+		p.webhooks = d
+	}
+}
+
+// NewPool returns a Pool. Call Start to begin processing.
+func NewPool(processor Processor, store store.JobStore, cfg Config, opts ...Option) *Pool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.VisibilityTimeout <= 0 {
+		cfg.VisibilityTimeout = DefaultVisibilityTimeout
+	}
+	if cfg.JobTimeout <= 0 {
+		cfg.JobTimeout = DefaultJobTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = defaultRetryBackoff
+	}
+	if cfg.MaxBatchItems <= 0 {
+		cfg.MaxBatchItems = DefaultMaxBatchItems
+	}
+	p := &Pool{
+		processor: processor,
+		store:     store,
+		cfg:       cfg,
+		now:       time.Now,
+		wake:      make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Enqueue validates req and queues it. Invalid requests are rejected
+// here rather than failing later in a worker.
+func (p *Pool) Enqueue(ctx context.Context, req *domain.ProcessRequest) (*domain.Job, error) {
+	if err := p.processor.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+
+	job := p.newJob(req, p.now().UTC())
+	if err := p.store.EnqueueJob(ctx, job); err != nil {
+		return nil, err
+	}
+	p.notify()
+	return job, nil
+}
+
+// EnqueueBatch validates every request and queues them together as one
+// batch. A single invalid request rejects the whole batch.
+func (p *Pool) EnqueueBatch(ctx context.Context, reqs []*domain.ProcessRequest) (*domain.Batch, error) {
+	if len(reqs) == 0 {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "requests must not be empty", nil)
+	}
+	if len(reqs) > p.cfg.MaxBatchItems {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument,
+			fmt.Sprintf("a batch may contain at most %d requests", p.cfg.MaxBatchItems), nil)
+	}
+	for i, req := range reqs {
+		if err := p.processor.ValidateRequest(req); err != nil {
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, fmt.Sprintf("requests[%d]: %s", i, domain.Message(err)), err)
+		}
+	}
+
+	now := p.now().UTC()
+	batch := &domain.Batch{
+		ID:        uuid.New().String(),
+		Status:    domain.BatchRunning,
+		Total:     len(reqs),
+		CreatedAt: now,
+	}
+	jobs := make([]*domain.Job, len(reqs))
+	for i, req := range reqs {
+		jobs[i] = p.newJob(req, now)
+		jobs[i].BatchID = batch.ID
+		jobs[i].BatchIndex = i
+	}
+	if err := p.store.CreateBatch(ctx, batch, jobs); err != nil {
+		return nil, err
+	}
+	p.notify()
+
+	batch.Counts = map[domain.JobStatus]int{domain.JobQueued: len(jobs)}
+	return batch, nil
+}
+
+func (p *Pool) newJob(req *domain.ProcessRequest, now time.Time) *domain.Job {
+	return &domain.Job{
+		ID:          uuid.New().String(),
+		Status:      domain.JobQueued,
+		Request:     *req,
+		UserID:      req.UserID,
+		APIKeyHash:  req.KeyHash(),
+		MaxAttempts: p.cfg.MaxAttempts,
+		AvailableAt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// notify wakes an idle worker.
+func (p *Pool) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Pool) Get(ctx context.Context, id string) (*domain.Job, error) {
+	return p.store.GetJob(ctx, id)
+}
+
+// Cancel stops a queued job immediately. A running job is canceled at
+// its worker's next heartbeat.
+func (p *Pool) Cancel(ctx context.Context, id string) (*domain.Job, error) {
+	return p.store.CancelJob(ctx, id)
+}
+
+// GetBatch returns a batch with its item counts. The batch is completed
+// once no item is queued or running.
+func (p *Pool) GetBatch(ctx context.Context, id string) (*domain.Batch, error) {
+	batch, err := p.store.GetBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	batch.Status = domain.BatchCompleted
+	if batch.Counts[domain.JobQueued]+batch.Counts[domain.JobRunning] > 0 {
+		batch.Status = domain.BatchRunning
+	}
+	return batch, nil
+}
+
+// BatchJobs returns a page of a batch's jobs in submission order.
+func (p *Pool) BatchJobs(ctx context.Context, batchID string, offset, limit int) ([]*domain.Job, error) {
+	if _, err := p.store.GetBatch(ctx, batchID); err != nil {
+		return nil, err
+	}
+	return p.store.ListBatchJobs(ctx, batchID, offset, limit)
+}
+
+// CancelBatch cancels every unfinished item of a batch. Finished items
+// keep their results.
+func (p *Pool) CancelBatch(ctx context.Context, id string) (*domain.Batch, error) {
+	const page = 500
+	for offset := 0; ; offset += page {
+		jobs, err := p.BatchJobs(ctx, id, offset, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			if job.Status.Terminal() {
+				continue
+			}
+			if _, err := p.store.CancelJob(ctx, job.ID); err != nil && !domain.HasCode(err, domain.ErrorCodeConflict) {
+				return nil, err
+			}
+		}
+		if len(jobs) < page {
+			break
+		}
+	}
+	return p.GetBatch(ctx, id)
+}
+
+// Start launches the workers.
+func (p *Pool) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	for i := 0; i < p.cfg.Workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.work(ctx)
+		}()
+	}
+	log.Printf(`{"level":"info","msg":"Job workers started","workers":%d}`, p.cfg.Workers)
+}
+
+// Stop interrupts running jobs and waits for the workers to exit.
+// Interrupted jobs are queued again without using up an attempt.
+func (p *Pool) Stop() {
+	if p.cancel == nil {
+		return
+	}
+	p.cancel()
+	p.wg.Wait()
+	log.Println(`{"level":"info","msg":"Job workers stopped"}`)
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := p.store.ClaimJob(ctx, uuid.New().String(), p.now().Add(p.cfg.VisibilityTimeout))
+		if err != nil && ctx.Err() == nil {
+			log.Printf(`{"level":"error","msg":"Failed to claim job","error":"%v"}`, err)
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-p.wake:
+			case <-time.After(p.cfg.PollInterval):
+			}
+			continue
+		}
+		p.run(ctx, job)
+	}
+}
+
+// run processes one claimed job and records the outcome.
+func (p *Pool) run(workerCtx context.Context, job *domain.Job) {
+	if job.CancelRequested {
+		p.finish(job, domain.JobCanceled, "canceled by request", "")
+		return
+	}
+	if job.Attempts > job.MaxAttempts {
+		// The lease of the last attempt expired, so its worker died.
+		p.finish(job, domain.JobDead, "", "")
+		p.notifyFailure(job, errors.New("job worker stopped responding"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(workerCtx, p.cfg.JobTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	progress := ""
+	ctx = service.WithProgress(ctx, func(stage string) {
+		mu.Lock()
+		progress = stage
+		mu.Unlock()
+	})
+	ctx = service.WithGenerationTimeout(ctx, p.cfg.JobTimeout)
+	ctx = service.WithJobID(ctx, job.ID)
+
+	canceled := make(chan struct{})
+	stopHeartbeat := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(p.cfg.VisibilityTimeout / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-ticker.C:
+			}
+			mu.Lock()
+			stage := progress
+			mu.Unlock()
+			current, err := p.store.HeartbeatJob(context.Background(), job.ID, job.LeaseID, stage, p.now().Add(p.cfg.VisibilityTimeout))
+			if err == nil && !current.CancelRequested {
+				continue
+			}
+			if err != nil {
+				log.Printf(`{"level":"warn","msg":"Lost job lease","job_id":"%s","error":"%v"}`, job.ID, err)
+			} else {
+				close(canceled)
+			}
+			cancel()
+			return
+		}
+	}()
+
+	req := job.Request
+	req.UserID, req.APIKeyHash = job.UserID, job.APIKeyHash
+	resp, err := p.processor.ProcessText(ctx, &req)
+	close(stopHeartbeat)
+	<-heartbeatDone
+
+	mu.Lock()
+	job.Progress = progress
+	mu.Unlock()
+
+	select {
+	case <-canceled:
+		p.finish(job, domain.JobCanceled, "canceled by request", "")
+		return
+	default:
+	}
+
+	switch {
+	case err == nil:
+		p.finish(job, domain.JobSucceeded, "", resp.ID)
+	case workerCtx.Err() != nil:
+		// Shutting down: hand the job back without counting the attempt.
+		job.Attempts--
+		p.retry(job, "interrupted by shutdown", 0)
+	case !retryable(err):
+		p.finish(job, domain.JobFailed, err.Error(), "")
+		p.notifyFailure(job, err)
+	case job.Attempts >= job.MaxAttempts:
+		p.finish(job, domain.JobDead, err.Error(), "")
+		p.notifyFailure(job, err)
+	default:
+		p.retry(job, err.Error(), p.backoff(job.Attempts))
+	}
+}
+
+func (p *Pool) finish(job *domain.Job, status domain.JobStatus, message, resultID string) {
+	now := p.now().UTC()
+	job.Status = status
+	job.ResultID = resultID
+	if message != "" {
+		job.Error = message
+	}
+	if status == domain.JobSucceeded {
+		job.Error = ""
+	}
+	job.FinishedAt = &now
+	p.save(job)
+}
+
+func (p *Pool) notifyFailure(job *domain.Job, err error) {
+	p.webhooks.Emit(context.Background(), webhook.FailureEvent(&job.Request, job.ID, err), job.Request.CallbackURL)
+}
+
+func (p *Pool) retry(job *domain.Job, message string, delay time.Duration) {
+	job.Status = domain.JobQueued
+	job.Error = message
+	job.AvailableAt = p.now().UTC().Add(delay)
+	p.save(job)
+}
+
+func (p *Pool) save(job *domain.Job) {
+	// The worker context may already be canceled; the outcome must still
+	// be recorded.
+	if err := p.store.FinishJob(context.Background(), job); err != nil {
+		log.Printf(`{"level":"error","msg":"Failed to record job outcome","job_id":"%s","status":"%s","error":"%v"}`, job.ID, job.Status, err)
+		return
+	}
+	log.Printf(`{"level":"info","msg":"Job attempt recorded","job_id":"%s","status":"%s","attempts":%d}`, job.ID, job.Status, job.Attempts)
+}
+
+// backoff doubles the retry delay with every attempt.
+func (p *Pool) backoff(attempt int) time.Duration {
+	delay := p.cfg.RetryBackoff
+	for i := 1; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// retryable reports whether an error may succeed on another attempt.
+// Invalid requests and missing resources fail permanently.
+func retryable(err error) bool {
+	switch domain.CodeOf(err) {
+	case domain.ErrorCodeInvalidArgument, domain.ErrorCodeNotFound, domain.ErrorCodeConflict:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,293 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/domain"
+	"learnforge/internal/experiment"
+	"learnforge/internal/service"
+	"learnforge/internal/store"
+	"learnforge/internal/verify"
+)
+
+// scriptedProcessor fails its first len(errs) calls with the given
+// errors, then succeeds. A request with text "block" waits for its context
+// to end. It records the caller scope of the last request.
+type scriptedProcessor struct {
+	mu      sync.Mutex
+	errs    []error
+	calls   int
+	keyHash string
+}
+
+func (p *scriptedProcessor) ValidateRequest(req *domain.ProcessRequest) error {
+	if req.Text == "" {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "text is required", nil)
+	}
+	return nil
+}
+
+func (p *scriptedProcessor) ProcessText(ctx context.Context, req *domain.ProcessRequest) (*domain.ProcessResponse, error) {
+	if req.Text == "block" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	p.keyHash = req.KeyHash()
+	if p.calls <= len(p.errs) {
+		return nil, p.errs[p.calls-1]
+	}
+	return &domain.ProcessResponse{ID: "result-1"}, nil
+}
+
+func waitForStatus(t *testing.T, pool *Pool, id string, status domain.JobStatus) *domain.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := pool.Get(context.Background(), id)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if job.Status == status {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	job, _ := pool.Get(context.Background(), id)
+	t.Fatalf("job %s did not reach %s, last state %+v", id, status, job)
+	return nil
+}
+
+func newTestPool(processor Processor) *Pool {
+	return NewPool(processor, store.NewInMemStore(), Config{
+		Workers:           2,
+		MaxAttempts:       3,
+		VisibilityTimeout: 30 * time.Millisecond,
+		PollInterval:      5 * time.Millisecond,
+		RetryBackoff:      time.Millisecond,
+	})
+}
+
+func TestPool_RetriesThenSucceeds(t *testing.T) {
+	upstream := domain.NewDomainError(domain.ErrorCodeUpstreamTimeout, "model timed out", nil)
+	processor := &scriptedProcessor{errs: []error{upstream, upstream}}
+	pool := newTestPool(processor)
+	pool.Start()
+	defer pool.Stop()
+
+	req := &domain.ProcessRequest{Text: "some text", APIKey: "secret"}
+	job, err := pool.Enqueue(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if job.Status != domain.JobQueued {
+		t.Errorf("expected queued job, got %s", job.Status)
+	}
+
+	done := waitForStatus(t, pool, job.ID, domain.JobSucceeded)
+	if done.Attempts != 3 || done.ResultID != "result-1" || done.Error != "" || done.FinishedAt == nil {
+		t.Errorf("unexpected finished job: %+v", done)
+	}
+	processor.mu.Lock()
+	if processor.keyHash != req.KeyHash() {
+		t.Errorf("job ran with key hash %q, want the caller's %q", processor.keyHash, req.KeyHash())
+	}
+	processor.mu.Unlock()
+
+	if _, err := pool.Enqueue(context.Background(), &domain.ProcessRequest{}); err == nil {
+		t.Error("expected invalid request to be rejected at enqueue")
+	}
+}
+
+func TestPool_DeadLetterAndPermanentFailure(t *testing.T) {
+	upstream := errors.New("connection reset")
+	pool := newTestPool(&scriptedProcessor{errs: []error{upstream, upstream, upstream}})
+	pool.Start()
+	defer pool.Stop()
+
+	job, _ := pool.Enqueue(context.Background(), &domain.ProcessRequest{Text: "some text"})
+	dead := waitForStatus(t, pool, job.ID, domain.JobDead)
+	if dead.Attempts != 3 || dead.Error != "connection reset" {
+		t.Errorf("unexpected dead job: %+v", dead)
+	}
+
+	invalid := domain.NewDomainError(domain.ErrorCodeInvalidArgument, "text too long", nil)
+	pool2 := newTestPool(&scriptedProcessor{errs: []error{invalid}})
+	pool2.Start()
+	defer pool2.Stop()
+
+	job, _ = pool2.Enqueue(context.Background(), &domain.ProcessRequest{Text: "some text"})
+	failed := waitForStatus(t, pool2, job.ID, domain.JobFailed)
+	if failed.Attempts != 1 {
+		t.Errorf("permanent errors should not be retried, got %d attempts", failed.Attempts)
+	}
+}
+
+func TestPool_Cancel(t *testing.T) {
+	pool := newTestPool(&scriptedProcessor{})
+	ctx := context.Background()
+
+	queued, _ := pool.Enqueue(ctx, &domain.ProcessRequest{Text: "some text"})
+	canceled, err := pool.Cancel(ctx, queued.ID)
+	if err != nil || canceled.Status != domain.JobCanceled {
+		t.Fatalf("expected queued job to be canceled, got %+v, %v", canceled, err)
+	}
+	if _, err := pool.Cancel(ctx, queued.ID); err == nil {
+		t.Error("expected conflict canceling a finished job")
+	}
+
+	pool.Start()
+	defer pool.Stop()
+
+	running, _ := pool.Enqueue(ctx, &domain.ProcessRequest{Text: "block"})
+	waitForStatus(t, pool, running.ID, domain.JobRunning)
+	if job, err := pool.Cancel(ctx, running.ID); err != nil || !job.CancelRequested {
+		t.Fatalf("expected cancel request on running job, got %+v, %v", job, err)
+	}
+	waitForStatus(t, pool, running.ID, domain.JobCanceled)
+}
+
+func TestPool_Batch(t *testing.T) {
+	pool := newTestPool(&scriptedProcessor{errs: []error{
+		domain.NewDomainError(domain.ErrorCodeInvalidArgument, "text too long", nil),
+	}})
+	ctx := context.Background()
+
+	_, err := pool.EnqueueBatch(ctx, []*domain.ProcessRequest{{Text: "a"}, {}})
+	if err == nil || !strings.Contains(err.Error(), "requests[1]") {
+		t.Fatalf("expected the invalid item to be named, got %v", err)
+	}
+
+	batch, err := pool.EnqueueBatch(ctx, []*domain.ProcessRequest{{Text: "a"}, {Text: "b"}, {Text: "c"}})
+	if err != nil || batch.Total != 3 || batch.Status != domain.BatchRunning {
+		t.Fatalf("EnqueueBatch: %+v, %v", batch, err)
+	}
+
+	pool.Start()
+	defer pool.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if batch, _ = pool.GetBatch(ctx, batch.ID); batch.Status == domain.BatchCompleted {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if batch.Status != domain.BatchCompleted || batch.Counts[domain.JobSucceeded] != 2 || batch.Counts[domain.JobFailed] != 1 {
+		t.Fatalf("expected a completed batch with one failed item, got %+v", batch)
+	}
+
+	items, err := pool.BatchJobs(ctx, batch.ID, 1, 10)
+	if err != nil || len(items) != 2 || items[0].BatchIndex != 1 || items[1].BatchIndex != 2 {
+		t.Errorf("expected items 1 and 2 in order, got %+v, %v", items, err)
+	}
+	if _, err := pool.GetBatch(ctx, "missing"); err == nil {
+		t.Error("expected not found for an unknown batch")
+	}
+}
+
+// providerCalls stands in for a model provider and records the most calls
+// it had in flight at once.
+type providerCalls struct {
+	mu             sync.Mutex
+	inFlight, peak int
+	total          int
+}
+
+func (c *providerCalls) call() {
+	c.mu.Lock()
+	c.inFlight++
+	c.total++
+	if c.inFlight > c.peak {
+		c.peak = c.inFlight
+	}
+	c.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+}
+
+// quizModel generates a quiz whose second answer the verifier disputes,
+// so every request also verifies and regenerates.
+type quizModel struct{ calls *providerCalls }
+
+func (m quizModel) ProcessText(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+	m.calls.call()
+	return &domain.ProcessResponse{Quiz: []domain.QuizItem{
+		{Q: "Who accepts writes?", Choices: []string{"Yes", "No"}, Answer: "Yes"},
+		{Q: "Do followers accept writes?", Choices: []string{"Yes", "No"}, Answer: "Yes"},
+	}}, nil
+}
+
+func (m quizModel) GenerateMeme(ctx context.Context, topic, question string) (string, error) {
+	return "", nil
+}
+
+func (m quizModel) Complete(ctx context.Context, prompt string) (string, error) {
+	m.calls.call()
+	return `{"items": [{"index": 0, "answer": "Yes", "defensible": ["Yes"]}, {"index": 1, "answer": "No", "defensible": ["No"]}]}`, nil
+}
+
+func TestPool_BatchStaysWithinModelLimit(t *testing.T) {
+	const maxConcurrent = 2
+	calls := &providerCalls{}
+	model := quizModel{calls: calls}
+	st := store.NewInMemStore()
+	experiments := experiment.NewManager(st, []*experiment.Experiment{{
+		Name:     "model",
+		Variants: []*experiment.Variant{{Name: "candidate", Percent: 50, Client: model}},
+	}})
+	svc := service.NewService(st, model,
+		service.WithRateLimiter(ai.NewLimiter(0, maxConcurrent)),
+		service.WithVerifier(verify.NewVerifier(model, verify.ActionRegenerate)),
+		service.WithExperiments(experiments),
+	)
+	pool := NewPool(svc, st, Config{
+		Workers:           8,
+		MaxAttempts:       1,
+		VisibilityTimeout: time.Second,
+		PollInterval:      5 * time.Millisecond,
+	})
+
+	var reqs []*domain.ProcessRequest
+	for i := 0; i < 8; i++ {
+		reqs = append(reqs, &domain.ProcessRequest{Text: "Only the leader accepts writes.", UserID: string(rune('a' + i))})
+	}
+	ctx := context.Background()
+	batch, err := pool.EnqueueBatch(ctx, reqs)
+	if err != nil {
+		t.Fatalf("EnqueueBatch: %v", err)
+	}
+	pool.Start()
+	defer pool.Stop()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if batch, _ = pool.GetBatch(ctx, batch.ID); batch.Status == domain.BatchCompleted {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if batch.Counts[domain.JobSucceeded] != len(reqs) {
+		t.Fatalf("expected every item to succeed, got %+v", batch)
+	}
+
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+	// Generation, verification, regeneration and its verification.
+	if calls.total != 4*len(reqs) {
+		t.Errorf("provider calls = %d, want %d", calls.total, 4*len(reqs))
+	}
+	if calls.peak > maxConcurrent {
+		t.Errorf("peak concurrent provider calls = %d, want at most %d", calls.peak, maxConcurrent)
+	}
+}
@@ -0,0 +1,216 @@
+// Package replay re-runs stored production requests through a candidate
+// provider, model or prompt and diffs the outputs. Results are never saved.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+// MaxLimit caps the number of requests replayed in one run.
+const MaxLimit = 200
+
+// Pricing converts token counts into USD.
+type Pricing struct {
+	InputCostPer1K  float64 `json:"input_cost_per_1k"`
+	OutputCostPer1K float64 `json:"output_cost_per_1k"`
+}
+
+func (p Pricing) cost(meta domain.Meta) float64 {
+	return float64(meta.PromptTokens)/1000*p.InputCostPer1K + float64(meta.CompletionTokens)/1000*p.OutputCostPer1K
+}
+
+// Candidate describes the provider, model and prompt under test.
+type Candidate struct {
+	Provider      string  `json:"provider"`
+	Model         string  `json:"model"`
+	PromptVersion string  `json:"prompt_version,omitempty"`
+	Instructions  string  `json:"instructions,omitempty"`
+	Pricing       Pricing `json:"pricing"`
+}
+
+// Options selects which stored requests to replay.
+type Options struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	Topic           string    `json:"topic,omitempty"`
+	Limit           int       `json:"limit"`
+	BaselinePricing Pricing   `json:"baseline_pricing"`
+}
+
+// Runner replays stored requests against a candidate client.
+type Runner struct {
+	store     store.Store
+	client    ai.Client
+	candidate Candidate
+	limiter   *ai.Limiter
+	timeout   time.Duration
+}
+
+// Option configures a Runner.
+type Option func(*Runner)
+
+// WithLimiter makes candidate calls wait for the model rate limit shared
+// with production traffic, so a replay cannot use up the provider quota.
+func WithLimiter(l *ai.Limiter) Option {
+	return func(r *Runner) {
+		r.limiter = l
+	}
+}
+
+func NewRunner(store store.Store, client ai.Client, candidate Candidate, opts ...Option) *Runner {
+	if candidate.PromptVersion == "" {
+		candidate.PromptVersion = ai.DefaultPromptVersion
+	}
+	r := &Runner{
+		store:     store,
+		client:    client,
+		candidate: candidate,
+		timeout:   60 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Runner) Run(ctx context.Context, opts Options) (*Report, error) {
+	if err := prepare(&opts); err != nil {
+		return nil, err
+	}
+	return r.run(ctx, opts)
+}
+
+// prepare validates opts and fills in the default time window.
+func prepare(opts *Options) error {
+	if opts.Limit <= 0 || opts.Limit > MaxLimit {
+		return domain.InvalidArgument(fmt.Sprintf("limit must be between 1 and %d", MaxLimit))
+	}
+	if opts.End.IsZero() {
+		opts.End = time.Now().UTC()
+	}
+	if opts.Start.IsZero() {
+		opts.Start = opts.End.AddDate(0, 0, -7)
+	}
+	if !opts.Start.Before(opts.End) {
+		return domain.InvalidArgument("start must be before end")
+	}
+	return nil
+}
+
+func (r *Runner) run(ctx context.Context, opts Options) (*Report, error) {
+	results, err := r.sample(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Candidate:   r.candidate,
+		Options:     opts,
+		GeneratedAt: time.Now().UTC(),
+	}
+	for _, stored := range results {
+		report.Items = append(report.Items, r.replay(ctx, stored, opts.BaselinePricing))
+	}
+	report.Summary = summarize(report.Items)
+
+	return report, nil
+}
+
+func (r *Runner) sample(ctx context.Context, opts Options) ([]*domain.StoredResult, error) {
+	results, err := r.store.GetByDateRange(ctx, opts.Start, opts.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored results: %w", err)
+	}
+
+	if opts.Topic != "" {
+		filtered := results[:0]
+		for _, result := range results {
+			if result.Topic == opts.Topic {
+				filtered = append(filtered, result)
+			}
+		}
+		results = filtered
+	}
+
+	if len(results) > opts.Limit {
+		rand.Shuffle(len(results), func(i, j int) {
+			results[i], results[j] = results[j], results[i]
+		})
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+func (r *Runner) replay(ctx context.Context, stored *domain.StoredResult, baselinePricing Pricing) ItemDiff {
+	item := ItemDiff{ResultID: stored.ID}
+
+	var req domain.ProcessRequest
+	if err := json.Unmarshal(stored.RequestJSON, &req); err != nil {
+		item.Error = fmt.Sprintf("failed to decode stored request: %v", err)
+		return item
+	}
+	var baseline domain.ProcessResponse
+	if err := json.Unmarshal(stored.ResponseJSON, &baseline); err != nil {
+		item.Error = fmt.Sprintf("failed to decode stored response: %v", err)
+		return item
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = "lesson"
+	}
+	language := req.Language
+	if language == "" {
+		language = "en"
+	}
+
+	release, err := r.limiter.Acquire(ctx)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	defer release()
+
+	replayCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	candidate, err := r.client.ProcessText(replayCtx, &ai.ProcessRequest{
+		Text:          req.Text,
+		Mode:          mode,
+		Topic:         req.Topic,
+		Level:         req.Level,
+		Language:      language,
+		PromptVersion: r.candidate.PromptVersion,
+		Instructions:  r.candidate.Instructions,
+	})
+	latency := time.Since(start).Milliseconds()
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+
+	if req.Topic != nil && *req.Topic != "" {
+		candidate.Topic = *req.Topic
+	}
+
+	baselineCost := baseline.Meta.CostUSD
+	if baselineCost == 0 {
+		baselineCost = baselinePricing.cost(baseline.Meta)
+	}
+
+	return diff(stored.ID, &baseline, candidate, metrics{
+		baselineLatency:  baseline.Meta.ProcessingMS,
+		candidateLatency: latency,
+		baselineCost:     baselineCost,
+		candidateCost:    r.candidate.Pricing.cost(candidate.Meta),
+	})
+}
@@ -0,0 +1,138 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+type stubAI struct {
+	resp *domain.ProcessResponse
+}
+
+func (s *stubAI) ProcessText(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+	copied := *s.resp
+	return &copied, nil
+}
+
+func (s *stubAI) GenerateMeme(ctx context.Context, topic, question string) (string, error) {
+	return "", nil
+}
+
+func TestRunner_DiffsWithoutOverwriting(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewInMemStore()
+
+	production := domain.ProcessResponse{
+		ID:        "res-1",
+		Topic:     "Raft",
+		Summary:   "Raft elects a leader that replicates the log.",
+		KeyPoints: []string{"Leaders replicate log entries"},
+		Quiz:      []domain.QuizItem{{Q: "Who accepts client commands?", Choices: []string{"Leader", "Follower"}, Answer: "Leader"}},
+		Meta:      domain.Meta{ProcessingMS: 900, PromptTokens: 1000, CompletionTokens: 500},
+	}
+	requestJSON, _ := json.Marshal(domain.ProcessRequest{Text: "Raft is a consensus algorithm."})
+	responseJSON, _ := json.Marshal(production)
+	st.Save(ctx, &domain.StoredResult{
+		ID:           "res-1",
+		RequestJSON:  requestJSON,
+		ResponseJSON: responseJSON,
+		Topic:        "Raft",
+		CreatedAt:    time.Now().Add(-time.Hour),
+	})
+
+	candidate := &stubAI{resp: &domain.ProcessResponse{
+		Topic:     "Consensus",
+		Summary:   "Raft elects a leader that replicates the log.",
+		KeyPoints: []string{"Leaders replicate log entries", "Terms increase monotonically"},
+		Meta:      domain.Meta{PromptTokens: 1000, CompletionTokens: 1000},
+	}}
+
+	runner := NewRunner(st, candidate, Candidate{Model: "candidate", Pricing: Pricing{OutputCostPer1K: 1}})
+	report, err := runner.Run(ctx, Options{Limit: 10, BaselinePricing: Pricing{OutputCostPer1K: 1}})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(report.Items) != 1 {
+		t.Fatalf("Expected 1 replayed item, got %d", len(report.Items))
+	}
+	item := report.Items[0]
+	if !item.TopicChanged {
+		t.Error("Expected topic change to be detected")
+	}
+	if len(item.KeyPointsAdded) != 1 || len(item.QuizRemoved) != 1 {
+		t.Errorf("Unexpected list diff: added=%v removed=%v", item.KeyPointsAdded, item.QuizRemoved)
+	}
+	if item.CostDeltaUSD != 0.5 {
+		t.Errorf("Expected cost delta 0.5, got %v", item.CostDeltaUSD)
+	}
+
+	stored, _ := st.Get(ctx, "res-1")
+	if string(stored.ResponseJSON) != string(responseJSON) {
+		t.Error("Replay must not overwrite the production result")
+	}
+}
+
+func TestRuns_ReplaysInTheBackground(t *testing.T) {
+	st := store.NewInMemStore()
+	requestJSON, _ := json.Marshal(domain.ProcessRequest{Text: "Raft is a consensus algorithm."})
+	responseJSON, _ := json.Marshal(domain.ProcessResponse{Topic: "Raft"})
+	st.Save(context.Background(), &domain.StoredResult{ID: "res-1", RequestJSON: requestJSON, ResponseJSON: responseJSON, CreatedAt: time.Now().Add(-time.Hour)})
+
+	runs := NewRuns()
+	defer runs.Stop()
+	runner := NewRunner(st, &stubAI{resp: &domain.ProcessResponse{Topic: "Raft"}}, Candidate{Model: "candidate"})
+	if _, err := runs.Start(runner, Options{Limit: MaxLimit + 1}); err == nil {
+		t.Fatal("Start() with a limit above MaxLimit succeeded, want an error")
+	}
+	run, err := runs.Start(runner, Options{Limit: 5})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for run.Status == RunRunning && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+		if run, err = runs.Get(run.ID); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+	if run.Status != RunCompleted || run.Report == nil || len(run.Report.Items) != 1 {
+		t.Fatalf("run = %+v, want a completed report of 1 item", run)
+	}
+	if _, err := runs.Get("missing"); err == nil {
+		t.Error("Get() of an unknown run succeeded, want not found")
+	}
+}
+
+func TestRunner_WaitsForTheSharedLimiter(t *testing.T) {
+	st := store.NewInMemStore()
+	requestJSON, _ := json.Marshal(domain.ProcessRequest{Text: "Raft is a consensus algorithm."})
+	responseJSON, _ := json.Marshal(domain.ProcessResponse{Topic: "Raft"})
+	st.Save(context.Background(), &domain.StoredResult{ID: "res-1", RequestJSON: requestJSON, ResponseJSON: responseJSON, CreatedAt: time.Now().Add(-time.Hour)})
+
+	// Production traffic holds the only slot.
+	limiter := ai.NewLimiter(0, 1)
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	runner := NewRunner(st, &stubAI{resp: &domain.ProcessResponse{Topic: "Raft"}}, Candidate{Model: "candidate"}, WithLimiter(limiter))
+	report, err := runner.Run(ctx, Options{Limit: 5})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(report.Items) != 1 || report.Items[0].Error == "" {
+		t.Fatalf("items = %+v, want the candidate call to wait for the limiter", report.Items)
+	}
+}
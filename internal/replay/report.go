@@ -0,0 +1,198 @@
+package replay
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/textutil"
+)
+
+// Side holds one side of a comparison.
+type Side struct {
+	Topic      string   `json:"topic"`
+	Summary    string   `json:"summary"`
+	KeyPoints  []string `json:"key_points"`
+	Flashcards []string `json:"flashcards"`
+	Quiz       []string `json:"quiz"`
+	LatencyMS  int64    `json:"latency_ms"`
+	Tokens     int      `json:"tokens"`
+	CostUSD    float64  `json:"cost_usd"`
+}
+
+// ItemDiff compares the production result with the candidate output for a
+// single stored request.
+type ItemDiff struct {
+	ResultID          string   `json:"result_id"`
+	Error             string   `json:"error,omitempty"`
+	Baseline          *Side    `json:"baseline,omitempty"`
+	Candidate         *Side    `json:"candidate,omitempty"`
+	TopicChanged      bool     `json:"topic_changed"`
+	SummarySimilarity float64  `json:"summary_similarity"`
+	KeyPointsAdded    []string `json:"key_points_added,omitempty"`
+	KeyPointsRemoved  []string `json:"key_points_removed,omitempty"`
+	QuizAdded         []string `json:"quiz_added,omitempty"`
+	QuizRemoved       []string `json:"quiz_removed,omitempty"`
+	LatencyDeltaMS    int64    `json:"latency_delta_ms"`
+	CostDeltaUSD      float64  `json:"cost_delta_usd"`
+}
+
+type Summary struct {
+	Replayed             int     `json:"replayed"`
+	Failures             int     `json:"failures"`
+	TopicAgreement       float64 `json:"topic_agreement"`
+	AvgSummarySimilarity float64 `json:"avg_summary_similarity"`
+	AvgLatencyDeltaMS    float64 `json:"avg_latency_delta_ms"`
+	BaselineCostUSD      float64 `json:"baseline_cost_usd"`
+	CandidateCostUSD     float64 `json:"candidate_cost_usd"`
+}
+
+type Report struct {
+	Candidate   Candidate  `json:"candidate"`
+	Options     Options    `json:"options"`
+	GeneratedAt time.Time  `json:"generated_at"`
+	Summary     Summary    `json:"summary"`
+	Items       []ItemDiff `json:"items"`
+}
+
+type metrics struct {
+	baselineLatency, candidateLatency int64
+	baselineCost, candidateCost       float64
+}
+
+// similarItems is the Jaccard similarity above which two statements are
+// treated as the same item when diffing lists.
+const similarItems = 0.6
+
+func diff(resultID string, baseline, candidate *domain.ProcessResponse, m metrics) ItemDiff {
+	b := side(baseline, m.baselineLatency, m.baselineCost)
+	c := side(candidate, m.candidateLatency, m.candidateCost)
+
+	return ItemDiff{
+		ResultID:          resultID,
+		Baseline:          b,
+		Candidate:         c,
+		TopicChanged:      domain.NormalizeText(b.Topic) != domain.NormalizeText(c.Topic),
+		SummarySimilarity: textutil.Jaccard(b.Summary, c.Summary),
+		KeyPointsAdded:    missingFrom(c.KeyPoints, b.KeyPoints),
+		KeyPointsRemoved:  missingFrom(b.KeyPoints, c.KeyPoints),
+		QuizAdded:         missingFrom(c.Quiz, b.Quiz),
+		QuizRemoved:       missingFrom(b.Quiz, c.Quiz),
+		LatencyDeltaMS:    m.candidateLatency - m.baselineLatency,
+		CostDeltaUSD:      m.candidateCost - m.baselineCost,
+	}
+}
+
+func side(resp *domain.ProcessResponse, latency int64, cost float64) *Side {
+	s := &Side{
+		Topic:     resp.Topic,
+		Summary:   resp.Summary,
+		KeyPoints: resp.KeyPoints,
+		LatencyMS: latency,
+		Tokens:    resp.Meta.PromptTokens + resp.Meta.CompletionTokens,
+		CostUSD:   cost,
+	}
+	for _, card := range resp.Flashcards {
+		s.Flashcards = append(s.Flashcards, card.Q)
+	}
+	for _, item := range resp.Quiz {
+		s.Quiz = append(s.Quiz, item.Q)
+	}
+	return s
+}
+
+// missingFrom returns the entries of a without a similar entry in b.
+func missingFrom(a, b []string) []string {
+	var missing []string
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if textutil.Jaccard(x, y) >= similarItems {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, x)
+		}
+	}
+	return missing
+}
+
+func summarize(items []ItemDiff) Summary {
+	var s Summary
+	var agreed int
+	var similarity, latency float64
+	for _, item := range items {
+		if item.Error != "" {
+			s.Failures++
+			continue
+		}
+		s.Replayed++
+		if !item.TopicChanged {
+			agreed++
+		}
+		similarity += item.SummarySimilarity
+		latency += float64(item.LatencyDeltaMS)
+		s.BaselineCostUSD += item.Baseline.CostUSD
+		s.CandidateCostUSD += item.Candidate.CostUSD
+	}
+	if s.Replayed > 0 {
+		n := float64(s.Replayed)
+		s.TopicAgreement = float64(agreed) / n
+		s.AvgSummarySimilarity = similarity / n
+		s.AvgLatencyDeltaMS = latency / n
+	}
+	return s
+}
+
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Replay: %s / %s (prompt %s)\n\n", r.Candidate.Provider, r.Candidate.Model, r.Candidate.PromptVersion))
+	b.WriteString(fmt.Sprintf("- Window: %s to %s\n", r.Options.Start.Format(time.RFC3339), r.Options.End.Format(time.RFC3339)))
+	if r.Options.Topic != "" {
+		b.WriteString(fmt.Sprintf("- Topic: %s\n", r.Options.Topic))
+	}
+	b.WriteString(fmt.Sprintf("- Replayed: %d, failures: %d\n", r.Summary.Replayed, r.Summary.Failures))
+	b.WriteString(fmt.Sprintf("- Topic agreement: %.0f%%\n", r.Summary.TopicAgreement*100))
+	b.WriteString(fmt.Sprintf("- Avg summary similarity: %.2f\n", r.Summary.AvgSummarySimilarity))
+	b.WriteString(fmt.Sprintf("- Avg latency delta: %+.0f ms\n", r.Summary.AvgLatencyDeltaMS))
+	b.WriteString(fmt.Sprintf("- Cost: $%.4f → $%.4f\n", r.Summary.BaselineCostUSD, r.Summary.CandidateCostUSD))
+
+	for _, item := range r.Items {
+		b.WriteString(fmt.Sprintf("\n## %s\n\n", item.ResultID))
+		if item.Error != "" {
+			b.WriteString(fmt.Sprintf("Error: %s\n", item.Error))
+			continue
+		}
+		b.WriteString("| | Production | Candidate |\n|---|---|---|\n")
+		b.WriteString(fmt.Sprintf("| Topic | %s | %s |\n", cell(item.Baseline.Topic), cell(item.Candidate.Topic)))
+		b.WriteString(fmt.Sprintf("| Summary | %s | %s |\n", cell(item.Baseline.Summary), cell(item.Candidate.Summary)))
+		b.WriteString(fmt.Sprintf("| Key points | %d | %d |\n", len(item.Baseline.KeyPoints), len(item.Candidate.KeyPoints)))
+		b.WriteString(fmt.Sprintf("| Flashcards | %d | %d |\n", len(item.Baseline.Flashcards), len(item.Candidate.Flashcards)))
+		b.WriteString(fmt.Sprintf("| Quiz items | %d | %d |\n", len(item.Baseline.Quiz), len(item.Candidate.Quiz)))
+		b.WriteString(fmt.Sprintf("| Latency | %d ms | %d ms |\n", item.Baseline.LatencyMS, item.Candidate.LatencyMS))
+		b.WriteString(fmt.Sprintf("| Cost | $%.4f | $%.4f |\n", item.Baseline.CostUSD, item.Candidate.CostUSD))
+
+		writeList(&b, "Quiz questions only in production", item.QuizRemoved)
+		writeList(&b, "Quiz questions only in candidate", item.QuizAdded)
+		writeList(&b, "Key points only in production", item.KeyPointsRemoved)
+		writeList(&b, "Key points only in candidate", item.KeyPointsAdded)
+	}
+	return b.String()
+}
+
+func cell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+}
+
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	b.WriteString(fmt.Sprintf("\n%s:\n", title))
+	for _, item := range items {
+		b.WriteString(fmt.Sprintf("- %s\n", item))
+	}
+}
@@ -0,0 +1,136 @@
+package replay
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"learnforge/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Run statuses
+const (
+	RunRunning   = "running"
+	RunCompleted = "completed"
+	RunFailed    = "failed"
+)
+
+const (
+	maxActiveRuns = 2  // replaying at the same time
+	maxKeptRuns   = 20 // finished runs kept for polling
+)
+
+// Run is a replay started in the background. Report is set once it
+// completes.
+type Run struct {
+	ID         string     `json:"id"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Report     *Report    `json:"report,omitempty"`
+}
+
+// Runs runs replays in the background, so a run outlives the request that
+// started it, and keeps the most recent ones in memory to be polled.
+// Runs are lost on restart.
+type Runs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	runs  map[string]*Run
+	order []string // oldest first
+}
+
+func NewRuns() *Runs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runs{
+		ctx:    ctx,
+		cancel: cancel,
+		runs:   make(map[string]*Run),
+	}
+}
+
+// Start validates opts and replays them through runner in the background.
+func (rs *Runs) Start(runner *Runner, opts Options) (*Run, error) {
+	if err := prepare(&opts); err != nil {
+		return nil, err
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	active := 0
+	for _, run := range rs.runs {
+		if run.Status == RunRunning {
+			active++
+		}
+	}
+	if active >= maxActiveRuns {
+		return nil, domain.NewDomainError(domain.ErrorCodeConflict, "too many replays are running, try again when one finishes", nil)
+	}
+	run := &Run{ID: uuid.New().String(), Status: RunRunning, StartedAt: time.Now().UTC()}
+	rs.runs[run.ID] = run
+	rs.order = append(rs.order, run.ID)
+	rs.evict()
+
+	rs.wg.Add(1)
+	go func() {
+		defer rs.wg.Done()
+		report, err := runner.run(rs.ctx, opts)
+		rs.finish(run.ID, report, err)
+	}()
+	copied := *run
+	return &copied, nil
+}
+
+// Get returns run id as it is now.
+func (rs *Runs) Get(id string) (*Run, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	run, ok := rs.runs[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "replay not found", nil)
+	}
+	copied := *run
+	return &copied, nil
+}
+
+// Stop cancels running replays and waits for them to return.
+func (rs *Runs) Stop() {
+	rs.cancel()
+	rs.wg.Wait()
+}
+
+func (rs *Runs) finish(id string, report *Report, err error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	run := rs.runs[id]
+	now := time.Now().UTC()
+	run.FinishedAt = &now
+	if err != nil {
+		run.Status, run.Error = RunFailed, domain.Message(err)
+		log.Printf(`{"level":"error","msg":"Replay failed","replay":"%s","error":"%v"}`, id, err)
+		return
+	}
+	run.Status, run.Report = RunCompleted, report
+}
+
+// evict drops the oldest finished runs beyond maxKeptRuns.
+func (rs *Runs) evict() {
+	excess := len(rs.order) - maxKeptRuns - maxActiveRuns
+	kept := rs.order[:0]
+	for _, id := range rs.order {
+		if excess > 0 && rs.runs[id].Status != RunRunning {
+			delete(rs.runs, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	rs.order = kept
+}
@@ -0,0 +1,244 @@
+// Package review moves results through draft, in review and published,
+// so nothing generated reaches learners before a person approved it.
+// Every transition and comment is kept as an audit trail.
+package review
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/slack"
+	"learnforge/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// DefaultQueueLimit bounds Queue when no limit is given.
+const DefaultQueueLimit = 100
+
+// transitions lists the statuses each action may start from and the
+// status it leads to.
+var transitions = map[string]struct {
+	from []string
+	to   string
+}{
+	domain.ReviewSubmit:  {from: []string{domain.ReviewDraft, domain.ReviewRejected}, to: domain.ReviewInReview},
+	domain.ReviewApprove: {from: []string{domain.ReviewInReview}, to: domain.ReviewPublished},
+	domain.ReviewReject:  {from: []string{domain.ReviewInReview}, to: domain.ReviewRejected},
+	domain.ReviewReopen:  {from: []string{domain.ReviewInReview, domain.ReviewPublished, domain.ReviewRejected}, to: domain.ReviewDraft},
+}
+
+// Review is a result's current status and its audit trail.
+type Review struct {
+	ResultID string                `json:"result_id"`
+	Status   string                `json:"status"`
+	Events   []*domain.ReviewEvent `json:"events"`
+}
+
+// QueueEntry is a result waiting for review.
+type QueueEntry struct {
+	ResultID  string    `json:"result_id"`
+	Topic     string    `json:"topic"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Workflow enforces the review transitions.
+type Workflow struct {
+	results store.Store
+	reviews store.ReviewStore
+	slack   *slack.Client
+	now     func() time.Time
+}
+
+// NewWorkflow returns a Workflow. slackClient may be nil to disable
+// notifications.
+func NewWorkflow(results store.Store, reviews store.ReviewStore, slackClient *slack.Client) *Workflow {
+	return &Workflow{
+		results: results,
+		reviews: reviews,
+		slack:   slackClient,
+		now:     time.Now,
+	}
+}
+
+// Submit asks for review of a draft or rejected result and notifies
+// reviewers.
+func (w *Workflow) Submit(ctx context.Context, id, actor, comment string) (*Review, error) {
+	event, err := w.transition(ctx, id, domain.ReviewSubmit, actor, comment)
+	if err != nil {
+		return nil, err
+	}
+	w.notify(event)
+	return w.Get(ctx, id)
+}
+
+// Approve publishes a result in review. Reviewers cannot approve results
+// they submitted themselves.
+func (w *Workflow) Approve(ctx context.Context, id, reviewer, comment string) (*Review, error) {
+	if _, err := w.transition(ctx, id, domain.ReviewApprove, reviewer, comment); err != nil {
+		return nil, err
+	}
+	return w.Get(ctx, id)
+}
+
+// Reject sends a result in review back to its author. A comment saying
+// what to fix is required.
+func (w *Workflow) Reject(ctx context.Context, id, reviewer, comment string) (*Review, error) {
+	if strings.TrimSpace(comment) == "" {
+		return nil, domain.InvalidArgument("a comment is required to reject a result")
+	}
+	if _, err := w.transition(ctx, id, domain.ReviewReject, reviewer, comment); err != nil {
+		return nil, err
+	}
+	return w.Get(ctx, id)
+}
+
+// Reopen returns a result to draft, for example to edit published
+// content. Learners stop seeing it until it is approved again.
+func (w *Workflow) Reopen(ctx context.Context, id, actor, comment string) (*Review, error) {
+	if _, err := w.transition(ctx, id, domain.ReviewReopen, actor, comment); err != nil {
+		return nil, err
+	}
+	return w.Get(ctx, id)
+}
+
+// Comment adds a comment without changing the status.
+func (w *Workflow) Comment(ctx context.Context, id, actor, comment string) (*Review, error) {
+	if strings.TrimSpace(comment) == "" {
+		return nil, domain.InvalidArgument("comment must not be empty")
+	}
+	if _, err := w.transition(ctx, id, domain.ReviewComment, actor, comment); err != nil {
+		return nil, err
+	}
+	return w.Get(ctx, id)
+}
+
+// Get returns a result's status and audit trail.
+func (w *Workflow) Get(ctx context.Context, id string) (*Review, error) {
+	stored, err := w.results.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	events, err := w.reviews.ListReviewEvents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &Review{ResultID: id, Status: stored.Status, Events: events}, nil
+}
+
+// Queue lists results in status, oldest first: in_review when status is
+// empty.
+func (w *Workflow) Queue(ctx context.Context, status string, limit int) ([]QueueEntry, error) {
+	if status == "" {
+		status = domain.ReviewInReview
+	}
+	if !domain.ValidateReviewStatus(status) {
+		return nil, domain.InvalidArgument("status must be one of: draft, in_review, published, rejected")
+	}
+	if limit <= 0 {
+		limit = DefaultQueueLimit
+	}
+	results, err := w.reviews.ListResultsByStatus(ctx, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]QueueEntry, 0, len(results))
+	for _, result := range results {
+		entries = append(entries, QueueEntry{
+			ResultID:  result.ID,
+			Topic:     result.Topic,
+			Status:    result.Status,
+			CreatedAt: result.CreatedAt,
+		})
+	}
+	return entries, nil
+}
+
+func (w *Workflow) transition(ctx context.Context, id, action, actor, comment string) (*domain.ReviewEvent, error) {
+	if strings.TrimSpace(actor) == "" {
+		return nil, domain.InvalidArgument("an actor is required for review actions")
+	}
+	stored, err := w.results.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &domain.ReviewEvent{
+		ID:        uuid.New().String(),
+		ResultID:  id,
+		Action:    action,
+		From:      stored.Status,
+		To:        stored.Status,
+		Actor:     actor,
+		Comment:   strings.TrimSpace(comment),
+		CreatedAt: w.now().UTC(),
+	}
+	if rule, ok := transitions[action]; ok {
+		if !contains(rule.from, stored.Status) {
+			return nil, domain.NewDomainError(domain.ErrorCodeConflict,
+				fmt.Sprintf("cannot %s a result that is %s", action, stored.Status), nil)
+		}
+		event.To = rule.to
+	}
+	if action == domain.ReviewApprove {
+		submitter, err := w.submitter(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if submitter == actor {
+			return nil, domain.NewDomainError(domain.ErrorCodeConflict, "a result must be approved by someone other than its submitter", nil)
+		}
+	}
+
+	if err := w.reviews.AddReviewEvent(ctx, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// submitter returns who submitted the current review round.
+func (w *Workflow) submitter(ctx context.Context, id string) (string, error) {
+	events, err := w.reviews.ListReviewEvents(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Action == domain.ReviewSubmit {
+			return events[i].Actor, nil
+		}
+	}
+	return "", nil
+}
+
+// notify tells reviewers that a result awaits review. It does not block
+// the request; failures are only logged.
+func (w *Workflow) notify(event *domain.ReviewEvent) {
+	if w.slack == nil {
+		return
+	}
+	text := fmt.Sprintf("`/v1/process/%s` was submitted by %s.", event.ResultID, event.Actor)
+	if event.Comment != "" {
+		text += "\n> " + event.Comment
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := w.slack.SendSummary(ctx, "Result awaiting review", text); err != nil {
+			log.Printf(`{"level":"error","msg":"Failed to send review notification to Slack","result_id":"%s","error":"%v"}`, event.ResultID, err)
+		}
+	}()
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package review
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+func TestWorkflow_Transitions(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	if err := st.Save(ctx, &domain.StoredResult{ID: "result-1", ResponseJSON: []byte(`{}`), CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorkflow(st, st, nil)
+
+	if _, err := w.Approve(ctx, "result-1", "ben", ""); err == nil {
+		t.Error("expected a draft to need submitting before approval")
+	}
+	if _, err := w.Submit(ctx, "result-1", "", ""); err == nil {
+		t.Error("expected review actions without an actor to be rejected")
+	}
+	if _, err := w.Submit(ctx, "result-1", "ana", "ready"); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if _, err := w.Submit(ctx, "result-1", "ana", ""); err == nil {
+		t.Error("expected a result in review not to be submitted again")
+	}
+	if _, err := w.Reject(ctx, "result-1", "ben", " "); err == nil {
+		t.Error("expected a rejection without a comment to be rejected")
+	}
+	if _, err := w.Reject(ctx, "result-1", "ben", "question 2 has two right answers"); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+
+	queue, err := w.Queue(ctx, domain.ReviewRejected, 0)
+	if err != nil || len(queue) != 1 || queue[0].ResultID != "result-1" {
+		t.Fatalf("Queue(rejected) = %+v, %v", queue, err)
+	}
+
+	if _, err := w.Submit(ctx, "result-1", "ana", "fixed"); err != nil {
+		t.Fatalf("resubmit: %v", err)
+	}
+	if _, err := w.Approve(ctx, "result-1", "ana", ""); err == nil {
+		t.Error("expected submitters not to approve their own results")
+	}
+	rev, err := w.Approve(ctx, "result-1", "ben", "looks good")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if rev.Status != domain.ReviewPublished {
+		t.Errorf("status = %s, want published", rev.Status)
+	}
+
+	want := []string{domain.ReviewSubmit, domain.ReviewReject, domain.ReviewSubmit, domain.ReviewApprove}
+	if len(rev.Events) != len(want) {
+		t.Fatalf("audit trail has %d events, want %d", len(rev.Events), len(want))
+	}
+	for i, event := range rev.Events {
+		if event.Action != want[i] {
+			t.Errorf("event %d = %s, want %s", i, event.Action, want[i])
+		}
+	}
+	if last := rev.Events[3]; last.From != domain.ReviewInReview || last.To != domain.ReviewPublished || last.Actor != "ben" || last.Comment != "looks good" {
+		t.Errorf("unexpected approval event: %+v", last)
+	}
+
+	rev, err = w.Reopen(ctx, "result-1", "ana", "typo in summary")
+	if err != nil || rev.Status != domain.ReviewDraft {
+		t.Fatalf("Reopen = %+v, %v", rev, err)
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/cache"
+	"learnforge/internal/domain"
+	"learnforge/internal/experiment"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Values of Meta.Cache
+const (
+	CacheHit    = "hit"
+	CacheMiss   = "miss"
+	CacheBypass = "bypass"
+)
+
+// generationCacheVersion is part of every key; bump it when the cached
+// value changes shape.
+const generationCacheVersion = "v1"
+
+var generationCacheTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "generation_cache_requests_total",
+		Help: "Generation cache lookups by result (hit, miss, bypass)",
+	},
+	[]string{"result"},
+)
+
+// CacheTTLs sets how long generated content is cached. Modes overrides
+// Default per mode; a zero or negative TTL disables caching.
+type CacheTTLs struct {
+	Default time.Duration
+	Modes   map[string]time.Duration
+}
+
+func (t CacheTTLs) forMode(mode string) time.Duration {
+	if ttl, ok := t.Modes[mode]; ok {
+		return ttl
+	}
+	return t.Default
+}
+
+// WithGenerationCache reuses generated content for identical requests, so
+// the same text is not paid for twice.
+func WithGenerationCache(c cache.Cache, ttls CacheTTLs) Option {
+	return func(s *Service) {
+		s.cache = c
+		s.cacheTTLs = ttls
+	}
+}
+
+type bypassCacheKey struct{}
+
+// WithoutCache makes requests on ctx skip the generation cache lookup.
+// The fresh result still replaces the cached one.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+// generationCacheKey addresses generated content by everything that shapes
+// it: the normalized text, mode, topic, level, language, model, prompt
+// version and instructions, and the verification action. It returns ""
+// when the request must not be cached. Experiment traffic is never
+// cached, so variant latency and cost stay comparable.
+func (s *Service) generationCacheKey(client ai.Client, aiReq *ai.ProcessRequest, assignment *experiment.Assignment) string {
+	if s.cache == nil || assignment != nil || s.cacheTTLs.forMode(aiReq.Mode) <= 0 {
+		return ""
+	}
+	verifyAction := ""
+	if s.verifier != nil {
+		verifyAction = s.verifier.Action()
+	}
+	parts := []string{
+		generationCacheVersion,
+		strings.Join(strings.Fields(aiReq.Text), " "),
+		aiReq.Mode,
+		deref(aiReq.Topic),
+		deref(aiReq.Level),
+		aiReq.Language,
+		ai.ModelName(client),
+		aiReq.PromptVersion,
+		aiReq.Instructions,
+		verifyAction,
+	}
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return "generation:" + hex.EncodeToString(hash[:])
+}
+
+// cachedGeneration returns the content cached under key. It reports a
+// miss, or a bypass when ctx asks to skip the cache.
+func (s *Service) cachedGeneration(ctx context.Context, key string) (*domain.ProcessResponse, string) {
+	if key == "" {
+		return nil, ""
+	}
+	if bypass, _ := ctx.Value(bypassCacheKey{}).(bool); bypass {
+		generationCacheTotal.WithLabelValues(CacheBypass).Inc()
+		return nil, CacheBypass
+	}
+
+	cached, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if err != cache.ErrNotFound {
+			log.Printf(`{"level":"warn","msg":"Generation cache lookup failed","error":"%v"}`, err)
+		}
+		generationCacheTotal.WithLabelValues(CacheMiss).Inc()
+		return nil, CacheMiss
+	}
+	var response domain.ProcessResponse
+	if err := json.Unmarshal([]byte(cached), &response); err != nil {
+		generationCacheTotal.WithLabelValues(CacheMiss).Inc()
+		return nil, CacheMiss
+	}
+	generationCacheTotal.WithLabelValues(CacheHit).Inc()
+	return &response, CacheHit
+}
+
+// cacheGeneration stores freshly generated content. Failures only cost a
+// future cache miss.
+func (s *Service) cacheGeneration(ctx context.Context, key, mode string, response *domain.ProcessResponse) {
+	if key == "" {
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	if err := s.cache.Set(ctx, key, string(data), s.cacheTTLs.forMode(mode)); err != nil {
+		log.Printf(`{"level":"warn","msg":"Failed to cache generation","error":"%v"}`, err)
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
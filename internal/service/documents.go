@@ -0,0 +1,328 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"learnforge/internal/docdiff"
+	"learnforge/internal/domain"
+	"learnforge/internal/webhook"
+)
+
+// fullRegenerationRatio is the share of changed sections above which the
+// whole document is sent to the model so the summary stays accurate.
+// Items from unchanged sections are kept either way.
+const fullRegenerationRatio = 0.5
+
+const maxDocumentIDLength = 200
+
+// revision is the previous version of a resubmitted document and how the
+// new text differs from it.
+type revision struct {
+	prev *domain.ProcessResponse
+	plan docdiff.Plan
+	// fresh discards every previous item, used when the previous result
+	// has no section information or was generated with other settings.
+	fresh bool
+}
+
+// partial reports whether only the changed sections need to be sent to the
+// model.
+func (r *revision) partial() bool {
+	return r != nil && !r.fresh && r.plan.ChangedRatio() <= fullRegenerationRatio
+}
+
+// documentResultID is the stable result ID of req's document. Document IDs
+// are scoped to the caller, so two callers can use the same document_id
+// without replacing each other's results.
+func documentResultID(req *domain.ProcessRequest) string {
+	key := "document:" + req.DocumentID
+	if scope := callerScope(req); scope != "" {
+		key = "document:" + scope + "\x00" + req.DocumentID
+	}
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])[:16]
+}
+
+func validateDocumentID(req *domain.ProcessRequest) error {
+	if req.DocumentID == "" {
+		return nil
+	}
+	if len(req.DocumentID) > maxDocumentIDLength || strings.TrimSpace(req.DocumentID) != req.DocumentID {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "document_id must be at most 200 characters without surrounding whitespace", nil)
+	}
+	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "document_id and idempotency_key cannot be combined; resubmitting a document is already idempotent", nil)
+	}
+	return nil
+}
+
+// previousRevision loads the stored result for req's document and diffs
+// its text against req.Text. It returns nil for the first submission.
+func (s *Service) previousRevision(ctx context.Context, req *domain.ProcessRequest) (*revision, error) {
+	if req.DocumentID == "" {
+		return nil, nil
+	}
+	stored, err := s.store.Get(ctx, documentResultID(req))
+	if err != nil {
+		if domain.HasCode(err, domain.ErrorCodeNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var prevReq domain.ProcessRequest
+	var prev domain.ProcessResponse
+	if err := json.Unmarshal(stored.RequestJSON, &prevReq); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored request", err)
+	}
+	if err := json.Unmarshal(stored.ResponseJSON, &prev); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+	}
+
+	rev := &revision{
+		prev: &prev,
+		plan: docdiff.Diff(docdiff.Split(prevReq.Text), docdiff.Split(req.Text)),
+	}
+	rev.fresh = !sameSettings(&prevReq, req) || !hasSections(&prev)
+	if rev.fresh {
+		rev.plan = docdiff.Diff(nil, rev.plan.Sections)
+	}
+	return rev, nil
+}
+
+// pruneRevision handles a resubmission without new or edited sections:
+// an identical document returns the stored result, and a document that
+// only lost sections drops their items without calling the model.
+func (s *Service) pruneRevision(ctx context.Context, req *domain.ProcessRequest, rev *revision, src origin) (*domain.ProcessResponse, error) {
+	if rev.plan.Removed == 0 {
+		s.webhooks.Callback(ctx, webhook.ResultEvent(domain.EventResultCompleted, rev.prev, jobIDFrom(ctx)), req.CallbackURL)
+		return rev.prev, nil
+	}
+
+	base := *rev.prev
+	base.KeyPoints, base.KeyPointRefs, base.Flashcards, base.Quiz = nil, nil, nil, nil
+	base.Meta.ProcessingMS, base.Meta.PromptTokens, base.Meta.CompletionTokens, base.Meta.CostUSD = 0, 0, 0, 0
+	resp := mergeRevision(req, &base, rev)
+	resp.Source = src.info
+	resp.CreatedAt = time.Now()
+
+	if err := s.storeResult(ctx, req, resp); err != nil {
+		return nil, err
+	}
+	s.webhooks.Emit(ctx, webhook.ResultEvent(domain.EventResultUpdated, resp, jobIDFrom(ctx)), req.CallbackURL)
+	return resp, nil
+}
+
+// sameSettings reports whether two requests generate comparable content.
+func sameSettings(a, b *domain.ProcessRequest) bool {
+	return modeOrDefault(a.Mode) == modeOrDefault(b.Mode) && deref(a.Level) == deref(b.Level) &&
+		languageOrDefault(a.Language) == languageOrDefault(b.Language)
+}
+
+func modeOrDefault(m string) string {
+	if m == "" {
+		return "lesson"
+	}
+	return m
+}
+
+func languageOrDefault(language string) string {
+	if language == "" {
+		return "en"
+	}
+	return language
+}
+
+func hasSections(resp *domain.ProcessResponse) bool {
+	for _, ref := range resp.KeyPointRefs {
+		if ref != nil && ref.SectionID != "" {
+			return true
+		}
+	}
+	for _, card := range resp.Flashcards {
+		if card.Ref != nil && card.Ref.SectionID != "" {
+			return true
+		}
+	}
+	for _, item := range resp.Quiz {
+		if item.Ref != nil && item.Ref.SectionID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeRevision combines generated content with the previous revision.
+// Generated items are assigned to the sections they cover; those landing
+// in unchanged sections are dropped in favour of the previous items, which
+// keep their IDs. Without a previous revision every item is simply tagged
+// with its section.
+func mergeRevision(req *domain.ProcessRequest, generated *domain.ProcessResponse, rev *revision) *domain.ProcessResponse {
+	sections := docdiff.Split(req.Text)
+	changed := sections
+	var prev *domain.ProcessResponse
+	if rev != nil {
+		sections = rev.plan.Sections
+		changed = rev.plan.Changed
+		prev = rev.prev
+	}
+
+	// Generated content only ever covers changed sections. Content from a
+	// partial call is pinned to the first changed section when it matches
+	// none, since it can only have come from one of them.
+	candidates := sections
+	if rev.partial() {
+		candidates = changed
+	}
+	isChanged := make(map[string]bool, len(changed))
+	for _, sec := range changed {
+		isChanged[sec.ID] = true
+	}
+	assign := func(text string, ref *domain.SourceRef) (*domain.SourceRef, bool) {
+		sec, ok := docdiff.Assign(text, candidates)
+		if !ok && rev.partial() && len(changed) > 0 {
+			sec, ok = changed[0], true
+		}
+		if !ok {
+			return ref, true
+		}
+		if ref == nil {
+			ref = &domain.SourceRef{}
+		}
+		ref.Section = sec.Title
+		ref.SectionID = sec.ID
+		return ref, isChanged[sec.ID]
+	}
+	// keep reports whether a previous item survives: its section is
+	// unchanged, or it was never tied to a section and nothing replaces it.
+	keep := func(ref *domain.SourceRef) bool {
+		if ref == nil || ref.SectionID == "" {
+			return rev.partial()
+		}
+		return rev.plan.Unchanged[ref.SectionID]
+	}
+
+	order := make(map[string]int, len(sections))
+	for i, sec := range sections {
+		order[sec.ID] = i
+	}
+	position := func(ref *domain.SourceRef) int {
+		if ref != nil {
+			if i, ok := order[ref.SectionID]; ok {
+				return i
+			}
+		}
+		return len(sections)
+	}
+
+	result := *generated
+	diff := &domain.RevisionDiff{Sections: len(sections), ChangedSections: len(changed)}
+
+	var keyPoints []keyPoint
+	var flashcards []domain.Flashcard
+	var quiz []domain.QuizItem
+	if prev != nil {
+		diff.RemovedSections = rev.plan.Removed
+		for i, kp := range prev.KeyPoints {
+			if ref := refAt(prev.KeyPointRefs, i); keep(ref) {
+				keyPoints = append(keyPoints, keyPoint{kp, ref})
+			}
+		}
+		for _, card := range prev.Flashcards {
+			if keep(card.Ref) {
+				flashcards = append(flashcards, card)
+				diff.KeptItems++
+			}
+		}
+		for _, item := range prev.Quiz {
+			if keep(item.Ref) {
+				quiz = append(quiz, item)
+				diff.KeptItems++
+			}
+		}
+		if rev.partial() {
+			result.Summary = prev.Summary
+			result.Topic = prev.Topic
+			result.TopicConfidence = prev.TopicConfidence
+		} else {
+			diff.FullRegeneration = true
+		}
+	}
+
+	for i, kp := range generated.KeyPoints {
+		if ref, ok := assign(kp, refAt(generated.KeyPointRefs, i)); ok {
+			keyPoints = append(keyPoints, keyPoint{kp, ref})
+		}
+	}
+	for _, card := range generated.Flashcards {
+		var ok bool
+		if card.Ref, ok = assign(card.Q+" "+card.A, card.Ref); ok {
+			card.ID = ""
+			flashcards = append(flashcards, card)
+			diff.RegeneratedItems++
+		}
+	}
+	for _, item := range generated.Quiz {
+		var ok bool
+		if item.Ref, ok = assign(item.Q+" "+item.Answer, item.Ref); ok {
+			item.ID = ""
+			quiz = append(quiz, item)
+			diff.RegeneratedItems++
+		}
+	}
+
+	// Order by section, previous items ahead of regenerated ones.
+	sort.SliceStable(keyPoints, func(i, j int) bool { return position(keyPoints[i].ref) < position(keyPoints[j].ref) })
+	sort.SliceStable(flashcards, func(i, j int) bool { return position(flashcards[i].Ref) < position(flashcards[j].Ref) })
+	sort.SliceStable(quiz, func(i, j int) bool { return position(quiz[i].Ref) < position(quiz[j].Ref) })
+
+	result.KeyPoints = make([]string, len(keyPoints))
+	result.KeyPointRefs = make([]*domain.SourceRef, len(keyPoints))
+	for i, kp := range keyPoints {
+		result.KeyPoints[i] = kp.text
+		result.KeyPointRefs[i] = kp.ref
+	}
+	result.Flashcards = flashcards
+	result.Quiz = quiz
+
+	result.DocumentID = req.DocumentID
+	result.Revision = 1
+	if prev != nil {
+		result.Revision = prev.Revision + 1
+		result.Meta.Diff = diff
+	}
+	return &result
+}
+
+type keyPoint struct {
+	text string
+	ref  *domain.SourceRef
+}
+
+func refAt(refs []*domain.SourceRef, i int) *domain.SourceRef {
+	if i < len(refs) {
+		return refs[i]
+	}
+	return nil
+}
+
+// assignItemIDs gives new flashcards and quiz items an ID. Existing IDs
+// are left alone so clients can track items across revisions.
+func assignItemIDs(resp *domain.ProcessResponse) {
+	for i := range resp.Flashcards {
+		if resp.Flashcards[i].ID == "" {
+			resp.Flashcards[i].ID = domain.NewItemID()
+		}
+	}
+	for i := range resp.Quiz {
+		if resp.Quiz[i].ID == "" {
+			resp.Quiz[i].ID = domain.NewItemID()
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+	"learnforge/internal/webhook"
+)
+
+const (
+	// DefaultIdempotencyTTL is how long a key keeps returning its result.
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// idempotencyLockMargin is added to the generation timeout when
+	// claiming a key, so a key held by a crashed instance frees up soon
+	// after its request would have timed out anyway.
+	idempotencyLockMargin = 30 * time.Second
+	idempotencyPoll       = 100 * time.Millisecond
+	idempotencyPurgeEvery = time.Hour
+)
+
+var errIdempotencyMismatch = domain.NewDomainError(domain.ErrorCodeConflict,
+	"idempotency key was already used with a different request", nil)
+
+// WithIdempotencyTTL sets how long idempotency keys are remembered.
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(s *Service) {
+		if ttl > 0 {
+			s.idempotencyTTL = ttl
+		}
+	}
+}
+
+// idempotency tracks keys whose requests are running in this process, so
+// concurrent duplicates wait for the first instead of calling the model.
+type idempotency struct {
+	mu        sync.Mutex
+	inFlight  map[string]*flight
+	lastPurge time.Time
+}
+
+type flight struct {
+	fingerprint string
+	done        chan struct{}
+	resp        *domain.ProcessResponse
+	err         error
+}
+
+// idempotent runs process once per idempotency key. A retry with the same
+// key and body gets the original result; the same key with a different
+// body is a conflict. Keys are scoped to the caller, see callerScope.
+func (s *Service) idempotent(ctx context.Context, req *domain.ProcessRequest, process func() (*domain.ProcessResponse, error)) (*domain.ProcessResponse, error) {
+	process = s.reportFailure(ctx, req, process)
+	if req.IdempotencyKey == nil || *req.IdempotencyKey == "" {
+		return process()
+	}
+	scope, key := callerScope(req), *req.IdempotencyKey
+	fingerprint, err := requestFingerprint(req)
+	if err != nil {
+		return nil, err
+	}
+
+	flightKey := scope + "\x00" + key
+	s.keys.mu.Lock()
+	if f, ok := s.keys.inFlight[flightKey]; ok {
+		s.keys.mu.Unlock()
+		if f.fingerprint != fingerprint {
+			return nil, errIdempotencyMismatch
+		}
+		select {
+		case <-f.done:
+			return f.resp, f.err
+		case <-ctx.Done():
+			return nil, domain.NewDomainError(domain.ErrorCodeUpstreamTimeout, "timed out waiting for a request with the same idempotency key", ctx.Err())
+		}
+	}
+	f := &flight{fingerprint: fingerprint, done: make(chan struct{})}
+	if s.keys.inFlight == nil {
+		s.keys.inFlight = make(map[string]*flight)
+	}
+	s.keys.inFlight[flightKey] = f
+	s.keys.mu.Unlock()
+
+	defer func() {
+		s.keys.mu.Lock()
+		delete(s.keys.inFlight, flightKey)
+		s.keys.mu.Unlock()
+		close(f.done)
+	}()
+
+	f.resp, f.err = s.claimAndProcess(ctx, req, scope, key, fingerprint, process)
+	return f.resp, f.err
+}
+
+// reportFailure sends result.failed when process fails. Background jobs
+// are left to the job pool, which reports them once retries run out.
+func (s *Service) reportFailure(ctx context.Context, req *domain.ProcessRequest, process func() (*domain.ProcessResponse, error)) func() (*domain.ProcessResponse, error) {
+	return func() (*domain.ProcessResponse, error) {
+		resp, err := process()
+		if err != nil && jobIDFrom(ctx) == "" {
+			// The caller may have gone away; the event must still be sent.
+			s.webhooks.Emit(context.Background(), webhook.FailureEvent(req, "", err), req.CallbackURL)
+		}
+		return resp, err
+	}
+}
+
+// claimAndProcess reserves the key in the store, which also coordinates
+// instances, and runs process if no earlier request holds it.
+func (s *Service) claimAndProcess(ctx context.Context, req *domain.ProcessRequest, scope, key, fingerprint string, process func() (*domain.ProcessResponse, error)) (*domain.ProcessResponse, error) {
+	if s.idempotencyStore == nil {
+		return process()
+	}
+	s.purgeIdempotencyKeys(ctx)
+
+	now := time.Now().UTC()
+	record := &domain.IdempotencyRecord{
+		Scope:       scope,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      domain.IdempotencyPending,
+		LockedUntil: now.Add(generationTimeout(ctx) + idempotencyLockMargin),
+		ExpiresAt:   now.Add(s.idempotencyTTL),
+		CreatedAt:   now,
+	}
+	for {
+		existing, err := s.idempotencyStore.ReserveIdempotencyKey(ctx, record, now)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			break
+		}
+		if existing.Fingerprint != fingerprint {
+			return nil, errIdempotencyMismatch
+		}
+		if existing.Status == domain.IdempotencyCompleted {
+			resp, err := s.GetResult(ctx, existing.ResultID)
+			if err != nil {
+				return nil, err
+			}
+			s.webhooks.Callback(ctx, webhook.ResultEvent(domain.EventResultCompleted, resp, jobIDFrom(ctx)), req.CallbackURL)
+			return resp, nil
+		}
+
+		// Another instance is processing the same request.
+		select {
+		case <-ctx.Done():
+			return nil, domain.NewDomainError(domain.ErrorCodeConflict, "a request with this idempotency key is still in progress", ctx.Err())
+		case <-time.After(idempotencyPoll):
+		}
+		now = time.Now().UTC()
+	}
+
+	resp, err := process()
+	// Record the outcome even if the caller has gone away.
+	if err != nil {
+		if releaseErr := s.idempotencyStore.ReleaseIdempotencyKey(context.Background(), scope, key, fingerprint); releaseErr != nil {
+			log.Printf(`{"level":"error","msg":"Failed to release idempotency key","error":"%v"}`, releaseErr)
+		}
+		return nil, err
+	}
+	if err := s.idempotencyStore.CompleteIdempotencyKey(context.Background(), scope, key, fingerprint, resp.ID); err != nil {
+		log.Printf(`{"level":"error","msg":"Failed to complete idempotency key","error":"%v"}`, err)
+	}
+	return resp, nil
+}
+
+// storeResult saves resp. Saving is best effort, except for requests with
+// an idempotency key: their key must be released rather than completed
+// with a result that was never stored.
+func (s *Service) storeResult(ctx context.Context, req *domain.ProcessRequest, resp *domain.ProcessResponse) error {
+	err := s.saveResult(ctx, req, resp)
+	if err == nil {
+		return nil
+	}
+	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" {
+		return domain.NewDomainError(domain.ErrorCodeInternal, "failed to save result", err)
+	}
+	log.Printf(`{"level":"error","msg":"Failed to save result","result_id":"%s","error":"%v"}`, resp.ID, err)
+	return nil
+}
+
+// purgeIdempotencyKeys drops expired keys at most once per
+// idempotencyPurgeEvery.
+func (s *Service) purgeIdempotencyKeys(ctx context.Context) {
+	now := time.Now().UTC()
+	s.keys.mu.Lock()
+	due := now.Sub(s.keys.lastPurge) >= idempotencyPurgeEvery
+	if due {
+		s.keys.lastPurge = now
+	}
+	s.keys.mu.Unlock()
+	if !due {
+		return
+	}
+	if err := s.idempotencyStore.DeleteExpiredIdempotencyKeys(ctx, now); err != nil {
+		log.Printf(`{"level":"warn","msg":"Failed to purge expired idempotency keys","error":"%v"}`, err)
+	}
+}
+
+// callerScope keeps callers from seeing each other's idempotency keys and
+// documents: both are scoped to the API key, or to the user when no API
+// key is sent. API keys are hashed since they must not be persisted.
+func callerScope(req *domain.ProcessRequest) string {
+	if hash := req.KeyHash(); hash != "" {
+		return "key:" + hash
+	}
+	if req.UserID != "" {
+		return "user:" + req.UserID
+	}
+	return ""
+}
+
+// requestFingerprint hashes the request body without the key itself.
+// Caller identity is not part of the body and is covered by the scope.
+func requestFingerprint(req *domain.ProcessRequest) (string, error) {
+	body := *req
+	body.IdempotencyKey = nil
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", domain.NewDomainError(domain.ErrorCodeInternal, "failed to fingerprint request", err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// idempotencyStoreOf returns st's idempotency support, if it has any.
+func idempotencyStoreOf(st store.Store) store.IdempotencyStore {
+	is, _ := st.(store.IdempotencyStore)
+	return is
+}
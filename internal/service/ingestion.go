@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/ingest"
+)
+
+// WithFetcher enables processing of source_url requests.
+func WithFetcher(f *ingest.Fetcher) Option {
+	return func(s *Service) {
+		s.fetcher = f
+	}
+}
+
+// WithExtractor overrides the default upload size limits.
+func WithExtractor(e *ingest.Extractor) Option {
+	return func(s *Service) {
+		s.extractor = e
+	}
+}
+
+// MaxUploadBytes is the largest upload accepted for any format.
+func (s *Service) MaxUploadBytes() int64 {
+	return s.extractor.MaxBytes()
+}
+
+// ProcessUpload extracts the text of an uploaded document and processes it
+// like a text request. The file's name, MIME type and hash are kept with
+// the result.
+func (s *Service) ProcessUpload(ctx context.Context, req *domain.ProcessRequest, upload *ingest.Upload) (*domain.ProcessResponse, error) {
+	if req.Text != "" || req.SourceURL != "" || len(req.Sources) > 0 {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "text, source_url and sources cannot be combined with a file upload", nil)
+	}
+
+	file, err := s.extractor.Extract(upload)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Text = file.Text
+	if file.Title != "" && !strings.HasPrefix(file.Text, "# ") {
+		req.Text = "# " + file.Title + "\n\n" + file.Text
+	}
+
+	if err := s.ValidateRequest(req); err != nil {
+		return nil, err
+	}
+	return s.idempotent(ctx, req, func() (*domain.ProcessResponse, error) {
+		return s.generate(ctx, req, origin{
+			info: &domain.SourceInfo{
+				Title:      file.Title,
+				FileName:   filepath.Base(upload.FileName),
+				FileSHA256: file.SHA256,
+				MimeType:   file.MimeType,
+				FileSize:   int64(len(upload.Data)),
+			},
+			transcript: file.Transcript,
+		})
+	})
+}
+
+// linkTranscript points key points, flashcards and quiz items at the
+// moment in the recording where they were discussed.
+func linkTranscript(resp *domain.ProcessResponse, t *ingest.Transcript) {
+	if t == nil {
+		return
+	}
+	locate := func(text string) *domain.SourceRef {
+		at, ok := t.Locate(text)
+		if !ok {
+			return nil
+		}
+		return &domain.SourceRef{Timestamp: ingest.FormatTimestamp(at), StartSeconds: int(at / time.Second)}
+	}
+
+	resp.KeyPointRefs = make([]*domain.SourceRef, len(resp.KeyPoints))
+	for i, kp := range resp.KeyPoints {
+		resp.KeyPointRefs[i] = locate(kp)
+	}
+	for i := range resp.Flashcards {
+		resp.Flashcards[i].Ref = locate(resp.Flashcards[i].Q + " " + resp.Flashcards[i].A)
+	}
+	for i := range resp.Quiz {
+		resp.Quiz[i].Ref = locate(resp.Quiz[i].Q + " " + resp.Quiz[i].Answer)
+	}
+}
+
+// resolveSource fetches req.SourceURL and replaces req.Text with the
+// extracted article so the stored request can be replayed without
+// refetching.
+func (s *Service) resolveSource(ctx context.Context, req *domain.ProcessRequest) (*domain.SourceInfo, error) {
+	if req.SourceURL == "" {
+		return nil, nil
+	}
+	if s.fetcher == nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "source_url ingestion is not enabled", nil)
+	}
+
+	doc, page, err := s.fetcher.FetchDocument(ctx, req.SourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Text = doc.Text
+	if doc.Title != "" {
+		req.Text = "# " + doc.Title + "\n\n" + doc.Text
+	}
+
+	fetchedAt := page.FetchedAt
+	return &domain.SourceInfo{
+		URL:          req.SourceURL,
+		CanonicalURL: doc.CanonicalURL,
+		Title:        doc.Title,
+		FetchedAt:    &fetchedAt,
+	}, nil
+}
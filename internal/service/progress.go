@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// Pipeline stages reported to a progress callback.
+const (
+	StageFetchingSource = "fetching_source"
+	StageGenerating     = "generating"
+	StageVerifying      = "verifying"
+	StageSaving         = "saving"
+)
+
+// defaultGenerationTimeout bounds the model call of a synchronous request.
+const defaultGenerationTimeout = 10 * time.Second
+
+type progressKey struct{}
+
+type generationTimeoutKey struct{}
+
+type jobIDKey struct{}
+
+// WithProgress returns a context under which ProcessText reports each
+// pipeline stage to fn as it starts.
+func WithProgress(ctx context.Context, fn func(stage string)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// WithGenerationTimeout replaces the limit on the model call, for callers
+// such as background workers that are not bound by an HTTP request.
+func WithGenerationTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, generationTimeoutKey{}, d)
+}
+
+// WithJobID marks a context as running the given background job, so
+// webhook events can refer to it.
+func WithJobID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, jobIDKey{}, id)
+}
+
+func jobIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(jobIDKey{}).(string)
+	return id
+}
+
+func reportProgress(ctx context.Context, stage string) {
+	if fn, ok := ctx.Value(progressKey{}).(func(string)); ok {
+		fn(stage)
+	}
+}
+
+func generationTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(generationTimeoutKey{}).(time.Duration); ok && d > 0 {
+		return d
+	}
+	return defaultGenerationTimeout
+}
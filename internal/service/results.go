@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+// Page sizes of result listings
+const (
+	DefaultResultPageSize = 20
+	MaxResultPageSize     = 100
+)
+
+// ListResults returns a page of stored results matching query. cursor is
+// the NextCursor of the previous page, or empty for the first.
+func (s *Service) ListResults(ctx context.Context, query domain.ResultQuery, cursor string) (*domain.ResultPage, error) {
+	if err := prepareResultQuery(&query, cursor); err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	query.Limit++ // one more to tell whether another page follows
+	stored, err := s.store.ListResults(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.ResultPage{Results: make([]domain.ResultOverview, 0, len(stored))}
+	if len(stored) > limit {
+		stored = stored[:limit]
+		last := stored[len(stored)-1]
+		next := domain.ResultCursor{Sort: query.Sort, ID: last.ID}
+		switch query.Sort {
+		case domain.SortRelevance:
+			next.Offset = limit
+			if query.Cursor != nil {
+				next.Offset += query.Cursor.Offset
+			}
+		case domain.SortTopic:
+			next.Key = last.Topic
+		default:
+			next.Key = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+		}
+		page.NextCursor = encodeCursor(next)
+	}
+	for _, result := range stored {
+		page.Results = append(page.Results, overview(result))
+	}
+	return page, nil
+}
+
+// ListPublishedResults lists results as learners see them: when review is
+// required, only published results.
+func (s *Service) ListPublishedResults(ctx context.Context, query domain.ResultQuery, cursor string) (*domain.ResultPage, error) {
+	if s.reviewRequired {
+		if query.Status != "" && query.Status != domain.ReviewPublished {
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "only published results can be listed without the admin API key", nil)
+		}
+		query.Status = domain.ReviewPublished
+	}
+	return s.ListResults(ctx, query, cursor)
+}
+
+// prepareResultQuery validates query, fills in defaults and decodes
+// cursor.
+func prepareResultQuery(query *domain.ResultQuery, cursor string) error {
+	if !domain.ValidateMode(query.Mode) {
+		return invalidQuery("mode must be one of: lesson, flashcards, quiz")
+	}
+	if query.Level != "" && !domain.ValidateLevel(&query.Level) {
+		return invalidQuery("level must be one of: beginner, intermediate, advanced")
+	}
+	if query.Status != "" && !domain.ValidateReviewStatus(query.Status) {
+		return invalidQuery("status must be one of: draft, in_review, published, rejected")
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return invalidQuery("from must be before to")
+	}
+
+	switch query.Sort {
+	case "":
+		query.Sort = domain.SortNewest
+		if query.Search != "" {
+			query.Sort = domain.SortRelevance
+		}
+	case domain.SortNewest, domain.SortOldest, domain.SortTopic:
+	case domain.SortRelevance:
+		if query.Search == "" {
+			return invalidQuery("sort=relevance requires a search query")
+		}
+	default:
+		return invalidQuery("sort must be one of: newest, oldest, topic, relevance")
+	}
+
+	switch {
+	case query.Limit == 0:
+		query.Limit = DefaultResultPageSize
+	case query.Limit < 0 || query.Limit > MaxResultPageSize:
+		return invalidQuery("limit must be between 1 and 100")
+	}
+
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil || c.Sort != query.Sort || c.Offset < 0 {
+			return invalidQuery("invalid cursor; it must come from a listing with the same sort")
+		}
+		query.Cursor = c
+	}
+	return nil
+}
+
+func encodeCursor(c domain.ResultCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*domain.ResultCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c domain.ResultCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func overview(result *domain.StoredResult) domain.ResultOverview {
+	o := domain.ResultOverview{
+		ID:        result.ID,
+		Topic:     result.Topic,
+		Mode:      result.Mode,
+		Level:     result.Level,
+		Language:  result.Language,
+		Provider:  result.Provider,
+		Status:    result.Status,
+		CreatedAt: result.CreatedAt,
+	}
+	var resp domain.ProcessResponse
+	if err := json.Unmarshal(result.ResponseJSON, &resp); err == nil {
+		o.DocumentID = resp.DocumentID
+		o.Model = resp.Meta.Model
+		o.Summary = resp.Summary
+		o.Flashcards = len(resp.Flashcards)
+		o.QuizItems = len(resp.Quiz)
+	}
+	return o
+}
+
+func invalidQuery(msg string) error {
+	return domain.NewDomainError(domain.ErrorCodeInvalidArgument, msg, nil)
+}
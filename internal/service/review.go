@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// reviewActor is recorded for review events the service makes itself.
+const reviewActor = "system"
+
+// WithReviewRequired hides results from learners until a reviewer has
+// published them.
+func WithReviewRequired(required bool) Option {
+	return func(s *Service) {
+		s.reviewRequired = required
+	}
+}
+
+// GetPublishedResult returns a result as learners see it: when review is
+// required, results that are not published are not found.
+func (s *Service) GetPublishedResult(ctx context.Context, id string) (*domain.ProcessResponse, error) {
+	resp, err := s.GetResult(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.reviewRequired && resp.Status != domain.ReviewPublished {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "result not found", nil)
+	}
+	return resp, nil
+}
+
+// previousStatus returns the review status of the result a resubmitted
+// document replaces, or "" for new results.
+func (s *Service) previousStatus(ctx context.Context, req *domain.ProcessRequest, id string) string {
+	if req.DocumentID == "" {
+		return ""
+	}
+	stored, err := s.store.Get(ctx, id)
+	if err != nil {
+		return ""
+	}
+	return stored.Status
+}
+
+// reopenResubmitted returns a resubmitted document to draft, since its
+// regenerated content has not been reviewed.
+func (s *Service) reopenResubmitted(ctx context.Context, id, prevStatus string) bool {
+	if prevStatus == "" || prevStatus == domain.ReviewDraft {
+		return true
+	}
+	if s.reviews == nil {
+		return false
+	}
+	err := s.reviews.AddReviewEvent(ctx, &domain.ReviewEvent{
+		ID:        uuid.New().String(),
+		ResultID:  id,
+		Action:    domain.ReviewReopen,
+		From:      prevStatus,
+		To:        domain.ReviewDraft,
+		Actor:     reviewActor,
+		Comment:   "document resubmitted",
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf(`{"level":"error","msg":"Failed to reopen resubmitted document for review","result_id":"%s","error":"%v"}`, id, err)
+		return false
+	}
+	return true
+}
+
+// reviewStoreOf returns st's review support, if it has any.
+func reviewStoreOf(st store.Store) store.ReviewStore {
+	rs, _ := st.(store.ReviewStore)
+	return rs
+}
@@ -2,71 +2,182 @@ package service
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"learnforge/internal/ai"
+	"learnforge/internal/cache"
+	"learnforge/internal/docdiff"
 	"learnforge/internal/domain"
+	"learnforge/internal/experiment"
+	"learnforge/internal/ingest"
 	"learnforge/internal/store"
+	"learnforge/internal/verify"
+	"learnforge/internal/webhook"
 
 	"github.com/google/uuid"
 )
 
 type Service struct {
-	store    store.Store
-	aiClient ai.Client
+	store       store.Store
+	aiClient    ai.Client
+	experiments *experiment.Manager
+	verifier    *verify.Verifier
+	fetcher     *ingest.Fetcher
+	extractor   *ingest.Extractor
+	webhooks    *webhook.Dispatcher
+	limiter     *ai.Limiter
+	cache       cache.Cache
+	cacheTTLs   CacheTTLs
+
+	// idempotencyStore is nil when the store cannot record keys; requests
+	// with the same key are then only deduplicated while in flight.
+	idempotencyStore store.IdempotencyStore
+	idempotencyTTL   time.Duration
+	keys             idempotency
+
+	// reviews is nil when the store has no review workflow.
+	reviews        store.ReviewStore
+	reviewRequired bool
+}
+
+// Option configures optional Service dependencies.
+type Option func(*Service)
+
+// WithExperiments routes a share of traffic to experiment variants.
+func WithExperiments(m *experiment.Manager) Option {
+	return func(s *Service) {
+		s.experiments = m
+	}
 }
 
-func NewService(store store.Store, aiClient ai.Client) *Service {
-	return &Service{
-		store:    store,
-		aiClient: aiClient,
+// WithWebhooks sends result events to registered endpoints and request
+// callback URLs.
+func WithWebhooks(d *webhook.Dispatcher) Option {
+	return func(s *Service) {
+		s.webhooks = d
 	}
 }
 
+// WithRateLimiter makes generation calls wait for the shared model rate
+// limit.
+func WithRateLimiter(l *ai.Limiter) Option {
+	return func(s *Service) {
+		s.limiter = l
+	}
+}
+
+func NewService(store store.Store, aiClient ai.Client, opts ...Option) *Service {
+	s := &Service{
+		store:            store,
+		aiClient:         aiClient,
+		extractor:        ingest.NewExtractor(nil),
+		idempotencyStore: idempotencyStoreOf(store),
+		idempotencyTTL:   DefaultIdempotencyTTL,
+		reviews:          reviewStoreOf(store),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
 func (s *Service) ProcessText(ctx context.Context, req *domain.ProcessRequest) (*domain.ProcessResponse, error) {
-	if err := s.validateRequest(req); err != nil {
+	if err := s.ValidateRequest(req); err != nil {
 		return nil, err
 	}
 
-	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" {
-		if existing, err := s.getByIdempotencyKey(ctx, *req.IdempotencyKey); err == nil {
-			return existing, nil
+	return s.idempotent(ctx, req, func() (*domain.ProcessResponse, error) {
+		if req.SourceURL != "" || len(req.Sources) > 0 {
+			reportProgress(ctx, StageFetchingSource)
 		}
-	}
+		source, err := s.resolveSource(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		sources, err := s.resolveSources(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.generate(ctx, req, origin{info: source, sources: sources})
+	})
+}
 
-	mode := req.Mode
-	if mode == "" {
-		mode = "lesson"
+// origin describes where a request's text came from.
+type origin struct {
+	info       *domain.SourceInfo
+	transcript *ingest.Transcript
+	sources    []sourceText
+}
+
+// generate runs the AI pipeline for a validated request whose text has
+// been resolved, and stores the result.
+func (s *Service) generate(ctx context.Context, req *domain.ProcessRequest, src origin) (*domain.ProcessResponse, error) {
+	rev, err := s.previousRevision(ctx, req)
+	if err != nil {
+		return nil, err
 	}
-	language := req.Language
-	if language == "" {
-		language = "en"
+	if rev != nil && !rev.fresh && len(rev.plan.Changed) == 0 {
+		return s.pruneRevision(ctx, req, rev, src)
+	}
+
+	mode := modeOrDefault(req.Mode)
+	language := languageOrDefault(req.Language)
+
+	text := req.Text
+	if rev.partial() {
+		text = docdiff.Text(rev.plan.Changed)
 	}
 
 	aiReq := &ai.ProcessRequest{
-		Text:     req.Text,
-		Mode:     mode,
-		Topic:    req.Topic,
-		Level:    req.Level,
-		Language: language,
+		Text:          text,
+		Mode:          mode,
+		Topic:         req.Topic,
+		Level:         req.Level,
+		Language:      language,
+		PromptVersion: ai.DefaultPromptVersion,
 	}
 
-	aiCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
+	client := s.aiClient
+	assignment := s.experiments.Assign(experimentSubject(req))
+	if assignment != nil {
+		client = assignment.Variant.Client
+		aiReq.PromptVersion = assignment.Variant.PromptVersion
+		aiReq.Instructions = assignment.Variant.Instructions
+	}
+	if len(src.sources) > 0 {
+		aiReq.Instructions = strings.TrimSpace(aiReq.Instructions + "\n\n" + synthesisInstructions(src.sources))
+	}
 
+	id := s.generateID(req)
+	cacheKey := s.generationCacheKey(client, aiReq, assignment)
 	startTime := time.Now()
-	response, err := s.aiClient.ProcessText(aiCtx, aiReq)
-	if err != nil {
-		return nil, err
+	response, cacheStatus := s.cachedGeneration(ctx, cacheKey)
+	if response != nil {
+		// A hit is a new result that cost nothing to generate.
+		response.ID = id
+		response.CreatedAt = time.Now()
+		response.Meta.ProcessingMS = time.Since(startTime).Milliseconds()
+		response.Meta.PromptTokens, response.Meta.CompletionTokens, response.Meta.CostUSD = 0, 0, 0
+	} else {
+		response, err = s.callModel(ctx, client, aiReq, assignment, id)
+		if err != nil {
+			return nil, err
+		}
+		s.cacheGeneration(ctx, cacheKey, mode, response)
 	}
-	processingTime := time.Since(startTime).Milliseconds()
+	response.Meta.Cache = cacheStatus
+	response.Source = src.info
 
-	response.Meta.ProcessingMS = processingTime
-	response.ID = s.generateID(req)
+	linkTranscript(response, src.transcript)
+	citeSources(response, src.sources)
+	if req.DocumentID != "" {
+		response = mergeRevision(req, response, rev)
+	}
+	assignItemIDs(response)
 
 	if req.Topic != nil && *req.Topic != "" {
 		response.Topic = *req.Topic
@@ -93,11 +204,55 @@ func (s *Service) ProcessText(ctx context.Context, req *domain.ProcessRequest) (
 		}
 	}
 
-	_ = s.saveResult(ctx, req, response)
+	reportProgress(ctx, StageSaving)
+	if err := s.storeResult(ctx, req, response); err != nil {
+		return nil, err
+	}
+
+	eventType := domain.EventResultCompleted
+	if rev != nil {
+		eventType = domain.EventResultUpdated
+	}
+	s.webhooks.Emit(ctx, webhook.ResultEvent(eventType, response, jobIDFrom(ctx)), req.CallbackURL)
 
 	return response, nil
 }
 
+// callModel generates content for aiReq and verifies its quiz answers.
+func (s *Service) callModel(ctx context.Context, client ai.Client, aiReq *ai.ProcessRequest, assignment *experiment.Assignment, id string) (*domain.ProcessResponse, error) {
+	reportProgress(ctx, StageGenerating)
+	aiCtx, cancel := context.WithTimeout(ctx, generationTimeout(ctx))
+	defer cancel()
+
+	release, err := s.limiter.Acquire(aiCtx)
+	if err != nil {
+		return nil, err
+	}
+	startTime := time.Now()
+	response, err := client.ProcessText(aiCtx, aiReq)
+	release()
+	if err != nil {
+		_ = s.experiments.RecordGeneration(ctx, assignment, "", time.Since(startTime), nil, err)
+		return nil, err
+	}
+	processingTime := time.Since(startTime)
+
+	response.Meta.ProcessingMS = processingTime.Milliseconds()
+	response.Meta.PromptVersion = aiReq.PromptVersion
+	response.ID = id
+
+	if assignment != nil {
+		response.Meta.Experiment = assignment.Experiment
+		response.Meta.Variant = assignment.Variant.Name
+		response.Meta.CostUSD = assignment.Variant.Cost(response.Meta.PromptTokens, response.Meta.CompletionTokens)
+		_ = s.experiments.RecordGeneration(ctx, assignment, response.ID, processingTime, &response.Meta, nil)
+	}
+
+	reportProgress(ctx, StageVerifying)
+	s.verifyQuiz(ctx, client, aiReq, response)
+	return response, nil
+}
+
 func (s *Service) GetResult(ctx context.Context, id string) (*domain.ProcessResponse, error) {
 	stored, err := s.store.Get(ctx, id)
 	if err != nil {
@@ -108,13 +263,44 @@ func (s *Service) GetResult(ctx context.Context, id string) (*domain.ProcessResp
 	if err := json.Unmarshal(stored.ResponseJSON, &response); err != nil {
 		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
 	}
+	response.Status = stored.Status
 
 	return &response, nil
 }
 
-func (s *Service) validateRequest(req *domain.ProcessRequest) error {
-	if req.Text == "" {
-		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "text is required", nil)
+// RecordOutcome attaches a learner outcome (feedback or quiz score in the
+// 0.0-1.0 range) to the experiment variant that generated the result.
+// Results generated outside any experiment are accepted and ignored.
+func (s *Service) RecordOutcome(ctx context.Context, id, kind string, score float64) error {
+	if kind != domain.OutcomeFeedback && kind != domain.OutcomeQuizScore {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "kind must be one of: feedback, quiz_score", nil)
+	}
+	if score < 0 || score > 1 {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "score must be between 0.0 and 1.0", nil)
+	}
+
+	stored, err := s.store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.experiments.RecordOutcome(ctx, stored.Experiment, stored.Variant, stored.ID, kind, score)
+}
+
+// ValidateRequest checks a request without processing it, so queued
+// requests can be rejected up front.
+func (s *Service) ValidateRequest(req *domain.ProcessRequest) error {
+	if err := validateDocumentID(req); err != nil {
+		return err
+	}
+	if err := validateSources(req); err != nil {
+		return err
+	}
+	if req.Text == "" && req.SourceURL == "" && len(req.Sources) == 0 {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "text, source_url or sources is required", nil)
+	}
+	if req.Text != "" && req.SourceURL != "" {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "provide either text or source_url, not both", nil)
 	}
 
 	if !domain.ValidateMode(req.Mode) {
@@ -125,21 +311,32 @@ func (s *Service) validateRequest(req *domain.ProcessRequest) error {
 		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "level must be one of: beginner, intermediate, advanced", nil)
 	}
 
+	if req.CallbackURL != "" {
+		if err := s.webhooks.ValidateCallback(req.CallbackURL); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (s *Service) generateID(req *domain.ProcessRequest) string {
-	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" {
-		hash := sha256.Sum256([]byte(*req.IdempotencyKey))
-		return hex.EncodeToString(hash[:])[:16]
+	if req.DocumentID != "" {
+		return documentResultID(req)
 	}
 	return uuid.New().String()
 }
 
-func (s *Service) getByIdempotencyKey(ctx context.Context, key string) (*domain.ProcessResponse, error) {
-	hash := sha256.Sum256([]byte(key))
-	id := hex.EncodeToString(hash[:])[:16]
-	return s.GetResult(ctx, id)
+// experimentSubject keeps experiment assignment sticky per user, falling
+// back to the caller's API key.
+func experimentSubject(req *domain.ProcessRequest) string {
+	if req.UserID != "" {
+		return "user:" + req.UserID
+	}
+	if hash := req.KeyHash(); hash != "" {
+		return "key:" + hash
+	}
+	return ""
 }
 
 func (s *Service) saveResult(ctx context.Context, req *domain.ProcessRequest, resp *domain.ProcessResponse) error {
@@ -148,6 +345,8 @@ func (s *Service) saveResult(ctx context.Context, req *domain.ProcessRequest, re
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	// The review status is stored with the result, not its content.
+	resp.Status = ""
 	responseJSON, err := json.Marshal(resp)
 	if err != nil {
 		return fmt.Errorf("failed to marshal response: %w", err)
@@ -160,8 +359,22 @@ func (s *Service) saveResult(ctx context.Context, req *domain.ProcessRequest, re
 		Topic:           resp.Topic,
 		TopicSource:     resp.TopicSource,
 		TopicConfidence: resp.TopicConfidence,
+		Experiment:      resp.Meta.Experiment,
+		Variant:         resp.Meta.Variant,
+		Status:          domain.ReviewDraft,
+		Mode:            modeOrDefault(req.Mode),
+		Level:           deref(req.Level),
+		Language:        languageOrDefault(req.Language),
+		Provider:        resp.Meta.Provider,
 		CreatedAt:       resp.CreatedAt,
 	}
 
-	return s.store.Save(ctx, stored)
+	prevStatus := s.previousStatus(ctx, req, resp.ID)
+	if err := s.store.Save(ctx, stored); err != nil {
+		return err
+	}
+	if s.reopenResubmitted(ctx, resp.ID, prevStatus) {
+		resp.Status = domain.ReviewDraft
+	}
+	return nil
 }
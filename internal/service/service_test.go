@@ -2,11 +2,21 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"learnforge/internal/ai"
+	"learnforge/internal/cache"
 	"learnforge/internal/domain"
+	"learnforge/internal/experiment"
+	"learnforge/internal/ingest"
+	"learnforge/internal/store"
+	"learnforge/internal/verify"
+	"learnforge/internal/webhook"
 )
 
 // mockAI is a mock AI client for testing
@@ -32,6 +42,10 @@ func (m *mockAI) ProcessText(ctx context.Context, req *ai.ProcessRequest) (*doma
 	}, nil
 }
 
+func (m *mockAI) GenerateMeme(ctx context.Context, topic, question string) (string, error) {
+	return "", nil
+}
+
 // mockStore is a mock store for testing
 type mockStore struct {
 	saveFunc func(ctx context.Context, result *domain.StoredResult) error
@@ -60,6 +74,10 @@ func (m *mockStore) GetByDateRange(ctx context.Context, start, end time.Time) ([
 	return nil, nil
 }
 
+func (m *mockStore) ListResults(ctx context.Context, query domain.ResultQuery) ([]*domain.StoredResult, error) {
+	return nil, nil
+}
+
 func (m *mockStore) Close() error {
 	return nil
 }
@@ -127,6 +145,536 @@ func TestService_ProcessText_Defaults(t *testing.T) {
 	}
 }
 
+func TestService_ProcessText_Experiment(t *testing.T) {
+	variantAI := &mockAI{
+		processFunc: func(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+			if req.PromptVersion != "v2" || req.Instructions != "Be concise." {
+				t.Errorf("unexpected prompt settings: %q %q", req.PromptVersion, req.Instructions)
+			}
+			return &domain.ProcessResponse{
+				Topic: "variant",
+				Meta:  domain.Meta{Model: "variant-model", PromptTokens: 1000, CompletionTokens: 2000},
+			}, nil
+		},
+	}
+
+	st := store.NewInMemStore()
+	manager := experiment.NewManager(st, []*experiment.Experiment{{
+		Name: "prompt-test",
+		Variants: []*experiment.Variant{{
+			Name:            "treatment",
+			Percent:         100,
+			PromptVersion:   "v2",
+			Instructions:    "Be concise.",
+			InputCostPer1K:  0.01,
+			OutputCostPer1K: 0.02,
+			Client:          variantAI,
+		}},
+	}})
+	svc := NewService(st, &mockAI{}, WithExperiments(manager))
+
+	ctx := context.Background()
+	resp, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: "test text", UserID: "learner-1"})
+	if err != nil {
+		t.Fatalf("ProcessText() error = %v", err)
+	}
+
+	if resp.Meta.Experiment != "prompt-test" || resp.Meta.Variant != "treatment" {
+		t.Errorf("Expected variant in meta, got %+v", resp.Meta)
+	}
+	if resp.Meta.CostUSD != 0.05 {
+		t.Errorf("Expected cost 0.05, got %v", resp.Meta.CostUSD)
+	}
+
+	stored, err := st.Get(ctx, resp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get stored result: %v", err)
+	}
+	if stored.Variant != "treatment" {
+		t.Errorf("Expected stored variant treatment, got %q", stored.Variant)
+	}
+
+	if err := svc.RecordOutcome(ctx, resp.ID, "quiz_score", 0.8); err != nil {
+		t.Fatalf("RecordOutcome() error = %v", err)
+	}
+
+	report, err := manager.Report(ctx, "prompt-test")
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if len(report.Variants) != 1 || report.Variants[0].Requests != 1 || report.Variants[0].AvgQuizScore != 0.8 {
+		t.Errorf("Unexpected report: %+v", report.Variants)
+	}
+}
+
+type stubCompleter struct {
+	replies []string
+}
+
+func (s *stubCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply, nil
+}
+
+func TestService_ProcessText_VerifyRegenerate(t *testing.T) {
+	calls := 0
+	aiClient := &mockAI{
+		processFunc: func(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+			calls++
+			if calls == 1 {
+				return &domain.ProcessResponse{Quiz: []domain.QuizItem{
+					{Q: "Good?", Choices: []string{"Yes", "No"}, Answer: "Yes"},
+					{Q: "Wrong?", Choices: []string{"Yes", "No"}, Answer: "Yes"},
+				}}, nil
+			}
+			if req.Mode != "quiz" {
+				t.Errorf("Expected regeneration in quiz mode, got %q", req.Mode)
+			}
+			return &domain.ProcessResponse{Quiz: []domain.QuizItem{
+				{Q: "Replacement?", Choices: []string{"A", "B"}, Answer: "B"},
+			}}, nil
+		},
+	}
+
+	completer := &stubCompleter{replies: []string{
+		`{"items": [{"index": 0, "answer": "Yes", "defensible": ["Yes"]}, {"index": 1, "answer": "No", "defensible": ["No"]}]}`,
+		`{"items": [{"index": 0, "answer": "B", "defensible": ["B"]}]}`,
+	}}
+
+	svc := NewService(&mockStore{}, aiClient, WithVerifier(verify.NewVerifier(completer, verify.ActionRegenerate)))
+	resp, err := svc.ProcessText(context.Background(), &domain.ProcessRequest{Text: "source"})
+	if err != nil {
+		t.Fatalf("ProcessText() error = %v", err)
+	}
+
+	if len(resp.Quiz) != 2 || resp.Quiz[1].Q != "Replacement?" {
+		t.Errorf("Expected flagged item to be replaced, got %+v", resp.Quiz)
+	}
+	v := resp.Meta.Verification
+	if v == nil || v.Flagged != 1 || v.Regenerated != 1 || v.Dropped != 0 {
+		t.Errorf("Unexpected verification summary: %+v", v)
+	}
+}
+
+func TestService_ProcessUpload(t *testing.T) {
+	var gotText string
+	aiClient := &mockAI{processFunc: func(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+		gotText = req.Text
+		return &domain.ProcessResponse{Topic: "cells", CreatedAt: time.Now()}, nil
+	}}
+	svc := NewService(&mockStore{}, aiClient)
+
+	upload := &ingest.Upload{FileName: "uploads/cells.md", Data: []byte("# Cells\n\nCells are the unit of life.")}
+	resp, err := svc.ProcessUpload(context.Background(), &domain.ProcessRequest{}, upload)
+	if err != nil {
+		t.Fatalf("ProcessUpload: %v", err)
+	}
+	if gotText != "# Cells\n\nCells are the unit of life." {
+		t.Errorf("text sent to AI = %q", gotText)
+	}
+	if resp.Source == nil || resp.Source.FileName != "cells.md" || resp.Source.MimeType != "text/markdown" || len(resp.Source.FileSHA256) != 64 {
+		t.Errorf("unexpected source: %+v", resp.Source)
+	}
+
+	_, err = svc.ProcessUpload(context.Background(), &domain.ProcessRequest{Text: "also text"}, upload)
+	if err == nil {
+		t.Error("expected error when combining text with an upload")
+	}
+}
+
+func TestService_ProcessText_DocumentRevision(t *testing.T) {
+	// The mock writes one flashcard per "##" section of the text it gets.
+	var sent []string
+	aiClient := &mockAI{processFunc: func(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+		sent = append(sent, req.Text)
+		resp := &domain.ProcessResponse{Summary: "Summary", CreatedAt: time.Now()}
+		for _, part := range strings.Split(req.Text, "## ")[1:] {
+			title, body, _ := strings.Cut(part, "\n\n")
+			resp.Flashcards = append(resp.Flashcards, domain.Flashcard{Q: "What about " + title + "?", A: strings.TrimSpace(body)})
+		}
+		return resp, nil
+	}}
+	svc := NewService(store.NewInMemStore(), aiClient)
+	ctx := context.Background()
+
+	v1 := "## Raft\n\nRaft elects a leader using randomized timeouts.\n\n" +
+		"## Paxos\n\nPaxos reaches consensus through proposers and acceptors.\n\n" +
+		"## Gossip\n\nGossip spreads membership updates between peers."
+	first, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: v1, DocumentID: "distributed-systems"})
+	if err != nil {
+		t.Fatalf("ProcessText() error = %v", err)
+	}
+	if first.Revision != 1 || len(first.Flashcards) != 3 {
+		t.Fatalf("first revision = %d with %d flashcards", first.Revision, len(first.Flashcards))
+	}
+	for _, card := range first.Flashcards {
+		if card.ID == "" || card.Ref == nil || card.Ref.SectionID == "" {
+			t.Errorf("flashcard missing id or section: %+v", card)
+		}
+	}
+
+	v2 := strings.Replace(v1, "randomized timeouts", "randomized election timeouts and heartbeats", 1)
+	second, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: v2, DocumentID: "distributed-systems"})
+	if err != nil {
+		t.Fatalf("ProcessText() error = %v", err)
+	}
+	if last := sent[len(sent)-1]; strings.Contains(last, "Paxos") || !strings.Contains(last, "heartbeats") {
+		t.Errorf("expected only the edited section to be regenerated, sent %q", last)
+	}
+	if second.ID != first.ID || second.Revision != 2 || len(second.Flashcards) != 3 {
+		t.Fatalf("second = id %s revision %d with %d flashcards", second.ID, second.Revision, len(second.Flashcards))
+	}
+	if second.Flashcards[0].ID == first.Flashcards[0].ID || !strings.Contains(second.Flashcards[0].A, "heartbeats") {
+		t.Errorf("edited section should get a new flashcard: %+v", second.Flashcards[0])
+	}
+	for i := 1; i < 3; i++ {
+		if second.Flashcards[i].ID != first.Flashcards[i].ID {
+			t.Errorf("unchanged flashcard %d changed id: %s -> %s", i, first.Flashcards[i].ID, second.Flashcards[i].ID)
+		}
+	}
+	if d := second.Meta.Diff; d == nil || d.ChangedSections != 1 || d.KeptItems != 2 || d.RegeneratedItems != 1 || d.FullRegeneration {
+		t.Errorf("unexpected diff: %+v", d)
+	}
+
+	calls := len(sent)
+	third, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: v2, DocumentID: "distributed-systems"})
+	if err != nil || len(sent) != calls || third.Revision != 2 {
+		t.Errorf("identical resubmission should return the stored result without generating: %v", err)
+	}
+
+	_, err = svc.ProcessText(ctx, &domain.ProcessRequest{Text: v2, DocumentID: "distributed-systems", IdempotencyKey: stringPtr("k")})
+	if err == nil {
+		t.Error("expected error when combining document_id with idempotency_key")
+	}
+}
+
+func TestService_ProcessText_DocumentsScopedToCaller(t *testing.T) {
+	st := store.NewInMemStore()
+	svc := NewService(st, &mockAI{})
+	ctx := context.Background()
+
+	ana, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: "## Raft\n\nRaft elects a leader.", DocumentID: "notes", APIKey: "key-a"})
+	if err != nil {
+		t.Fatalf("ProcessText() error = %v", err)
+	}
+	ben, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: "## Paxos\n\nPaxos uses acceptors.", DocumentID: "notes", UserID: "ben"})
+	if err != nil {
+		t.Fatalf("ProcessText() error = %v", err)
+	}
+	if ana.ID == ben.ID || ben.Revision != 1 {
+		t.Fatalf("callers with the same document_id share result %s, revision %d", ben.ID, ben.Revision)
+	}
+	stored, err := st.Get(ctx, ana.ID)
+	if err != nil || !strings.Contains(string(stored.RequestJSON), "Raft") {
+		t.Errorf("the first caller's document was replaced: %v", err)
+	}
+
+	again, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: "## Raft\n\nRaft elects a leader.", DocumentID: "notes", APIKey: "key-a"})
+	if err != nil || again.ID != ana.ID {
+		t.Errorf("resubmission by the same caller = %+v, %v, want result %s", again, err, ana.ID)
+	}
+}
+
+func TestService_ProcessText_FailureCallback(t *testing.T) {
+	st := store.NewInMemStore()
+	upstream := domain.NewDomainError(domain.ErrorCodeUpstreamError, "model unavailable", nil)
+	aiClient := &mockAI{processFunc: func(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+		return nil, upstream
+	}}
+	svc := NewService(st, aiClient, WithWebhooks(webhook.NewDispatcher(st, webhook.Options{Secret: "s"})))
+	ctx := context.Background()
+
+	req := &domain.ProcessRequest{Text: "Raft elects a leader.", CallbackURL: "https://example.com/hook"}
+	if _, err := svc.ProcessText(ctx, req); err == nil {
+		t.Fatal("ProcessText() succeeded, want the model error")
+	}
+	// Jobs are retried, and the pool reports them once they give up.
+	if _, err := svc.ProcessText(WithJobID(ctx, "job-1"), req); err == nil {
+		t.Fatal("ProcessText() in a job succeeded, want the model error")
+	}
+
+	deliveries, err := st.ListDeliveries(ctx, "", domain.DeliveryPending, 0)
+	if err != nil || len(deliveries) != 1 || deliveries[0].EventType != domain.EventResultFailed {
+		t.Fatalf("deliveries = %+v, %v, want one result.failed callback", deliveries, err)
+	}
+	var event domain.WebhookEvent
+	if err := json.Unmarshal(deliveries[0].Payload, &event); err != nil || event.Data.Error == nil || event.Data.Error.Code != domain.ErrorCodeUpstreamError {
+		t.Errorf("unexpected payload %s: %v", deliveries[0].Payload, err)
+	}
+}
+
+func TestService_ProcessText_Sources(t *testing.T) {
+	var got *ai.ProcessRequest
+	aiClient := &mockAI{processFunc: func(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+		got = req
+		return &domain.ProcessResponse{
+			KeyPoints: []string{
+				"HTTP/2 multiplexes streams over one connection",
+				"HTTP/2 multiplexes many streams over a single connection",
+				"HTTP/3 runs over QUIC instead of TCP",
+			},
+			Flashcards: []domain.Flashcard{
+				{Q: "What transport does HTTP/3 use?", A: "QUIC over UDP"},
+				{Q: "How does HTTP/2 send requests concurrently?", A: "By multiplexing streams on one TCP connection"},
+			},
+			CreatedAt: time.Now(),
+		}, nil
+	}}
+	svc := NewService(&mockStore{}, aiClient)
+
+	resp, err := svc.ProcessText(context.Background(), &domain.ProcessRequest{Sources: []domain.SourceDocument{
+		{Title: "RFC 9113", Text: "HTTP/2 multiplexes concurrent streams over a single TCP connection."},
+		{Text: "HTTP/3 maps HTTP semantics onto QUIC, which runs over UDP."},
+	}})
+	if err != nil {
+		t.Fatalf("ProcessText() error = %v", err)
+	}
+
+	if !strings.HasPrefix(got.Text, "## [1] RFC 9113\n\n") || !strings.Contains(got.Text, "## [2] Source 2\n\n") {
+		t.Errorf("unexpected combined text: %q", got.Text)
+	}
+	if !strings.Contains(got.Instructions, "2 sources") {
+		t.Errorf("expected synthesis instructions, got %q", got.Instructions)
+	}
+	if len(resp.KeyPoints) != 2 {
+		t.Errorf("expected the repeated key point to be removed, got %v", resp.KeyPoints)
+	}
+	if len(resp.Sources) != 2 || resp.Sources[1].Title != "Source 2" {
+		t.Errorf("unexpected sources: %+v", resp.Sources)
+	}
+	if ref := resp.Flashcards[0].Ref; ref == nil || ref.SourceIndex != 2 {
+		t.Errorf("HTTP/3 flashcard should cite source 2, got %+v", ref)
+	}
+	if ref := resp.Flashcards[1].Ref; ref == nil || ref.SourceIndex != 1 || ref.Source != "RFC 9113" {
+		t.Errorf("HTTP/2 flashcard should cite source 1, got %+v", ref)
+	}
+
+	_, err = svc.ProcessText(context.Background(), &domain.ProcessRequest{Sources: []domain.SourceDocument{{Text: "only one"}}})
+	if err == nil {
+		t.Error("expected error for a single source")
+	}
+	_, err = svc.ProcessText(context.Background(), &domain.ProcessRequest{Text: "also text", Sources: []domain.SourceDocument{{Text: "a"}, {Text: "b"}}})
+	if err == nil {
+		t.Error("expected error when combining sources with text")
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestService_ProcessText_Idempotency(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	release := make(chan struct{})
+	aiClient := &mockAI{processFunc: func(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		<-release
+		return &domain.ProcessResponse{Summary: "Summary", CreatedAt: time.Now()}, nil
+	}}
+	svc := NewService(store.NewInMemStore(), aiClient)
+	ctx := context.Background()
+	newReq := func(apiKey, text string) *domain.ProcessRequest {
+		return &domain.ProcessRequest{Text: text, IdempotencyKey: stringPtr("onboarding-1"), APIKey: apiKey}
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*domain.ProcessResponse, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := svc.ProcessText(ctx, newReq("team-a", "Welcome aboard"))
+			if err != nil {
+				t.Errorf("ProcessText() error = %v", err)
+			}
+			results[i] = resp
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("expected concurrent duplicates to share one generation, got %d calls", calls)
+	}
+	for _, resp := range results[1:] {
+		if resp == nil || results[0] == nil || resp.ID != results[0].ID {
+			t.Fatalf("expected the same result for every duplicate")
+		}
+	}
+
+	again, err := svc.ProcessText(ctx, newReq("team-a", "Welcome aboard"))
+	if err != nil || again.ID != results[0].ID || calls != 1 {
+		t.Errorf("expected a retry to return the stored result, got %v", err)
+	}
+
+	_, err = svc.ProcessText(ctx, newReq("team-a", "Something else"))
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeConflict {
+		t.Errorf("expected conflict for a reused key with a different body, got %v", err)
+	}
+
+	other, err := svc.ProcessText(ctx, newReq("team-b", "Welcome aboard"))
+	if err != nil || other.ID == results[0].ID || calls != 2 {
+		t.Errorf("keys must be scoped per API key: %v", err)
+	}
+}
+
+// flakyStore fails the next failSaves saves.
+type flakyStore struct {
+	*store.InMemStore
+	failSaves int
+}
+
+func (f *flakyStore) Save(ctx context.Context, result *domain.StoredResult) error {
+	if f.failSaves > 0 {
+		f.failSaves--
+		return errors.New("connection reset")
+	}
+	return f.InMemStore.Save(ctx, result)
+}
+
+func TestService_ProcessText_IdempotencyReleasesUnsavedResults(t *testing.T) {
+	st := &flakyStore{InMemStore: store.NewInMemStore(), failSaves: 1}
+	svc := NewService(st, &mockAI{})
+	ctx := context.Background()
+	req := func() *domain.ProcessRequest {
+		return &domain.ProcessRequest{Text: "Welcome aboard", IdempotencyKey: stringPtr("onboarding-1")}
+	}
+
+	if _, err := svc.ProcessText(ctx, req()); err == nil {
+		t.Fatal("expected an error when the result could not be saved")
+	}
+	resp, err := svc.ProcessText(ctx, req())
+	if err != nil {
+		t.Fatalf("expected the retry to generate again, got %v", err)
+	}
+	again, err := svc.ProcessText(ctx, req())
+	if err != nil || again.ID != resp.ID {
+		t.Errorf("expected the stored result for the completed key, got %v", err)
+	}
+}
+
+func TestService_ProcessText_GenerationCache(t *testing.T) {
+	calls := 0
+	aiClient := &mockAI{processFunc: func(ctx context.Context, req *ai.ProcessRequest) (*domain.ProcessResponse, error) {
+		calls++
+		return &domain.ProcessResponse{Summary: "Summary", Meta: domain.Meta{CostUSD: 0.01, PromptTokens: 900, CompletionTokens: 300}, CreatedAt: time.Now()}, nil
+	}}
+	svc := NewService(&mockStore{}, aiClient, WithGenerationCache(cache.NewInMemCache(), CacheTTLs{Default: time.Hour}))
+	ctx := context.Background()
+
+	first, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: "Welcome to the team.\nRead the handbook."})
+	if err != nil || first.Meta.Cache != CacheMiss {
+		t.Fatalf("expected a miss, got %+v, %v", first.Meta, err)
+	}
+	second, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: "  Welcome to the team. Read  the handbook. "})
+	if err != nil || second.Meta.Cache != CacheHit || calls != 1 {
+		t.Fatalf("expected whitespace-only changes to hit the cache, got %+v after %d calls", second.Meta, calls)
+	}
+	if second.ID == first.ID || second.Summary != first.Summary || second.Meta.CostUSD != 0 || second.Meta.PromptTokens+second.Meta.CompletionTokens != 0 {
+		t.Errorf("a hit should be a new, free result with the cached content: %+v", second)
+	}
+	if !second.CreatedAt.After(first.CreatedAt) {
+		t.Errorf("a hit should be created now, got %v after %v", second.CreatedAt, first.CreatedAt)
+	}
+
+	bypassed, _ := svc.ProcessText(WithoutCache(ctx), &domain.ProcessRequest{Text: "Welcome to the team. Read the handbook."})
+	if bypassed.Meta.Cache != CacheBypass || calls != 2 {
+		t.Errorf("expected no-cache to call the model, got %s after %d calls", bypassed.Meta.Cache, calls)
+	}
+
+	other, _ := svc.ProcessText(ctx, &domain.ProcessRequest{Text: "Welcome to the team. Read the handbook.", Level: stringPtr("advanced")})
+	if other.Meta.Cache != CacheMiss || calls != 3 {
+		t.Errorf("a different level must not share the cached result, got %s", other.Meta.Cache)
+	}
+}
+
+func TestService_ReviewVisibility(t *testing.T) {
+	st := store.NewInMemStore()
+	svc := NewService(st, &mockAI{}, WithReviewRequired(true))
+	ctx := context.Background()
+
+	resp, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: "## Raft\n\nRaft elects a leader.", DocumentID: "raft"})
+	if err != nil {
+		t.Fatalf("ProcessText() error = %v", err)
+	}
+	if resp.Status != domain.ReviewDraft {
+		t.Errorf("new results should be drafts, got %q", resp.Status)
+	}
+	if _, err := svc.GetPublishedResult(ctx, resp.ID); err == nil {
+		t.Error("expected drafts to be hidden from learners")
+	}
+
+	for _, step := range [][2]string{{domain.ReviewDraft, domain.ReviewInReview}, {domain.ReviewInReview, domain.ReviewPublished}} {
+		if err := st.AddReviewEvent(ctx, &domain.ReviewEvent{ID: step[1], ResultID: resp.ID, From: step[0], To: step[1], Actor: "ana"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	published, err := svc.GetPublishedResult(ctx, resp.ID)
+	if err != nil || published.Status != domain.ReviewPublished {
+		t.Fatalf("GetPublishedResult() = %+v, %v", published, err)
+	}
+
+	// Regenerated content has not been reviewed.
+	resp, err = svc.ProcessText(ctx, &domain.ProcessRequest{Text: "## Raft\n\nRaft elects a leader with timeouts.", DocumentID: "raft"})
+	if err != nil {
+		t.Fatalf("resubmit error = %v", err)
+	}
+	if resp.Status != domain.ReviewDraft {
+		t.Errorf("resubmitted documents should return to draft, got %q", resp.Status)
+	}
+	if _, err := svc.GetPublishedResult(ctx, resp.ID); err == nil {
+		t.Error("expected the resubmitted document to be hidden until reviewed again")
+	}
+	events, _ := st.ListReviewEvents(ctx, resp.ID)
+	if len(events) != 3 || events[2].Action != domain.ReviewReopen || events[2].Actor != reviewActor {
+		t.Errorf("expected a reopen event in the audit trail, got %+v", events)
+	}
+}
+
+func TestService_ListResults(t *testing.T) {
+	svc := NewService(store.NewInMemStore(), &mockAI{}, WithReviewRequired(true))
+	ctx := context.Background()
+
+	for _, text := range []string{"first text", "second text", "third text"} {
+		if _, err := svc.ProcessText(ctx, &domain.ProcessRequest{Text: text, Mode: "quiz"}); err != nil {
+			t.Fatalf("ProcessText() error = %v", err)
+		}
+	}
+
+	page, err := svc.ListResults(ctx, domain.ResultQuery{Mode: "quiz", Limit: 2}, "")
+	if err != nil {
+		t.Fatalf("ListResults() error = %v", err)
+	}
+	if len(page.Results) != 2 || page.NextCursor == "" {
+		t.Fatalf("first page = %d results, cursor %q", len(page.Results), page.NextCursor)
+	}
+	if r := page.Results[0]; r.Mode != "quiz" || r.Language != "en" || r.Provider != "test" || r.QuizItems != 1 || r.Status != domain.ReviewDraft {
+		t.Errorf("unexpected overview: %+v", r)
+	}
+
+	next, err := svc.ListResults(ctx, domain.ResultQuery{Mode: "quiz", Limit: 2}, page.NextCursor)
+	if err != nil {
+		t.Fatalf("ListResults() next page error = %v", err)
+	}
+	if len(next.Results) != 1 || next.NextCursor != "" || next.Results[0].ID == page.Results[1].ID {
+		t.Errorf("second page = %+v", next)
+	}
+
+	if _, err := svc.ListResults(ctx, domain.ResultQuery{Sort: domain.SortOldest}, page.NextCursor); err == nil {
+		t.Error("expected a cursor from another sort order to be rejected")
+	}
+	if _, err := svc.ListResults(ctx, domain.ResultQuery{Sort: domain.SortRelevance}, ""); err == nil {
+		t.Error("expected relevance without a search query to be rejected")
+	}
+
+	published, err := svc.ListPublishedResults(ctx, domain.ResultQuery{}, "")
+	if err != nil || len(published.Results) != 0 {
+		t.Errorf("learners should not see drafts: %+v, %v", published, err)
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"learnforge/internal/docdiff"
+	"learnforge/internal/domain"
+	"learnforge/internal/textutil"
+)
+
+const maxSources = 10
+
+// duplicateSimilarity is the word overlap above which two key points or
+// questions drawn from different sources are treated as the same.
+const duplicateSimilarity = 0.6
+
+// sourceText is a resolved request source.
+type sourceText struct {
+	info domain.SourceInfo
+	text string
+}
+
+func validateSources(req *domain.ProcessRequest) error {
+	if len(req.Sources) == 0 {
+		return nil
+	}
+	if req.Text != "" || req.SourceURL != "" {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "sources cannot be combined with text or source_url", nil)
+	}
+	if len(req.Sources) < 2 || len(req.Sources) > maxSources {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, fmt.Sprintf("sources must contain between 2 and %d entries", maxSources), nil)
+	}
+	for i, src := range req.Sources {
+		if (strings.TrimSpace(src.Text) == "") == (src.URL == "") {
+			return domain.NewDomainError(domain.ErrorCodeInvalidArgument, fmt.Sprintf("sources[%d] needs either text or url", i), nil)
+		}
+	}
+	return nil
+}
+
+// resolveSources fetches URL sources and replaces req.Text with all
+// sources, each under a numbered heading the model can refer to.
+func (s *Service) resolveSources(ctx context.Context, req *domain.ProcessRequest) ([]sourceText, error) {
+	if len(req.Sources) == 0 {
+		return nil, nil
+	}
+
+	sources := make([]sourceText, len(req.Sources))
+	for i, src := range req.Sources {
+		st := sourceText{info: domain.SourceInfo{Title: strings.TrimSpace(src.Title)}, text: strings.TrimSpace(src.Text)}
+		if src.URL != "" {
+			if s.fetcher == nil {
+				return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "source_url ingestion is not enabled", nil)
+			}
+			doc, page, err := s.fetcher.FetchDocument(ctx, src.URL)
+			if err != nil {
+				return nil, domain.NewDomainError(domain.CodeOf(err), fmt.Sprintf("sources[%d]: %s", i, domain.Message(err)), err)
+			}
+			fetchedAt := page.FetchedAt
+			st.text = doc.Text
+			st.info.URL = src.URL
+			st.info.CanonicalURL = doc.CanonicalURL
+			st.info.FetchedAt = &fetchedAt
+			if st.info.Title == "" {
+				st.info.Title = doc.Title
+			}
+			if st.info.Title == "" {
+				if u, err := url.Parse(src.URL); err == nil {
+					st.info.Title = u.Host
+				}
+			}
+		}
+		if st.info.Title == "" {
+			st.info.Title = "Source " + strconv.Itoa(i+1)
+		}
+		sources[i] = st
+	}
+
+	parts := make([]string, len(sources))
+	for i, src := range sources {
+		parts[i] = fmt.Sprintf("## [%d] %s\n\n%s", i+1, src.info.Title, src.text)
+	}
+	req.Text = strings.Join(parts, "\n\n")
+	return sources, nil
+}
+
+func synthesisInstructions(sources []sourceText) string {
+	return fmt.Sprintf("The text combines %d sources, each under a heading numbered [1] to [%d]. "+
+		"Write one lesson that reconciles them: a single summary that states where the sources agree and where they differ, "+
+		"key points without repeating the same fact for each source, and flashcards and quiz questions that are each "+
+		"answerable from one source. Do not treat the sources as one continuous document.", len(sources), len(sources))
+}
+
+// citeSources attributes key points, flashcards and quiz items to the
+// source whose words they match best, and removes key points and
+// questions that repeat an earlier one.
+func citeSources(resp *domain.ProcessResponse, sources []sourceText) {
+	if len(sources) == 0 {
+		return
+	}
+	resp.Sources = make([]domain.SourceInfo, len(sources))
+	sections := make([]docdiff.Section, len(sources))
+	for i, src := range sources {
+		resp.Sources[i] = src.info
+		sections[i] = docdiff.Section{ID: strconv.Itoa(i + 1), Title: src.info.Title, Text: src.text}
+	}
+
+	cite := func(text string, ref *domain.SourceRef) *domain.SourceRef {
+		sec, ok := docdiff.Assign(text, sections)
+		if !ok {
+			return ref
+		}
+		if ref == nil {
+			ref = &domain.SourceRef{}
+		}
+		ref.Source = sec.Title
+		ref.SourceIndex, _ = strconv.Atoi(sec.ID)
+		return ref
+	}
+
+	var keyPoints []string
+	var keyPointRefs []*domain.SourceRef
+	for i, kp := range resp.KeyPoints {
+		if isDuplicate(kp, keyPoints) {
+			continue
+		}
+		keyPoints = append(keyPoints, kp)
+		keyPointRefs = append(keyPointRefs, cite(kp, refAt(resp.KeyPointRefs, i)))
+	}
+	resp.KeyPoints, resp.KeyPointRefs = keyPoints, keyPointRefs
+
+	var questions []string
+	flashcards := resp.Flashcards[:0]
+	for _, card := range resp.Flashcards {
+		if isDuplicate(card.Q, questions) {
+			continue
+		}
+		questions = append(questions, card.Q)
+		card.Ref = cite(card.Q+" "+card.A, card.Ref)
+		flashcards = append(flashcards, card)
+	}
+	resp.Flashcards = flashcards
+
+	questions = nil
+	quiz := resp.Quiz[:0]
+	for _, item := range resp.Quiz {
+		if isDuplicate(item.Q, questions) {
+			continue
+		}
+		questions = append(questions, item.Q)
+		item.Ref = cite(item.Q+" "+item.Answer, item.Ref)
+		quiz = append(quiz, item)
+	}
+	resp.Quiz = quiz
+}
+
+func isDuplicate(text string, seen []string) bool {
+	for _, s := range seen {
+		if len(textutil.ContentWords(text)) == 0 {
+			if domain.NormalizeText(text) == domain.NormalizeText(s) {
+				return true
+			}
+		} else if textutil.Jaccard(text, s) >= duplicateSimilarity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/domain"
+	"learnforge/internal/verify"
+)
+
+// WithVerifier enables the answer-checking pass over generated quiz items.
+func WithVerifier(v *verify.Verifier) Option {
+	return func(s *Service) {
+		s.verifier = v
+	}
+}
+
+// verifyQuiz checks the generated quiz against the source text and applies
+// the configured action to flagged items. Verification failures never fail
+// the request; they are reported in Meta.Verification instead.
+func (s *Service) verifyQuiz(ctx context.Context, client ai.Client, aiReq *ai.ProcessRequest, response *domain.ProcessResponse) {
+	if s.verifier == nil || len(response.Quiz) == 0 {
+		return
+	}
+
+	verification := &domain.Verification{
+		Action:  s.verifier.Action(),
+		Checked: len(response.Quiz),
+	}
+	response.Meta.Verification = verification
+
+	verifyCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	verdicts, err := s.checkQuiz(verifyCtx, aiReq.Text, response.Quiz)
+	if err != nil {
+		verification.Error = err.Error()
+		return
+	}
+
+	var kept, flagged []domain.QuizItem
+	for i, item := range response.Quiz {
+		if verdicts[i].Flagged {
+			item.NeedsReview = true
+			item.ReviewNote = verdicts[i].Note
+			flagged = append(flagged, item)
+			continue
+		}
+		kept = append(kept, item)
+	}
+	verification.Flagged = len(flagged)
+
+	switch s.verifier.Action() {
+	case verify.ActionMark:
+		return
+	case verify.ActionRegenerate:
+		replacements := s.regenerateQuiz(verifyCtx, client, aiReq, response.Quiz, len(flagged))
+		verification.Regenerated = len(replacements)
+		kept = append(kept, replacements...)
+	}
+
+	verification.Dropped = len(flagged) - verification.Regenerated
+	response.Quiz = kept
+}
+
+// regenerateQuiz asks for replacement questions and returns those that pass
+// verification, at most n.
+func (s *Service) regenerateQuiz(ctx context.Context, client ai.Client, aiReq *ai.ProcessRequest, existing []domain.QuizItem, n int) []domain.QuizItem {
+	if n == 0 {
+		return nil
+	}
+
+	var avoid []string
+	for _, item := range existing {
+		avoid = append(avoid, "- "+item.Q)
+	}
+
+	req := *aiReq
+	req.Mode = "quiz"
+	req.Instructions = strings.TrimSpace(fmt.Sprintf("%s\n\nGenerate %d new quiz questions whose answers are stated explicitly in the text "+
+		"and have exactly one correct choice. Do not repeat these questions:\n%s", aiReq.Instructions, n, strings.Join(avoid, "\n")))
+
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		return nil
+	}
+	resp, err := client.ProcessText(ctx, &req)
+	release()
+	if err != nil || len(resp.Quiz) == 0 {
+		return nil
+	}
+
+	verdicts, err := s.checkQuiz(ctx, aiReq.Text, resp.Quiz)
+	if err != nil {
+		return nil
+	}
+
+	var replacements []domain.QuizItem
+	for i, item := range resp.Quiz {
+		if len(replacements) == n {
+			break
+		}
+		if !verdicts[i].Flagged && domain.ValidateQuizItem(item) == nil {
+			replacements = append(replacements, item)
+		}
+	}
+	return replacements
+}
+
+// checkQuiz runs the verifier's model call under the shared rate limit.
+func (s *Service) checkQuiz(ctx context.Context, source string, items []domain.QuizItem) ([]verify.Verdict, error) {
+	release, err := s.limiter.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return s.verifier.Check(ctx, source, items)
+}
@@ -0,0 +1,109 @@
+package srs
+
+import (
+	"math"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+// DefaultRetention is the probability of recall FSRS schedules reviews at.
+const DefaultRetention = 0.9
+
+// fsrsWeights are the default FSRS-4.5 model parameters.
+var fsrsWeights = [17]float64{
+	0.4872, 1.4003, 3.7145, 13.8206, 5.1618, 1.2298, 0.8975, 0.031,
+	1.6474, 0.1367, 1.0461, 2.1072, 0.0793, 0.3246, 1.587, 0.2272, 2.8755,
+}
+
+const (
+	fsrsDecay  = -0.5
+	fsrsFactor = 19.0 / 81.0 // makes retrievability 0.9 after Stability days
+)
+
+// fsrsGrade maps ratings to FSRS grades 1-4.
+var fsrsGrade = map[string]float64{
+	domain.RatingAgain: 1,
+	domain.RatingHard:  2,
+	domain.RatingGood:  3,
+	domain.RatingEasy:  4,
+}
+
+// FSRS is the Free Spaced Repetition Scheduler (version 4.5). It models
+// each card's memory stability and difficulty and schedules the next
+// review for when recall probability falls to the desired retention.
+type FSRS struct {
+	retention float64
+	w         [17]float64
+}
+
+// NewFSRS returns an FSRS scheduler targeting retention, between 0 and 1
+// exclusive; higher retention means shorter intervals.
+func NewFSRS(retention float64) *FSRS {
+	if retention <= 0 || retention >= 1 {
+		retention = DefaultRetention
+	}
+	return &FSRS{retention: retention, w: fsrsWeights}
+}
+
+func (f *FSRS) Name() string {
+	return SchedulerFSRS
+}
+
+func (f *FSRS) Next(state domain.CardState, rating string, now time.Time) domain.CardState {
+	g := fsrsGrade[rating]
+	switch {
+	case state.Stability == 0 && state.Reps == 0 && state.Lapses == 0:
+		state.Stability = f.w[int(g)-1]
+		state.Difficulty = f.initialDifficulty(g)
+	default:
+		if state.Stability == 0 {
+			// Reviewed by SM-2 so far: its interval is the best estimate
+			// of how long the card is remembered.
+			state.Stability = math.Max(float64(state.IntervalDays), f.w[2])
+			state.Difficulty = f.initialDifficulty(fsrsGrade[domain.RatingGood])
+		}
+		elapsed := math.Max(now.Sub(state.LastReview).Hours()/24, 0)
+		r := math.Pow(1+fsrsFactor*elapsed/state.Stability, fsrsDecay)
+		if rating == domain.RatingAgain {
+			state.Stability = f.forgetStability(state.Difficulty, state.Stability, r)
+		} else {
+			state.Stability = f.recallStability(state.Difficulty, state.Stability, r, g)
+		}
+		state.Difficulty = f.nextDifficulty(state.Difficulty, g)
+	}
+
+	interval := state.Stability / fsrsFactor * (math.Pow(f.retention, 1/fsrsDecay) - 1)
+	advance(&state, SchedulerFSRS, rating, now, round(interval))
+	return state
+}
+
+func (f *FSRS) initialDifficulty(g float64) float64 {
+	return clampDifficulty(f.w[4] - (g-3)*f.w[5])
+}
+
+// nextDifficulty moves difficulty by the grade, reverting towards the
+// difficulty of an easy first review.
+func (f *FSRS) nextDifficulty(d, g float64) float64 {
+	next := d - f.w[6]*(g-3)
+	return clampDifficulty(f.w[7]*f.initialDifficulty(4) + (1-f.w[7])*next)
+}
+
+func (f *FSRS) recallStability(d, s, r, g float64) float64 {
+	bonus := 1.0
+	switch g {
+	case 2:
+		bonus = f.w[15]
+	case 4:
+		bonus = f.w[16]
+	}
+	return s * (1 + math.Exp(f.w[8])*(11-d)*math.Pow(s, -f.w[9])*(math.Exp(f.w[10]*(1-r))-1)*bonus)
+}
+
+func (f *FSRS) forgetStability(d, s, r float64) float64 {
+	return f.w[11] * math.Pow(d, -f.w[12]) * (math.Pow(s+1, f.w[13]) - 1) * math.Exp(f.w[14]*(1-r))
+}
+
+func clampDifficulty(d float64) float64 {
+	return math.Min(math.Max(d, 1), 10)
+}
@@ -0,0 +1,69 @@
+// Package srs schedules flashcard reviews with spaced repetition. The
+// interval after each review comes from a Scheduler; SM-2 and FSRS are
+// built in.
+package srs
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+// Scheduler names
+const (
+	SchedulerSM2  = "sm2"
+	SchedulerFSRS = "fsrs"
+)
+
+// MaxIntervalDays caps intervals of every scheduler.
+const MaxIntervalDays = 36500
+
+// Scheduler computes a card's next state from a review.
+type Scheduler interface {
+	Name() string
+	// Next returns state after a review rated rating at now. For a card's
+	// first review, state only has its identifying fields set. States
+	// written by another scheduler are converted on the fly.
+	Next(state domain.CardState, rating string, now time.Time) domain.CardState
+}
+
+// NewScheduler returns the scheduler called name, with default parameters.
+func NewScheduler(name string) (Scheduler, error) {
+	switch name {
+	case SchedulerSM2:
+		return NewSM2(), nil
+	case SchedulerFSRS, "":
+		return NewFSRS(DefaultRetention), nil
+	}
+	return nil, fmt.Errorf("unknown spaced-repetition scheduler %q (want sm2 or fsrs)", name)
+}
+
+// advance records a review of state with the bookkeeping every scheduler
+// shares and schedules it intervalDays later.
+func advance(state *domain.CardState, scheduler, rating string, now time.Time, intervalDays int) {
+	if rating == domain.RatingAgain {
+		if state.Reps > 0 {
+			state.Lapses++
+		}
+		state.Reps = 0
+	} else {
+		state.Reps++
+	}
+	if intervalDays < 1 {
+		intervalDays = 1
+	}
+	if intervalDays > MaxIntervalDays {
+		intervalDays = MaxIntervalDays
+	}
+	state.Scheduler = scheduler
+	state.IntervalDays = intervalDays
+	state.LastRating = rating
+	state.LastReview = now.UTC()
+	state.Due = now.UTC().AddDate(0, 0, intervalDays)
+}
+
+func round(days float64) int {
+	return int(math.Round(days))
+}
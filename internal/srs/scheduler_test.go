@@ -0,0 +1,84 @@
+package srs
+
+import (
+	"testing"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+// replay rates a new card with ratings, each review on the day it is due.
+func replay(s Scheduler, ratings ...string) []domain.CardState {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	state := domain.CardState{LearnerID: "ana", ResultID: "r1", CardID: "c1"}
+	var states []domain.CardState
+	for _, rating := range ratings {
+		state = s.Next(state, rating, now)
+		states = append(states, state)
+		now = state.Due
+	}
+	return states
+}
+
+func intervals(states []domain.CardState) []int {
+	var out []int
+	for _, s := range states {
+		out = append(out, s.IntervalDays)
+	}
+	return out
+}
+
+func TestSM2_Intervals(t *testing.T) {
+	states := replay(NewSM2(), domain.RatingGood, domain.RatingGood, domain.RatingGood, domain.RatingAgain, domain.RatingGood)
+	want := []int{1, 6, 15, 1, 1}
+	got := intervals(states)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("intervals = %v, want %v", got, want)
+		}
+	}
+	if last := states[len(states)-1]; last.Lapses != 1 || last.Reps != 1 || last.Ease != 2.5 {
+		t.Errorf("unexpected state after a lapse: %+v", last)
+	}
+
+	hard := replay(NewSM2(), domain.RatingHard, domain.RatingHard, domain.RatingHard)
+	if ease := hard[2].Ease; ease >= 2.5 {
+		t.Errorf("ease after hard ratings = %v, want below 2.5", ease)
+	}
+}
+
+func TestFSRS_Intervals(t *testing.T) {
+	f := NewFSRS(DefaultRetention)
+	good := intervals(replay(f, domain.RatingGood, domain.RatingGood, domain.RatingGood, domain.RatingGood))
+	for i := 1; i < len(good); i++ {
+		if good[i] <= good[i-1] {
+			t.Fatalf("intervals after good ratings = %v, want them to grow", good)
+		}
+	}
+
+	first := map[string]int{}
+	for _, rating := range []string{domain.RatingAgain, domain.RatingHard, domain.RatingGood, domain.RatingEasy} {
+		first[rating] = replay(f, rating)[0].IntervalDays
+	}
+	if !(first["again"] <= first["hard"] && first["hard"] < first["good"] && first["good"] < first["easy"]) {
+		t.Errorf("first intervals = %v, want them ordered by rating", first)
+	}
+
+	lapsed := replay(f, domain.RatingGood, domain.RatingGood, domain.RatingGood, domain.RatingAgain)
+	if last := lapsed[3]; last.IntervalDays >= lapsed[2].IntervalDays || last.Lapses != 1 {
+		t.Errorf("a lapse should shorten the interval: %+v", last)
+	}
+
+	strict := intervals(replay(NewFSRS(0.97), domain.RatingGood, domain.RatingGood, domain.RatingGood))
+	if strict[2] >= good[2] {
+		t.Errorf("higher retention should schedule sooner: %v vs %v", strict, good)
+	}
+}
+
+func TestFSRS_ContinuesSM2State(t *testing.T) {
+	sm2 := replay(NewSM2(), domain.RatingGood, domain.RatingGood)
+	next := NewFSRS(DefaultRetention).Next(sm2[1], domain.RatingGood, sm2[1].Due)
+	if next.Scheduler != SchedulerFSRS || next.Stability < 6 || next.IntervalDays <= 6 || next.Reps != 3 {
+		t.Errorf("unexpected state after switching schedulers: %+v", next)
+	}
+}
@@ -0,0 +1,313 @@
+package srs
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/feedback"
+	"learnforge/internal/store"
+)
+
+// Limits of due card listings
+const (
+	DefaultDueLimit = 50
+	MaxDueLimit     = 500
+	DefaultNewCards = 20
+)
+
+// Service tracks each learner's flashcard reviews.
+type Service struct {
+	states         store.CardStateStore
+	results        store.Store
+	decks          store.DeckStore
+	scheduler      Scheduler
+	feedback       *feedback.Service
+	reviewRequired bool
+	now            func() time.Time
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithReviewRequired hides cards of unpublished results from learners.
+func WithReviewRequired(required bool) Option {
+	return func(s *Service) {
+		s.reviewRequired = required
+	}
+}
+
+// WithFeedback leaves low-rated cards out of decks that exclude them.
+func WithFeedback(feedback *feedback.Service) Option {
+	return func(s *Service) {
+		s.feedback = feedback
+	}
+}
+
+func NewService(states store.CardStateStore, results store.Store, decks store.DeckStore, scheduler Scheduler, opts ...Option) *Service {
+	s := &Service{
+		states:    states,
+		results:   results,
+		decks:     decks,
+		scheduler: scheduler,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// DueQuery selects cards to study. Without a deck or result, only cards
+// the learner has studied before are returned; with one, up to NewCards
+// of its cards the learner has not studied yet follow the due ones.
+type DueQuery struct {
+	DeckID   string
+	ResultID string
+	Limit    int
+	NewCards int
+}
+
+// ReviewInput is a learner's rating of a card.
+type ReviewInput struct {
+	ResultID string `json:"result_id"`
+	CardID   string `json:"card_id"`
+	Rating   string `json:"rating"`
+}
+
+// Due returns the cards learnerID should study now, most overdue first.
+// learner hides cards that learners cannot see.
+func (s *Service) Due(ctx context.Context, learnerID string, q DueQuery, learner bool) ([]domain.DueCard, error) {
+	if err := requireLearner(learnerID); err != nil {
+		return nil, err
+	}
+	if q.DeckID != "" && q.ResultID != "" {
+		return nil, domain.InvalidArgument("deck_id and result_id cannot be combined")
+	}
+	switch {
+	case q.Limit == 0:
+		q.Limit = DefaultDueLimit
+	case q.Limit < 0 || q.Limit > MaxDueLimit:
+		return nil, domain.InvalidArgument("limit must be between 1 and 500")
+	}
+	if q.NewCards < 0 {
+		return nil, domain.InvalidArgument("new must not be negative")
+	}
+
+	now := s.now()
+	results := newResultCache(s, learner)
+	if q.DeckID == "" && q.ResultID == "" {
+		states, err := s.states.ListDueCardStates(ctx, learnerID, now, q.Limit)
+		if err != nil {
+			return nil, err
+		}
+		cards := make([]domain.DueCard, 0, len(states))
+		for _, state := range states {
+			card, err := results.card(ctx, state.ResultID, state.CardID)
+			if err != nil {
+				return nil, err
+			}
+			if card != nil {
+				card.State = state
+				cards = append(cards, *card)
+			}
+		}
+		return cards, nil
+	}
+
+	candidates, err := s.candidates(ctx, q, results)
+	if err != nil {
+		return nil, err
+	}
+	resultIDs := make([]string, 0, len(candidates))
+	seen := make(map[string]bool)
+	for _, card := range candidates {
+		if !seen[card.ResultID] {
+			seen[card.ResultID] = true
+			resultIDs = append(resultIDs, card.ResultID)
+		}
+	}
+	states, err := s.states.ListCardStates(ctx, learnerID, resultIDs)
+	if err != nil {
+		return nil, err
+	}
+	byCard := make(map[string]*domain.CardState, len(states))
+	for _, state := range states {
+		byCard[state.ResultID+"/"+state.CardID] = state
+	}
+
+	var due, fresh []domain.DueCard
+	for _, card := range candidates {
+		state, ok := byCard[card.ResultID+"/"+card.Flashcard.ID]
+		switch {
+		case !ok && len(fresh) < q.NewCards:
+			fresh = append(fresh, card)
+		case ok && !state.Due.After(now):
+			card.State = state
+			due = append(due, card)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool { return due[i].State.Due.Before(due[j].State.Due) })
+	cards := append(due, fresh...)
+	if len(cards) > q.Limit {
+		cards = cards[:q.Limit]
+	}
+	if cards == nil {
+		cards = []domain.DueCard{}
+	}
+	return cards, nil
+}
+
+// Review records learnerID's rating of a card and schedules its next
+// review.
+func (s *Service) Review(ctx context.Context, learnerID string, in ReviewInput, learner bool) (*domain.CardState, error) {
+	if err := requireLearner(learnerID); err != nil {
+		return nil, err
+	}
+	if in.ResultID == "" || in.CardID == "" {
+		return nil, domain.InvalidArgument("result_id and card_id are required")
+	}
+	if !domain.ValidateRating(in.Rating) {
+		return nil, domain.InvalidArgument("rating must be one of: again, hard, good, easy")
+	}
+	card, err := newResultCache(s, learner).card(ctx, in.ResultID, in.CardID)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "flashcard not found", nil)
+	}
+
+	state, err := s.states.GetCardState(ctx, learnerID, in.ResultID, in.CardID)
+	if domain.HasCode(err, domain.ErrorCodeNotFound) {
+		state, err = &domain.CardState{LearnerID: learnerID, ResultID: in.ResultID, CardID: in.CardID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	next := s.scheduler.Next(*state, in.Rating, s.now())
+	if err := s.states.SaveCardState(ctx, &next); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// candidates returns the flashcards of the deck or result q names, in
+// their order there.
+func (s *Service) candidates(ctx context.Context, q DueQuery, results *resultCache) ([]domain.DueCard, error) {
+	if q.ResultID != "" {
+		resp, err := results.load(ctx, q.ResultID)
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "result not found", nil)
+		}
+		var cards []domain.DueCard
+		for _, fc := range resp.Flashcards {
+			if fc.ID != "" {
+				cards = append(cards, domain.DueCard{ResultID: q.ResultID, Topic: resp.Topic, Flashcard: fc})
+			}
+		}
+		return cards, nil
+	}
+
+	d, err := s.decks.GetDeck(ctx, q.DeckID)
+	if err != nil {
+		return nil, err
+	}
+	lowRated, err := s.lowRated(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	var cards []domain.DueCard
+	for _, item := range d.Items {
+		if item.Kind != domain.DeckFlashcard || lowRated[feedback.Key(item.ResultID, item.Kind, item.ItemID)] {
+			continue
+		}
+		card, err := results.card(ctx, item.ResultID, item.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if card != nil {
+			cards = append(cards, *card)
+		}
+	}
+	return cards, nil
+}
+
+// lowRated returns the low-rated items of d when d excludes them.
+func (s *Service) lowRated(ctx context.Context, d *domain.Deck) (map[string]bool, error) {
+	if s.feedback == nil || !d.ExcludeLowRated {
+		return nil, nil
+	}
+	var resultIDs []string
+	seen := make(map[string]bool)
+	for _, item := range d.Items {
+		if !seen[item.ResultID] {
+			seen[item.ResultID] = true
+			resultIDs = append(resultIDs, item.ResultID)
+		}
+	}
+	return s.feedback.LowRated(ctx, resultIDs)
+}
+
+// resultCache loads each result once per call and hides results the
+// reader cannot see.
+type resultCache struct {
+	s       *Service
+	learner bool
+	loaded  map[string]*domain.ProcessResponse
+}
+
+func newResultCache(s *Service, learner bool) *resultCache {
+	return &resultCache{s: s, learner: learner, loaded: make(map[string]*domain.ProcessResponse)}
+}
+
+// load returns a result's content, or nil when it is gone or hidden.
+func (c *resultCache) load(ctx context.Context, id string) (*domain.ProcessResponse, error) {
+	if resp, ok := c.loaded[id]; ok {
+		return resp, nil
+	}
+	stored, err := c.s.results.Get(ctx, id)
+	if domain.HasCode(err, domain.ErrorCodeNotFound) {
+		c.loaded[id] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var resp *domain.ProcessResponse
+	if !c.learner || !c.s.reviewRequired || stored.Status == domain.ReviewPublished {
+		resp = &domain.ProcessResponse{}
+		if err := json.Unmarshal(stored.ResponseJSON, resp); err != nil {
+			return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to unmarshal stored result", err)
+		}
+	}
+	c.loaded[id] = resp
+	return resp, nil
+}
+
+// card returns a flashcard of a result, or nil when it is gone or hidden.
+func (c *resultCache) card(ctx context.Context, resultID, cardID string) (*domain.DueCard, error) {
+	resp, err := c.load(ctx, resultID)
+	if err != nil || resp == nil {
+		return nil, err
+	}
+	for _, fc := range resp.Flashcards {
+		if fc.ID == cardID {
+			return &domain.DueCard{ResultID: resultID, Topic: resp.Topic, Flashcard: fc}, nil
+		}
+	}
+	return nil, nil
+}
+
+func requireLearner(learnerID string) error {
+	if strings.TrimSpace(learnerID) == "" {
+		return domain.InvalidArgument("X-User-ID is required to identify the learner")
+	}
+	return nil
+}
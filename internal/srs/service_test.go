@@ -0,0 +1,73 @@
+package srs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+func TestService_DueAndReview(t *testing.T) {
+	st := store.NewInMemStore()
+	ctx := context.Background()
+	resp, _ := json.Marshal(domain.ProcessResponse{
+		Topic: "Raft",
+		Flashcards: []domain.Flashcard{
+			{ID: "c1", Q: "What does the leader replicate?", A: "Log entries"},
+			{ID: "c2", Q: "What triggers an election?", A: "A timeout"},
+			{ID: "c3", Q: "How many votes win an election?", A: "A majority"},
+		},
+	})
+	if err := st.Save(ctx, &domain.StoredResult{ID: "raft", Topic: "Raft", ResponseJSON: resp, CreatedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	s := NewService(st, st, st, NewSM2())
+	s.now = func() time.Time { return now }
+
+	cards, err := s.Due(ctx, "ana", DueQuery{ResultID: "raft", NewCards: 2}, true)
+	if err != nil {
+		t.Fatalf("Due: %v", err)
+	}
+	if len(cards) != 2 || cards[0].Flashcard.ID != "c1" || cards[0].State != nil {
+		t.Fatalf("new cards = %+v, want c1 and c2 without state", cards)
+	}
+
+	if _, err := s.Review(ctx, "ana", ReviewInput{ResultID: "raft", CardID: "c1", Rating: "meh"}, true); err == nil {
+		t.Error("expected an unknown rating to be rejected")
+	}
+	if _, err := s.Review(ctx, "ana", ReviewInput{ResultID: "raft", CardID: "gone", Rating: domain.RatingGood}, true); err == nil {
+		t.Error("expected an unknown card to be rejected")
+	}
+	for _, id := range []string{"c1", "c2"} {
+		state, err := s.Review(ctx, "ana", ReviewInput{ResultID: "raft", CardID: id, Rating: domain.RatingGood}, true)
+		if err != nil {
+			t.Fatalf("Review: %v", err)
+		}
+		if !state.Due.Equal(now.AddDate(0, 0, 1)) {
+			t.Errorf("due = %v, want a day later", state.Due)
+		}
+	}
+
+	cards, err = s.Due(ctx, "ana", DueQuery{}, true)
+	if err != nil || len(cards) != 0 {
+		t.Fatalf("nothing should be due right after studying: %+v, %v", cards, err)
+	}
+
+	now = now.AddDate(0, 0, 2)
+	cards, err = s.Due(ctx, "ana", DueQuery{}, true)
+	if err != nil || len(cards) != 2 || cards[0].State == nil || cards[0].Flashcard.A != "Log entries" {
+		t.Fatalf("due cards = %+v, %v", cards, err)
+	}
+	cards, err = s.Due(ctx, "ana", DueQuery{ResultID: "raft", NewCards: 5}, true)
+	if err != nil || len(cards) != 3 || cards[2].Flashcard.ID != "c3" || cards[2].State != nil {
+		t.Fatalf("due and new cards = %+v, %v", cards, err)
+	}
+	if cards, _ := s.Due(ctx, "ben", DueQuery{}, true); len(cards) != 0 {
+		t.Errorf("state must be per learner, ben has %d due cards", len(cards))
+	}
+}
@@ -0,0 +1,62 @@
+package srs
+
+import (
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+const (
+	sm2InitialEase = 2.5
+	sm2MinEase     = 1.3
+)
+
+// sm2Quality maps ratings to SM-2's 0-5 response quality; below 3 is a
+// failed recall.
+var sm2Quality = map[string]float64{
+	domain.RatingAgain: 1,
+	domain.RatingHard:  3,
+	domain.RatingGood:  4,
+	domain.RatingEasy:  5,
+}
+
+// SM2 is the SuperMemo-2 algorithm: intervals of 1 and 6 days, then the
+// previous interval times an ease factor that drops with hard ratings and
+// grows with easy ones.
+type SM2 struct{}
+
+func NewSM2() *SM2 {
+	return &SM2{}
+}
+
+func (s *SM2) Name() string {
+	return SchedulerSM2
+}
+
+func (s *SM2) Next(state domain.CardState, rating string, now time.Time) domain.CardState {
+	if state.Ease == 0 {
+		state.Ease = sm2InitialEase
+	}
+
+	interval := 1
+	if rating != domain.RatingAgain {
+		switch state.Reps {
+		case 0:
+			interval = 1
+		case 1:
+			interval = 6
+		default:
+			interval = round(float64(state.IntervalDays) * state.Ease)
+		}
+
+		// Failed recalls restart the repetitions but keep the ease.
+		q := sm2Quality[rating]
+		state.Ease += 0.1 - (5-q)*(0.08+(5-q)*0.02)
+		if state.Ease < sm2MinEase {
+			state.Ease = sm2MinEase
+		}
+	}
+
+	advance(&state, SchedulerSM2, rating, now, interval)
+	return state
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func (s *InMemStore) SaveAttempt(ctx context.Context, attempt *domain.Attempt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts = append(s.attempts, copyAttempt(attempt))
+	return nil
+}
+
+func (s *InMemStore) ListAttempts(ctx context.Context, learnerID string, limit int) ([]*domain.Attempt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var attempts []*domain.Attempt
+	for i := len(s.attempts) - 1; i >= 0; i-- {
+		if s.attempts[i].LearnerID != learnerID {
+			continue
+		}
+		attempts = append(attempts, copyAttempt(s.attempts[i]))
+		if limit > 0 && len(attempts) == limit {
+			break
+		}
+	}
+	return attempts, nil
+}
+
+func copyAttempt(attempt *domain.Attempt) *domain.Attempt {
+	copied := *attempt
+	copied.Answers = append([]domain.AttemptAnswer(nil), attempt.Answers...)
+	return &copied
+}
+
+func (s *InMemStore) ListResultAttempts(ctx context.Context, resultID string) ([]*domain.Attempt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var attempts []*domain.Attempt
+	for _, attempt := range s.attempts {
+		if attempt.ResultID == resultID {
+			attempts = append(attempts, copyAttempt(attempt))
+		}
+	}
+	return attempts, nil
+}
+
+func (s *InMemStore) ListAttemptedResults(ctx context.Context, from, to time.Time) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []string
+	seen := make(map[string]bool)
+	for _, attempt := range s.attempts {
+		if attempt.ResultID == "" || attempt.CreatedAt.Before(from) || !attempt.CreatedAt.Before(to) || seen[attempt.ResultID] {
+			continue
+		}
+		seen[attempt.ResultID] = true
+		ids = append(ids, attempt.ResultID)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func (s *PostgresStore) SaveAttempt(ctx context.Context, attempt *domain.Attempt) error {
+	attemptJSON, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO quiz_attempts (id, result_id, learner_id, topic, score, attempt_json, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err = s.db.ExecContext(ctx, query, attempt.ID, attempt.ResultID, attempt.LearnerID, attempt.Topic, attempt.Score, attemptJSON, attempt.CreatedAt)
+	return err
+}
+
+func (s *PostgresStore) ListAttempts(ctx context.Context, learnerID string, limit int) ([]*domain.Attempt, error) {
+	query := `
+		SELECT attempt_json FROM quiz_attempts
+		WHERE learner_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+	args := []interface{}{learnerID}
+	if limit > 0 {
+		query += " LIMIT $2"
+		args = append(args, limit)
+	}
+	return s.queryAttempts(ctx, query, args...)
+}
+
+func (s *PostgresStore) ListResultAttempts(ctx context.Context, resultID string) ([]*domain.Attempt, error) {
+	return s.queryAttempts(ctx, `
+		SELECT attempt_json FROM quiz_attempts
+		WHERE result_id = $1
+		ORDER BY created_at, id
+	`, resultID)
+}
+
+func (s *PostgresStore) ListAttemptedResults(ctx context.Context, from, to time.Time) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT DISTINCT result_id FROM quiz_attempts
+		WHERE created_at >= $1 AND created_at < $2 AND result_id <> ''
+		ORDER BY result_id
+	`, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *PostgresStore) queryAttempts(ctx context.Context, query string, args ...interface{}) ([]*domain.Attempt, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attempts []*domain.Attempt
+	for rows.Next() {
+		var attemptJSON []byte
+		if err := rows.Scan(&attemptJSON); err != nil {
+			return nil, err
+		}
+		var attempt domain.Attempt
+		if err := json.Unmarshal(attemptJSON, &attempt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, &attempt)
+	}
+	return attempts, rows.Err()
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func cardMapKey(learnerID, resultID, cardID string) string {
+	return learnerID + "\x00" + resultID + "\x00" + cardID
+}
+
+func (s *InMemStore) SaveCardState(ctx context.Context, state *domain.CardState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *state
+	s.cards[cardMapKey(state.LearnerID, state.ResultID, state.CardID)] = &stored
+	return nil
+}
+
+func (s *InMemStore) GetCardState(ctx context.Context, learnerID, resultID, cardID string) (*domain.CardState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.cards[cardMapKey(learnerID, resultID, cardID)]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "card state not found", nil)
+	}
+	copied := *state
+	return &copied, nil
+}
+
+func (s *InMemStore) ListCardStates(ctx context.Context, learnerID string, resultIDs []string) ([]*domain.CardState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(resultIDs))
+	for _, id := range resultIDs {
+		wanted[id] = true
+	}
+	var states []*domain.CardState
+	for _, state := range s.cards {
+		if state.LearnerID != learnerID || (len(wanted) > 0 && !wanted[state.ResultID]) {
+			continue
+		}
+		copied := *state
+		states = append(states, &copied)
+	}
+	sortByDue(states)
+	return states, nil
+}
+
+func (s *InMemStore) ListDueCardStates(ctx context.Context, learnerID string, due time.Time, limit int) ([]*domain.CardState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var states []*domain.CardState
+	for _, state := range s.cards {
+		if state.LearnerID != learnerID || state.Due.After(due) {
+			continue
+		}
+		copied := *state
+		states = append(states, &copied)
+	}
+	sortByDue(states)
+	if limit > 0 && len(states) > limit {
+		states = states[:limit]
+	}
+	return states, nil
+}
+
+func sortByDue(states []*domain.CardState) {
+	sort.Slice(states, func(i, j int) bool {
+		if !states[i].Due.Equal(states[j].Due) {
+			return states[i].Due.Before(states[j].Due)
+		}
+		return cardMapKey("", states[i].ResultID, states[i].CardID) < cardMapKey("", states[j].ResultID, states[j].CardID)
+	})
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"learnforge/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+func (s *PostgresStore) SaveCardState(ctx context.Context, state *domain.CardState) error {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO card_states (learner_id, result_id, card_id, due, state_json, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (learner_id, result_id, card_id) DO UPDATE SET
+			due = EXCLUDED.due,
+			state_json = EXCLUDED.state_json,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = s.db.ExecContext(ctx, query, state.LearnerID, state.ResultID, state.CardID, state.Due, stateJSON)
+	return err
+}
+
+func (s *PostgresStore) GetCardState(ctx context.Context, learnerID, resultID, cardID string) (*domain.CardState, error) {
+	var stateJSON []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT state_json FROM card_states
+		WHERE learner_id = $1 AND result_id = $2 AND card_id = $3
+	`, learnerID, resultID, cardID).Scan(&stateJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "card state not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state domain.CardState
+	if err := json.Unmarshal(stateJSON, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (s *PostgresStore) ListCardStates(ctx context.Context, learnerID string, resultIDs []string) ([]*domain.CardState, error) {
+	if resultIDs == nil {
+		resultIDs = []string{}
+	}
+	return s.queryCardStates(ctx, `
+		SELECT state_json FROM card_states
+		WHERE learner_id = $1 AND (cardinality($2::TEXT[]) = 0 OR result_id = ANY($2))
+		ORDER BY due, result_id, card_id
+	`, learnerID, pq.Array(resultIDs))
+}
+
+func (s *PostgresStore) ListDueCardStates(ctx context.Context, learnerID string, due time.Time, limit int) ([]*domain.CardState, error) {
+	return s.queryCardStates(ctx, `
+		SELECT state_json FROM card_states
+		WHERE learner_id = $1 AND due <= $2
+		ORDER BY due, result_id, card_id
+		LIMIT $3
+	`, learnerID, due, limit)
+}
+
+func (s *PostgresStore) queryCardStates(ctx context.Context, query string, args ...interface{}) ([]*domain.CardState, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []*domain.CardState
+	for rows.Next() {
+		var stateJSON []byte
+		if err := rows.Scan(&stateJSON); err != nil {
+			return nil, err
+		}
+		var state domain.CardState
+		if err := json.Unmarshal(stateJSON, &state); err != nil {
+			return nil, err
+		}
+		states = append(states, &state)
+	}
+	return states, rows.Err()
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"sort"
+
+	"learnforge/internal/domain"
+)
+
+func (s *InMemStore) SaveDeck(ctx context.Context, deck *domain.Deck) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decks[deck.ID] = copyDeck(deck)
+	return nil
+}
+
+func (s *InMemStore) GetDeck(ctx context.Context, id string) (*domain.Deck, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	deck, ok := s.decks[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "deck not found", nil)
+	}
+	return copyDeck(deck), nil
+}
+
+func (s *InMemStore) ListDecks(ctx context.Context, owner, tag string) ([]*domain.Deck, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var decks []*domain.Deck
+	for _, deck := range s.decks {
+		if owner != "" && deck.Owner != owner {
+			continue
+		}
+		if tag != "" && !hasTag(deck.Tags, tag) {
+			continue
+		}
+		decks = append(decks, copyDeck(deck))
+	}
+	sort.Slice(decks, func(i, j int) bool {
+		if decks[i].Name != decks[j].Name {
+			return decks[i].Name < decks[j].Name
+		}
+		return decks[i].ID < decks[j].ID
+	})
+	return decks, nil
+}
+
+func (s *InMemStore) DeleteDeck(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.decks[id]; !ok {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "deck not found", nil)
+	}
+	delete(s.decks, id)
+	return nil
+}
+
+// copyDeck copies the slices callers may modify.
+func copyDeck(deck *domain.Deck) *domain.Deck {
+	copied := *deck
+	copied.Tags = append([]string(nil), deck.Tags...)
+	copied.Items = make([]domain.DeckItem, len(deck.Items))
+	for i, item := range deck.Items {
+		item.Tags = append([]string(nil), item.Tags...)
+		copied.Items[i] = item
+	}
+	return &copied
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
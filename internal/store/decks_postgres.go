@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"learnforge/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+func (s *PostgresStore) SaveDeck(ctx context.Context, deck *domain.Deck) error {
+	deckJSON, err := json.Marshal(deck)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO decks (id, name, owner, tags, deck_json, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			owner = EXCLUDED.owner,
+			tags = EXCLUDED.tags,
+			deck_json = EXCLUDED.deck_json,
+			updated_at = EXCLUDED.updated_at
+	`
+	tags := deck.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	_, err = s.db.ExecContext(ctx, query, deck.ID, deck.Name, deck.Owner, pq.Array(tags), deckJSON, deck.CreatedAt, deck.UpdatedAt)
+	return err
+}
+
+func (s *PostgresStore) GetDeck(ctx context.Context, id string) (*domain.Deck, error) {
+	var deckJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT deck_json FROM decks WHERE id = $1`, id).Scan(&deckJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "deck not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var deck domain.Deck
+	if err := json.Unmarshal(deckJSON, &deck); err != nil {
+		return nil, err
+	}
+	return &deck, nil
+}
+
+func (s *PostgresStore) ListDecks(ctx context.Context, owner, tag string) ([]*domain.Deck, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT deck_json FROM decks
+		WHERE ($1 = '' OR owner = $1) AND ($2 = '' OR $2 = ANY(tags))
+		ORDER BY name, id
+	`, owner, tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decks []*domain.Deck
+	for rows.Next() {
+		var deckJSON []byte
+		if err := rows.Scan(&deckJSON); err != nil {
+			return nil, err
+		}
+		var deck domain.Deck
+		if err := json.Unmarshal(deckJSON, &deck); err != nil {
+			return nil, err
+		}
+		decks = append(decks, &deck)
+	}
+	return decks, rows.Err()
+}
+
+func (s *PostgresStore) DeleteDeck(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM decks WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "deck not found", nil)
+	}
+	return nil
+}
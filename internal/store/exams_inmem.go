@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"sort"
+
+	"learnforge/internal/domain"
+)
+
+func (s *InMemStore) SaveExam(ctx context.Context, exam *domain.Exam) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exams[exam.ID] = copyExam(exam)
+	return nil
+}
+
+func (s *InMemStore) GetExam(ctx context.Context, id string) (*domain.Exam, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	exam, ok := s.exams[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "exam not found", nil)
+	}
+	return copyExam(exam), nil
+}
+
+func (s *InMemStore) ListExams(ctx context.Context) ([]*domain.Exam, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	exams := make([]*domain.Exam, 0, len(s.exams))
+	for _, exam := range s.exams {
+		exams = append(exams, copyExam(exam))
+	}
+	sort.Slice(exams, func(i, j int) bool {
+		if !exams[i].CreatedAt.Equal(exams[j].CreatedAt) {
+			return exams[i].CreatedAt.After(exams[j].CreatedAt)
+		}
+		return exams[i].ID > exams[j].ID
+	})
+	return exams, nil
+}
+
+func (s *InMemStore) DeleteExam(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.exams[id]; !ok {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "exam not found", nil)
+	}
+	delete(s.exams, id)
+	return nil
+}
+
+// copyExam copies the slices callers may modify.
+func copyExam(exam *domain.Exam) *domain.Exam {
+	copied := *exam
+	copied.Items = append([]domain.ExamItem(nil), exam.Items...)
+	copied.Variants = make([]domain.ExamVariant, len(exam.Variants))
+	for i, variant := range exam.Variants {
+		variant.Questions = append([]domain.ExamQuestion(nil), variant.Questions...)
+		copied.Variants[i] = variant
+	}
+	return &copied
+}
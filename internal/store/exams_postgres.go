@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"learnforge/internal/domain"
+)
+
+func (s *PostgresStore) SaveExam(ctx context.Context, exam *domain.Exam) error {
+	examJSON, err := json.Marshal(exam)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO exams (id, title, exam_json, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			title = EXCLUDED.title,
+			exam_json = EXCLUDED.exam_json
+	`
+	_, err = s.db.ExecContext(ctx, query, exam.ID, exam.Title, examJSON, exam.CreatedAt)
+	return err
+}
+
+func (s *PostgresStore) GetExam(ctx context.Context, id string) (*domain.Exam, error) {
+	var examJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT exam_json FROM exams WHERE id = $1`, id).Scan(&examJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "exam not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var exam domain.Exam
+	if err := json.Unmarshal(examJSON, &exam); err != nil {
+		return nil, err
+	}
+	return &exam, nil
+}
+
+func (s *PostgresStore) ListExams(ctx context.Context) ([]*domain.Exam, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT exam_json FROM exams ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exams []*domain.Exam
+	for rows.Next() {
+		var examJSON []byte
+		if err := rows.Scan(&examJSON); err != nil {
+			return nil, err
+		}
+		var exam domain.Exam
+		if err := json.Unmarshal(examJSON, &exam); err != nil {
+			return nil, err
+		}
+		exams = append(exams, &exam)
+	}
+	return exams, rows.Err()
+}
+
+func (s *PostgresStore) DeleteExam(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM exams WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "exam not found", nil)
+	}
+	return nil
+}
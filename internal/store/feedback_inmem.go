@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func (s *InMemStore) SaveFeedback(ctx context.Context, feedback *domain.Feedback) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.feedback[:0]
+	for _, f := range s.feedback {
+		if f.LearnerID != feedback.LearnerID || f.ResultID != feedback.ResultID || f.Kind != feedback.Kind || f.ItemID != feedback.ItemID {
+			kept = append(kept, f)
+		}
+	}
+	s.feedback = append(kept, copyFeedback(feedback))
+	return nil
+}
+
+func (s *InMemStore) ListResultFeedback(ctx context.Context, resultIDs []string) ([]*domain.Feedback, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(resultIDs))
+	for _, id := range resultIDs {
+		wanted[id] = true
+	}
+	var feedback []*domain.Feedback
+	for _, f := range s.feedback {
+		if wanted[f.ResultID] {
+			feedback = append(feedback, copyFeedback(f))
+		}
+	}
+	return feedback, nil
+}
+
+func (s *InMemStore) ListFeedback(ctx context.Context, from, to time.Time) ([]*domain.Feedback, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var feedback []*domain.Feedback
+	for _, f := range s.feedback {
+		if !f.CreatedAt.Before(from) && f.CreatedAt.Before(to) {
+			feedback = append(feedback, copyFeedback(f))
+		}
+	}
+	return feedback, nil
+}
+
+func copyFeedback(feedback *domain.Feedback) *domain.Feedback {
+	copied := *feedback
+	copied.Categories = append([]string(nil), feedback.Categories...)
+	return &copied
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"learnforge/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+func (s *PostgresStore) SaveFeedback(ctx context.Context, feedback *domain.Feedback) error {
+	feedbackJSON, err := json.Marshal(feedback)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO item_feedback (id, result_id, kind, item_id, learner_id, rating, feedback_json, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (learner_id, result_id, kind, item_id) DO UPDATE SET
+			id = EXCLUDED.id,
+			rating = EXCLUDED.rating,
+			feedback_json = EXCLUDED.feedback_json,
+			created_at = EXCLUDED.created_at
+	`
+	_, err = s.db.ExecContext(ctx, query, feedback.ID, feedback.ResultID, feedback.Kind, feedback.ItemID,
+		feedback.LearnerID, feedback.Rating, feedbackJSON, feedback.CreatedAt)
+	return err
+}
+
+func (s *PostgresStore) ListResultFeedback(ctx context.Context, resultIDs []string) ([]*domain.Feedback, error) {
+	return s.queryFeedback(ctx, `
+		SELECT feedback_json FROM item_feedback
+		WHERE result_id = ANY($1)
+		ORDER BY created_at, id
+	`, pq.Array(resultIDs))
+}
+
+func (s *PostgresStore) ListFeedback(ctx context.Context, from, to time.Time) ([]*domain.Feedback, error) {
+	return s.queryFeedback(ctx, `
+		SELECT feedback_json FROM item_feedback
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY created_at, id
+	`, from, to)
+}
+
+func (s *PostgresStore) queryFeedback(ctx context.Context, query string, args ...interface{}) ([]*domain.Feedback, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feedback []*domain.Feedback
+	for rows.Next() {
+		var feedbackJSON []byte
+		if err := rows.Scan(&feedbackJSON); err != nil {
+			return nil, err
+		}
+		var f domain.Feedback
+		if err := json.Unmarshal(feedbackJSON, &f); err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, &f)
+	}
+	return feedback, rows.Err()
+}
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func idempotencyMapKey(scope, key string) string {
+	return scope + "\x00" + key
+}
+
+func (s *InMemStore) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := idempotencyMapKey(record.Scope, record.Key)
+	if existing, ok := s.idempotency[k]; ok && !idempotencyReclaimable(existing, now) {
+		copied := *existing
+		return &copied, nil
+	}
+	stored := *record
+	s.idempotency[k] = &stored
+	return nil, nil
+}
+
+func idempotencyReclaimable(record *domain.IdempotencyRecord, now time.Time) bool {
+	return !record.ExpiresAt.After(now) ||
+		(record.Status == domain.IdempotencyPending && !record.LockedUntil.After(now))
+}
+
+func (s *InMemStore) CompleteIdempotencyKey(ctx context.Context, scope, key, fingerprint, resultID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.idempotency[idempotencyMapKey(scope, key)]
+	if ok && record.Status == domain.IdempotencyPending && record.Fingerprint == fingerprint {
+		record.Status = domain.IdempotencyCompleted
+		record.ResultID = resultID
+	}
+	return nil
+}
+
+func (s *InMemStore) ReleaseIdempotencyKey(ctx context.Context, scope, key, fingerprint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	k := idempotencyMapKey(scope, key)
+	if record, ok := s.idempotency[k]; ok && record.Status == domain.IdempotencyPending && record.Fingerprint == fingerprint {
+		delete(s.idempotency, k)
+	}
+	return nil
+}
+
+func (s *InMemStore) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, record := range s.idempotency {
+		if !record.ExpiresAt.After(now) {
+			delete(s.idempotency, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func (s *PostgresStore) ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	// Take the key if it is free or reclaimable; otherwise report the
+	// holder. The existing row can vanish between the two statements, so
+	// try again a few times.
+	reserve := `
+		INSERT INTO idempotency_keys (scope, key, fingerprint, status, result_id, locked_until, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, '', $5, $6, $7)
+		ON CONFLICT (scope, key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status = EXCLUDED.status,
+			result_id = '',
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at,
+			created_at = EXCLUDED.created_at
+		WHERE idempotency_keys.expires_at <= $8
+			OR (idempotency_keys.status = 'pending' AND idempotency_keys.locked_until <= $8)
+		RETURNING scope
+	`
+	lookup := `
+		SELECT scope, key, fingerprint, status, result_id, locked_until, expires_at, created_at
+		FROM idempotency_keys WHERE scope = $1 AND key = $2
+	`
+	for try := 0; try < 3; try++ {
+		var scope string
+		err := s.db.QueryRowContext(ctx, reserve, record.Scope, record.Key, record.Fingerprint, record.Status,
+			record.LockedUntil, record.ExpiresAt, record.CreatedAt, now).Scan(&scope)
+		if err == nil {
+			return nil, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		var existing domain.IdempotencyRecord
+		err = s.db.QueryRowContext(ctx, lookup, record.Scope, record.Key).Scan(
+			&existing.Scope, &existing.Key, &existing.Fingerprint, &existing.Status, &existing.ResultID,
+			&existing.LockedUntil, &existing.ExpiresAt, &existing.CreatedAt)
+		if err == nil {
+			return &existing, nil
+		}
+		if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+	return nil, domain.NewDomainError(domain.ErrorCodeConflict, "idempotency key is being reused concurrently", nil)
+}
+
+func (s *PostgresStore) CompleteIdempotencyKey(ctx context.Context, scope, key, fingerprint, resultID string) error {
+	query := `
+		UPDATE idempotency_keys SET status = 'completed', result_id = $4
+		WHERE scope = $1 AND key = $2 AND fingerprint = $3 AND status = 'pending'
+	`
+	_, err := s.db.ExecContext(ctx, query, scope, key, fingerprint, resultID)
+	return err
+}
+
+func (s *PostgresStore) ReleaseIdempotencyKey(ctx context.Context, scope, key, fingerprint string) error {
+	query := `DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND fingerprint = $3 AND status = 'pending'`
+	_, err := s.db.ExecContext(ctx, query, scope, key, fingerprint)
+	return err
+}
+
+func (s *PostgresStore) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	return err
+}
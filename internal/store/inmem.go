@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,21 +12,71 @@ import (
 type InMemStore struct {
 	mu      sync.RWMutex
 	results map[string]*domain.StoredResult
+	events  []*domain.ExperimentEvent
+	courses map[string]*domain.Course
+	feeds   map[string]*domain.FeedSubscription
+	jobs    map[string]*domain.Job
+	batches map[string]*domain.Batch
+
+	webhooks   map[string]*domain.WebhookEndpoint
+	deliveries map[string]*domain.WebhookDelivery
+
+	idempotency map[string]*domain.IdempotencyRecord // by scope and key
+	revisions   map[string][]*domain.ResultRevision  // by result ID, oldest first
+	reviews     map[string][]*domain.ReviewEvent     // by result ID, oldest first
+
+	decks    map[string]*domain.Deck
+	cards    map[string]*domain.CardState // by learner, result and card ID
+	attempts []*domain.Attempt            // oldest first
+	exams    map[string]*domain.Exam
+	feedback []*domain.Feedback // oldest first
+
+	// search is an inverted index of result content.
+	search searchIndex
 }
 
 func NewInMemStore() *InMemStore {
 	return &InMemStore{
 		results: make(map[string]*domain.StoredResult),
+		courses: make(map[string]*domain.Course),
+		feeds:   make(map[string]*domain.FeedSubscription),
+		jobs:    make(map[string]*domain.Job),
+		batches: make(map[string]*domain.Batch),
+
+		webhooks:   make(map[string]*domain.WebhookEndpoint),
+		deliveries: make(map[string]*domain.WebhookDelivery),
+
+		idempotency: make(map[string]*domain.IdempotencyRecord),
+		revisions:   make(map[string][]*domain.ResultRevision),
+		reviews:     make(map[string][]*domain.ReviewEvent),
+
+		decks:  make(map[string]*domain.Deck),
+		cards:  make(map[string]*domain.CardState),
+		exams:  make(map[string]*domain.Exam),
+		search: newSearchIndex(),
 	}
 }
 
 func (s *InMemStore) Save(ctx context.Context, result *domain.StoredResult) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.results[result.ID] = result
+	s.putResult(result)
 	return nil
 }
 
+// putResult stores a copy of result, keeping the review status of an
+// existing result. s.mu must be held.
+func (s *InMemStore) putResult(result *domain.StoredResult) {
+	stored := *result
+	if existing, ok := s.results[result.ID]; ok {
+		stored.Status = existing.Status
+	} else if stored.Status == "" {
+		stored.Status = domain.ReviewDraft
+	}
+	s.results[result.ID] = &stored
+	s.search.add(result.ID, searchDocument(&stored))
+}
+
 func (s *InMemStore) Get(ctx context.Context, id string) (*domain.StoredResult, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -65,6 +116,94 @@ func (s *InMemStore) GetByDateRange(ctx context.Context, start, end time.Time) (
 	return results, nil
 }
 
+func (s *InMemStore) SaveExperimentEvent(ctx context.Context, event *domain.ExperimentEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *InMemStore) ListExperimentEvents(ctx context.Context, experiment string) ([]*domain.ExperimentEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var events []*domain.ExperimentEvent
+	for _, event := range s.events {
+		if event.Experiment == experiment {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *InMemStore) SaveCourse(ctx context.Context, course *domain.Course) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.courses[course.ID] = course
+	return nil
+}
+
+func (s *InMemStore) GetCourse(ctx context.Context, id string) (*domain.Course, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	course, ok := s.courses[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "course not found", nil)
+	}
+	return course, nil
+}
+
+func (s *InMemStore) ListCourses(ctx context.Context) ([]*domain.Course, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	courses := make([]*domain.Course, 0, len(s.courses))
+	for _, course := range s.courses {
+		courses = append(courses, course)
+	}
+	sort.Slice(courses, func(i, j int) bool { return courses[i].Name < courses[j].Name })
+	return courses, nil
+}
+
+func (s *InMemStore) SaveFeed(ctx context.Context, feed *domain.FeedSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feeds[feed.ID] = feed
+	return nil
+}
+
+func (s *InMemStore) GetFeed(ctx context.Context, id string) (*domain.FeedSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	feed, ok := s.feeds[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "feed subscription not found", nil)
+	}
+	return feed, nil
+}
+
+func (s *InMemStore) ListFeeds(ctx context.Context) ([]*domain.FeedSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	feeds := make([]*domain.FeedSubscription, 0, len(s.feeds))
+	for _, feed := range s.feeds {
+		feeds = append(feeds, feed)
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].CreatedAt.Before(feeds[j].CreatedAt) })
+	return feeds, nil
+}
+
+func (s *InMemStore) DeleteFeed(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.feeds[id]; !ok {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "feed subscription not found", nil)
+	}
+	delete(s.feeds, id)
+	return nil
+}
+
 func (s *InMemStore) Close() error {
 	return nil
 }
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -60,3 +61,60 @@ func TestInMemStore_GetNotFound(t *testing.T) {
 		t.Errorf("Expected ErrorCodeNotFound, got %s", domainErr.Code)
 	}
 }
+
+func TestInMemStore_ListResults(t *testing.T) {
+	store := NewInMemStore()
+	ctx := context.Background()
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	save := func(id, topic, mode, summary string, age int) {
+		t.Helper()
+		response := []byte(`{"summary":"` + summary + `","flashcards":[{"q":"What is ` + topic + `?","a":"` + summary + `"}]}`)
+		if err := store.Save(ctx, &domain.StoredResult{
+			ID: id, Topic: topic, Mode: mode, Language: "en", ResponseJSON: response,
+			CreatedAt: base.Add(-time.Duration(age) * time.Hour),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save("raft", "Raft", "lesson", "Raft elects a leader with randomized timeouts", 1)
+	save("paxos", "Paxos", "quiz", "Paxos reaches consensus with proposers and acceptors", 2)
+	save("gossip", "Gossip", "lesson", "Gossip spreads membership between peers", 3)
+	save("raft-2", "raft", "flashcards", "The Raft leader replicates the log and the leader sends heartbeats", 4)
+
+	ids := func(results []*domain.StoredResult) []string {
+		var out []string
+		for _, r := range results {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+	check := func(name string, query domain.ResultQuery, want ...string) {
+		t.Helper()
+		results, err := store.ListResults(ctx, query)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := ids(results); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	check("newest", domain.ResultQuery{Sort: domain.SortNewest}, "raft", "paxos", "gossip", "raft-2")
+	check("topic filter is case-insensitive", domain.ResultQuery{Topic: "RAFT", Sort: domain.SortOldest}, "raft-2", "raft")
+	check("mode", domain.ResultQuery{Mode: "lesson", Sort: domain.SortNewest}, "raft", "gossip")
+	check("date range", domain.ResultQuery{From: base.Add(-3 * time.Hour), To: base.Add(-time.Hour), Sort: domain.SortNewest}, "paxos", "gossip")
+	check("search matches every word", domain.ResultQuery{Search: "leader heartbeats", Sort: domain.SortNewest}, "raft-2")
+	check("relevance", domain.ResultQuery{Search: "leader", Sort: domain.SortRelevance}, "raft-2", "raft")
+	check("stop words only", domain.ResultQuery{Search: "the", Sort: domain.SortNewest})
+
+	first := domain.ResultQuery{Sort: domain.SortNewest, Limit: 2}
+	check("first page", first, "raft", "paxos")
+	first.Cursor = &domain.ResultCursor{Key: base.Add(-2 * time.Hour).Format(time.RFC3339Nano), ID: "paxos"}
+	check("next page", first, "gossip", "raft-2")
+
+	// Saving replaces the indexed content.
+	save("gossip", "Gossip", "lesson", "Gossip uses heartbeats to detect failures", 3)
+	check("reindexed", domain.ResultQuery{Search: "heartbeats", Sort: domain.SortNewest}, "gossip", "raft-2")
+	check("old content forgotten", domain.ResultQuery{Search: "membership", Sort: domain.SortNewest})
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func (s *InMemStore) EnqueueJob(ctx context.Context, job *domain.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *job
+	s.jobs[job.ID] = &stored
+	return nil
+}
+
+func (s *InMemStore) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "job not found", nil)
+	}
+	copied := *job
+	return &copied, nil
+}
+
+func (s *InMemStore) ClaimJob(ctx context.Context, leaseID string, lockedUntil time.Time) (*domain.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var next *domain.Job
+	for _, job := range s.jobs {
+		available := (job.Status == domain.JobQueued && !job.AvailableAt.After(now)) ||
+			(job.Status == domain.JobRunning && job.LockedUntil != nil && job.LockedUntil.Before(now))
+		if available && (next == nil || job.AvailableAt.Before(next.AvailableAt)) {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+
+	next.Status = domain.JobRunning
+	next.Attempts++
+	next.LeaseID = leaseID
+	next.LockedUntil = &lockedUntil
+	if next.StartedAt == nil {
+		next.StartedAt = &now
+	}
+	next.UpdatedAt = now
+	copied := *next
+	return &copied, nil
+}
+
+func (s *InMemStore) HeartbeatJob(ctx context.Context, id, leaseID, progress string, lockedUntil time.Time) (*domain.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok || job.Status != domain.JobRunning || job.LeaseID != leaseID {
+		return nil, errLeaseLost
+	}
+	job.LockedUntil = &lockedUntil
+	job.Progress = progress
+	job.UpdatedAt = time.Now()
+	copied := *job
+	return &copied, nil
+}
+
+func (s *InMemStore) FinishJob(ctx context.Context, job *domain.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.jobs[job.ID]
+	if !ok || stored.Status != domain.JobRunning || stored.LeaseID != job.LeaseID {
+		return errLeaseLost
+	}
+	stored.Status = job.Status
+	stored.Attempts = job.Attempts
+	stored.ResultID = job.ResultID
+	stored.Error = job.Error
+	stored.Progress = job.Progress
+	stored.AvailableAt = job.AvailableAt
+	stored.FinishedAt = job.FinishedAt
+	stored.LeaseID = ""
+	stored.LockedUntil = nil
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *InMemStore) CancelJob(ctx context.Context, id string) (*domain.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "job not found", nil)
+	}
+
+	now := time.Now()
+	switch job.Status {
+	case domain.JobQueued:
+		job.Status = domain.JobCanceled
+		job.FinishedAt = &now
+	case domain.JobRunning:
+		job.CancelRequested = true
+	default:
+		return nil, errJobFinished
+	}
+	job.UpdatedAt = now
+	copied := *job
+	return &copied, nil
+}
+
+func (s *InMemStore) CreateBatch(ctx context.Context, batch *domain.Batch, jobs []*domain.Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *batch
+	s.batches[batch.ID] = &stored
+	for _, job := range jobs {
+		copied := *job
+		s.jobs[job.ID] = &copied
+	}
+	return nil
+}
+
+func (s *InMemStore) GetBatch(ctx context.Context, id string) (*domain.Batch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	batch, ok := s.batches[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "batch not found", nil)
+	}
+	copied := *batch
+	copied.Counts = make(map[domain.JobStatus]int)
+	for _, job := range s.jobs {
+		if job.BatchID == id {
+			copied.Counts[job.Status]++
+		}
+	}
+	return &copied, nil
+}
+
+func (s *InMemStore) ListBatchJobs(ctx context.Context, batchID string, offset, limit int) ([]*domain.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var jobs []*domain.Job
+	for _, job := range s.jobs {
+		if job.BatchID == batchID {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].BatchIndex < jobs[j].BatchIndex })
+	if offset >= len(jobs) {
+		return nil, nil
+	}
+	jobs = jobs[offset:]
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
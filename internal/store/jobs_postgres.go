@@ -0,0 +1,252 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+const jobColumns = `id, status, request_json, user_id, api_key_hash, progress, attempts, max_attempts, result_id, error,
+	cancel_requested, lease_id, locked_until, available_at, started_at, finished_at, created_at, updated_at,
+	batch_id, batch_index`
+
+func scanJob(row rowScanner) (*domain.Job, error) {
+	var job domain.Job
+	var requestJSON []byte
+	var lockedUntil, startedAt, finishedAt sql.NullTime
+	if err := row.Scan(
+		&job.ID,
+		&job.Status,
+		&requestJSON,
+		&job.UserID,
+		&job.APIKeyHash,
+		&job.Progress,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.ResultID,
+		&job.Error,
+		&job.CancelRequested,
+		&job.LeaseID,
+		&lockedUntil,
+		&job.AvailableAt,
+		&startedAt,
+		&finishedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.BatchID,
+		&job.BatchIndex,
+	); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(requestJSON, &job.Request); err != nil {
+		return nil, err
+	}
+	job.LockedUntil = nullTime(lockedUntil)
+	job.StartedAt = nullTime(startedAt)
+	job.FinishedAt = nullTime(finishedAt)
+	return &job, nil
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func insertJob(ctx context.Context, db execer, job *domain.Job) error {
+	requestJSON, err := json.Marshal(job.Request)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO jobs (id, status, request_json, user_id, api_key_hash, max_attempts, available_at, created_at, updated_at, batch_id, batch_index)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err = db.ExecContext(ctx, query, job.ID, job.Status, requestJSON, job.UserID, job.APIKeyHash, job.MaxAttempts,
+		job.AvailableAt, job.CreatedAt, job.UpdatedAt, job.BatchID, job.BatchIndex)
+	return err
+}
+
+func (s *PostgresStore) EnqueueJob(ctx context.Context, job *domain.Job) error {
+	return insertJob(ctx, s.db, job)
+}
+
+func (s *PostgresStore) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	job, err := scanJob(s.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "job not found", nil)
+	}
+	return job, err
+}
+
+// ClaimJob uses SKIP LOCKED so concurrent workers, in this process or
+// another, never claim the same row.
+func (s *PostgresStore) ClaimJob(ctx context.Context, leaseID string, lockedUntil time.Time) (*domain.Job, error) {
+	query := `
+		UPDATE jobs SET
+			status = 'running',
+			attempts = attempts + 1,
+			lease_id = $1,
+			locked_until = $2,
+			started_at = COALESCE(started_at, NOW()),
+			updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = 'queued' AND available_at <= NOW())
+				OR (status = 'running' AND locked_until < NOW())
+			ORDER BY available_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + jobColumns
+	job, err := scanJob(s.db.QueryRowContext(ctx, query, leaseID, lockedUntil))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (s *PostgresStore) HeartbeatJob(ctx context.Context, id, leaseID, progress string, lockedUntil time.Time) (*domain.Job, error) {
+	query := `
+		UPDATE jobs SET locked_until = $3, progress = $4, updated_at = NOW()
+		WHERE id = $1 AND lease_id = $2 AND status = 'running'
+		RETURNING ` + jobColumns
+	job, err := scanJob(s.db.QueryRowContext(ctx, query, id, leaseID, lockedUntil, progress))
+	if err == sql.ErrNoRows {
+		return nil, errLeaseLost
+	}
+	return job, err
+}
+
+func (s *PostgresStore) FinishJob(ctx context.Context, job *domain.Job) error {
+	query := `
+		UPDATE jobs SET
+			status = $3,
+			result_id = $4,
+			error = $5,
+			progress = $6,
+			available_at = $7,
+			finished_at = $8,
+			attempts = $9,
+			lease_id = '',
+			locked_until = NULL,
+			updated_at = NOW()
+		WHERE id = $1 AND lease_id = $2 AND status = 'running'
+	`
+	res, err := s.db.ExecContext(ctx, query, job.ID, job.LeaseID, job.Status, job.ResultID, job.Error,
+		job.Progress, job.AvailableAt, job.FinishedAt, job.Attempts)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+func (s *PostgresStore) CancelJob(ctx context.Context, id string) (*domain.Job, error) {
+	query := `
+		UPDATE jobs SET
+			status = CASE WHEN status = 'queued' THEN 'canceled' ELSE status END,
+			finished_at = CASE WHEN status = 'queued' THEN NOW() ELSE finished_at END,
+			cancel_requested = (status = 'running'),
+			updated_at = NOW()
+		WHERE id = $1 AND status IN ('queued', 'running')
+		RETURNING ` + jobColumns
+	job, err := scanJob(s.db.QueryRowContext(ctx, query, id))
+	if err != sql.ErrNoRows {
+		return job, err
+	}
+	if _, err := s.GetJob(ctx, id); err != nil {
+		return nil, err
+	}
+	return nil, errJobFinished
+}
+
+func (s *PostgresStore) CreateBatch(ctx context.Context, batch *domain.Batch, jobs []*domain.Job) error {
+	batchJSON, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO batches (id, batch_json, created_at) VALUES ($1, $2, $3)`,
+		batch.ID, batchJSON, batch.CreatedAt); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		if err := insertJob(ctx, tx, job); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStore) GetBatch(ctx context.Context, id string) (*domain.Batch, error) {
+	var batchJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT batch_json FROM batches WHERE id = $1`, id).Scan(&batchJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "batch not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var batch domain.Batch
+	if err := json.Unmarshal(batchJSON, &batch); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM jobs WHERE batch_id = $1 GROUP BY status`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batch.Counts = make(map[domain.JobStatus]int)
+	for rows.Next() {
+		var status domain.JobStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		batch.Counts[status] = count
+	}
+	return &batch, rows.Err()
+}
+
+func (s *PostgresStore) ListBatchJobs(ctx context.Context, batchID string, offset, limit int) ([]*domain.Job, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.db.QueryContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE batch_id = $1 ORDER BY batch_index LIMIT $2 OFFSET $3`,
+		batchID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*domain.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
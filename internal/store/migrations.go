@@ -34,6 +34,342 @@ var migrations = []Migration{
 			DROP TABLE IF EXISTS processed_results;
 		`,
 	},
+	{
+		Version: 2,
+		Up: `
+			ALTER TABLE processed_results ADD COLUMN IF NOT EXISTS experiment TEXT NOT NULL DEFAULT '';
+			ALTER TABLE processed_results ADD COLUMN IF NOT EXISTS variant TEXT NOT NULL DEFAULT '';
+
+			CREATE TABLE IF NOT EXISTS experiment_events (
+				id TEXT PRIMARY KEY,
+				experiment TEXT NOT NULL,
+				variant TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				result_id TEXT NOT NULL DEFAULT '',
+				success BOOLEAN NOT NULL,
+				latency_ms BIGINT NOT NULL DEFAULT 0,
+				prompt_tokens INTEGER NOT NULL DEFAULT 0,
+				completion_tokens INTEGER NOT NULL DEFAULT 0,
+				cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+				outcome_kind TEXT NOT NULL DEFAULT '',
+				score DOUBLE PRECISION NOT NULL DEFAULT 0,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_experiment_events_experiment ON experiment_events(experiment, variant);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_experiment_events_experiment;
+			DROP TABLE IF EXISTS experiment_events;
+			ALTER TABLE processed_results DROP COLUMN IF EXISTS variant;
+			ALTER TABLE processed_results DROP COLUMN IF EXISTS experiment;
+		`,
+	},
+	{
+		Version: 3,
+		Up: `
+			CREATE TABLE IF NOT EXISTS courses (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				course_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS courses;
+		`,
+	},
+	{
+		Version: 4,
+		Up: `
+			CREATE TABLE IF NOT EXISTS feed_subscriptions (
+				id TEXT PRIMARY KEY,
+				feed_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS feed_subscriptions;
+		`,
+	},
+	{
+		Version: 5,
+		Up: `
+			CREATE TABLE IF NOT EXISTS jobs (
+				id TEXT PRIMARY KEY,
+				status TEXT NOT NULL,
+				request_json JSONB NOT NULL,
+				user_id TEXT NOT NULL DEFAULT '',
+				progress TEXT NOT NULL DEFAULT '',
+				attempts INTEGER NOT NULL DEFAULT 0,
+				max_attempts INTEGER NOT NULL,
+				result_id TEXT NOT NULL DEFAULT '',
+				error TEXT NOT NULL DEFAULT '',
+				cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+				lease_id TEXT NOT NULL DEFAULT '',
+				locked_until TIMESTAMPTZ,
+				available_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				started_at TIMESTAMPTZ,
+				finished_at TIMESTAMPTZ,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_jobs_status_available ON jobs(status, available_at);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_jobs_status_available;
+			DROP TABLE IF EXISTS jobs;
+		`,
+	},
+	{
+		Version: 6,
+		Up: `
+			CREATE TABLE IF NOT EXISTS webhook_endpoints (
+				id TEXT PRIMARY KEY,
+				endpoint_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE TABLE IF NOT EXISTS webhook_deliveries (
+				id TEXT PRIMARY KEY,
+				endpoint_id TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL,
+				next_attempt_at TIMESTAMPTZ NOT NULL,
+				delivery_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+			CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries(created_at);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_webhook_deliveries_created_at;
+			DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+			DROP TABLE IF EXISTS webhook_deliveries;
+			DROP TABLE IF EXISTS webhook_endpoints;
+		`,
+	},
+	{
+		Version: 7,
+		Up: `
+			CREATE TABLE IF NOT EXISTS batches (
+				id TEXT PRIMARY KEY,
+				batch_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			ALTER TABLE jobs ADD COLUMN IF NOT EXISTS batch_id TEXT NOT NULL DEFAULT '';
+			ALTER TABLE jobs ADD COLUMN IF NOT EXISTS batch_index INTEGER NOT NULL DEFAULT 0;
+			CREATE INDEX IF NOT EXISTS idx_jobs_batch ON jobs(batch_id, batch_index);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_jobs_batch;
+			ALTER TABLE jobs DROP COLUMN IF EXISTS batch_index;
+			ALTER TABLE jobs DROP COLUMN IF EXISTS batch_id;
+			DROP TABLE IF EXISTS batches;
+		`,
+	},
+	{
+		Version: 8,
+		Up: `
+			CREATE TABLE IF NOT EXISTS idempotency_keys (
+				scope TEXT NOT NULL,
+				key TEXT NOT NULL,
+				fingerprint TEXT NOT NULL,
+				status TEXT NOT NULL,
+				result_id TEXT NOT NULL DEFAULT '',
+				locked_until TIMESTAMPTZ NOT NULL,
+				expires_at TIMESTAMPTZ NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (scope, key)
+			);
+			CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_idempotency_keys_expires_at;
+			DROP TABLE IF EXISTS idempotency_keys;
+		`,
+	},
+	{
+		Version: 9,
+		Up: `
+			CREATE TABLE IF NOT EXISTS result_revisions (
+				result_id TEXT NOT NULL,
+				number INTEGER NOT NULL,
+				revision_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (result_id, number)
+			);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS result_revisions;
+		`,
+	},
+	{
+		Version: 10,
+		Up: `
+			ALTER TABLE processed_results ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'draft';
+			CREATE INDEX IF NOT EXISTS idx_processed_results_status ON processed_results(status, created_at);
+
+			CREATE TABLE IF NOT EXISTS review_events (
+				id TEXT PRIMARY KEY,
+				result_id TEXT NOT NULL,
+				action TEXT NOT NULL,
+				from_status TEXT NOT NULL,
+				to_status TEXT NOT NULL,
+				actor TEXT NOT NULL,
+				comment TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_review_events_result ON review_events(result_id, created_at);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS review_events;
+			DROP INDEX IF EXISTS idx_processed_results_status;
+			ALTER TABLE processed_results DROP COLUMN IF EXISTS status;
+		`,
+	},
+	{
+		Version: 11,
+		Up: `
+			ALTER TABLE processed_results ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT '';
+			ALTER TABLE processed_results ADD COLUMN IF NOT EXISTS level TEXT NOT NULL DEFAULT '';
+			ALTER TABLE processed_results ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT '';
+			ALTER TABLE processed_results ADD COLUMN IF NOT EXISTS provider TEXT NOT NULL DEFAULT '';
+			ALTER TABLE processed_results ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+			-- Approximates searchDocument; rows are reindexed exactly on their next save.
+			UPDATE processed_results SET
+				mode = COALESCE(NULLIF(request_json->>'mode', ''), 'lesson'),
+				level = COALESCE(request_json->>'level', ''),
+				language = COALESCE(NULLIF(request_json->>'language', ''), 'en'),
+				provider = COALESCE(response_json->'meta'->>'provider', ''),
+				search_vector = to_tsvector('english', topic || ' ' || COALESCE(response_json->>'summary', ''))
+					|| jsonb_to_tsvector('english', jsonb_build_array(response_json->'key_points', response_json->'flashcards', response_json->'quiz'), '["string"]');
+
+			CREATE INDEX IF NOT EXISTS idx_processed_results_search ON processed_results USING GIN (search_vector);
+			CREATE INDEX IF NOT EXISTS idx_processed_results_created_id ON processed_results(created_at, id);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_processed_results_created_id;
+			DROP INDEX IF EXISTS idx_processed_results_search;
+			ALTER TABLE processed_results DROP COLUMN IF EXISTS search_vector;
+			ALTER TABLE processed_results DROP COLUMN IF EXISTS provider;
+			ALTER TABLE processed_results DROP COLUMN IF EXISTS language;
+			ALTER TABLE processed_results DROP COLUMN IF EXISTS level;
+			ALTER TABLE processed_results DROP COLUMN IF EXISTS mode;
+		`,
+	},
+	{
+		Version: 12,
+		Up: `
+			CREATE TABLE IF NOT EXISTS decks (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				owner TEXT NOT NULL,
+				tags TEXT[] NOT NULL DEFAULT '{}',
+				deck_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_decks_owner ON decks(owner);
+			CREATE INDEX IF NOT EXISTS idx_decks_tags ON decks USING GIN (tags);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS decks;
+		`,
+	},
+	{
+		Version: 13,
+		Up: `
+			CREATE TABLE IF NOT EXISTS card_states (
+				learner_id TEXT NOT NULL,
+				result_id TEXT NOT NULL,
+				card_id TEXT NOT NULL,
+				due TIMESTAMPTZ NOT NULL,
+				state_json JSONB NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				PRIMARY KEY (learner_id, result_id, card_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_card_states_due ON card_states(learner_id, due);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS card_states;
+		`,
+	},
+	{
+		Version: 14,
+		Up: `
+			CREATE TABLE IF NOT EXISTS quiz_attempts (
+				id TEXT PRIMARY KEY,
+				result_id TEXT NOT NULL,
+				learner_id TEXT NOT NULL,
+				topic TEXT NOT NULL,
+				score DOUBLE PRECISION NOT NULL,
+				attempt_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_quiz_attempts_learner ON quiz_attempts(learner_id, created_at DESC);
+			CREATE INDEX IF NOT EXISTS idx_quiz_attempts_result ON quiz_attempts(result_id);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS quiz_attempts;
+		`,
+	},
+	{
+		Version: 15,
+		Up: `
+			CREATE INDEX IF NOT EXISTS idx_quiz_attempts_created ON quiz_attempts(created_at);
+		`,
+		Down: `
+			DROP INDEX IF EXISTS idx_quiz_attempts_created;
+		`,
+	},
+	{
+		Version: 16,
+		Up: `
+			CREATE TABLE IF NOT EXISTS exams (
+				id TEXT PRIMARY KEY,
+				title TEXT NOT NULL,
+				exam_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			);
+			CREATE INDEX IF NOT EXISTS idx_exams_created ON exams(created_at DESC);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS exams;
+		`,
+	},
+	{
+		Version: 17,
+		Up: `
+			CREATE TABLE IF NOT EXISTS item_feedback (
+				id TEXT PRIMARY KEY,
+				result_id TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				item_id TEXT NOT NULL DEFAULT '',
+				learner_id TEXT NOT NULL,
+				rating TEXT NOT NULL,
+				feedback_json JSONB NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+				UNIQUE (learner_id, result_id, kind, item_id)
+			);
+			CREATE INDEX IF NOT EXISTS idx_item_feedback_result ON item_feedback(result_id);
+			CREATE INDEX IF NOT EXISTS idx_item_feedback_created ON item_feedback(created_at);
+		`,
+		Down: `
+			DROP TABLE IF EXISTS item_feedback;
+		`,
+	},
+	{
+		Version: 18,
+		Up: `
+			ALTER TABLE jobs ADD COLUMN IF NOT EXISTS api_key_hash TEXT NOT NULL DEFAULT '';
+		`,
+		Down: `
+			ALTER TABLE jobs DROP COLUMN IF EXISTS api_key_hash;
+		`,
+	},
 }
 
 func runMigrations(db *sql.DB) error {
@@ -96,4 +432,3 @@ func applyMigration(db *sql.DB, migration Migration) error {
 
 	return tx.Commit()
 }
-
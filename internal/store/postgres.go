@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"learnforge/internal/domain"
@@ -32,48 +33,109 @@ func NewPostgresStore(databaseURL string) (*PostgresStore, error) {
 	return store, nil
 }
 
+const resultColumns = `id, request_json, response_json, topic, topic_source, topic_confidence, experiment, variant, status, mode, level, language, provider, created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanResult(row rowScanner) (*domain.StoredResult, error) {
+	var result domain.StoredResult
+	var createdAt time.Time
+	if err := row.Scan(
+		&result.ID,
+		&result.RequestJSON,
+		&result.ResponseJSON,
+		&result.Topic,
+		&result.TopicSource,
+		&result.TopicConfidence,
+		&result.Experiment,
+		&result.Variant,
+		&result.Status,
+		&result.Mode,
+		&result.Level,
+		&result.Language,
+		&result.Provider,
+		&createdAt,
+	); err != nil {
+		return nil, err
+	}
+	result.CreatedAt = createdAt
+	return &result, nil
+}
+
+func (s *PostgresStore) queryResults(ctx context.Context, query string, args ...interface{}) ([]*domain.StoredResult, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*domain.StoredResult
+	for rows.Next() {
+		result, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
 func (s *PostgresStore) Save(ctx context.Context, result *domain.StoredResult) error {
+	return saveResult(ctx, s.db, result)
+}
+
+func saveResult(ctx context.Context, db execer, result *domain.StoredResult) error {
 	query := `
-		INSERT INTO processed_results (id, request_json, response_json, topic, topic_source, topic_confidence, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO processed_results (id, request_json, response_json, topic, topic_source, topic_confidence, experiment, variant, status,
+			mode, level, language, provider, search_vector, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, to_tsvector('english', $14), $15)
 		ON CONFLICT (id) DO UPDATE SET
 			request_json = EXCLUDED.request_json,
 			response_json = EXCLUDED.response_json,
 			topic = EXCLUDED.topic,
 			topic_source = EXCLUDED.topic_source,
-			topic_confidence = EXCLUDED.topic_confidence
+			topic_confidence = EXCLUDED.topic_confidence,
+			experiment = EXCLUDED.experiment,
+			variant = EXCLUDED.variant,
+			mode = EXCLUDED.mode,
+			level = EXCLUDED.level,
+			language = EXCLUDED.language,
+			provider = EXCLUDED.provider,
+			search_vector = EXCLUDED.search_vector
 	`
+	// An existing result keeps its status; see ReviewStore.
+	status := result.Status
+	if status == "" {
+		status = domain.ReviewDraft
+	}
 
-	_, err := s.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		result.ID,
 		result.RequestJSON,
 		result.ResponseJSON,
 		result.Topic,
 		result.TopicSource,
 		result.TopicConfidence,
+		result.Experiment,
+		result.Variant,
+		status,
+		result.Mode,
+		result.Level,
+		result.Language,
+		result.Provider,
+		searchDocument(result),
 		result.CreatedAt,
 	)
 	return err
 }
 
 func (s *PostgresStore) Get(ctx context.Context, id string) (*domain.StoredResult, error) {
-	query := `
-		SELECT id, request_json, response_json, topic, topic_source, topic_confidence, created_at
-		FROM processed_results
-		WHERE id = $1
-	`
+	query := `SELECT ` + resultColumns + ` FROM processed_results WHERE id = $1`
 
-	var result domain.StoredResult
-	var createdAt time.Time
-	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&result.ID,
-		&result.RequestJSON,
-		&result.ResponseJSON,
-		&result.Topic,
-		&result.TopicSource,
-		&result.TopicConfidence,
-		&createdAt,
-	)
+	result, err := scanResult(s.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "result not found", nil)
 	}
@@ -81,81 +143,220 @@ func (s *PostgresStore) Get(ctx context.Context, id string) (*domain.StoredResul
 		return nil, err
 	}
 
-	result.CreatedAt = createdAt
-	return &result, nil
+	return result, nil
 }
 
 func (s *PostgresStore) GetByTopic(ctx context.Context, topic string, limit int) ([]*domain.StoredResult, error) {
 	query := `
-		SELECT id, request_json, response_json, topic, topic_source, topic_confidence, created_at
+		SELECT ` + resultColumns + `
 		FROM processed_results
 		WHERE topic = $1
 		ORDER BY created_at DESC
 		LIMIT $2
 	`
+	return s.queryResults(ctx, query, topic, limit)
+}
+
+func (s *PostgresStore) GetByDateRange(ctx context.Context, start, end time.Time) ([]*domain.StoredResult, error) {
+	query := `
+		SELECT ` + resultColumns + `
+		FROM processed_results
+		WHERE created_at >= $1 AND created_at < $2
+		ORDER BY created_at DESC
+	`
+	return s.queryResults(ctx, query, start, end)
+}
 
-	rows, err := s.db.QueryContext(ctx, query, topic, limit)
+func (s *PostgresStore) SaveExperimentEvent(ctx context.Context, event *domain.ExperimentEvent) error {
+	query := `
+		INSERT INTO experiment_events (id, experiment, variant, kind, result_id, success, latency_ms,
+			prompt_tokens, completion_tokens, cost_usd, outcome_kind, score, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	_, err := s.db.ExecContext(ctx, query,
+		event.ID,
+		event.Experiment,
+		event.Variant,
+		event.Kind,
+		event.ResultID,
+		event.Success,
+		event.LatencyMS,
+		event.PromptTokens,
+		event.CompletionTokens,
+		event.CostUSD,
+		event.OutcomeKind,
+		event.Score,
+		event.CreatedAt,
+	)
+	return err
+}
+
+func (s *PostgresStore) ListExperimentEvents(ctx context.Context, experiment string) ([]*domain.ExperimentEvent, error) {
+	query := `
+		SELECT id, experiment, variant, kind, result_id, success, latency_ms,
+			prompt_tokens, completion_tokens, cost_usd, outcome_kind, score, created_at
+		FROM experiment_events
+		WHERE experiment = $1
+		ORDER BY created_at
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, experiment)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var results []*domain.StoredResult
+	var events []*domain.ExperimentEvent
 	for rows.Next() {
-		var result domain.StoredResult
-		var createdAt time.Time
+		var event domain.ExperimentEvent
 		if err := rows.Scan(
-			&result.ID,
-			&result.RequestJSON,
-			&result.ResponseJSON,
-			&result.Topic,
-			&result.TopicSource,
-			&result.TopicConfidence,
-			&createdAt,
+			&event.ID,
+			&event.Experiment,
+			&event.Variant,
+			&event.Kind,
+			&event.ResultID,
+			&event.Success,
+			&event.LatencyMS,
+			&event.PromptTokens,
+			&event.CompletionTokens,
+			&event.CostUSD,
+			&event.OutcomeKind,
+			&event.Score,
+			&event.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
-		result.CreatedAt = createdAt
-		results = append(results, &result)
+		events = append(events, &event)
 	}
 
-	return results, rows.Err()
+	return events, rows.Err()
 }
 
-func (s *PostgresStore) GetByDateRange(ctx context.Context, start, end time.Time) ([]*domain.StoredResult, error) {
+func (s *PostgresStore) SaveCourse(ctx context.Context, course *domain.Course) error {
+	courseJSON, err := json.Marshal(course)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		SELECT id, request_json, response_json, topic, topic_source, topic_confidence, created_at
-		FROM processed_results
-		WHERE created_at >= $1 AND created_at < $2
-		ORDER BY created_at DESC
+		INSERT INTO courses (id, name, course_json, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			course_json = EXCLUDED.course_json,
+			updated_at = EXCLUDED.updated_at
 	`
+	_, err = s.db.ExecContext(ctx, query, course.ID, course.Name, courseJSON, course.CreatedAt, course.UpdatedAt)
+	return err
+}
+
+func (s *PostgresStore) GetCourse(ctx context.Context, id string) (*domain.Course, error) {
+	var courseJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT course_json FROM courses WHERE id = $1`, id).Scan(&courseJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "course not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := s.db.QueryContext(ctx, query, start, end)
+	var course domain.Course
+	if err := json.Unmarshal(courseJSON, &course); err != nil {
+		return nil, err
+	}
+	return &course, nil
+}
+
+func (s *PostgresStore) ListCourses(ctx context.Context) ([]*domain.Course, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT course_json FROM courses ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var results []*domain.StoredResult
+	var courses []*domain.Course
 	for rows.Next() {
-		var result domain.StoredResult
-		var createdAt time.Time
-		if err := rows.Scan(
-			&result.ID,
-			&result.RequestJSON,
-			&result.ResponseJSON,
-			&result.Topic,
-			&result.TopicSource,
-			&result.TopicConfidence,
-			&createdAt,
-		); err != nil {
+		var courseJSON []byte
+		if err := rows.Scan(&courseJSON); err != nil {
+			return nil, err
+		}
+		var course domain.Course
+		if err := json.Unmarshal(courseJSON, &course); err != nil {
 			return nil, err
 		}
-		result.CreatedAt = createdAt
-		results = append(results, &result)
+		courses = append(courses, &course)
 	}
 
-	return results, rows.Err()
+	return courses, rows.Err()
+}
+
+func (s *PostgresStore) SaveFeed(ctx context.Context, feed *domain.FeedSubscription) error {
+	feedJSON, err := json.Marshal(feed)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO feed_subscriptions (id, feed_json, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			feed_json = EXCLUDED.feed_json,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = s.db.ExecContext(ctx, query, feed.ID, feedJSON, feed.CreatedAt, feed.UpdatedAt)
+	return err
+}
+
+func (s *PostgresStore) GetFeed(ctx context.Context, id string) (*domain.FeedSubscription, error) {
+	var feedJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT feed_json FROM feed_subscriptions WHERE id = $1`, id).Scan(&feedJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "feed subscription not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var feed domain.FeedSubscription
+	if err := json.Unmarshal(feedJSON, &feed); err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (s *PostgresStore) ListFeeds(ctx context.Context) ([]*domain.FeedSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT feed_json FROM feed_subscriptions ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feeds []*domain.FeedSubscription
+	for rows.Next() {
+		var feedJSON []byte
+		if err := rows.Scan(&feedJSON); err != nil {
+			return nil, err
+		}
+		var feed domain.FeedSubscription
+		if err := json.Unmarshal(feedJSON, &feed); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, &feed)
+	}
+
+	return feeds, rows.Err()
+}
+
+func (s *PostgresStore) DeleteFeed(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM feed_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "feed subscription not found", nil)
+	}
+	return nil
 }
 
 func (s *PostgresStore) Close() error {
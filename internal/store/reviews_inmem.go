@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"sort"
+
+	"learnforge/internal/domain"
+)
+
+func (s *InMemStore) AddReviewEvent(ctx context.Context, event *domain.ReviewEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, ok := s.results[event.ResultID]
+	if !ok {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "result not found", nil)
+	}
+	if result.Status != event.From {
+		return errStatusChanged
+	}
+	if event.To != event.From {
+		// Replace rather than modify, readers may hold the old copy.
+		updated := *result
+		updated.Status = event.To
+		s.results[event.ResultID] = &updated
+	}
+	stored := *event
+	s.reviews[event.ResultID] = append(s.reviews[event.ResultID], &stored)
+	return nil
+}
+
+func (s *InMemStore) ListReviewEvents(ctx context.Context, resultID string) ([]*domain.ReviewEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]*domain.ReviewEvent, 0, len(s.reviews[resultID]))
+	for _, event := range s.reviews[resultID] {
+		copied := *event
+		events = append(events, &copied)
+	}
+	return events, nil
+}
+
+func (s *InMemStore) ListResultsByStatus(ctx context.Context, status string, limit int) ([]*domain.StoredResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var results []*domain.StoredResult
+	for _, result := range s.results {
+		if result.Status == status {
+			results = append(results, result)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt.Before(results[j].CreatedAt)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
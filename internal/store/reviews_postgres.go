@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"learnforge/internal/domain"
+)
+
+func (s *PostgresStore) AddReviewEvent(ctx context.Context, event *domain.ReviewEvent) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock the result so concurrent transitions are ordered.
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM processed_results WHERE id = $1 FOR UPDATE`, event.ResultID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "result not found", nil)
+	}
+	if err != nil {
+		return err
+	}
+	if status != event.From {
+		return errStatusChanged
+	}
+	if event.To != event.From {
+		if _, err := tx.ExecContext(ctx, `UPDATE processed_results SET status = $2 WHERE id = $1`, event.ResultID, event.To); err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO review_events (id, result_id, action, from_status, to_status, actor, comment, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, event.ID, event.ResultID, event.Action, event.From, event.To, event.Actor, event.Comment, event.CreatedAt)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStore) ListReviewEvents(ctx context.Context, resultID string) ([]*domain.ReviewEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, result_id, action, from_status, to_status, actor, comment, created_at
+		FROM review_events
+		WHERE result_id = $1
+		ORDER BY created_at, id
+	`, resultID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*domain.ReviewEvent{}
+	for rows.Next() {
+		var event domain.ReviewEvent
+		if err := rows.Scan(&event.ID, &event.ResultID, &event.Action, &event.From, &event.To, &event.Actor, &event.Comment, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+	return events, rows.Err()
+}
+
+func (s *PostgresStore) ListResultsByStatus(ctx context.Context, status string, limit int) ([]*domain.StoredResult, error) {
+	query := `
+		SELECT ` + resultColumns + `
+		FROM processed_results
+		WHERE status = $1
+		ORDER BY created_at
+		LIMIT $2
+	`
+	return s.queryResults(ctx, query, status, limit)
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+
+	"learnforge/internal/domain"
+)
+
+func (s *InMemStore) AddRevision(ctx context.Context, rev *domain.ResultRevision, result *domain.StoredResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.revisions[rev.ResultID] {
+		if existing.Number == rev.Number {
+			return errRevisionTaken
+		}
+	}
+	stored := *rev
+	s.revisions[rev.ResultID] = append(s.revisions[rev.ResultID], &stored)
+	if result != nil {
+		s.putResult(result)
+	}
+	return nil
+}
+
+func (s *InMemStore) ListRevisions(ctx context.Context, resultID string) ([]*domain.ResultRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := make([]*domain.ResultRevision, 0, len(s.revisions[resultID]))
+	for _, rev := range s.revisions[resultID] {
+		copied := *rev
+		revisions = append(revisions, &copied)
+	}
+	return revisions, nil
+}
+
+func (s *InMemStore) GetRevision(ctx context.Context, resultID string, number int) (*domain.ResultRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, rev := range s.revisions[resultID] {
+		if rev.Number == number {
+			copied := *rev
+			return &copied, nil
+		}
+	}
+	return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "revision not found", nil)
+}
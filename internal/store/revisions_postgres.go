@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"learnforge/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+func (s *PostgresStore) AddRevision(ctx context.Context, rev *domain.ResultRevision, result *domain.StoredResult) error {
+	revisionJSON, err := json.Marshal(rev)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO result_revisions (result_id, number, revision_json, created_at) VALUES ($1, $2, $3, $4)`,
+		rev.ResultID, rev.Number, revisionJSON, rev.CreatedAt)
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return errRevisionTaken
+	}
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		if err := saveResult(ctx, tx, result); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *PostgresStore) ListRevisions(ctx context.Context, resultID string) ([]*domain.ResultRevision, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT revision_json FROM result_revisions WHERE result_id = $1 ORDER BY number`, resultID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*domain.ResultRevision
+	for rows.Next() {
+		var revisionJSON []byte
+		if err := rows.Scan(&revisionJSON); err != nil {
+			return nil, err
+		}
+		var rev domain.ResultRevision
+		if err := json.Unmarshal(revisionJSON, &rev); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (s *PostgresStore) GetRevision(ctx context.Context, resultID string, number int) (*domain.ResultRevision, error) {
+	var revisionJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT revision_json FROM result_revisions WHERE result_id = $1 AND number = $2`,
+		resultID, number).Scan(&revisionJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "revision not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rev domain.ResultRevision
+	if err := json.Unmarshal(revisionJSON, &rev); err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
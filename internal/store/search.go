@@ -0,0 +1,27 @@
+package store
+
+import (
+	"encoding/json"
+	"strings"
+
+	"learnforge/internal/domain"
+)
+
+// searchDocument returns the text a result is found by: its summary, key
+// points, flashcards and quiz items.
+func searchDocument(result *domain.StoredResult) string {
+	var resp domain.ProcessResponse
+	if err := json.Unmarshal(result.ResponseJSON, &resp); err != nil {
+		return ""
+	}
+	parts := []string{resp.Topic, resp.Summary}
+	parts = append(parts, resp.KeyPoints...)
+	for _, card := range resp.Flashcards {
+		parts = append(parts, card.Q, card.A)
+	}
+	for _, item := range resp.Quiz {
+		parts = append(parts, item.Q)
+		parts = append(parts, item.Choices...)
+	}
+	return strings.Join(parts, "\n")
+}
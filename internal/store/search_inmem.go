@@ -0,0 +1,150 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/textutil"
+)
+
+// searchIndex maps content words to the results containing them. It is
+// guarded by the store's mutex.
+type searchIndex struct {
+	postings map[string]map[string]int // word -> result ID -> occurrences
+	words    map[string][]string       // result ID -> its distinct words
+}
+
+func newSearchIndex() searchIndex {
+	return searchIndex{
+		postings: make(map[string]map[string]int),
+		words:    make(map[string][]string),
+	}
+}
+
+// add indexes text for id, replacing what was indexed before.
+func (idx searchIndex) add(id, text string) {
+	for _, word := range idx.words[id] {
+		delete(idx.postings[word], id)
+		if len(idx.postings[word]) == 0 {
+			delete(idx.postings, word)
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, word := range textutil.ContentWords(text) {
+		counts[word]++
+	}
+	words := make([]string, 0, len(counts))
+	for word, n := range counts {
+		if idx.postings[word] == nil {
+			idx.postings[word] = make(map[string]int)
+		}
+		idx.postings[word][id] = n
+		words = append(words, word)
+	}
+	idx.words[id] = words
+}
+
+// match scores the results containing every content word of query by
+// their total occurrences. A query without content words matches nothing.
+func (idx searchIndex) match(query string) map[string]int {
+	var scores map[string]int
+	for _, word := range textutil.ContentWords(query) {
+		next := make(map[string]int)
+		for id, n := range idx.postings[word] {
+			if scores == nil {
+				next[id] = n
+			} else if score, ok := scores[id]; ok {
+				next[id] = score + n
+			}
+		}
+		scores = next
+		if len(scores) == 0 {
+			break
+		}
+	}
+	return scores
+}
+
+func (s *InMemStore) ListResults(ctx context.Context, query domain.ResultQuery) ([]*domain.StoredResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var scores map[string]int
+	if query.Search != "" {
+		scores = s.search.match(query.Search)
+	}
+
+	var results []*domain.StoredResult
+	for id, result := range s.results {
+		if _, ok := scores[id]; query.Search != "" && !ok {
+			continue
+		}
+		if matchesQuery(result, &query) {
+			results = append(results, result)
+		}
+	}
+
+	less := func(a, b *domain.StoredResult) bool {
+		switch query.Sort {
+		case domain.SortOldest:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+			return a.ID < b.ID
+		case domain.SortTopic:
+			if a.Topic != b.Topic {
+				return a.Topic < b.Topic
+			}
+			return a.ID < b.ID
+		case domain.SortRelevance:
+			if scores[a.ID] != scores[b.ID] {
+				return scores[a.ID] > scores[b.ID]
+			}
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	}
+	sort.Slice(results, func(i, j int) bool { return less(results[i], results[j]) })
+
+	if c := query.Cursor; c != nil {
+		start := 0
+		if query.Sort == domain.SortRelevance {
+			start = c.Offset
+		} else {
+			last := &domain.StoredResult{ID: c.ID, Topic: c.Key}
+			if query.Sort != domain.SortTopic {
+				last.CreatedAt, _ = time.Parse(time.RFC3339Nano, c.Key)
+			}
+			start = sort.Search(len(results), func(i int) bool { return less(last, results[i]) })
+		}
+		if start > len(results) {
+			start = len(results)
+		}
+		results = results[start:]
+	}
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+func matchesQuery(result *domain.StoredResult, q *domain.ResultQuery) bool {
+	switch {
+	case q.Topic != "" && !strings.EqualFold(result.Topic, q.Topic),
+		q.Mode != "" && result.Mode != q.Mode,
+		q.Level != "" && result.Level != q.Level,
+		q.Language != "" && result.Language != q.Language,
+		q.Provider != "" && result.Provider != q.Provider,
+		q.Status != "" && result.Status != q.Status,
+		!q.From.IsZero() && result.CreatedAt.Before(q.From),
+		!q.To.IsZero() && !result.CreatedAt.Before(q.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func (s *PostgresStore) ListResults(ctx context.Context, query domain.ResultQuery) ([]*domain.StoredResult, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if query.Topic != "" {
+		where = append(where, "lower(topic) = lower("+arg(query.Topic)+")")
+	}
+	for column, value := range map[string]string{
+		"mode":     query.Mode,
+		"level":    query.Level,
+		"language": query.Language,
+		"provider": query.Provider,
+		"status":   query.Status,
+	} {
+		if value != "" {
+			where = append(where, column+" = "+arg(value))
+		}
+	}
+	if !query.From.IsZero() {
+		where = append(where, "created_at >= "+arg(query.From))
+	}
+	if !query.To.IsZero() {
+		where = append(where, "created_at < "+arg(query.To))
+	}
+	tsquery := ""
+	if query.Search != "" {
+		tsquery = "websearch_to_tsquery('english', " + arg(query.Search) + ")"
+		where = append(where, "search_vector @@ "+tsquery)
+	}
+
+	var order, offset string
+	switch query.Sort {
+	case domain.SortOldest:
+		order = "created_at, id"
+	case domain.SortTopic:
+		order = "topic, id"
+	case domain.SortRelevance:
+		order = "ts_rank(search_vector, " + tsquery + ") DESC, created_at DESC, id DESC"
+	default:
+		order = "created_at DESC, id DESC"
+	}
+	if c := query.Cursor; c != nil {
+		switch query.Sort {
+		case domain.SortRelevance:
+			offset = " OFFSET " + arg(c.Offset)
+		case domain.SortTopic:
+			where = append(where, "(topic, id) > ("+arg(c.Key)+", "+arg(c.ID)+")")
+		default:
+			createdAt, err := time.Parse(time.RFC3339Nano, c.Key)
+			if err != nil {
+				return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "invalid cursor", err)
+			}
+			op := "<"
+			if query.Sort == domain.SortOldest {
+				op = ">"
+			}
+			where = append(where, "(created_at, id) "+op+" ("+arg(createdAt)+", "+arg(c.ID)+")")
+		}
+	}
+
+	sqlQuery := `SELECT ` + resultColumns + ` FROM processed_results`
+	if len(where) > 0 {
+		sqlQuery += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	sqlQuery += ` ORDER BY ` + order
+	if query.Limit > 0 {
+		sqlQuery += ` LIMIT ` + arg(query.Limit)
+	}
+	sqlQuery += offset
+	return s.queryResults(ctx, sqlQuery, args...)
+}
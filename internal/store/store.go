@@ -12,5 +12,196 @@ type Store interface {
 	Get(ctx context.Context, id string) (*domain.StoredResult, error)
 	GetByTopic(ctx context.Context, topic string, limit int) ([]*domain.StoredResult, error)
 	GetByDateRange(ctx context.Context, start, end time.Time) ([]*domain.StoredResult, error)
+	// ListResults returns up to query.Limit results matching query, in its
+	// sort order and after its cursor. Search matches every word of
+	// query.Search against the summary, key points and items.
+	ListResults(ctx context.Context, query domain.ResultQuery) ([]*domain.StoredResult, error)
 	Close() error
 }
+
+type ExperimentStore interface {
+	SaveExperimentEvent(ctx context.Context, event *domain.ExperimentEvent) error
+	ListExperimentEvents(ctx context.Context, experiment string) ([]*domain.ExperimentEvent, error)
+}
+
+type CourseStore interface {
+	SaveCourse(ctx context.Context, course *domain.Course) error
+	GetCourse(ctx context.Context, id string) (*domain.Course, error)
+	ListCourses(ctx context.Context) ([]*domain.Course, error)
+}
+
+type FeedStore interface {
+	SaveFeed(ctx context.Context, feed *domain.FeedSubscription) error
+	GetFeed(ctx context.Context, id string) (*domain.FeedSubscription, error)
+	ListFeeds(ctx context.Context) ([]*domain.FeedSubscription, error)
+	DeleteFeed(ctx context.Context, id string) error
+}
+
+// DeckStore holds user-defined decks.
+type DeckStore interface {
+	SaveDeck(ctx context.Context, deck *domain.Deck) error
+	GetDeck(ctx context.Context, id string) (*domain.Deck, error)
+	// ListDecks returns decks by name. Empty filters match everything.
+	ListDecks(ctx context.Context, owner, tag string) ([]*domain.Deck, error)
+	DeleteDeck(ctx context.Context, id string) error
+}
+
+// CardStateStore holds learners' spaced-repetition state per flashcard.
+type CardStateStore interface {
+	SaveCardState(ctx context.Context, state *domain.CardState) error
+	GetCardState(ctx context.Context, learnerID, resultID, cardID string) (*domain.CardState, error)
+	// ListCardStates returns a learner's states for cards of resultIDs, or
+	// for all cards when resultIDs is empty.
+	ListCardStates(ctx context.Context, learnerID string, resultIDs []string) ([]*domain.CardState, error)
+	// ListDueCardStates returns up to limit states due at or before due,
+	// most overdue first.
+	ListDueCardStates(ctx context.Context, learnerID string, due time.Time, limit int) ([]*domain.CardState, error)
+}
+
+// AttemptStore holds learners' scored quiz attempts.
+type AttemptStore interface {
+	SaveAttempt(ctx context.Context, attempt *domain.Attempt) error
+	// ListAttempts returns a learner's attempts, newest first. A limit of
+	// 0 returns all of them.
+	ListAttempts(ctx context.Context, learnerID string, limit int) ([]*domain.Attempt, error)
+	// ListResultAttempts returns all attempts of a result's quiz, oldest
+	// first.
+	ListResultAttempts(ctx context.Context, resultID string) ([]*domain.Attempt, error)
+	// ListAttemptedResults returns the IDs of results attempted in
+	// [from, to). Exam attempts are left out.
+	ListAttemptedResults(ctx context.Context, from, to time.Time) ([]string, error)
+}
+
+// ExamStore holds built exams. Exams do not change once built.
+type ExamStore interface {
+	SaveExam(ctx context.Context, exam *domain.Exam) error
+	GetExam(ctx context.Context, id string) (*domain.Exam, error)
+	// ListExams returns all exams, newest first.
+	ListExams(ctx context.Context) ([]*domain.Exam, error)
+	DeleteExam(ctx context.Context, id string) error
+}
+
+// FeedbackStore holds learners' feedback on results and their items.
+type FeedbackStore interface {
+	// SaveFeedback stores feedback, replacing the learner's earlier
+	// feedback on the same item.
+	SaveFeedback(ctx context.Context, feedback *domain.Feedback) error
+	// ListResultFeedback returns the feedback on resultIDs, oldest first.
+	ListResultFeedback(ctx context.Context, resultIDs []string) ([]*domain.Feedback, error)
+	// ListFeedback returns the feedback given in [from, to), oldest first.
+	ListFeedback(ctx context.Context, from, to time.Time) ([]*domain.Feedback, error)
+}
+
+// JobStore is a durable queue of processing jobs. Workers lease jobs for
+// a visibility timeout; a job whose lease expires without a heartbeat is
+// handed to another worker.
+type JobStore interface {
+	EnqueueJob(ctx context.Context, job *domain.Job) error
+	GetJob(ctx context.Context, id string) (*domain.Job, error)
+	// ClaimJob leases the oldest available job under leaseID until
+	// lockedUntil and increments its attempts. It returns nil when no job
+	// is available.
+	ClaimJob(ctx context.Context, leaseID string, lockedUntil time.Time) (*domain.Job, error)
+	// HeartbeatJob extends a lease and records progress. It returns the
+	// job so workers notice cancellation, or a conflict error when the
+	// lease was lost.
+	HeartbeatJob(ctx context.Context, id, leaseID, progress string, lockedUntil time.Time) (*domain.Job, error)
+	// FinishJob stores the outcome of an attempt and releases the lease:
+	// a terminal status, or queued again for a retry at AvailableAt.
+	FinishJob(ctx context.Context, job *domain.Job) error
+	// CancelJob cancels a queued job, or asks the worker running it to
+	// stop. Finished jobs cannot be canceled.
+	CancelJob(ctx context.Context, id string) (*domain.Job, error)
+
+	// CreateBatch stores a batch and enqueues its jobs together.
+	CreateBatch(ctx context.Context, batch *domain.Batch, jobs []*domain.Job) error
+	// GetBatch returns a batch with Counts filled from its jobs.
+	GetBatch(ctx context.Context, id string) (*domain.Batch, error)
+	// ListBatchJobs returns a batch's jobs in submission order.
+	ListBatchJobs(ctx context.Context, batchID string, offset, limit int) ([]*domain.Job, error)
+}
+
+// WebhookStore holds registered webhook endpoints and the delivery log.
+type WebhookStore interface {
+	SaveWebhook(ctx context.Context, endpoint *domain.WebhookEndpoint) error
+	GetWebhook(ctx context.Context, id string) (*domain.WebhookEndpoint, error)
+	ListWebhooks(ctx context.Context) ([]*domain.WebhookEndpoint, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	// ListDeliveries returns the newest deliveries first. Empty filters
+	// match everything.
+	ListDeliveries(ctx context.Context, endpointID string, status domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error)
+	// ClaimDeliveries returns up to limit pending deliveries that are due
+	// and postpones them to leaseUntil, so other instances skip them
+	// while they are being sent.
+	ClaimDeliveries(ctx context.Context, leaseUntil time.Time, limit int) ([]*domain.WebhookDelivery, error)
+}
+
+// RevisionStore keeps the edit history of results.
+type RevisionStore interface {
+	// AddRevision appends rev and, when result is not nil, saves the
+	// result in the same transaction. It fails with a conflict when the
+	// revision number is taken, so concurrent edits cannot both win.
+	AddRevision(ctx context.Context, rev *domain.ResultRevision, result *domain.StoredResult) error
+	// ListRevisions returns a result's revisions, oldest first.
+	ListRevisions(ctx context.Context, resultID string) ([]*domain.ResultRevision, error)
+	GetRevision(ctx context.Context, resultID string, number int) (*domain.ResultRevision, error)
+}
+
+// ReviewStore keeps the review status of results and its audit trail.
+// Save keeps the status of an existing result; status changes only happen
+// through AddReviewEvent.
+type ReviewStore interface {
+	// AddReviewEvent appends event to the audit trail. When event.To
+	// differs from event.From the result moves to event.To in the same
+	// transaction; it fails with a conflict when the result is no longer
+	// in event.From.
+	AddReviewEvent(ctx context.Context, event *domain.ReviewEvent) error
+	// ListReviewEvents returns a result's review events, oldest first.
+	ListReviewEvents(ctx context.Context, resultID string) ([]*domain.ReviewEvent, error)
+	// ListResultsByStatus returns up to limit results in status, oldest
+	// first.
+	ListResultsByStatus(ctx context.Context, status string, limit int) ([]*domain.StoredResult, error)
+}
+
+// IdempotencyStore records idempotency keys. Keys are unique per scope.
+type IdempotencyStore interface {
+	// ReserveIdempotencyKey claims record's key for a new request. It
+	// returns nil when the key was free, expired, or held by a pending
+	// claim whose lock passed now; otherwise it returns the existing
+	// record and stores nothing.
+	ReserveIdempotencyKey(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error)
+	// CompleteIdempotencyKey marks a pending key as done with resultID.
+	CompleteIdempotencyKey(ctx context.Context, scope, key, fingerprint, resultID string) error
+	// ReleaseIdempotencyKey frees a pending key after its request failed.
+	ReleaseIdempotencyKey(ctx context.Context, scope, key, fingerprint string) error
+	// DeleteExpiredIdempotencyKeys removes keys that expired before now.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) error
+}
+
+var (
+	errLeaseLost     = domain.NewDomainError(domain.ErrorCodeConflict, "job lease lost", nil)
+	errJobFinished   = domain.NewDomainError(domain.ErrorCodeConflict, "job already finished", nil)
+	errRevisionTaken = domain.NewDomainError(domain.ErrorCodeConflict, "result was edited concurrently; reload and try again", nil)
+	errStatusChanged = domain.NewDomainError(domain.ErrorCodeConflict, "review status changed concurrently; reload and try again", nil)
+)
+
+// Backend is implemented by every storage backend and groups the result
+// store with the feature-specific stores.
+type Backend interface {
+	Store
+	ExperimentStore
+	CourseStore
+	FeedStore
+	JobStore
+	WebhookStore
+	IdempotencyStore
+	RevisionStore
+	ReviewStore
+	DeckStore
+	CardStateStore
+	AttemptStore
+	ExamStore
+	FeedbackStore
+}
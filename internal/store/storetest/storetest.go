@@ -0,0 +1,32 @@
+// Package storetest seeds stores for tests of the packages built on them.
+package storetest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+)
+
+// SaveResult saves result with resp as its response. The topic defaults to
+// resp's, and the creation time to now.
+func SaveResult(t testing.TB, st store.Store, result domain.StoredResult, resp domain.ProcessResponse) {
+	t.Helper()
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result.ResponseJSON = data
+	if result.Topic == "" {
+		result.Topic = resp.Topic
+	}
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now()
+	}
+	if err := st.Save(context.Background(), &result); err != nil {
+		t.Fatal(err)
+	}
+}
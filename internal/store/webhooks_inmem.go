@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func (s *InMemStore) SaveWebhook(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *endpoint
+	s.webhooks[endpoint.ID] = &stored
+	return nil
+}
+
+func (s *InMemStore) GetWebhook(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	endpoint, ok := s.webhooks[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "webhook not found", nil)
+	}
+	copied := *endpoint
+	return &copied, nil
+}
+
+func (s *InMemStore) ListWebhooks(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	endpoints := make([]*domain.WebhookEndpoint, 0, len(s.webhooks))
+	for _, endpoint := range s.webhooks {
+		copied := *endpoint
+		endpoints = append(endpoints, &copied)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].CreatedAt.Before(endpoints[j].CreatedAt) })
+	return endpoints, nil
+}
+
+func (s *InMemStore) DeleteWebhook(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[id]; !ok {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "webhook not found", nil)
+	}
+	delete(s.webhooks, id)
+	return nil
+}
+
+// copyDelivery keeps callers from sharing the attempts slice with the
+// stored delivery.
+func copyDelivery(d *domain.WebhookDelivery) *domain.WebhookDelivery {
+	copied := *d
+	copied.Attempts = append([]domain.DeliveryAttempt(nil), d.Attempts...)
+	return &copied
+}
+
+func (s *InMemStore) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[delivery.ID] = copyDelivery(delivery)
+	return nil
+}
+
+func (s *InMemStore) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "webhook delivery not found", nil)
+	}
+	return copyDelivery(delivery), nil
+}
+
+func (s *InMemStore) ListDeliveries(ctx context.Context, endpointID string, status domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var deliveries []*domain.WebhookDelivery
+	for _, d := range s.deliveries {
+		if (endpointID == "" || d.EndpointID == endpointID) && (status == "" || d.Status == status) {
+			deliveries = append(deliveries, copyDelivery(d))
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt) })
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *InMemStore) ClaimDeliveries(ctx context.Context, leaseUntil time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var due []*domain.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*domain.WebhookDelivery, len(due))
+	for i, d := range due {
+		d.NextAttemptAt = leaseUntil
+		claimed[i] = copyDelivery(d)
+	}
+	return claimed, nil
+}
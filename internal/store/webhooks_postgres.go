@@ -0,0 +1,166 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+func (s *PostgresStore) SaveWebhook(ctx context.Context, endpoint *domain.WebhookEndpoint) error {
+	endpointJSON, err := json.Marshal(endpoint)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhook_endpoints (id, endpoint_json, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO UPDATE SET endpoint_json = EXCLUDED.endpoint_json
+	`
+	_, err = s.db.ExecContext(ctx, query, endpoint.ID, endpointJSON, endpoint.CreatedAt)
+	return err
+}
+
+func (s *PostgresStore) GetWebhook(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	var endpointJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT endpoint_json FROM webhook_endpoints WHERE id = $1`, id).Scan(&endpointJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "webhook not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoint domain.WebhookEndpoint
+	if err := json.Unmarshal(endpointJSON, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (s *PostgresStore) ListWebhooks(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT endpoint_json FROM webhook_endpoints ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var endpoints []*domain.WebhookEndpoint
+	for rows.Next() {
+		var endpointJSON []byte
+		if err := rows.Scan(&endpointJSON); err != nil {
+			return nil, err
+		}
+		var endpoint domain.WebhookEndpoint
+		if err := json.Unmarshal(endpointJSON, &endpoint); err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, &endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
+
+func (s *PostgresStore) DeleteWebhook(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "webhook not found", nil)
+	}
+	return nil
+}
+
+func (s *PostgresStore) SaveDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	deliveryJSON, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (id, endpoint_id, status, next_attempt_at, delivery_json, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			delivery_json = EXCLUDED.delivery_json,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err = s.db.ExecContext(ctx, query, delivery.ID, delivery.EndpointID, delivery.Status, delivery.NextAttemptAt,
+		deliveryJSON, delivery.CreatedAt, delivery.UpdatedAt)
+	return err
+}
+
+func (s *PostgresStore) GetDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	var deliveryJSON []byte
+	err := s.db.QueryRowContext(ctx, `SELECT delivery_json FROM webhook_deliveries WHERE id = $1`, id).Scan(&deliveryJSON)
+	if err == sql.ErrNoRows {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "webhook delivery not found", nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var delivery domain.WebhookDelivery
+	if err := json.Unmarshal(deliveryJSON, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (s *PostgresStore) queryDeliveries(ctx context.Context, query string, args ...interface{}) ([]*domain.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var deliveryJSON []byte
+		if err := rows.Scan(&deliveryJSON); err != nil {
+			return nil, err
+		}
+		var delivery domain.WebhookDelivery
+		if err := json.Unmarshal(deliveryJSON, &delivery); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (s *PostgresStore) ListDeliveries(ctx context.Context, endpointID string, status domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		SELECT delivery_json FROM webhook_deliveries
+		WHERE ($1 = '' OR endpoint_id = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+	if limit <= 0 {
+		limit = 100
+	}
+	return s.queryDeliveries(ctx, query, endpointID, string(status), limit)
+}
+
+// ClaimDeliveries moves next_attempt_at to the lease; the stored JSON is
+// brought up to date when the attempt is saved.
+func (s *PostgresStore) ClaimDeliveries(ctx context.Context, leaseUntil time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING delivery_json
+	`
+	return s.queryDeliveries(ctx, query, leaseUntil, limit)
+}
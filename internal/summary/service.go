@@ -5,27 +5,66 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	"learnforge/internal/analytics"
 	"learnforge/internal/cache"
+	"learnforge/internal/domain"
+	"learnforge/internal/feedback"
 	"learnforge/internal/slack"
 	"learnforge/internal/store"
 )
 
+// maxSlackFlaggedItems bounds the flagged questions listed in Slack.
+const maxSlackFlaggedItems = 5
+
+// maxSlackFeedbackGroups bounds the prompt versions and models listed in
+// Slack.
+const maxSlackFeedbackGroups = 3
+
+// feedbackWindow is the period of the feedback counts in summaries.
+const feedbackWindow = 7 * 24 * time.Hour
+
 type Service struct {
 	store    store.Store
 	cache    cache.Cache
 	slack    *slack.Client
 	slackErr *slack.Client
+	items    *analytics.Analyzer
+	feedback *feedback.Service
+}
+
+// Option configures a Service.
+type Option func(*Service)
+
+// WithItemAnalytics adds quiz items flagged by item analytics to daily
+// summaries.
+func WithItemAnalytics(items *analytics.Analyzer) Option {
+	return func(s *Service) {
+		s.items = items
+	}
+}
+
+// WithFeedback adds the learner feedback of the week ending with the day
+// to daily summaries.
+func WithFeedback(feedback *feedback.Service) Option {
+	return func(s *Service) {
+		s.feedback = feedback
+	}
 }
 
-func NewService(store store.Store, cache cache.Cache, slackSummary, slackError *slack.Client) *Service {
-	return &Service{
+func NewService(store store.Store, cache cache.Cache, slackSummary, slackError *slack.Client, opts ...Option) *Service {
+	s := &Service{
 		store:    store,
 		cache:    cache,
 		slack:    slackSummary,
 		slackErr: slackError,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 type DailySummary struct {
@@ -33,6 +72,14 @@ type DailySummary struct {
 	TotalRequests int          `json:"total_requests"`
 	Topics        []TopicStats `json:"topics"`
 	Errors        int          `json:"errors"`
+
+	// FlaggedItems are quiz items of results attempted that day that
+	// item analytics flagged, worst first.
+	FlaggedItems []domain.ItemStats `json:"flagged_items,omitempty"`
+
+	// Feedback is the learner feedback of the seven days ending with the
+	// day.
+	Feedback *domain.FeedbackReport `json:"feedback,omitempty"`
 }
 
 type TopicStats struct {
@@ -81,6 +128,22 @@ func (s *Service) GenerateDailySummary(ctx context.Context, date time.Time) (*Da
 		return summary.Topics[i].Count > summary.Topics[j].Count
 	})
 
+	if s.items != nil {
+		flagged, err := s.items.Flagged(ctx, startOfDay, endOfDay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze quiz items: %w", err)
+		}
+		summary.FlaggedItems = flagged
+	}
+
+	if s.feedback != nil {
+		report, err := s.feedback.Report(ctx, endOfDay.Add(-feedbackWindow), endOfDay)
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate feedback: %w", err)
+		}
+		summary.Feedback = report
+	}
+
 	summaryJSON, _ := json.Marshal(summary)
 	s.cache.Set(ctx, key, string(summaryJSON), 7*24*time.Hour)
 
@@ -111,9 +174,56 @@ func (s *Service) SendSummaryToSlack(ctx context.Context, summary *DailySummary)
 		}
 	}
 
+	if len(summary.FlaggedItems) > 0 {
+		content += fmt.Sprintf("\n*Flagged Questions (%d):*\n", len(summary.FlaggedItems))
+		for i, item := range summary.FlaggedItems {
+			if i >= maxSlackFlaggedItems {
+				break
+			}
+			content += fmt.Sprintf("• %s: %q (%s; p=%.2f, n=%d) `/v1/process/%s/item-stats`\n",
+				item.Topic, item.Question, strings.Join(item.Flags, ", "), item.PValue, item.Responses, item.ResultID)
+		}
+	}
+
+	if fb := summary.Feedback; fb != nil && fb.Totals.Up+fb.Totals.Down > 0 {
+		content += fmt.Sprintf("\n*Learner Feedback (last 7 days):* 👍 %d · 👎 %d\n", fb.Totals.Up, fb.Totals.Down)
+		if categories := formatCategories(fb.Totals.Categories); categories != "" {
+			content += fmt.Sprintf("• %s\n", categories)
+		}
+		for i, group := range fb.Groups {
+			if i >= maxSlackFeedbackGroups {
+				break
+			}
+			content += fmt.Sprintf("• Prompt %s, %s/%s: 👍 %d · 👎 %d\n",
+				group.PromptVersion, group.Provider, group.Model, group.Up, group.Down)
+		}
+		if len(fb.LowRated) > 0 {
+			content += fmt.Sprintf("• %d low-rated items `/v1/admin/feedback`\n", len(fb.LowRated))
+		}
+	}
+
 	return s.slack.SendSummary(ctx, "LearnForge Daily Summary", content)
 }
 
+// formatCategories lists feedback categories, most used first.
+func formatCategories(categories map[string]int) string {
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if categories[names[i]] != categories[names[j]] {
+			return categories[names[i]] > categories[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s: %d", strings.ReplaceAll(name, "_", " "), categories[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
 func (s *Service) LogError(ctx context.Context, err error, errorContext map[string]string) {
 	if s.slackErr != nil {
 		go func() {
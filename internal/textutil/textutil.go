@@ -0,0 +1,135 @@
+// Package textutil holds small, dependency-free text helpers shared by the
+// evaluation, ingestion and matching code.
+package textutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "have": true,
+	"in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
+	"or": true, "that": true, "the": true, "this": true, "to": true, "was": true,
+	"were": true, "what": true, "which": true, "who": true, "why": true, "will": true,
+	"with": true, "how": true, "when": true, "where": true, "does": true, "do": true,
+	"can": true, "not": true, "but": true, "into": true, "than": true, "then": true,
+	"they": true, "their": true, "these": true, "those": true, "there": true,
+}
+
+// Words splits text into lowercase words, dropping punctuation.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ContentWords returns the words of text that carry meaning: stop words and
+// one- or two-letter tokens are dropped.
+func ContentWords(text string) []string {
+	var words []string
+	for _, w := range Words(text) {
+		if len([]rune(w)) > 2 && !stopWords[w] {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// WordSet returns the set of content words in text.
+func WordSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range ContentWords(text) {
+		set[w] = true
+	}
+	return set
+}
+
+// Coverage returns the share of content words in text that also appear in
+// the reference set. Text without content words is fully covered.
+func Coverage(text string, reference map[string]bool) float64 {
+	words := ContentWords(text)
+	if len(words) == 0 {
+		return 1
+	}
+	found := 0
+	for _, w := range words {
+		if reference[w] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(words))
+}
+
+// Jaccard returns the Jaccard similarity of the content words of a and b.
+func Jaccard(a, b string) float64 {
+	setA, setB := WordSet(a), WordSet(b)
+	if len(setA) == 0 && len(setB) == 0 {
+		return 1
+	}
+	intersection := 0
+	for w := range setA {
+		if setB[w] {
+			intersection++
+		}
+	}
+	union := len(setA) + len(setB) - intersection
+	return float64(intersection) / float64(union)
+}
+
+// Sentences splits text on sentence-ending punctuation.
+func Sentences(text string) []string {
+	var sentences []string
+	var current strings.Builder
+	for _, r := range text {
+		current.WriteRune(r)
+		if r == '.' || r == '!' || r == '?' || r == '\n' {
+			if s := strings.TrimSpace(current.String()); s != "" {
+				sentences = append(sentences, s)
+			}
+			current.Reset()
+		}
+	}
+	if s := strings.TrimSpace(current.String()); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// Syllables estimates the syllable count of an English word by counting
+// vowel groups.
+func Syllables(word string) int {
+	word = strings.ToLower(word)
+	count := 0
+	prevVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+	if strings.HasSuffix(word, "e") && count > 1 && !strings.HasSuffix(word, "le") {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// GradeLevel returns the Flesch-Kincaid grade level of text.
+func GradeLevel(text string) float64 {
+	sentences := Sentences(text)
+	words := Words(text)
+	if len(sentences) == 0 || len(words) == 0 {
+		return 0
+	}
+	syllables := 0
+	for _, w := range words {
+		syllables += Syllables(w)
+	}
+	return 0.39*float64(len(words))/float64(len(sentences)) +
+		11.8*float64(syllables)/float64(len(words)) - 15.59
+}
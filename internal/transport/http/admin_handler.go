@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/domain"
+	"learnforge/internal/replay"
+	"learnforge/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ClientFactory builds an AI client for a provider and model using the
+// server's credentials.
+type ClientFactory func(provider, model string) ai.Client
+
+// AdminHandler serves operator-only endpoints behind ADMIN_API_KEY.
+type AdminHandler struct {
+	store     store.Store
+	newClient ClientFactory
+	// limiter is the model rate limit production traffic uses.
+	limiter *ai.Limiter
+	replays *replay.Runs
+	apiKey  string
+}
+
+func NewAdminHandler(store store.Store, newClient ClientFactory, limiter *ai.Limiter, replays *replay.Runs, apiKey string) *AdminHandler {
+	return &AdminHandler{
+		store:     store,
+		newClient: newClient,
+		limiter:   limiter,
+		replays:   replays,
+		apiKey:    apiKey,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(RequireAPIKey(h.apiKey))
+		r.Post("/v1/admin/replays", h.startReplay)
+		r.Get("/v1/admin/replays/{id}", h.getReplay)
+	})
+}
+
+type replayRequest struct {
+	Candidate       replay.Candidate `json:"candidate"`
+	Start           *time.Time       `json:"start,omitempty"`
+	End             *time.Time       `json:"end,omitempty"`
+	Topic           string           `json:"topic,omitempty"`
+	Limit           int              `json:"limit"`
+	BaselinePricing replay.Pricing   `json:"baseline_pricing"`
+}
+
+// startReplay starts a replay in the background. Replaying can take far
+// longer than the server's write timeout, so the report is polled.
+func (h *AdminHandler) startReplay(w http.ResponseWriter, r *http.Request) {
+	var req replayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid request body", err)
+		return
+	}
+	if req.Candidate.Model == "" {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "candidate.model is required", nil)
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	opts := replay.Options{
+		Topic:           req.Topic,
+		Limit:           req.Limit,
+		BaselinePricing: req.BaselinePricing,
+	}
+	if req.Start != nil {
+		opts.Start = *req.Start
+	}
+	if req.End != nil {
+		opts.End = *req.End
+	}
+
+	client := h.newClient(req.Candidate.Provider, req.Candidate.Model)
+	run, err := h.replays.Start(replay.NewRunner(h.store, client, req.Candidate, replay.WithLimiter(h.limiter)), opts)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", "/v1/admin/replays/"+run.ID)
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+func (h *AdminHandler) getReplay(w http.ResponseWriter, r *http.Request) {
+	run, err := h.replays.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"learnforge/internal/analytics"
+	"learnforge/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AnalyticsHandler exposes quiz item statistics. Every route requires the
+// admin API key.
+type AnalyticsHandler struct {
+	analyzer *analytics.Analyzer
+	adminKey string
+}
+
+func NewAnalyticsHandler(analyzer *analytics.Analyzer, adminKey string) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyzer: analyzer,
+		adminKey: adminKey,
+	}
+}
+
+func (h *AnalyticsHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(RequireAPIKey(h.adminKey))
+		r.Get("/v1/process/{id}/item-stats", h.quizStats)
+		r.Get("/v1/admin/item-stats/flagged", h.flagged)
+	})
+}
+
+func (h *AnalyticsHandler) quizStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.analyzer.QuizStats(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// flagged lists flagged items of results attempted in the last days days,
+// 30 by default.
+func (h *AnalyticsHandler) flagged(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "days must be between 1 and 365", err)
+			return
+		}
+		days = n
+	}
+	now := time.Now().UTC()
+	items, err := h.analyzer.Flagged(r.Context(), now.AddDate(0, 0, -days), now)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": items})
+}
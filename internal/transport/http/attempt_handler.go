@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"learnforge/internal/attempt"
+	"learnforge/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// AttemptHandler records quiz attempts and serves learner history.
+// Learners are identified by X-User-ID and can only read their own
+// history unless the request carries the admin API key.
+type AttemptHandler struct {
+	recorder *attempt.Recorder
+	adminKey string
+}
+
+func NewAttemptHandler(recorder *attempt.Recorder, adminKey string) *AttemptHandler {
+	return &AttemptHandler{
+		recorder: recorder,
+		adminKey: adminKey,
+	}
+}
+
+func (h *AttemptHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/v1/process/{id}/attempts", h.submit)
+	r.Get("/v1/learners/{id}/history", h.history)
+}
+
+func (h *AttemptHandler) submit(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Answers []attempt.Answer `json:"answers"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	result, err := h.recorder.Submit(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), body.Answers, !hasAPIKey(r, h.adminKey))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, result)
+}
+
+func (h *AttemptHandler) history(w http.ResponseWriter, r *http.Request) {
+	learnerID := chi.URLParam(r, "id")
+	if r.Header.Get("X-User-ID") != learnerID && !hasAPIKey(r, h.adminKey) {
+		writeError(w, http.StatusForbidden, domain.ErrorCodeInvalidArgument, "learners can only read their own history", nil)
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "limit must be a positive integer", err)
+			return
+		}
+		limit = n
+	}
+	history, err := h.recorder.History(r.Context(), learnerID, limit, !hasAPIKey(r, h.adminKey))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
@@ -0,0 +1,246 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/jobs"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxBatchBodyBytes bounds a batch submission, whether JSON or JSONL.
+const maxBatchBodyBytes = 64 << 20
+
+// BatchHandler runs many requests as one unit. Each request becomes a job
+// on the shared worker pool, so items run concurrently within the model
+// rate limit and are retried independently.
+type BatchHandler struct {
+	pool    *jobs.Pool
+	results interface {
+		GetResult(ctx context.Context, id string) (*domain.ProcessResponse, error)
+	}
+}
+
+func NewBatchHandler(pool *jobs.Pool, results interface {
+	GetResult(ctx context.Context, id string) (*domain.ProcessResponse, error)
+}) *BatchHandler {
+	return &BatchHandler{
+		pool:    pool,
+		results: results,
+	}
+}
+
+func (h *BatchHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/v1/batches", h.createBatch)
+	r.Get("/v1/batches/{id}", h.getBatch)
+	r.Get("/v1/batches/{id}/items", h.listItems)
+	r.Get("/v1/batches/{id}/results", h.downloadResults)
+	r.Post("/v1/batches/{id}/cancel", h.cancelBatch)
+}
+
+// createBatch accepts {"requests": [...]}, a JSONL body with one request
+// per line, or a multipart form with a JSONL file in "file".
+func (h *BatchHandler) createBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)
+
+	reqs, err := decodeBatchRequests(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, domain.ErrorCodeInvalidArgument, "batch is too large", err)
+			return
+		}
+		handleServiceError(w, err)
+		return
+	}
+	for _, req := range reqs {
+		req.APIKey = r.Header.Get("X-API-Key")
+		req.UserID = r.Header.Get("X-User-ID")
+	}
+
+	batch, err := h.pool.EnqueueBatch(r.Context(), reqs)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/v1/batches/"+batch.ID)
+	writeJSON(w, http.StatusAccepted, batch)
+}
+
+func decodeBatchRequests(r *http.Request) ([]*domain.ProcessRequest, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-ndjson", "application/jsonl":
+		return decodeJSONL(r.Body)
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(8 << 20); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, err
+			}
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "invalid multipart form", err)
+		}
+		defer r.MultipartForm.RemoveAll()
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "file is required", err)
+		}
+		defer file.Close()
+		return decodeJSONL(file)
+	default:
+		var body struct {
+			Requests []*domain.ProcessRequest `json:"requests"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, err
+			}
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "invalid request body", err)
+		}
+		for i, req := range body.Requests {
+			if req == nil {
+				return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, fmt.Sprintf("requests[%d]: must be an object", i), nil)
+			}
+		}
+		return body.Requests, nil
+	}
+}
+
+// decodeJSONL reads one request per non-blank line.
+func decodeJSONL(r io.Reader) ([]*domain.ProcessRequest, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxBatchBodyBytes)
+
+	var reqs []*domain.ProcessRequest
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var req domain.ProcessRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, fmt.Sprintf("line %d: invalid JSON", line), err)
+		}
+		reqs = append(reqs, &req)
+	}
+	if err := scanner.Err(); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, err
+		}
+		return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument, "failed to read batch", err)
+	}
+	return reqs, nil
+}
+
+func (h *BatchHandler) getBatch(w http.ResponseWriter, r *http.Request) {
+	batch, err := h.pool.GetBatch(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
+
+func (h *BatchHandler) listItems(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	offset := 0
+	if v := query.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "offset must be a non-negative integer", err)
+			return
+		}
+		offset = n
+	}
+	limit := 100
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "limit must be between 1 and 500", err)
+			return
+		}
+		limit = n
+	}
+
+	batchJobs, err := h.pool.BatchJobs(r.Context(), chi.URLParam(r, "id"), offset, limit)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	items := make([]domain.BatchItem, 0, len(batchJobs))
+	for _, job := range batchJobs {
+		items = append(items, job.Item())
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"items":  items,
+		"offset": offset,
+		"limit":  limit,
+	})
+}
+
+// batchResultLine is one line of the results download.
+type batchResultLine struct {
+	domain.BatchItem
+	Result *domain.ProcessResponse `json:"result,omitempty"`
+}
+
+// downloadResults streams one JSONL line per item in submission order.
+// It may be called while the batch runs; unfinished items carry their
+// current status and no result.
+func (h *BatchHandler) downloadResults(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if _, err := h.pool.GetBatch(r.Context(), id); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="batch-%s.jsonl"`, id))
+	w.WriteHeader(http.StatusOK)
+
+	const page = 200
+	enc := json.NewEncoder(w)
+	for offset := 0; ; offset += page {
+		batchJobs, err := h.pool.BatchJobs(r.Context(), id, offset, page)
+		if err != nil {
+			// Headers are already sent; a truncated download is all we can signal.
+			return
+		}
+		for _, job := range batchJobs {
+			line := batchResultLine{BatchItem: job.Item()}
+			if job.Status == domain.JobSucceeded {
+				line.Result, err = h.results.GetResult(r.Context(), job.ResultID)
+				if err != nil {
+					line.Error = "result no longer available"
+				}
+			}
+			if err := enc.Encode(line); err != nil {
+				return
+			}
+		}
+		if len(batchJobs) < page {
+			return
+		}
+	}
+}
+
+func (h *BatchHandler) cancelBatch(w http.ResponseWriter, r *http.Request) {
+	batch, err := h.pool.CancelBatch(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, batch)
+}
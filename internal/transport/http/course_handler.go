@@ -0,0 +1,117 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"learnforge/internal/course"
+	"learnforge/internal/domain"
+	"learnforge/internal/store"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CourseHandler serves courses built from docs directories. Builds are an
+// admin operation restricted to directories under docsRoot.
+type CourseHandler struct {
+	builder  *course.Builder
+	store    store.CourseStore
+	adminKey string
+	docsRoot string
+}
+
+func NewCourseHandler(builder *course.Builder, store store.CourseStore, adminKey, docsRoot string) *CourseHandler {
+	return &CourseHandler{
+		builder:  builder,
+		store:    store,
+		adminKey: adminKey,
+		docsRoot: docsRoot,
+	}
+}
+
+func (h *CourseHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/v1/courses", h.listCourses)
+	r.Get("/v1/courses/{id}", h.getCourse)
+
+	if h.adminKey != "" && h.docsRoot != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(RequireAPIKey(h.adminKey))
+			r.Post("/v1/admin/courses", h.buildCourse)
+		})
+	}
+}
+
+func (h *CourseHandler) listCourses(w http.ResponseWriter, r *http.Request) {
+	courses, err := h.store.ListCourses(r.Context())
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	if courses == nil {
+		courses = []*domain.Course{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"courses": courses})
+}
+
+func (h *CourseHandler) getCourse(w http.ResponseWriter, r *http.Request) {
+	c, err := h.store.GetCourse(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, c)
+}
+
+type buildCourseRequest struct {
+	Dir      string `json:"dir"` // relative to DOCS_ROOT
+	ID       string `json:"id,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Level    string `json:"level,omitempty"`
+	Language string `json:"language,omitempty"`
+	Force    bool   `json:"force,omitempty"`
+}
+
+// buildCourse starts a background build and returns 202 with the course
+// ID; poll GET /v1/courses/{id} for the result.
+func (h *CourseHandler) buildCourse(w http.ResponseWriter, r *http.Request) {
+	var req buildCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid request body", err)
+		return
+	}
+
+	dir, ok := h.resolveDir(req.Dir)
+	if !ok {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "dir must be a relative path inside DOCS_ROOT", nil)
+		return
+	}
+
+	id, err := h.builder.Start(course.Options{
+		Dir:      dir,
+		ID:       req.ID,
+		Name:     req.Name,
+		Level:    req.Level,
+		Language: req.Language,
+		Force:    req.Force,
+	})
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"id": id, "status": "building"})
+}
+
+func (h *CourseHandler) resolveDir(dir string) (string, bool) {
+	if filepath.IsAbs(dir) {
+		return "", false
+	}
+	joined := filepath.Join(h.docsRoot, filepath.Clean("/"+dir))
+	rel, err := filepath.Rel(h.docsRoot, joined)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return joined, true
+}
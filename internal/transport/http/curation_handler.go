@@ -0,0 +1,181 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"learnforge/internal/curation"
+	"learnforge/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CurationHandler lets instructors edit stored results. Edits require the
+// admin API key and an X-User-ID header naming the author; revisions are
+// readable by anyone who can read the result.
+type CurationHandler struct {
+	editor   *curation.Editor
+	adminKey string
+}
+
+func NewCurationHandler(editor *curation.Editor, adminKey string) *CurationHandler {
+	return &CurationHandler{
+		editor:   editor,
+		adminKey: adminKey,
+	}
+}
+
+func (h *CurationHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/v1/process/{id}/revisions", h.listRevisions)
+	r.Get("/v1/process/{id}/revisions/{number}", h.getRevision)
+
+	if h.adminKey == "" {
+		return
+	}
+	r.Group(func(r chi.Router) {
+		r.Use(RequireAPIKey(h.adminKey))
+		r.Patch("/v1/process/{id}", h.updateResult)
+		r.Post("/v1/process/{id}/flashcards", h.addFlashcard)
+		r.Put("/v1/process/{id}/flashcards/{itemID}", h.updateFlashcard)
+		r.Delete("/v1/process/{id}/flashcards/{itemID}", h.deleteFlashcard)
+		r.Post("/v1/process/{id}/quiz", h.addQuizItem)
+		r.Put("/v1/process/{id}/quiz/{itemID}", h.updateQuizItem)
+		r.Delete("/v1/process/{id}/quiz/{itemID}", h.deleteQuizItem)
+		r.Post("/v1/process/{id}/revisions/{number}/revert", h.revert)
+	})
+}
+
+func (h *CurationHandler) updateResult(w http.ResponseWriter, r *http.Request) {
+	var patch curation.Patch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+	resp, err := h.editor.Update(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), patch)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *CurationHandler) addFlashcard(w http.ResponseWriter, r *http.Request) {
+	var card domain.Flashcard
+	if !decodeBody(w, r, &card) {
+		return
+	}
+	added, err := h.editor.AddFlashcard(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), card)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, added)
+}
+
+func (h *CurationHandler) updateFlashcard(w http.ResponseWriter, r *http.Request) {
+	var card domain.Flashcard
+	if !decodeBody(w, r, &card) {
+		return
+	}
+	updated, err := h.editor.UpdateFlashcard(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "itemID"), r.Header.Get("X-User-ID"), card)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *CurationHandler) deleteFlashcard(w http.ResponseWriter, r *http.Request) {
+	if err := h.editor.DeleteFlashcard(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "itemID"), r.Header.Get("X-User-ID")); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CurationHandler) addQuizItem(w http.ResponseWriter, r *http.Request) {
+	var item domain.QuizItem
+	if !decodeBody(w, r, &item) {
+		return
+	}
+	added, err := h.editor.AddQuizItem(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), item)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, added)
+}
+
+func (h *CurationHandler) updateQuizItem(w http.ResponseWriter, r *http.Request) {
+	var item domain.QuizItem
+	if !decodeBody(w, r, &item) {
+		return
+	}
+	updated, err := h.editor.UpdateQuizItem(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "itemID"), r.Header.Get("X-User-ID"), item)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (h *CurationHandler) deleteQuizItem(w http.ResponseWriter, r *http.Request) {
+	if err := h.editor.DeleteQuizItem(r.Context(), chi.URLParam(r, "id"), chi.URLParam(r, "itemID"), r.Header.Get("X-User-ID")); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CurationHandler) listRevisions(w http.ResponseWriter, r *http.Request) {
+	revisions, err := h.editor.Revisions(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"revisions": revisions})
+}
+
+func (h *CurationHandler) getRevision(w http.ResponseWriter, r *http.Request) {
+	number, ok := revisionNumber(w, r)
+	if !ok {
+		return
+	}
+	rev, err := h.editor.Revision(r.Context(), chi.URLParam(r, "id"), number)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rev)
+}
+
+func (h *CurationHandler) revert(w http.ResponseWriter, r *http.Request) {
+	number, ok := revisionNumber(w, r)
+	if !ok {
+		return
+	}
+	resp, err := h.editor.Revert(r.Context(), chi.URLParam(r, "id"), number, r.Header.Get("X-User-ID"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func revisionNumber(w http.ResponseWriter, r *http.Request) (int, bool) {
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil || number < 1 {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "revision number must be a positive integer", err)
+		return 0, false
+	}
+	return number, true
+}
+
+// decodeBody decodes a JSON request body into v, writing a 400 on failure.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid request body", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"net/http"
+
+	"learnforge/internal/deck"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// DeckHandler exposes decks. Anyone may list, read and copy decks; changes
+// are made on behalf of the user in X-User-ID and only by a deck's owner.
+type DeckHandler struct {
+	manager  *deck.Manager
+	adminKey string
+}
+
+func NewDeckHandler(manager *deck.Manager, adminKey string) *DeckHandler {
+	return &DeckHandler{
+		manager:  manager,
+		adminKey: adminKey,
+	}
+}
+
+func (h *DeckHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/v1/decks", h.list)
+	r.Post("/v1/decks", h.create)
+	r.Get("/v1/decks/{id}", h.get)
+	r.Patch("/v1/decks/{id}", h.update)
+	r.Delete("/v1/decks/{id}", h.delete)
+	r.Post("/v1/decks/{id}/items", h.addItems)
+	r.Put("/v1/decks/{id}/items/{itemID}", h.tagItem)
+	r.Delete("/v1/decks/{id}/items/{itemID}", h.removeItem)
+	r.Put("/v1/decks/{id}/order", h.reorder)
+	r.Post("/v1/decks/{id}/dedupe", h.dedupe)
+	r.Post("/v1/decks/{id}/copy", h.copy)
+}
+
+func (h *DeckHandler) list(w http.ResponseWriter, r *http.Request) {
+	decks, err := h.manager.List(r.Context(), r.URL.Query().Get("owner"), r.URL.Query().Get("tag"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"decks": decks})
+}
+
+func (h *DeckHandler) create(w http.ResponseWriter, r *http.Request) {
+	var in deck.Input
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	change, err := h.manager.Create(r.Context(), r.Header.Get("X-User-ID"), in)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, change)
+}
+
+// get resolves the deck's items. Without the admin API key, items of
+// results learners cannot see are marked missing.
+func (h *DeckHandler) get(w http.ResponseWriter, r *http.Request) {
+	d, err := h.manager.Get(r.Context(), chi.URLParam(r, "id"), !hasAPIKey(r, h.adminKey))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (h *DeckHandler) update(w http.ResponseWriter, r *http.Request) {
+	var patch deck.Patch
+	if !decodeBody(w, r, &patch) {
+		return
+	}
+	d, err := h.manager.Update(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), patch)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (h *DeckHandler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.manager.Delete(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID")); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *DeckHandler) addItems(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Items []deck.ItemRef `json:"items"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	change, err := h.manager.AddItems(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), body.Items)
+	h.writeChange(w, change, err)
+}
+
+func (h *DeckHandler) tagItem(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	change, err := h.manager.TagItem(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), chi.URLParam(r, "itemID"), body.Tags)
+	h.writeChange(w, change, err)
+}
+
+func (h *DeckHandler) removeItem(w http.ResponseWriter, r *http.Request) {
+	change, err := h.manager.RemoveItem(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), chi.URLParam(r, "itemID"))
+	h.writeChange(w, change, err)
+}
+
+func (h *DeckHandler) reorder(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		ItemIDs []string `json:"item_ids"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	change, err := h.manager.Reorder(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), body.ItemIDs)
+	h.writeChange(w, change, err)
+}
+
+func (h *DeckHandler) dedupe(w http.ResponseWriter, r *http.Request) {
+	change, err := h.manager.Dedupe(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"))
+	h.writeChange(w, change, err)
+}
+
+func (h *DeckHandler) copy(w http.ResponseWriter, r *http.Request) {
+	var req deck.CopyRequest
+	if r.ContentLength != 0 && !decodeBody(w, r, &req) {
+		return
+	}
+	change, err := h.manager.Copy(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	status := http.StatusOK
+	if req.TargetDeckID == "" {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, change)
+}
+
+func (h *DeckHandler) writeChange(w http.ResponseWriter, change *deck.Change, err error) {
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, change)
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"learnforge/internal/attempt"
+	"learnforge/internal/domain"
+	"learnforge/internal/exam"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ExamHandler exposes exams. Building exams and reading their answer keys
+// require the admin API key; learners fetch a variant without answers and
+// submit attempts at it.
+type ExamHandler struct {
+	builder  *exam.Builder
+	recorder *attempt.Recorder
+	adminKey string
+}
+
+func NewExamHandler(builder *exam.Builder, recorder *attempt.Recorder, adminKey string) *ExamHandler {
+	return &ExamHandler{
+		builder:  builder,
+		recorder: recorder,
+		adminKey: adminKey,
+	}
+}
+
+func (h *ExamHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/v1/exams/{id}/variants/{n}", h.variant)
+	r.Post("/v1/exams/{id}/attempts", h.submit)
+
+	if h.adminKey != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(RequireAPIKey(h.adminKey))
+			r.Post("/v1/exams", h.build)
+			r.Get("/v1/exams", h.list)
+			r.Get("/v1/exams/{id}", h.get)
+			r.Delete("/v1/exams/{id}", h.delete)
+			r.Get("/v1/exams/{id}/variants/{n}/key", h.answerKey)
+			r.Get("/v1/exams/{id}/variants/{n}/print", h.print)
+		})
+	}
+}
+
+func (h *ExamHandler) build(w http.ResponseWriter, r *http.Request) {
+	var in exam.Input
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	e, err := h.builder.Build(r.Context(), r.Header.Get("X-User-ID"), in)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, e)
+}
+
+func (h *ExamHandler) list(w http.ResponseWriter, r *http.Request) {
+	exams, err := h.builder.List(r.Context())
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"exams": exams})
+}
+
+func (h *ExamHandler) get(w http.ResponseWriter, r *http.Request) {
+	e, err := h.builder.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (h *ExamHandler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.builder.Delete(r.Context(), chi.URLParam(r, "id")); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// variant shows the answers only to requests with the admin API key.
+func (h *ExamHandler) variant(w http.ResponseWriter, r *http.Request) {
+	n, ok := variantNumber(w, r)
+	if !ok {
+		return
+	}
+	v, err := h.builder.Variant(r.Context(), chi.URLParam(r, "id"), n, hasAPIKey(r, h.adminKey))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (h *ExamHandler) answerKey(w http.ResponseWriter, r *http.Request) {
+	n, ok := variantNumber(w, r)
+	if !ok {
+		return
+	}
+	key, err := h.builder.AnswerKey(r.Context(), chi.URLParam(r, "id"), n)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"answers": key})
+}
+
+func (h *ExamHandler) print(w http.ResponseWriter, r *http.Request) {
+	n, ok := variantNumber(w, r)
+	if !ok {
+		return
+	}
+	answers, _ := strconv.ParseBool(r.URL.Query().Get("answers"))
+	text, err := h.builder.Printable(r.Context(), chi.URLParam(r, "id"), n, answers)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(text))
+}
+
+func (h *ExamHandler) submit(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Variant int              `json:"variant"`
+		Answers []attempt.Answer `json:"answers"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	e, err := h.builder.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	result, err := h.recorder.SubmitExam(r.Context(), e, body.Variant, r.Header.Get("X-User-ID"), body.Answers, !hasAPIKey(r, h.adminKey))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, result)
+}
+
+func variantNumber(w http.ResponseWriter, r *http.Request) (int, bool) {
+	n, err := strconv.Atoi(chi.URLParam(r, "n"))
+	if err != nil || n < 1 {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "variant must be a positive integer", err)
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+
+	"learnforge/internal/experiment"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ExperimentHandler exposes experiments and their per-variant reports.
+// Every route requires the admin API key.
+type ExperimentHandler struct {
+	manager  *experiment.Manager
+	adminKey string
+}
+
+func NewExperimentHandler(manager *experiment.Manager, adminKey string) *ExperimentHandler {
+	return &ExperimentHandler{
+		manager:  manager,
+		adminKey: adminKey,
+	}
+}
+
+func (h *ExperimentHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(RequireAPIKey(h.adminKey))
+		r.Get("/v1/experiments", h.listExperiments)
+		r.Get("/v1/experiments/{name}/report", h.getReport)
+	})
+}
+
+type experimentView struct {
+	Name     string        `json:"name"`
+	Variants []variantView `json:"variants"`
+}
+
+type variantView struct {
+	Name          string `json:"name"`
+	Percent       int    `json:"percent"`
+	Provider      string `json:"provider"`
+	Model         string `json:"model"`
+	PromptVersion string `json:"prompt_version"`
+}
+
+func (h *ExperimentHandler) listExperiments(w http.ResponseWriter, r *http.Request) {
+	views := make([]experimentView, 0)
+	for _, exp := range h.manager.Experiments() {
+		view := experimentView{Name: exp.Name}
+		for _, v := range exp.Variants {
+			view.Variants = append(view.Variants, variantView{
+				Name:          v.Name,
+				Percent:       v.Percent,
+				Provider:      v.Provider,
+				Model:         v.Model,
+				PromptVersion: v.PromptVersion,
+			})
+		}
+		views = append(views, view)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"experiments": views,
+	})
+}
+
+func (h *ExperimentHandler) getReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.manager.Report(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/feed"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// FeedHandler manages RSS/Atom subscriptions. Every route is an admin
+// operation since polling spends generation budget.
+type FeedHandler struct {
+	manager  *feed.Manager
+	adminKey string
+}
+
+func NewFeedHandler(manager *feed.Manager, adminKey string) *FeedHandler {
+	return &FeedHandler{
+		manager:  manager,
+		adminKey: adminKey,
+	}
+}
+
+func (h *FeedHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(RequireAPIKey(h.adminKey))
+		r.Post("/v1/admin/feeds", h.subscribe)
+		r.Get("/v1/admin/feeds", h.listFeeds)
+		r.Get("/v1/admin/feeds/{id}", h.getFeed)
+		r.Delete("/v1/admin/feeds/{id}", h.unsubscribe)
+		r.Post("/v1/admin/feeds/{id}/poll", h.poll)
+	})
+}
+
+func (h *FeedHandler) subscribe(w http.ResponseWriter, r *http.Request) {
+	var req feed.SubscribeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid request body", err)
+		return
+	}
+
+	sub, err := h.manager.Subscribe(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, sub)
+}
+
+func (h *FeedHandler) listFeeds(w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.manager.List(r.Context())
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	if feeds == nil {
+		feeds = []*domain.FeedSubscription{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"feeds": feeds})
+}
+
+func (h *FeedHandler) getFeed(w http.ResponseWriter, r *http.Request) {
+	sub, err := h.manager.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sub)
+}
+
+func (h *FeedHandler) unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := h.manager.Unsubscribe(r.Context(), chi.URLParam(r, "id")); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// poll runs a poll immediately instead of waiting for the schedule.
+func (h *FeedHandler) poll(w http.ResponseWriter, r *http.Request) {
+	sub, lessons, err := h.manager.Poll(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	if lessons == nil {
+		lessons = []domain.FeedLesson{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"feed": sub, "lessons": lessons})
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/feedback"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// FeedbackHandler takes learners' feedback on results and serves its
+// aggregates. Learners are identified by X-User-ID; reading feedback
+// requires the admin API key.
+type FeedbackHandler struct {
+	service  *feedback.Service
+	adminKey string
+}
+
+func NewFeedbackHandler(service *feedback.Service, adminKey string) *FeedbackHandler {
+	return &FeedbackHandler{
+		service:  service,
+		adminKey: adminKey,
+	}
+}
+
+func (h *FeedbackHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/v1/process/{id}/feedback", h.submit)
+
+	if h.adminKey != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(RequireAPIKey(h.adminKey))
+			r.Get("/v1/process/{id}/feedback", h.resultFeedback)
+			r.Get("/v1/admin/feedback", h.report)
+		})
+	}
+}
+
+func (h *FeedbackHandler) submit(w http.ResponseWriter, r *http.Request) {
+	var in feedback.Input
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	f, err := h.service.Submit(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), in, !hasAPIKey(r, h.adminKey))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, f)
+}
+
+func (h *FeedbackHandler) resultFeedback(w http.ResponseWriter, r *http.Request) {
+	items, err := h.service.ResultFeedback(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": items})
+}
+
+// report aggregates the feedback of the last days days, 7 by default.
+func (h *FeedbackHandler) report(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 365 {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "days must be between 1 and 365", err)
+			return
+		}
+		days = n
+	}
+	now := time.Now().UTC()
+	report, err := h.service.Report(r.Context(), now.AddDate(0, 0, -days), now)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -3,11 +3,16 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"learnforge/internal/domain"
+	"learnforge/internal/ingest"
 	"learnforge/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -18,24 +23,30 @@ type Handler struct {
 	summaryService interface {
 		LogError(ctx context.Context, err error, context map[string]string)
 	}
+	// adminKey lets staff read results that are not published yet.
+	adminKey string
 }
 
 func NewHandler(service *service.Service, summaryService interface {
 	LogError(ctx context.Context, err error, context map[string]string)
-}) *Handler {
+}, adminKey string) *Handler {
 	return &Handler{
 		service:        service,
 		summaryService: summaryService,
+		adminKey:       adminKey,
 	}
 }
 
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Post("/v1/process", h.processText)
+	r.Post("/v1/process/upload", h.processUpload)
 	r.Get("/v1/process/{id}", h.getResult)
+	r.Get("/v1/results", h.listResults)
+	r.Post("/v1/process/{id}/outcome", h.recordOutcome)
 	r.Get("/healthz", h.healthz)
 	r.Get("/readyz", h.readyz)
 	r.Get("/metrics", h.metrics)
-	
+
 	h.RegisterOpenAPIRoutes(r)
 }
 
@@ -45,8 +56,14 @@ func (h *Handler) processText(w http.ResponseWriter, r *http.Request) {
 		h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid request body", err)
 		return
 	}
+	req.APIKey = r.Header.Get("X-API-Key")
+	req.UserID = r.Header.Get("X-User-ID")
+	if err := applyIdempotencyHeader(r, &req); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
 
-	ctx := r.Context()
+	ctx := cacheContext(r)
 	response, err := h.service.ProcessText(ctx, &req)
 	if err != nil {
 		if h.summaryService != nil {
@@ -63,6 +80,89 @@ func (h *Handler) processText(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, response)
 }
 
+// processUpload accepts a multipart form with the document in "file" and
+// the optional ProcessRequest options as plain form fields.
+func (h *Handler) processUpload(w http.ResponseWriter, r *http.Request) {
+	// Allow room for the multipart envelope and form fields.
+	r.Body = http.MaxBytesReader(w, r.Body, h.service.MaxUploadBytes()+64<<10)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeError(w, http.StatusRequestEntityTooLarge, domain.ErrorCodeInvalidArgument, "uploaded file is too large", err)
+			return
+		}
+		h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid multipart form", err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "file is required", err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "failed to read uploaded file", err)
+		return
+	}
+
+	req := domain.ProcessRequest{
+		Mode:       r.FormValue("mode"),
+		Language:   r.FormValue("language"),
+		DocumentID: r.FormValue("document_id"),
+		APIKey:     r.Header.Get("X-API-Key"),
+		UserID:     r.Header.Get("X-User-ID"),
+	}
+	if v := r.FormValue("topic"); v != "" {
+		req.Topic = &v
+	}
+	if v := r.FormValue("level"); v != "" {
+		req.Level = &v
+	}
+	if v := r.FormValue("idempotency_key"); v != "" {
+		req.IdempotencyKey = &v
+	}
+	if err := applyIdempotencyHeader(r, &req); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	if v := r.FormValue("callback_url"); v != "" {
+		req.CallbackURL = v
+	}
+	if v := r.FormValue("generate_meme"); v != "" {
+		req.GenerateMeme, err = strconv.ParseBool(v)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "generate_meme must be a boolean", err)
+			return
+		}
+	}
+
+	upload := &ingest.Upload{
+		FileName: header.Filename,
+		MimeType: header.Header.Get("Content-Type"),
+		Data:     data,
+	}
+
+	ctx := cacheContext(r)
+	response, err := h.service.ProcessUpload(ctx, &req, upload)
+	if err != nil {
+		if h.summaryService != nil {
+			requestID := ctx.Value("request_id")
+			h.summaryService.LogError(ctx, err, map[string]string{
+				"request_id": fmt.Sprintf("%v", requestID),
+				"endpoint":   "/v1/process/upload",
+			})
+		}
+		h.handleServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, response)
+}
+
 func (h *Handler) getResult(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
@@ -71,7 +171,11 @@ func (h *Handler) getResult(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	response, err := h.service.GetResult(ctx, id)
+	getResult := h.service.GetPublishedResult
+	if hasAPIKey(r, h.adminKey) {
+		getResult = h.service.GetResult
+	}
+	response, err := getResult(ctx, id)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -80,6 +184,24 @@ func (h *Handler) getResult(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, response)
 }
 
+func (h *Handler) recordOutcome(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Kind  string  `json:"kind"`
+		Score float64 `json:"score"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid request body", err)
+		return
+	}
+
+	if err := h.service.RecordOutcome(r.Context(), chi.URLParam(r, "id"), body.Kind, body.Score); err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *Handler) healthz(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, map[string]string{
 		"status": "ok",
@@ -100,43 +222,25 @@ func (h *Handler) metrics(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("# Metrics endpoint\n"))
 }
 
-func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
-	domainErr, ok := err.(*domain.DomainError)
-	if !ok {
-		h.writeError(w, http.StatusInternalServerError, domain.ErrorCodeInternal, "internal server error", err)
-		return
-	}
-
-	var statusCode int
-	switch domainErr.Code {
-	case domain.ErrorCodeInvalidArgument:
-		statusCode = http.StatusBadRequest
-	case domain.ErrorCodeNotFound:
-		statusCode = http.StatusNotFound
-	case domain.ErrorCodeUpstreamTimeout:
-		statusCode = http.StatusGatewayTimeout
-	case domain.ErrorCodeUpstreamError:
-		statusCode = http.StatusBadGateway
-	default:
-		statusCode = http.StatusInternalServerError
+// cacheContext honors Cache-Control: no-cache by skipping the generation
+// cache for the request.
+func cacheContext(r *http.Request) context.Context {
+	for _, directive := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return service.WithoutCache(r.Context())
+		}
 	}
+	return r.Context()
+}
 
-	h.writeError(w, statusCode, domainErr.Code, domainErr.Message, domainErr.Err)
+func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
+	handleServiceError(w, err)
 }
 
 func (h *Handler) writeError(w http.ResponseWriter, statusCode int, code domain.ErrorCode, message string, err error) {
-	errorResponse := map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    string(code),
-			"message": message,
-		},
-	}
-
-	h.writeJSON(w, statusCode, errorResponse)
+	writeError(w, statusCode, code, message, err)
 }
 
 func (h *Handler) writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(data)
+	writeJSON(w, statusCode, data)
 }
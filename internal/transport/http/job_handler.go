@@ -0,0 +1,135 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/jobs"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// JobHandler exposes asynchronous processing: a request is queued and
+// polled instead of held open until the model answers.
+type JobHandler struct {
+	pool    *jobs.Pool
+	results resultReader
+	// adminKey reads and cancels any job, and sees unpublished results.
+	adminKey string
+}
+
+// resultReader serves results as GET /v1/process/{id} does.
+type resultReader interface {
+	GetResult(ctx context.Context, id string) (*domain.ProcessResponse, error)
+	GetPublishedResult(ctx context.Context, id string) (*domain.ProcessResponse, error)
+}
+
+func NewJobHandler(pool *jobs.Pool, results resultReader, adminKey string) *JobHandler {
+	return &JobHandler{
+		pool:     pool,
+		results:  results,
+		adminKey: adminKey,
+	}
+}
+
+func (h *JobHandler) RegisterRoutes(r chi.Router) {
+	r.Post("/v1/jobs", h.createJob)
+	r.Get("/v1/jobs/{id}", h.getJob)
+	r.Post("/v1/jobs/{id}/cancel", h.cancelJob)
+}
+
+// jobResponse embeds the result once the job has succeeded.
+type jobResponse struct {
+	*domain.Job
+	Result *domain.ProcessResponse `json:"result,omitempty"`
+}
+
+func (h *JobHandler) createJob(w http.ResponseWriter, r *http.Request) {
+	var req domain.ProcessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid request body", err)
+		return
+	}
+	req.APIKey = r.Header.Get("X-API-Key")
+	req.UserID = r.Header.Get("X-User-ID")
+	if err := applyIdempotencyHeader(r, &req); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	job, err := h.pool.Enqueue(r.Context(), &req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/v1/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, jobResponse{Job: job})
+}
+
+func (h *JobHandler) getJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.ownJob(r)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+
+	resp := jobResponse{Job: job}
+	if job.Status == domain.JobSucceeded {
+		getResult := h.results.GetPublishedResult
+		if hasAPIKey(r, h.adminKey) {
+			getResult = h.results.GetResult
+		}
+		resp.Result, err = getResult(r.Context(), job.ResultID)
+		if err != nil && !domain.HasCode(err, domain.ErrorCodeNotFound) {
+			handleServiceError(w, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *JobHandler) cancelJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.ownJob(r)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	job, err = h.pool.Cancel(r.Context(), job.ID)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	// A running job stops at its worker's next heartbeat.
+	status := http.StatusOK
+	if job.Status == domain.JobRunning {
+		status = http.StatusAccepted
+	}
+	writeJSON(w, status, jobResponse{Job: job})
+}
+
+// ownJob returns the job in the URL if the caller submitted it: jobs are
+// scoped like idempotency keys, to the API key or else the user. Other
+// callers get not found, so job IDs reveal nothing.
+func (h *JobHandler) ownJob(r *http.Request) (*domain.Job, error) {
+	job, err := h.pool.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		return nil, err
+	}
+	if hasAPIKey(r, h.adminKey) {
+		return job, nil
+	}
+	caller := &domain.ProcessRequest{APIKey: r.Header.Get("X-API-Key"), UserID: r.Header.Get("X-User-ID")}
+	owned := true
+	switch {
+	case job.APIKeyHash != "":
+		owned = caller.KeyHash() == job.APIKeyHash
+	case job.UserID != "":
+		owned = caller.UserID == job.UserID
+	}
+	if !owned {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "job not found", nil)
+	}
+	return job, nil
+}
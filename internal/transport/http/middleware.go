@@ -2,11 +2,14 @@ package http
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"log"
 	"net/http"
 	"time"
 
+	"learnforge/internal/domain"
+
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
@@ -114,6 +117,20 @@ func MetricsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// RequireAPIKey rejects requests whose X-API-Key header does not match key
+func RequireAPIKey(key string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := r.Header.Get("X-API-Key")
+			if apiKey == "" || subtle.ConstantTimeCompare([]byte(apiKey), []byte(key)) != 1 {
+				writeError(w, http.StatusUnauthorized, domain.ErrorCodeInvalidArgument, "invalid or missing API key", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // RecordAIRequest records metrics for an AI request
 func RecordAIRequest(duration time.Duration, status string) {
 	aiRequestsTotal.WithLabelValues(status).Inc()
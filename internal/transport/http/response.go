@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"learnforge/internal/domain"
+)
+
+func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code domain.ErrorCode, message string, err error) {
+	errorResponse := map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    string(code),
+			"message": message,
+		},
+	}
+	writeJSON(w, statusCode, errorResponse)
+}
+
+func handleServiceError(w http.ResponseWriter, err error) {
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) {
+		writeError(w, http.StatusInternalServerError, domain.ErrorCodeInternal, "internal server error", err)
+		return
+	}
+
+	var statusCode int
+	switch domainErr.Code {
+	case domain.ErrorCodeInvalidArgument:
+		statusCode = http.StatusBadRequest
+	case domain.ErrorCodeNotFound:
+		statusCode = http.StatusNotFound
+	case domain.ErrorCodeConflict:
+		statusCode = http.StatusConflict
+	case domain.ErrorCodeUpstreamTimeout:
+		statusCode = http.StatusGatewayTimeout
+	case domain.ErrorCodeUpstreamError:
+		statusCode = http.StatusBadGateway
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	writeError(w, statusCode, domainErr.Code, domainErr.Message, domainErr.Err)
+}
+
+// applyIdempotencyHeader copies the Idempotency-Key header into req. The
+// header and the idempotency_key field may both be sent only if they agree.
+func applyIdempotencyHeader(r *http.Request, req *domain.ProcessRequest) error {
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		return nil
+	}
+	if req.IdempotencyKey != nil && *req.IdempotencyKey != "" && *req.IdempotencyKey != key {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "Idempotency-Key header and idempotency_key differ", nil)
+	}
+	req.IdempotencyKey = &key
+	return nil
+}
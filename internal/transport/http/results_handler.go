@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"learnforge/internal/domain"
+)
+
+// listResults serves GET /v1/results. Without the admin API key only
+// results visible to learners are listed.
+func (h *Handler) listResults(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := domain.ResultQuery{
+		Topic:    params.Get("topic"),
+		Mode:     params.Get("mode"),
+		Level:    params.Get("level"),
+		Language: params.Get("language"),
+		Provider: params.Get("provider"),
+		Status:   params.Get("status"),
+		Search:   params.Get("q"),
+		Sort:     params.Get("sort"),
+	}
+	var err error
+	if query.From, err = parseTimeParam(params.Get("from"), false); err != nil {
+		h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "from must be a date (YYYY-MM-DD) or RFC 3339 time", err)
+		return
+	}
+	if query.To, err = parseTimeParam(params.Get("to"), true); err != nil {
+		h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "to must be a date (YYYY-MM-DD) or RFC 3339 time", err)
+		return
+	}
+	if v := params.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit < 1 {
+			h.writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "limit must be a positive integer", err)
+			return
+		}
+	}
+
+	list := h.service.ListPublishedResults
+	if hasAPIKey(r, h.adminKey) {
+		list = h.service.ListResults
+	}
+	page, err := list(r.Context(), query, params.Get("cursor"))
+	if err != nil {
+		h.handleServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, page)
+}
+
+// parseTimeParam accepts a date or an RFC 3339 time. A date used as an
+// upper bound includes the whole day.
+func parseTimeParam(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/review"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ReviewHandler exposes the review workflow. Every route requires the
+// admin API key and acts on behalf of the user in X-User-ID.
+type ReviewHandler struct {
+	workflow *review.Workflow
+	adminKey string
+}
+
+func NewReviewHandler(workflow *review.Workflow, adminKey string) *ReviewHandler {
+	return &ReviewHandler{
+		workflow: workflow,
+		adminKey: adminKey,
+	}
+}
+
+func (h *ReviewHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(RequireAPIKey(h.adminKey))
+		r.Get("/v1/admin/review-queue", h.queue)
+		r.Get("/v1/process/{id}/review", h.getReview)
+		r.Post("/v1/process/{id}/review/submit", h.action(h.workflow.Submit))
+		r.Post("/v1/process/{id}/review/approve", h.action(h.workflow.Approve))
+		r.Post("/v1/process/{id}/review/reject", h.action(h.workflow.Reject))
+		r.Post("/v1/process/{id}/review/reopen", h.action(h.workflow.Reopen))
+		r.Post("/v1/process/{id}/review/comments", h.action(h.workflow.Comment))
+	})
+}
+
+func (h *ReviewHandler) queue(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "limit must be between 1 and 500", err)
+			return
+		}
+		limit = n
+	}
+	entries, err := h.workflow.Queue(r.Context(), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": entries})
+}
+
+func (h *ReviewHandler) getReview(w http.ResponseWriter, r *http.Request) {
+	rev, err := h.workflow.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rev)
+}
+
+// action adapts a workflow step to a handler. The body is optional and
+// carries {"comment": "..."}.
+func (h *ReviewHandler) action(step func(ctx context.Context, id, actor, comment string) (*review.Review, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Comment string `json:"comment"`
+		}
+		if r.ContentLength != 0 && !decodeBody(w, r, &body) {
+			return
+		}
+		rev, err := step(r.Context(), chi.URLParam(r, "id"), r.Header.Get("X-User-ID"), body.Comment)
+		if err != nil {
+			handleServiceError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, rev)
+	}
+}
+
+// hasAPIKey reports whether r carries key. It is false when key is empty.
+func hasAPIKey(r *http.Request, key string) bool {
+	apiKey := r.Header.Get("X-API-Key")
+	return key != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(key)) == 1
+}
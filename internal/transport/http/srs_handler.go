@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/srs"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// SRSHandler exposes spaced-repetition study of flashcards for the
+// learner in X-User-ID.
+type SRSHandler struct {
+	service  *srs.Service
+	adminKey string
+}
+
+func NewSRSHandler(service *srs.Service, adminKey string) *SRSHandler {
+	return &SRSHandler{
+		service:  service,
+		adminKey: adminKey,
+	}
+}
+
+func (h *SRSHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/v1/reviews/due", h.due)
+	r.Post("/v1/reviews", h.review)
+}
+
+func (h *SRSHandler) due(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := srs.DueQuery{
+		DeckID:   q.Get("deck_id"),
+		ResultID: q.Get("result_id"),
+		NewCards: srs.DefaultNewCards,
+	}
+	var err error
+	if v := q.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil || query.Limit < 1 {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "limit must be a positive integer", err)
+			return
+		}
+	}
+	if v := q.Get("new"); v != "" {
+		if query.NewCards, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "new must be an integer", err)
+			return
+		}
+	}
+
+	cards, err := h.service.Due(r.Context(), r.Header.Get("X-User-ID"), query, !hasAPIKey(r, h.adminKey))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"cards": cards})
+}
+
+func (h *SRSHandler) review(w http.ResponseWriter, r *http.Request) {
+	var in srs.ReviewInput
+	if !decodeBody(w, r, &in) {
+		return
+	}
+	state, err := h.service.Review(r.Context(), r.Header.Get("X-User-ID"), in, !hasAPIKey(r, h.adminKey))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
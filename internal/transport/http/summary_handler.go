@@ -1,7 +1,6 @@
 package http
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -67,17 +66,9 @@ func (h *SummaryHandler) generateSummary(w http.ResponseWriter, r *http.Request)
 }
 
 func (h *SummaryHandler) writeError(w http.ResponseWriter, statusCode int, code domain.ErrorCode, message string, err error) {
-	errorResponse := map[string]interface{}{
-		"error": map[string]interface{}{
-			"code":    string(code),
-			"message": message,
-		},
-	}
-	h.writeJSON(w, statusCode, errorResponse)
+	writeError(w, statusCode, code, message, err)
 }
 
 func (h *SummaryHandler) writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(data)
+	writeJSON(w, statusCode, data)
 }
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/webhook"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// WebhookHandler manages webhook endpoints and the delivery log.
+type WebhookHandler struct {
+	dispatcher *webhook.Dispatcher
+	adminKey   string
+}
+
+func NewWebhookHandler(dispatcher *webhook.Dispatcher, adminKey string) *WebhookHandler {
+	return &WebhookHandler{
+		dispatcher: dispatcher,
+		adminKey:   adminKey,
+	}
+}
+
+func (h *WebhookHandler) RegisterRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(RequireAPIKey(h.adminKey))
+		r.Post("/v1/admin/webhooks", h.register)
+		r.Get("/v1/admin/webhooks", h.listWebhooks)
+		r.Get("/v1/admin/webhooks/deliveries", h.listDeliveries)
+		r.Get("/v1/admin/webhooks/deliveries/{id}", h.getDelivery)
+		r.Post("/v1/admin/webhooks/deliveries/{id}/replay", h.replay)
+		r.Get("/v1/admin/webhooks/{id}", h.getWebhook)
+		r.Delete("/v1/admin/webhooks/{id}", h.unregister)
+	})
+}
+
+func (h *WebhookHandler) register(w http.ResponseWriter, r *http.Request) {
+	var req webhook.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "invalid request body", err)
+		return
+	}
+
+	endpoint, err := h.dispatcher.Register(r.Context(), req)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, endpoint)
+}
+
+func (h *WebhookHandler) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.dispatcher.List(r.Context())
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	if endpoints == nil {
+		endpoints = []*domain.WebhookEndpoint{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"webhooks": endpoints})
+}
+
+func (h *WebhookHandler) getWebhook(w http.ResponseWriter, r *http.Request) {
+	endpoint, err := h.dispatcher.Get(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, endpoint)
+}
+
+func (h *WebhookHandler) unregister(w http.ResponseWriter, r *http.Request) {
+	if err := h.dispatcher.Unregister(r.Context(), chi.URLParam(r, "id")); err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listDeliveries returns the delivery log, newest first, filtered by the
+// optional endpoint_id and status query parameters.
+func (h *WebhookHandler) listDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	status := domain.DeliveryStatus(query.Get("status"))
+	switch status {
+	case "", domain.DeliveryPending, domain.DeliveryDelivered, domain.DeliveryFailed:
+	default:
+		writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "status must be one of: pending, delivered, failed", nil)
+		return
+	}
+
+	limit := 50
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			writeError(w, http.StatusBadRequest, domain.ErrorCodeInvalidArgument, "limit must be between 1 and 500", err)
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.dispatcher.Deliveries(r.Context(), query.Get("endpoint_id"), status, limit)
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []*domain.WebhookDelivery{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) getDelivery(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.dispatcher.Delivery(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, delivery)
+}
+
+// replay resends a delivered or failed delivery and returns it with the
+// new attempt.
+func (h *WebhookHandler) replay(w http.ResponseWriter, r *http.Request) {
+	delivery, err := h.dispatcher.Replay(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		handleServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, delivery)
+}
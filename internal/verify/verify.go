@@ -0,0 +1,139 @@
+// Package verify independently re-answers generated quiz items from the
+// source text and flags items whose keyed answer is wrong or ambiguous.
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"learnforge/internal/ai"
+	"learnforge/internal/domain"
+)
+
+// Actions applied to flagged quiz items
+const (
+	ActionMark       = "mark"
+	ActionDrop       = "drop"
+	ActionRegenerate = "regenerate"
+)
+
+// ValidAction reports whether action is a supported verification action.
+func ValidAction(action string) bool {
+	return action == ActionMark || action == ActionDrop || action == ActionRegenerate
+}
+
+// Verdict is the verifier's independent answer to one quiz item.
+type Verdict struct {
+	Index      int      `json:"index"`
+	Answer     string   `json:"answer"`
+	Defensible []string `json:"defensible"`
+	Reason     string   `json:"reason"`
+	Flagged    bool     `json:"-"`
+	Note       string   `json:"-"`
+}
+
+type Verifier struct {
+	completer ai.Completer
+	action    string
+}
+
+func NewVerifier(completer ai.Completer, action string) *Verifier {
+	return &Verifier{
+		completer: completer,
+		action:    action,
+	}
+}
+
+func (v *Verifier) Action() string {
+	return v.action
+}
+
+// Check answers every item using only the source text and returns one
+// verdict per item, in order.
+func (v *Verifier) Check(ctx context.Context, source string, items []domain.QuizItem) ([]Verdict, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	type promptItem struct {
+		Index   int      `json:"index"`
+		Q       string   `json:"q"`
+		Choices []string `json:"choices"`
+	}
+	promptItems := make([]promptItem, len(items))
+	for i, item := range items {
+		promptItems[i] = promptItem{Index: i, Q: item.Q, Choices: item.Choices}
+	}
+	itemsJSON, err := json.Marshal(promptItems)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quiz items: %w", err)
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("You are checking multiple-choice questions. Use ONLY the source text below; do not use outside knowledge.\n\n")
+	prompt.WriteString("Source text:\n")
+	prompt.WriteString(source)
+	prompt.WriteString("\n\nQuestions (JSON):\n")
+	prompt.Write(itemsJSON)
+	prompt.WriteString("\n\nFor each question, pick the single best choice according to the source. ")
+	prompt.WriteString("List in \"defensible\" every choice that the source text could reasonably support as correct. ")
+	prompt.WriteString("If the source does not answer the question, set \"answer\" to an empty string.\n\n")
+	prompt.WriteString(`Respond ONLY with JSON: {"items": [{"index": 0, "answer": "exact choice text", "defensible": ["exact choice text"], "reason": "short explanation"}]}`)
+
+	reply, err := v.completer.Complete(ctx, prompt.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Items []Verdict `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(reply), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse verifier reply: %w", err)
+	}
+
+	verdicts := make([]Verdict, len(items))
+	for i := range verdicts {
+		verdicts[i] = Verdict{Index: i, Flagged: true, Note: "verifier returned no answer"}
+	}
+	for _, verdict := range parsed.Items {
+		if verdict.Index < 0 || verdict.Index >= len(items) {
+			continue
+		}
+		judge(&verdict, items[verdict.Index])
+		verdicts[verdict.Index] = verdict
+	}
+	return verdicts, nil
+}
+
+func judge(verdict *Verdict, item domain.QuizItem) {
+	switch {
+	case verdict.Answer == "":
+		verdict.Flagged = true
+		verdict.Note = "source text does not answer the question"
+	case !domain.SameChoice(verdict.Answer, item.Answer):
+		verdict.Flagged = true
+		verdict.Note = fmt.Sprintf("verifier answered %q", verdict.Answer)
+	case len(distinct(verdict.Defensible)) > 1:
+		verdict.Flagged = true
+		verdict.Note = fmt.Sprintf("several choices are defensible: %s", strings.Join(verdict.Defensible, "; "))
+	}
+	if verdict.Flagged && verdict.Reason != "" {
+		verdict.Note += " (" + verdict.Reason + ")"
+	}
+}
+
+func distinct(values []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, v := range values {
+		n := domain.NormalizeText(domain.ChoiceText(v))
+		if n != "" && !seen[n] {
+			seen[n] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,45 @@
+package verify
+
+import (
+	"context"
+	"testing"
+
+	"learnforge/internal/domain"
+)
+
+type stubCompleter struct {
+	reply string
+}
+
+func (s *stubCompleter) Complete(ctx context.Context, prompt string) (string, error) {
+	return s.reply, nil
+}
+
+func TestVerifier_Check(t *testing.T) {
+	items := []domain.QuizItem{
+		{Q: "Who replicates the log?", Choices: []string{"Leader", "Follower"}, Answer: "Leader"},
+		{Q: "Who starts elections?", Choices: []string{"Leader", "Candidate"}, Answer: "Leader"},
+		{Q: "What is committed?", Choices: []string{"Majority entries", "Replicated entries"}, Answer: "Majority entries"},
+		{Q: "Unanswered?", Choices: []string{"A", "B"}, Answer: "A"},
+		{Q: "Who votes?", Choices: []string{"A. Leader", "B. Followers"}, Answer: "B. Followers"},
+	}
+
+	completer := &stubCompleter{reply: `{"items": [
+		{"index": 0, "answer": "leader", "defensible": ["Leader"]},
+		{"index": 1, "answer": "Candidate", "defensible": ["Candidate"]},
+		{"index": 2, "answer": "Majority entries", "defensible": ["Majority entries", "Replicated entries"]},
+		{"index": 4, "answer": "Followers", "defensible": ["B. Followers", "Followers"]}
+	]}`}
+
+	verdicts, err := NewVerifier(completer, ActionMark).Check(context.Background(), "source", items)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	want := []bool{false, true, true, true, false}
+	for i, flagged := range want {
+		if verdicts[i].Flagged != flagged {
+			t.Errorf("item %d: flagged = %v, want %v (%s)", i, verdicts[i].Flagged, flagged, verdicts[i].Note)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+// Package webhook delivers signed result events to registered endpoints
+// and per-request callback URLs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"learnforge/internal/domain"
+	"learnforge/internal/ingest"
+	"learnforge/internal/store"
+
+	"github.com/google/uuid"
+)
+
+// Headers set on every delivery. The signature is
+// "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body)).
+const (
+	HeaderEvent     = "X-LearnForge-Event"
+	HeaderDelivery  = "X-LearnForge-Delivery"
+	HeaderTimestamp = "X-LearnForge-Timestamp"
+	HeaderSignature = "X-LearnForge-Signature"
+)
+
+// Options tunes delivery. Zero values fall back to the defaults.
+type Options struct {
+	// Secret signs deliveries to request callback URLs. Callbacks are
+	// rejected when it is empty.
+	Secret string
+	// MaxAttempts is the number of attempts before a delivery is marked
+	// failed. Retries back off exponentially from RetryBackoff.
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	Timeout      time.Duration
+	PollInterval time.Duration
+	// AllowPrivateNetworks disables the SSRF address checks. Only for tests
+	// and trusted deployments.
+	AllowPrivateNetworks bool
+}
+
+const (
+	DefaultMaxAttempts  = 8
+	DefaultRetryBackoff = 30 * time.Second
+
+	defaultTimeout      = 10 * time.Second
+	defaultPollInterval = 2 * time.Second
+	maxBackoff          = 6 * time.Hour
+	claimBatch          = 20
+)
+
+// Dispatcher records deliveries and sends them in the background. A nil
+// *Dispatcher drops events.
+type Dispatcher struct {
+	store  store.WebhookStore
+	opts   Options
+	client *http.Client
+	now    func() time.Time
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(store store.WebhookStore, opts Options) *Dispatcher {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultRetryBackoff
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+
+	client := ingest.NewHTTPClient(ingest.FetchOptions{
+		Timeout:              opts.Timeout,
+		MaxRedirects:         1,
+		AllowPrivateNetworks: opts.AllowPrivateNetworks,
+	})
+	// A redirect is reported as a failed attempt rather than followed, so
+	// the signed body is only ever sent to the registered URL.
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &Dispatcher{
+		store:  store,
+		opts:   opts,
+		client: client,
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Start launches the delivery loop.
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.loop(ctx)
+	}()
+	log.Println(`{"level":"info","msg":"Webhook dispatcher started"}`)
+}
+
+// Stop interrupts in-flight deliveries and waits for the loop to exit.
+// Pending deliveries are sent after the next start, by this or another
+// instance.
+func (d *Dispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	d.wg.Wait()
+	log.Println(`{"level":"info","msg":"Webhook dispatcher stopped"}`)
+}
+
+// RegisterRequest describes a new endpoint.
+type RegisterRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// Register stores an endpoint with a new signing secret. The returned
+// endpoint is the only place the secret is shown.
+func (d *Dispatcher) Register(ctx context.Context, req RegisterRequest) (*domain.WebhookEndpoint, error) {
+	if err := validateURL(req.URL, "url"); err != nil {
+		return nil, err
+	}
+	for _, event := range req.Events {
+		if !domain.ValidateEventType(event) {
+			return nil, domain.NewDomainError(domain.ErrorCodeInvalidArgument,
+				fmt.Sprintf("unknown event %q; must be one of: %s, %s, %s", event,
+					domain.EventResultCompleted, domain.EventResultUpdated, domain.EventResultFailed), nil)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeInternal, "failed to generate webhook secret", err)
+	}
+
+	endpoint := &domain.WebhookEndpoint{
+		ID:          uuid.New().String(),
+		URL:         req.URL,
+		Secret:      "whsec_" + hex.EncodeToString(secret),
+		Events:      req.Events,
+		Description: req.Description,
+		CreatedAt:   d.now().UTC(),
+	}
+	if endpoint.Events == nil {
+		endpoint.Events = []string{}
+	}
+	if err := d.store.SaveWebhook(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func (d *Dispatcher) Get(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	endpoint, err := d.store.GetWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = ""
+	return endpoint, nil
+}
+
+func (d *Dispatcher) List(ctx context.Context) ([]*domain.WebhookEndpoint, error) {
+	endpoints, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, endpoint := range endpoints {
+		endpoint.Secret = ""
+	}
+	return endpoints, nil
+}
+
+// Unregister deletes an endpoint. Its pending deliveries fail.
+func (d *Dispatcher) Unregister(ctx context.Context, id string) error {
+	return d.store.DeleteWebhook(ctx, id)
+}
+
+func (d *Dispatcher) Deliveries(ctx context.Context, endpointID string, status domain.DeliveryStatus, limit int) ([]*domain.WebhookDelivery, error) {
+	return d.store.ListDeliveries(ctx, endpointID, status, limit)
+}
+
+func (d *Dispatcher) Delivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	return d.store.GetDelivery(ctx, id)
+}
+
+// ValidateCallback checks a request's callback_url before the request is
+// processed.
+func (d *Dispatcher) ValidateCallback(rawURL string) error {
+	if d == nil || d.opts.Secret == "" {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, "callback_url is not enabled", nil)
+	}
+	return validateURL(rawURL, "callback_url")
+}
+
+// Emit queues event for every subscribed endpoint and for callbackURL.
+// Failures are logged; an event is never worth failing a request over.
+func (d *Dispatcher) Emit(ctx context.Context, event *domain.WebhookEvent, callbackURL string) {
+	if d == nil {
+		return
+	}
+	endpoints, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		log.Printf(`{"level":"error","msg":"Failed to list webhooks","event":"%s","error":"%v"}`, event.Type, err)
+	}
+	var targets []*domain.WebhookEndpoint
+	for _, endpoint := range endpoints {
+		if endpoint.Subscribed(event.Type) {
+			targets = append(targets, endpoint)
+		}
+	}
+	d.enqueue(ctx, event, targets, callbackURL)
+}
+
+// Callback queues event for callbackURL only, for requests answered from
+// an existing result.
+func (d *Dispatcher) Callback(ctx context.Context, event *domain.WebhookEvent, callbackURL string) {
+	if d == nil {
+		return
+	}
+	d.enqueue(ctx, event, nil, callbackURL)
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, event *domain.WebhookEvent, endpoints []*domain.WebhookEndpoint, callbackURL string) {
+	if len(endpoints) == 0 && callbackURL == "" {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf(`{"level":"error","msg":"Failed to marshal webhook event","event":"%s","error":"%v"}`, event.Type, err)
+		return
+	}
+
+	now := d.now().UTC()
+	newDelivery := func(endpointID, target string) *domain.WebhookDelivery {
+		return &domain.WebhookDelivery{
+			ID:            uuid.New().String(),
+			EventID:       event.ID,
+			EventType:     event.Type,
+			EndpointID:    endpointID,
+			URL:           target,
+			Payload:       payload,
+			Status:        domain.DeliveryPending,
+			Attempts:      []domain.DeliveryAttempt{},
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, endpoint := range endpoints {
+		deliveries = append(deliveries, newDelivery(endpoint.ID, endpoint.URL))
+	}
+	if callbackURL != "" {
+		deliveries = append(deliveries, newDelivery("", callbackURL))
+	}
+	for _, delivery := range deliveries {
+		if err := d.store.SaveDelivery(ctx, delivery); err != nil {
+			log.Printf(`{"level":"error","msg":"Failed to queue webhook delivery","event":"%s","url":"%s","error":"%v"}`, event.Type, delivery.URL, err)
+		}
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Replay sends a finished delivery once more, immediately. The attempt
+// is added to the delivery's log; a failed replay is not retried.
+func (d *Dispatcher) Replay(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	delivery, err := d.store.GetDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == domain.DeliveryPending {
+		return nil, domain.NewDomainError(domain.ErrorCodeConflict, "delivery is still being retried", nil)
+	}
+
+	attempt, _ := d.send(ctx, delivery)
+	attempt.Replay = true
+	d.record(delivery, attempt, true)
+	if err := d.store.SaveDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func (d *Dispatcher) loop(ctx context.Context) {
+	for ctx.Err() == nil {
+		lease := d.opts.Timeout*claimBatch + time.Minute
+		deliveries, err := d.store.ClaimDeliveries(ctx, d.now().Add(lease), claimBatch)
+		if err != nil && ctx.Err() == nil {
+			log.Printf(`{"level":"error","msg":"Failed to claim webhook deliveries","error":"%v"}`, err)
+		}
+		for _, delivery := range deliveries {
+			attempt, permanent := d.send(ctx, delivery)
+			d.record(delivery, attempt, permanent)
+			// Save even after shutdown began so the attempt is not lost.
+			if err := d.store.SaveDelivery(context.Background(), delivery); err != nil {
+				log.Printf(`{"level":"error","msg":"Failed to save webhook delivery","delivery_id":"%s","error":"%v"}`, delivery.ID, err)
+			}
+		}
+		if len(deliveries) == claimBatch {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-d.wake:
+		case <-time.After(d.opts.PollInterval):
+		}
+	}
+}
+
+// record appends attempt and moves the delivery to its next state. A
+// failed final attempt is not retried.
+func (d *Dispatcher) record(delivery *domain.WebhookDelivery, attempt domain.DeliveryAttempt, final bool) {
+	now := d.now().UTC()
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.UpdatedAt = now
+
+	switch {
+	case attempt.Error == "":
+		delivery.Status = domain.DeliveryDelivered
+		delivery.DeliveredAt = &now
+	case final || len(delivery.Attempts) >= d.opts.MaxAttempts:
+		delivery.Status = domain.DeliveryFailed
+	default:
+		delivery.Status = domain.DeliveryPending
+		delivery.NextAttemptAt = now.Add(d.backoff(len(delivery.Attempts)))
+	}
+	if delivery.Status != domain.DeliveryDelivered {
+		log.Printf(`{"level":"warn","msg":"Webhook delivery failed","delivery_id":"%s","url":"%s","attempt":%d,"error":"%s"}`,
+			delivery.ID, delivery.URL, len(delivery.Attempts), attempt.Error)
+	}
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.opts.RetryBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// send makes one HTTP attempt. Any 2xx response counts as delivered.
+// permanent reports failures that retrying cannot fix.
+func (d *Dispatcher) send(ctx context.Context, delivery *domain.WebhookDelivery) (attempt domain.DeliveryAttempt, permanent bool) {
+	start := d.now()
+	attempt.At = start.UTC()
+	defer func() {
+		attempt.DurationMS = time.Since(start).Milliseconds()
+	}()
+
+	secret, err := d.secretFor(ctx, delivery)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, errors.Is(err, errEndpointDeleted)
+	}
+
+	timestamp := start.Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, true
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "LearnForge-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, false
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("endpoint responded with HTTP %d", resp.StatusCode)
+	}
+	return attempt, false
+}
+
+var errEndpointDeleted = errors.New("webhook endpoint was deleted")
+
+func (d *Dispatcher) secretFor(ctx context.Context, delivery *domain.WebhookDelivery) (string, error) {
+	if delivery.EndpointID == "" {
+		if d.opts.Secret == "" {
+			return "", errors.New("webhook secret is not configured")
+		}
+		return d.opts.Secret, nil
+	}
+	endpoint, err := d.store.GetWebhook(ctx, delivery.EndpointID)
+	if err != nil {
+		if domain.HasCode(err, domain.ErrorCodeNotFound) {
+			return "", errEndpointDeleted
+		}
+		return "", err
+	}
+	return endpoint.Secret, nil
+}
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func validateURL(rawURL, field string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return domain.NewDomainError(domain.ErrorCodeInvalidArgument, field+" must be an absolute http or https URL without credentials", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"time"
+
+	"learnforge/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ResultEvent describes a stored result. jobID is set when the result
+// was generated by a background job.
+func ResultEvent(eventType string, resp *domain.ProcessResponse, jobID string) *domain.WebhookEvent {
+	return &domain.WebhookEvent{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data: domain.WebhookEventData{
+			ResultID:   resp.ID,
+			JobID:      jobID,
+			DocumentID: resp.DocumentID,
+			Revision:   resp.Revision,
+			Result:     resp,
+		},
+	}
+}
+
+// FailureEvent describes a request that will not produce a result. jobID
+// is set when the request ran as a background job.
+func FailureEvent(req *domain.ProcessRequest, jobID string, err error) *domain.WebhookEvent {
+	failure := &domain.WebhookError{Code: domain.CodeOf(err), Message: domain.Message(err)}
+	return &domain.WebhookEvent{
+		ID:        uuid.New().String(),
+		Type:      domain.EventResultFailed,
+		CreatedAt: time.Now().UTC(),
+		Data: domain.WebhookEventData{
+			JobID:      jobID,
+			DocumentID: req.DocumentID,
+			Error:      failure,
+		},
+	}
+}